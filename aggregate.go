@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// Numeric is the set of types an aggregate can be scanned into via [Sum],
+// [Avg], [Min], and [Max].
+type Numeric interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// aggregate selects the given aggregate expression from the store's table with
+// the given query options, and scans the single value returned. The zero value
+// is returned if the aggregate is NULL, as is the case when no rows match.
+func aggregate[T Numeric, M Model](ctx context.Context, s *Store[M], expr query.Expr, opts ...query.Option) (T, error) {
+	var v sql.Null[T]
+
+	q := s.selectQuery(ctx, expr, opts...)
+
+	rows, err := s.query(ctx, s.table, OpSelect, q)
+
+	if err != nil {
+		return v.V, err
+	}
+
+	defer rows.Close()
+
+	if rows.Next() {
+		if err := rows.Scan(&v); err != nil {
+			return v.V, err
+		}
+	}
+	return v.V, rows.Err()
+}
+
+// Sum returns the sum of the given column of the models in the store that
+// match the given query options. Since methods cannot have type parameters,
+// this is a function that takes the store, with the type to scan the sum into
+// given explicitly, for example,
+//
+//	views, err := database.Sum[int64](ctx, posts, "views", query.WhereEq("user_id", query.Arg(1)))
+//
+// Zero is returned if no models match.
+func Sum[T Numeric, M Model](ctx context.Context, s *Store[M], col string, opts ...query.Option) (T, error) {
+	return aggregate[T](ctx, s, query.Sum(query.Ident(col)), opts...)
+}
+
+// Avg returns the average of the given column of the models in the store that
+// match the given query options, as per [Sum]. Typically T would be a float64,
+// since the average of an integer column is not always an integer.
+func Avg[T Numeric, M Model](ctx context.Context, s *Store[M], col string, opts ...query.Option) (T, error) {
+	return aggregate[T](ctx, s, query.Avg(query.Ident(col)), opts...)
+}
+
+// Min returns the minimum of the given column of the models in the store that
+// match the given query options, as per [Sum].
+func Min[T Numeric, M Model](ctx context.Context, s *Store[M], col string, opts ...query.Option) (T, error) {
+	return aggregate[T](ctx, s, query.Min(query.Ident(col)), opts...)
+}
+
+// Max returns the maximum of the given column of the models in the store that
+// match the given query options, as per [Sum].
+func Max[T Numeric, M Model](ctx context.Context, s *Store[M], col string, opts ...query.Option) (T, error) {
+	return aggregate[T](ctx, s, query.Max(query.Ident(col)), opts...)
+}
+
+// Aggregate groups the models in the store that match the given query options
+// by the given columns, and scans each group into a struct of type T via
+// [Scanner.ScanStruct]. The given aggregate expressions are selected after
+// the grouped columns, and would be aliased via [query.As] so they can be
+// mapped to the fields of T, for example,
+//
+//	type PostCount struct {
+//	    UserID int64
+//	    Posts  int64
+//	}
+//
+//	counts, err := database.Aggregate[PostCount](
+//	    ctx,
+//	    posts,
+//	    []string{"user_id"},
+//	    []query.Expr{query.As(query.Count("*"), "posts")},
+//	    query.OrderDesc("posts"),
+//	)
+//
+// Like [Sum], this is a function that takes the store, since methods cannot
+// have type parameters.
+func Aggregate[T any, M Model](ctx context.Context, s *Store[M], groupCols []string, aggExprs []query.Expr, opts ...query.Option) ([]T, error) {
+	exprs := make([]query.Expr, 0, len(groupCols)+len(aggExprs))
+
+	for _, col := range groupCols {
+		exprs = append(exprs, query.Ident(col))
+	}
+	exprs = append(exprs, aggExprs...)
+
+	if len(groupCols) > 0 {
+		opts = append(opts, query.GroupBy(groupCols...))
+	}
+
+	q := s.selectQuery(ctx, query.Exprs(exprs...), opts...)
+
+	rows, err := s.query(ctx, s.table, OpSelect, q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	sc, err := s.newScanner(rows.Rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tt := make([]T, 0)
+
+	for rows.Next() {
+		var t T
+
+		if err := sc.ScanStruct(&t); err != nil {
+			return nil, err
+		}
+		tt = append(tt, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tt, nil
+}
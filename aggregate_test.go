@@ -0,0 +1,128 @@
+package database
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestAggregate(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	})
+
+	for i, n := range []int{1, 2, 6} {
+		m := &M{
+			ID:   int64(i + 1),
+			Int:  n,
+			Blob: []byte{},
+			Time: time.Now(),
+		}
+
+		if err := store.Create(ctx, m); err != nil {
+			t.Fatalf("store.Create(ctx, m): %v\n", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		fn   func() (float64, error)
+		want float64
+	}{
+		{"Sum", func() (float64, error) { return Sum[float64](ctx, store, "int") }, 9},
+		{"Avg", func() (float64, error) { return Avg[float64](ctx, store, "int") }, 3},
+		{"Min", func() (float64, error) { return Min[float64](ctx, store, "int") }, 1},
+		{"Max", func() (float64, error) { return Max[float64](ctx, store, "int") }, 6},
+		{"Max", func() (float64, error) { return Max[float64](ctx, store, "int", query.WhereLt("int", query.Arg(6))) }, 2},
+		{"Sum", func() (float64, error) { return Sum[float64](ctx, store, "int", query.WhereGt("int", query.Arg(6))) }, 0},
+	}
+
+	for i, test := range tests {
+		got, err := test.fn()
+
+		if err != nil {
+			t.Fatalf("tests[%d] - %s(ctx, store, %q): %v\n", i, test.name, "int", err)
+		}
+
+		if got != test.want {
+			t.Fatalf("tests[%d] - %s(ctx, store, %q) = %v, want = %v\n", i, test.name, "int", got, test.want)
+		}
+	}
+
+	n, err := Sum[int64](ctx, store, "int")
+
+	if err != nil {
+		t.Fatalf("Sum[int64](ctx, store, %q): %v\n", "int", err)
+	}
+
+	if n != 9 {
+		t.Fatalf("Sum[int64](ctx, store, %q) = %v, want = %v\n", "int", n, 9)
+	}
+}
+
+func TestAggregateGroupBy(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	})
+
+	for i, str := range []string{"foo", "bar", "foo", "foo", "bar", "baz"} {
+		m := &M{
+			ID:   int64(i + 1),
+			Str:  str,
+			Int:  i,
+			Blob: []byte{},
+			Time: time.Now(),
+		}
+
+		if err := store.Create(ctx, m); err != nil {
+			t.Fatalf("store.Create(ctx, m): %v\n", err)
+		}
+	}
+
+	type Group struct {
+		Str   string
+		Count int64
+		Total int64
+	}
+
+	groups, err := Aggregate[Group](
+		ctx,
+		store,
+		[]string{"str"},
+		[]query.Expr{
+			query.As(query.Count("*"), "count"),
+			query.As(query.Sum(query.Ident("int")), "total"),
+		},
+		query.WhereNotEq("str", query.Arg("baz")),
+		query.OrderDesc("count"),
+	)
+
+	if err != nil {
+		t.Fatalf("Aggregate[Group](ctx, store, ...): %v\n", err)
+	}
+
+	want := []Group{
+		{"foo", 3, 0 + 2 + 3},
+		{"bar", 2, 1 + 4},
+	}
+
+	if !slices.Equal(groups, want) {
+		t.Fatalf("groups = %v, want = %v\n", groups, want)
+	}
+}
@@ -0,0 +1,276 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// isArray reports whether the given type is a slice or array that is stored as
+// an array in the database. Byte slices are excluded, since these are stored
+// as blobs.
+func isArray(rt reflect.Type) bool {
+	switch rt.Kind() {
+	case reflect.Slice, reflect.Array:
+		return rt.Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// arrayValue returns the given slice or array as a value that can be passed to
+// the database. For [Postgres] this is an array literal, such as {1,2,3},
+// otherwise the value is encoded as a JSON array. If the value is not a slice
+// or array, or it implements [driver.Valuer], then false is returned.
+func arrayValue(d Dialect, v any) (any, bool, error) {
+	if _, ok := v.(driver.Valuer); ok {
+		return nil, false, nil
+	}
+
+	rv := reflect.ValueOf(v)
+
+	if !rv.IsValid() || !isArray(rv.Type()) {
+		return nil, false, nil
+	}
+
+	if rv.Kind() == reflect.Slice && rv.IsNil() {
+		return nil, true, nil
+	}
+
+	if d == Postgres {
+		s, err := pgArray(rv)
+
+		if err != nil {
+			return nil, false, err
+		}
+		return s, true, nil
+	}
+
+	b, err := json.Marshal(v)
+
+	if err != nil {
+		return nil, false, err
+	}
+	return string(b), true, nil
+}
+
+// pgArray encodes the given slice or array as a PostgreSQL array literal.
+func pgArray(rv reflect.Value) (string, error) {
+	var buf strings.Builder
+
+	buf.WriteByte('{')
+
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		el := rv.Index(i)
+
+		if el.Kind() == reflect.Pointer {
+			if el.IsNil() {
+				buf.WriteString("NULL")
+				continue
+			}
+			el = el.Elem()
+		}
+
+		switch el.Kind() {
+		case reflect.Bool:
+			if el.Bool() {
+				buf.WriteByte('t')
+			} else {
+				buf.WriteByte('f')
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			buf.WriteString(strconv.FormatInt(el.Int(), 10))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			buf.WriteString(strconv.FormatUint(el.Uint(), 10))
+		case reflect.Float32, reflect.Float64:
+			buf.WriteString(strconv.FormatFloat(el.Float(), 'g', -1, el.Type().Bits()))
+		case reflect.String:
+			s := el.String()
+			s = strings.ReplaceAll(s, `\`, `\\`)
+			s = strings.ReplaceAll(s, `"`, `\"`)
+
+			buf.WriteByte('"')
+			buf.WriteString(s)
+			buf.WriteByte('"')
+		default:
+			return "", fmt.Errorf("cannot encode %s as array element", el.Type())
+		}
+	}
+
+	buf.WriteByte('}')
+	return buf.String(), nil
+}
+
+// parsePgArray parses the given one-dimensional PostgreSQL array literal. NULL
+// elements are returned as nil.
+func parsePgArray(s string) ([]*string, error) {
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, fmt.Errorf("invalid array %q", s)
+	}
+
+	s = s[1 : len(s)-1]
+
+	elems := make([]*string, 0)
+
+	if s == "" {
+		return elems, nil
+	}
+
+	for i := 0; i <= len(s); {
+		var (
+			buf    strings.Builder
+			quoted bool
+		)
+
+		if i < len(s) && s[i] == '{' {
+			return nil, errors.New("multi-dimensional arrays are not supported")
+		}
+
+		if i < len(s) && s[i] == '"' {
+			quoted = true
+			i++
+
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' {
+					i++
+
+					if i == len(s) {
+						break
+					}
+				}
+				buf.WriteByte(s[i])
+			}
+
+			if i == len(s) {
+				return nil, errors.New("unterminated quoted array element")
+			}
+			i++
+		} else {
+			for ; i < len(s) && s[i] != ','; i++ {
+				buf.WriteByte(s[i])
+			}
+		}
+
+		if i < len(s) && s[i] != ',' {
+			return nil, fmt.Errorf("unexpected %q in array", s[i])
+		}
+		i++
+
+		el := buf.String()
+
+		if !quoted && strings.EqualFold(el, "NULL") {
+			elems = append(elems, nil)
+			continue
+		}
+		elems = append(elems, &el)
+	}
+	return elems, nil
+}
+
+// scanArray scans the given source value into the given slice or array field.
+// The source can either be a PostgreSQL array literal, or a JSON array.
+func scanArray(src any, field reflect.Value) error {
+	var s string
+
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return fmt.Errorf("cannot scan %T into %s", src, field.Type())
+	}
+
+	s = strings.TrimSpace(s)
+
+	if !strings.HasPrefix(s, "{") {
+		return json.Unmarshal([]byte(s), field.Addr().Interface())
+	}
+
+	elems, err := parsePgArray(s)
+
+	if err != nil {
+		return err
+	}
+
+	arr := field
+
+	if field.Kind() == reflect.Slice {
+		arr = reflect.MakeSlice(field.Type(), len(elems), len(elems))
+	} else if len(elems) > field.Len() {
+		return fmt.Errorf("cannot scan %d elements into %s", len(elems), field.Type())
+	}
+
+	for i, el := range elems {
+		if el == nil {
+			continue
+		}
+
+		if err := setText(arr.Index(i), *el); err != nil {
+			return err
+		}
+	}
+
+	if field.Kind() == reflect.Slice {
+		field.Set(arr)
+	}
+	return nil
+}
+
+// setText sets the given value from its textual representation.
+func setText(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+
+		if err := setText(ptr.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		switch s {
+		case "t", "true":
+			v.SetBool(true)
+		case "f", "false":
+			v.SetBool(false)
+		default:
+			return fmt.Errorf("cannot parse %q as bool", s)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i64, err := strconv.ParseInt(s, 10, v.Type().Bits())
+
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as int: %v", s, err)
+		}
+		v.SetInt(i64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u64, err := strconv.ParseUint(s, 10, v.Type().Bits())
+
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as uint: %v", s, err)
+		}
+		v.SetUint(u64)
+	case reflect.Float32, reflect.Float64:
+		f64, err := strconv.ParseFloat(s, v.Type().Bits())
+
+		if err != nil {
+			return fmt.Errorf("cannot parse %q as float: %v", s, err)
+		}
+		v.SetFloat(f64)
+	default:
+		return fmt.Errorf("cannot parse %q as %s", s, v.Type())
+	}
+	return nil
+}
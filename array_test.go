@@ -0,0 +1,160 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestPgArray(t *testing.T) {
+	s := "x"
+
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{[]int{1, 2, 3}, "{1,2,3}"},
+		{[]string{"a", "b c", `d"e`, `f\g`}, `{"a","b c","d\"e","f\\g"}`},
+		{[]bool{true, false}, "{t,f}"},
+		{[2]float64{1.5, 2}, "{1.5,2}"},
+		{[]*string{&s, nil}, `{"x",NULL}`},
+		{[]int{}, "{}"},
+	}
+
+	for i, test := range tests {
+		got, err := pgArray(reflect.ValueOf(test.in))
+
+		if err != nil {
+			t.Fatalf("tests[%d] - pgArray(%v): %v\n", i, test.in, err)
+		}
+
+		if got != test.want {
+			t.Fatalf("tests[%d] - pgArray(%v) = %q, want = %q\n", i, test.in, got, test.want)
+		}
+	}
+}
+
+func TestParsePgArray(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []any
+	}{
+		{"{}", []any{}},
+		{"{1,2,3}", []any{"1", "2", "3"}},
+		{`{"a","b c","d\"e","f\\g"}`, []any{"a", "b c", `d"e`, `f\g`}},
+		{`{x,NULL,"NULL"}`, []any{"x", nil, "NULL"}},
+	}
+
+	for i, test := range tests {
+		elems, err := parsePgArray(test.in)
+
+		if err != nil {
+			t.Fatalf("tests[%d] - parsePgArray(%q): %v\n", i, test.in, err)
+		}
+
+		got := make([]any, 0, len(elems))
+
+		for _, el := range elems {
+			if el == nil {
+				got = append(got, nil)
+				continue
+			}
+			got = append(got, *el)
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Fatalf("tests[%d] - parsePgArray(%q) = %v, want = %v\n", i, test.in, got, test.want)
+		}
+	}
+
+	for _, in := range []string{"1,2", "{{1},{2}}", `{"a}`} {
+		if _, err := parsePgArray(in); err == nil {
+			t.Fatalf("parsePgArray(%q): expected error, got nil\n", in)
+		}
+	}
+}
+
+type Tagged struct {
+	ID     int64
+	Tags   []string
+	Scores [3]int
+}
+
+func (t *Tagged) Table() string { return "tagged" }
+
+func (t *Tagged) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{t.ID},
+	}
+}
+
+func (t *Tagged) Params() Params {
+	return Params{
+		"id":     CreateOnlyParam(t.ID),
+		"tags":   MutableParam(t.Tags),
+		"scores": MutableParam(t.Scores),
+	}
+}
+
+const taggedSchema = `CREATE TABLE IF NOT EXISTS tagged (
+	id     INTEGER NOT NULL,
+	tags   TEXT,
+	scores TEXT NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+func TestArrayScanning(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, taggedSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", taggedSchema, err)
+	}
+
+	store := NewStore(db, func() *Tagged {
+		return &Tagged{}
+	}, WithDialect(SQLite))
+
+	want := &Tagged{
+		ID:     1,
+		Tags:   []string{"go", "sql"},
+		Scores: [3]int{1, 2, 3},
+	}
+
+	if err := store.Create(ctx, want); err != nil {
+		t.Fatalf("store.Create(ctx, want): %v\n", err)
+	}
+
+	// Columns holding PostgreSQL array literals can be scanned too.
+	q := "INSERT INTO tagged (id, tags, scores) VALUES (2, '{go,\"s q l\"}', '{4,5,6}')"
+
+	if _, err := db.ExecContext(ctx, q); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", q, err)
+	}
+
+	tt, err := store.SelectAll(ctx, query.OrderAsc("id"))
+
+	if err != nil {
+		t.Fatalf("store.SelectAll(ctx, query.OrderAsc(%q)): %v\n", "id", err)
+	}
+
+	if len(tt) != 2 {
+		t.Fatalf("len(tt) = %v, want = %v\n", len(tt), 2)
+	}
+
+	if !reflect.DeepEqual(tt[0], want) {
+		t.Fatalf("tt[0] = %v, want = %v\n", tt[0], want)
+	}
+
+	want2 := &Tagged{
+		ID:     2,
+		Tags:   []string{"go", "s q l"},
+		Scores: [3]int{4, 5, 6},
+	}
+
+	if !reflect.DeepEqual(tt[1], want2) {
+		t.Fatalf("tt[1] = %v, want = %v\n", tt[1], want2)
+	}
+}
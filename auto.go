@@ -0,0 +1,193 @@
+package database
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Struct tag options for deriving the mode of a [Param] via [AutoModel].
+const (
+	generatedOption  = "generated"
+	createOnlyOption = "createonly"
+	updateOnlyOption = "updateonly"
+)
+
+// tagOptions are the options that can be given in a "db" struct tag alongside
+// the column name.
+var tagOptions = []string{
+	jsonOption,
+	encryptedOption,
+	sensitiveOption,
+	generatedOption,
+	createOnlyOption,
+	updateOnlyOption,
+}
+
+// Auto is a [Model] whose Params and PrimaryKey are derived from the struct
+// tags of T. This is created via [AutoModel], and wraps the struct value V.
+type Auto[T any] struct {
+	V *T `db:"*:*"`
+
+	table string   `db:"-"`
+	pk    []string `db:"-"`
+}
+
+// AutoModel returns an [Auto] for the struct type T stored in the given table,
+// with the given primary key columns. This allows for simple models to be
+// used with a [Store] without implementing [Model] by hand. The columns of the
+// Params are taken from the "db" struct tags of T's fields, or the snake_case
+// form of the field name if untagged. Fields tagged with "-", and fields that
+// map to the columns of other structs are skipped.
+//
+// Every Param is a [MutableParam], except for the primary key columns which are
+// a [CreateOnlyParam]. This can be changed via the generated, createonly, and
+// updateonly struct tag options, for example,
+//
+//	type Post struct {
+//	    ID        int64     `db:"id,generated"`
+//	    Title     string    `db:"title"`
+//	    CreatedAt time.Time `db:"created_at,createonly"`
+//	}
+//
+//	post := database.AutoModel[Post]("posts", "id")
+//
+//	posts := database.NewStore(db, post.New)
+//
+//	if err := posts.Create(ctx, post.Of(&Post{Title: "Hello"})); err != nil {
+//	    // Handle error.
+//	}
+//
+// Models with more complex requirements, such as computed Params, should still
+// implement [Model] by hand.
+func AutoModel[T any](table string, pk ...string) *Auto[T] {
+	if reflect.TypeFor[T]().Kind() != reflect.Struct {
+		panic("database: AutoModel type must be a struct")
+	}
+
+	return &Auto[T]{
+		table: table,
+		pk:    pk,
+	}
+}
+
+// New returns a new [Auto] for the same table and primary key, wrapping a new
+// zero value of T. This would be given to [NewStore].
+func (a *Auto[T]) New() *Auto[T] {
+	return a.Of(new(T))
+}
+
+// Of returns a new [Auto] for the same table and primary key, wrapping the
+// given value of T.
+func (a *Auto[T]) Of(v *T) *Auto[T] {
+	return &Auto[T]{
+		V:     v,
+		table: a.table,
+		pk:    a.pk,
+	}
+}
+
+func (a *Auto[T]) Table() string { return a.table }
+
+// PrimaryKey returns the [PrimaryKey] of the wrapped value, or nil if no
+// primary key columns were given to [AutoModel].
+func (a *Auto[T]) PrimaryKey() *PrimaryKey {
+	if len(a.pk) == 0 {
+		return nil
+	}
+
+	params := a.Params()
+	vals := make([]any, 0, len(a.pk))
+
+	for _, col := range a.pk {
+		vals = append(vals, params[col].value)
+	}
+
+	return &PrimaryKey{
+		Columns: a.pk,
+		Values:  vals,
+	}
+}
+
+// Params returns the [Params] of the wrapped value, as derived from the struct
+// tags of T. The Params are ordered by the declaration of the fields of T.
+func (a *Auto[T]) Params() Params {
+	params := make(Params)
+
+	rv := reflect.ValueOf(a.V)
+
+	if rv.IsNil() {
+		rv = reflect.New(reflect.TypeFor[T]())
+	}
+
+	a.params(params, rv.Elem())
+	return params
+}
+
+func (a *Auto[T]) params(params Params, rv reflect.Value) {
+	rt := rv.Type()
+
+	// Indexes of the embedded structs, these are derived after all other
+	// fields, so the fields of the outer struct take precedence.
+	embedded := make([]int, 0)
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get(scanAliasTag)
+
+		if tag == "-" || strings.Contains(tag, ":") {
+			continue
+		}
+
+		if tag == "" && isNestedStruct(sf) {
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				embedded = append(embedded, i)
+			}
+			continue
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		opts := strings.Split(tag, ",")
+		col := opts[0]
+
+		if slices.Contains(tagOptions, col) {
+			col = ""
+		}
+
+		if col == "" {
+			col = SnakeCase(sf.Name)
+		}
+
+		if _, ok := params[col]; ok {
+			continue
+		}
+
+		v := rv.Field(i).Interface()
+
+		var p Param
+
+		switch {
+		case slices.Contains(opts, generatedOption):
+			p = GeneratedParam(v)
+		case slices.Contains(opts, createOnlyOption):
+			p = CreateOnlyParam(v)
+		case slices.Contains(opts, updateOnlyOption):
+			p = UpdateOnlyParam(v)
+		case slices.Contains(a.pk, col):
+			p = CreateOnlyParam(v)
+		default:
+			p = MutableParam(v)
+		}
+
+		// Params are ordered by the declaration of their fields.
+		p.order = len(params) + 1
+		params[col] = p
+	}
+
+	for _, i := range embedded {
+		a.params(params, rv.Field(i))
+	}
+}
@@ -0,0 +1,131 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+type Timestamps struct {
+	CreatedAt time.Time `db:"created_at,createonly"`
+	UpdatedAt Null[time.Time]
+}
+
+type Note struct {
+	ID    int64 `db:"id,generated"`
+	Title string
+	Body  string   `db:"content"`
+	Tags  []string `db:"tags,json"`
+	Draft bool     `db:"-"`
+
+	Timestamps
+}
+
+const noteSchema = `CREATE TABLE IF NOT EXISTS notes (
+	id         INTEGER NOT NULL,
+	title      TEXT NOT NULL,
+	content    TEXT NOT NULL,
+	tags       TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NULL,
+	PRIMARY KEY (id)
+);`
+
+func TestAutoModelParams(t *testing.T) {
+	note := AutoModel[Note]("notes", "id")
+
+	params := note.Of(&Note{ID: 1, Title: "hello"}).Params()
+
+	tests := []struct {
+		col  string
+		mode paramMode
+	}{
+		{"id", 0},
+		{"title", paramCreate | paramUpdate},
+		{"content", paramCreate | paramUpdate},
+		{"tags", paramCreate | paramUpdate},
+		{"created_at", paramCreate},
+		{"updated_at", paramCreate | paramUpdate},
+	}
+
+	if len(params) != len(tests) {
+		t.Fatalf("len(params) = %v, want = %v\n", len(params), len(tests))
+	}
+
+	for i, test := range tests {
+		p, ok := params[test.col]
+
+		if !ok {
+			t.Fatalf("tests[%d] - params[%q] not found\n", i, test.col)
+		}
+
+		if p.mode != test.mode {
+			t.Fatalf("tests[%d] - params[%q].mode = %v, want = %v\n", i, test.col, p.mode, test.mode)
+		}
+	}
+
+	pk := note.Of(&Note{ID: 1}).PrimaryKey()
+
+	if pk.Values[0] != int64(1) {
+		t.Fatalf("pk.Values[0] = %v, want = %v\n", pk.Values[0], 1)
+	}
+}
+
+func TestAutoModel(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, noteSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", noteSchema, err)
+	}
+
+	note := AutoModel[Note]("notes", "id")
+
+	store := NewStore(db, note.New, WithDialect(SQLite))
+
+	n := &Note{
+		Title: "hello",
+		Body:  "world",
+		Tags:  []string{"a", "b"},
+		Timestamps: Timestamps{
+			CreatedAt: time.Now().UTC(),
+		},
+	}
+
+	if err := store.Create(ctx, note.Of(n)); err != nil {
+		t.Fatalf("store.Create(ctx, note.Of(n)): %v\n", err)
+	}
+
+	if n.ID == 0 {
+		t.Fatalf("n.ID = %v, want non-zero\n", n.ID)
+	}
+
+	n.Title = "goodbye"
+
+	if _, err := store.Update(ctx, note.Of(n)); err != nil {
+		t.Fatalf("store.Update(ctx, note.Of(n)): %v\n", err)
+	}
+
+	got, ok, err := store.Get(ctx, query.WhereEq("id", query.Arg(n.ID)))
+
+	if err != nil {
+		t.Fatalf("store.Get(ctx): %v\n", err)
+	}
+
+	if !ok {
+		t.Fatalf("ok = %v, want = %v\n", ok, true)
+	}
+
+	if got.V.Title != "goodbye" || got.V.Body != "world" {
+		t.Fatalf("got.V = %v, want = %v\n", got.V, n)
+	}
+
+	if len(got.V.Tags) != 2 || got.V.Tags[1] != "b" {
+		t.Fatalf("got.V.Tags = %v, want = %v\n", got.V.Tags, n.Tags)
+	}
+
+	if !got.V.CreatedAt.Equal(n.CreatedAt) {
+		t.Fatalf("got.V.CreatedAt = %v, want = %v\n", got.V.CreatedAt, n.CreatedAt)
+	}
+}
@@ -0,0 +1,221 @@
+package database
+
+import (
+	"context"
+	"sync"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// batch is a single query made by a [BatchLoader] for the keys queued at the
+// time of the query.
+type batch struct {
+	done chan struct{}
+	err  error
+}
+
+type batchEntry[M Model] struct {
+	batch *batch
+	model M
+	ok    bool
+}
+
+// BatchLoader loads the models of a [Store] by the value of a column, such as
+// a primary or foreign key, collecting the keys requested and resolving them
+// via a single WHERE IN query. This avoids the N+1 queries of calling
+// [Store.Get] for each model in a list, for example,
+//
+//	users := database.NewBatchLoader(userStore, "id")
+//
+//	for _, p := range pp {
+//	    users.Queue(p.UserID)
+//	}
+//
+//	for _, p := range pp {
+//	    u, ok, err := users.Load(ctx, p.UserID)
+//	    ...
+//	}
+//
+// would load all of the users in a single query when the first is loaded. The
+// loaded models are kept for the lifetime of the loader, so a loader would
+// typically be created for each request, or render pass, and discarded
+// afterwards. A BatchLoader is safe for concurrent use, concurrent calls to
+// Load for a key that is already being queried wait on that query rather than
+// making another.
+type BatchLoader[M Model] struct {
+	store *Store[M]
+	col   string
+
+	mu      sync.Mutex
+	queued  []any
+	entries map[string]*batchEntry[M]
+}
+
+// NewBatchLoader returns a [BatchLoader] for loading the models of the given
+// store by the given column. If the column is not unique, then the first model
+// selected for a key is the one loaded.
+func NewBatchLoader[M Model](s *Store[M], col string) *BatchLoader[M] {
+	return &BatchLoader[M]{
+		store:   s,
+		col:     col,
+		entries: make(map[string]*batchEntry[M]),
+	}
+}
+
+// Queue adds the given keys to be queried on the next call to
+// [BatchLoader.Load] or [BatchLoader.LoadMany]. Keys that have already been
+// loaded or queued are ignored.
+func (l *BatchLoader[M]) Queue(keys ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.queue(keys)
+}
+
+// queue queues the given keys that have not already been loaded or queued,
+// and returns the entries for each of the keys.
+func (l *BatchLoader[M]) queue(keys []any) []*batchEntry[M] {
+	ee := make([]*batchEntry[M], 0, len(keys))
+
+	for _, key := range keys {
+		k := keyOf([]any{key})
+
+		e, ok := l.entries[k]
+
+		if !ok {
+			e = &batchEntry[M]{}
+
+			l.entries[k] = e
+			l.queued = append(l.queued, key)
+		}
+		ee = append(ee, e)
+	}
+	return ee
+}
+
+// dispatch queries the queued keys. The entries for the keys are given the
+// batch being queried so that they can be waited on.
+func (l *BatchLoader[M]) dispatch(ctx context.Context) {
+	l.mu.Lock()
+
+	keys := l.queued
+	l.queued = nil
+
+	if len(keys) == 0 {
+		l.mu.Unlock()
+		return
+	}
+
+	b := &batch{
+		done: make(chan struct{}),
+	}
+
+	for _, key := range keys {
+		l.entries[keyOf([]any{key})].batch = b
+	}
+	l.mu.Unlock()
+
+	mm, err := l.store.Select(ctx, query.Columns("*"), query.WhereIn(l.col, query.List(keys...)))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer close(b.done)
+
+	if err != nil {
+		b.err = err
+
+		// Forget the keys of the failed batch so that they are queried again
+		// on the next load.
+		for _, key := range keys {
+			delete(l.entries, keyOf([]any{key}))
+		}
+		return
+	}
+
+	for _, m := range mm {
+		vals, err := paramValues(m, []string{l.col})
+
+		if err != nil {
+			b.err = err
+			return
+		}
+
+		e, ok := l.entries[keyOf(vals)]
+
+		if !ok || e.batch != b || e.ok {
+			continue
+		}
+
+		e.model = m
+		e.ok = true
+	}
+}
+
+// wait waits for the given entry to be loaded, dispatching the queued keys if
+// the entry has not yet been queried.
+func (l *BatchLoader[M]) wait(ctx context.Context, e *batchEntry[M]) error {
+	l.mu.Lock()
+	b := e.batch
+	l.mu.Unlock()
+
+	if b == nil {
+		l.dispatch(ctx)
+
+		l.mu.Lock()
+		b = e.batch
+		l.mu.Unlock()
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.done:
+	}
+	return b.err
+}
+
+// Load returns the model for the given key, and whether it was found. If the
+// key has not been loaded, then it is queried along with any other queued
+// keys.
+func (l *BatchLoader[M]) Load(ctx context.Context, key any) (M, bool, error) {
+	var zero M
+
+	l.mu.Lock()
+	e := l.queue([]any{key})[0]
+	l.mu.Unlock()
+
+	if err := l.wait(ctx, e); err != nil {
+		return zero, false, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return e.model, e.ok, nil
+}
+
+// LoadMany returns the models for the given keys, querying any that have not
+// been loaded along with any other queued keys. The models are returned in
+// the order of the given keys, and keys that were not found are omitted.
+func (l *BatchLoader[M]) LoadMany(ctx context.Context, keys ...any) ([]M, error) {
+	l.mu.Lock()
+	ee := l.queue(keys)
+	l.mu.Unlock()
+
+	mm := make([]M, 0, len(keys))
+
+	for _, e := range ee {
+		if err := l.wait(ctx, e); err != nil {
+			return nil, err
+		}
+
+		l.mu.Lock()
+		ok := e.ok
+		l.mu.Unlock()
+
+		if ok {
+			mm = append(mm, e.model)
+		}
+	}
+	return mm, nil
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestBatchLoader(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, userPostSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", userPostSchema, err)
+	}
+
+	var rec metricsRecorder
+
+	users := NewStore(db, func() *User {
+		return &User{}
+	}, WithMetrics(&rec))
+
+	for i := 0; i < 3; i++ {
+		u := User{
+			ID:    int64(i),
+			Email: rand.Text(),
+		}
+
+		if err := users.Create(ctx, &u); err != nil {
+			t.Fatalf("users.Create(ctx, &u): %v\n", err)
+		}
+	}
+
+	posts := make([]*Post, 0, 10)
+
+	for i := 0; i < 10; i++ {
+		posts = append(posts, &Post{
+			ID:    int64(i),
+			User:  &User{ID: int64(i % 4)},
+			Title: fmt.Sprintf("Post %d", i+1),
+		})
+	}
+
+	t.Run("Load", func(t *testing.T) {
+		rec = metricsRecorder{}
+
+		loader := NewBatchLoader(users, "id")
+
+		for _, p := range posts {
+			loader.Queue(p.User.ID)
+		}
+
+		for _, p := range posts {
+			u, ok, err := loader.Load(ctx, p.User.ID)
+
+			if err != nil {
+				t.Fatalf("loader.Load(ctx, %v): %v\n", p.User.ID, err)
+			}
+
+			if want := p.User.ID < 3; ok != want {
+				t.Fatalf("loader.Load(ctx, %v): ok = %v, want = %v\n", p.User.ID, ok, want)
+			}
+
+			if ok && u.ID != p.User.ID {
+				t.Fatalf("u.ID = %v, want = %v\n", u.ID, p.User.ID)
+			}
+		}
+
+		if n := len(rec.ops); n != 1 {
+			t.Fatalf("len(rec.ops) = %v, want = %v\n", n, 1)
+		}
+
+		// Keys already loaded are not queried again.
+		if _, _, err := loader.Load(ctx, int64(0)); err != nil {
+			t.Fatalf("loader.Load(ctx, 0): %v\n", err)
+		}
+
+		if n := len(rec.ops); n != 1 {
+			t.Fatalf("len(rec.ops) = %v, want = %v\n", n, 1)
+		}
+	})
+
+	t.Run("LoadMany", func(t *testing.T) {
+		rec = metricsRecorder{}
+
+		loader := NewBatchLoader(users, "id")
+
+		uu, err := loader.LoadMany(ctx, int64(2), int64(3), int64(0), int64(2))
+
+		if err != nil {
+			t.Fatalf("loader.LoadMany(ctx, 2, 3, 0, 2): %v\n", err)
+		}
+
+		want := []int64{2, 0, 2}
+
+		if len(uu) != len(want) {
+			t.Fatalf("len(uu) = %v, want = %v\n", len(uu), len(want))
+		}
+
+		for i, u := range uu {
+			if u.ID != want[i] {
+				t.Fatalf("uu[%d].ID = %v, want = %v\n", i, u.ID, want[i])
+			}
+		}
+
+		if n := len(rec.ops); n != 1 {
+			t.Fatalf("len(rec.ops) = %v, want = %v\n", n, 1)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		loader := NewBatchLoader(users, "id")
+
+		for _, p := range posts {
+			loader.Queue(p.User.ID)
+		}
+
+		var wg sync.WaitGroup
+
+		errs := make([]error, len(posts))
+
+		for i, p := range posts {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, _, errs[i] = loader.Load(ctx, p.User.ID)
+			}()
+		}
+		wg.Wait()
+
+		for i, err := range errs {
+			if err != nil {
+				t.Fatalf("errs[%d] = %v\n", i, err)
+			}
+		}
+	})
+}
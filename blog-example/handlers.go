@@ -45,18 +45,13 @@ func HomeHandler(tmpl *template.Template, posts *database.Store[*Post], users *d
 			opts = append(opts, WhereTag(tag))
 		}
 
-		pp, err := posts.Select(ctx, database.Columns(p, p.User), opts...)
+		pp, err := posts.Preload("Tags", LoadTags).Select(ctx, database.Columns(p, p.User), opts...)
 
 		if err != nil {
 			InternalServerError(w, err)
 			return
 		}
 
-		if err := LoadTags(ctx, posts.DB, pp); err != nil {
-			InternalServerError(w, err)
-			return
-		}
-
 		var data struct {
 			Users []*User
 			Posts []*Post
@@ -96,7 +91,6 @@ func CreatePostHandler(posts *database.Store[*Post], users *database.Store[*User
 		now := time.Now()
 
 		p := Post{
-			ID:        now.UnixNano(),
 			User:      u,
 			Title:     r.PostForm.Get("title"),
 			Content:   r.PostForm.Get("content"),
@@ -111,9 +105,12 @@ func CreatePostHandler(posts *database.Store[*Post], users *database.Store[*User
 		tags := strings.Split(r.PostForm.Get("tags"), ",")
 
 		for _, tag := range tags {
-			q := query.Insert("post_tags", query.Columns("post_id", "name"), query.Values(p.ID, tag))
+			q := query.InsertMap("post_tags", map[string]any{
+				"post_id": p.ID,
+				"name":    tag,
+			})
 
-			if _, err := posts.DB.ExecContext(ctx, q.Build(), q.Args()...); err != nil {
+			if _, err := posts.Exec(ctx, q); err != nil {
 				InternalServerError(w, err)
 				return
 			}
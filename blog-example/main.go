@@ -3,59 +3,35 @@ package main
 import (
 	"context"
 	"database/sql"
-	_ "embed"
+	"embed"
 	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
-	"net/url"
 	"os"
 	"os/signal"
 	"time"
 
 	"github.com/andrewpillar/database"
-	"github.com/andrewpillar/database/query"
+	"github.com/andrewpillar/database/migrate"
+	"github.com/andrewpillar/database/seed"
 
 	_ "modernc.org/sqlite"
 )
 
-var SQLitePragmas = [...]string{
-	"busy_timeout=5000",
-	"cache_size=1000000000",
-	"foreign_keys=true",
-	"journal_mode=WAL",
-	"synchronous=NORMAL",
-	"temp_store=memory",
-}
-
 func OpenDB() (*sql.DB, error) {
-	dbname := fmt.Sprintf("%s.sqlite", os.Args[0])
-
-	url, err := url.Parse(dbname)
-
-	if err != nil {
-		return nil, err
-	}
-
-	q := url.Query()
-
-	for _, pragma := range SQLitePragmas {
-		q.Add("_pragma", pragma)
-	}
-
-	url.RawQuery = q.Encode()
-
-	db, err := sql.Open("sqlite", url.String())
-
-	if err != nil {
-		return nil, err
-	}
-	return db, nil
+	return database.Open("sqlite", database.Config{
+		Name:    fmt.Sprintf("%s.sqlite", os.Args[0]),
+		Pragmas: database.DefaultSQLitePragmas.Pragmas(),
+	})
 }
 
 //go:embed home.tmpl
 var homeTmpl []byte
 
+//go:embed migrations
+var migrations embed.FS
+
 func main() {
 	db, err := OpenDB()
 
@@ -69,14 +45,16 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	for _, schema := range []string{
-		UserSchema,
-		PostSchema,
-	} {
-		if _, err := db.ExecContext(ctx, schema); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
-		}
+	mm, err := migrate.Load(migrations, "migrations")
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if _, err := migrate.Migrate(ctx, db, mm, migrate.WithDialect(database.SQLite)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
 	tmpl, err := template.New("home.tmpl").Parse(string(homeTmpl))
@@ -88,37 +66,37 @@ func main() {
 
 	users := database.NewStore(db, func() *User {
 		return &User{}
-	})
+	}, database.WithDialect(database.SQLite))
 
-	for _, username := range DefaultUsers {
-		_, ok, err := users.Get(ctx, query.WhereEq("username", query.Arg(username)))
+	seeder := seed.New()
 
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
-		}
+	seeder.Add("default_users", func(ctx context.Context, tx *sql.Tx) error {
+		now := time.Now().UTC()
 
-		if !ok {
-			now := time.Now().UTC()
+		uu := make([]*User, 0, len(DefaultUsers))
 
-			u := User{
-				ID:        now.UnixNano(),
+		for _, username := range DefaultUsers {
+			uu = append(uu, &User{
 				Username:  username,
 				CreatedAt: now,
-			}
-
-			if err := users.Create(ctx, &u); err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				os.Exit(1)
-			}
+			})
 		}
+
+		// Users created before the seed was tracked are ignored.
+		_, err := users.With(tx).CreateIgnore(ctx, uu...)
+		return err
+	})
+
+	if _, err := seeder.Run(ctx, db); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
 	posts := database.NewStore(db, func() *Post {
 		return &Post{
 			User: &User{},
 		}
-	})
+	}, database.WithDialect(database.SQLite))
 
 	mux := http.NewServeMux()
 
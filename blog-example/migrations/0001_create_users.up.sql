@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS users (
+	id         INTEGER NOT NULL,
+	username   VARCHAR UNIQUE NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (id)
+);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS posts (
+	id         INTEGER NOT NULL,
+	user_id    INTEGER NOT NULL,
+	title      VARCHAR NOT NULL,
+	content    TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NULL,
+	PRIMARY KEY (id),
+	FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS post_tags (
+	post_id VARCHAR NOT NULL,
+	name    VARCHAR NOT NULL,
+	PRIMARY KEY (post_id, name)
+);
@@ -9,23 +9,6 @@ import (
 	"github.com/andrewpillar/database/query"
 )
 
-const PostSchema = `CREATE TABLE IF NOT EXISTS posts (
-	id         INTEGER NOT NULL,
-	user_id    INTEGER NOT NULL,
-	title      VARCHAR NOT NULL,
-	content    TEXT NOT NULL,
-	created_at TIMESTAMP NOT NULL,
-	updated_at TIMESTAMP NULL,
-	PRIMARY KEY (id),
-	FOREIGN KEY (user_id) REFERENCES users(id)
-);
-
-CREATE TABLE IF NOT EXISTS post_tags (
-	post_id VARCHAR NOT NULL,
-	name    VARCHAR NOT NULL,
-	PRIMARY KEY (post_id, name)
-);`
-
 type Post struct {
 	ID        int64
 	User      *User `db:"user_id:id,users.*:*"`
@@ -56,44 +39,38 @@ func (p *Post) Params() database.Params {
 	}
 }
 
-func LoadTags(ctx context.Context, db *sql.DB, pp []*Post) error {
-	// Table to look up the post's position in the given slice. The key is the
-	// post's ID.
-	tab := make(map[int64]int)
-
-	for i, p := range pp {
-		tab[p.ID] = i
-	}
-
-	q := query.Select(
-		query.Columns("post_id", "name"),
-		query.From("post_tags"),
-		query.WhereIn("post_id", database.List("id", pp...)),
-	)
+// PostTag is a tag on a post.
+type PostTag struct {
+	PostID int64
+	Name   string
+}
 
-	rows, err := db.QueryContext(ctx, q.Build(), q.Args()...)
+func (t *PostTag) Table() string { return "post_tags" }
 
-	if err != nil {
-		return err
+func (t *PostTag) PrimaryKey() *database.PrimaryKey {
+	return &database.PrimaryKey{
+		Columns: []string{"post_id", "name"},
+		Values:  []any{t.PostID, t.Name},
 	}
+}
 
-	defer rows.Close()
-
-	var (
-		postId int64
-		tag    string
-	)
+func (t *PostTag) Params() database.Params {
+	return database.Params{
+		"post_id": database.CreateOnlyParam(t.PostID),
+		"name":    database.CreateOnlyParam(t.Name),
+	}
+}
 
-	for rows.Next() {
-		if err := rows.Scan(&postId, &tag); err != nil {
-			return err
-		}
+// LoadTags is a database.Loader that loads the tags for each of the given
+// posts in a single query.
+func LoadTags(ctx context.Context, db database.DB, pp []*Post) error {
+	return database.LoadRelated(ctx, db, pp, "post_id", func(p *Post, tt []*PostTag) {
+		p.Tags = make([]string, 0, len(tt))
 
-		if pos, ok := tab[postId]; ok {
-			pp[pos].Tags = append(pp[pos].Tags, tag)
+		for _, t := range tt {
+			p.Tags = append(p.Tags, t.Name)
 		}
-	}
-	return nil
+	})
 }
 
 func WhereTag(tag string) query.Option {
@@ -6,13 +6,6 @@ import (
 	"github.com/andrewpillar/database"
 )
 
-const UserSchema = `CREATE TABLE IF NOT EXISTS users (
-	id         INTEGER NOT NULL,
-	username   VARCHAR UNIQUE NOT NULL,
-	created_at TIMESTAMP NOT NULL,
-	PRIMARY KEY (id)
-);`
-
 type User struct {
 	ID        int64
 	Username  string
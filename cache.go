@@ -0,0 +1,179 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// Cache is the interface used by a [Store] for caching the models it selects.
+// Cached values are keyed by the table they were selected from, and a key
+// derived from the query used to select them.
+//
+// Get returns the value for the given table and key, and whether it was
+// found.
+//
+// Set puts the value in the cache for the given table and key.
+//
+// Invalidate removes all of the values for the given table. This is called
+// whenever the Store writes to the table, or once the transaction the write
+// was made in is committed.
+type Cache interface {
+	Get(table, key string) (any, bool)
+
+	Set(table, key string, v any)
+
+	Invalidate(table string)
+}
+
+// WithCache configures the [Cache] a [Store] uses for the models returned from
+// [Store.Select] and [Store.Get]. Any create, update, or delete performed by
+// the store invalidates the cache for the store's table.
+//
+// The cache is not consulted by a store that is operating on a [sql.Tx], so
+// that reads within a transaction see the writes made within it. Writes made
+// within a transaction begun via [Tx], or [Store.WithTx], only invalidate the
+// cache once the transaction is committed, and not at all if it is rolled
+// back. Writes made within any other transaction invalidate the cache
+// immediately, so a concurrent read may cache the rows from before the commit.
+// Cached models are shared between callers, so they should not be modified.
+//
+// Cached models are keyed only by the store's table, so a write to any other
+// table does not invalidate them. Queries that join or select from other
+// tables should not be run through a store with a cache, unless stale results
+// from those tables can be tolerated.
+func WithCache(c Cache) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.cache = c
+	}
+}
+
+func cacheKey(q *query.Query) string {
+	return fmt.Sprintf("%s %v", q.Build(), unredact(q.Args()))
+}
+
+func (s *Store[M]) useCache() bool {
+	if s.cfg.cache == nil {
+		return false
+	}
+
+	_, tx := s.DB.(*sql.Tx)
+	return !tx
+}
+
+func (s *Store[M]) cached(q *query.Query) ([]M, bool) {
+	if !s.useCache() {
+		return nil, false
+	}
+
+	v, ok := s.cfg.cache.Get(s.table, cacheKey(q))
+
+	if !ok {
+		return nil, false
+	}
+
+	mm, ok := v.([]M)
+
+	if !ok {
+		return nil, false
+	}
+	return slices.Clone(mm), true
+}
+
+func (s *Store[M]) cache(q *query.Query, mm []M) {
+	if s.useCache() {
+		s.cfg.cache.Set(s.table, cacheKey(q), slices.Clone(mm))
+	}
+}
+
+// invalidate invalidates the given table in the store's Cache, if any. If the
+// store is operating on a transaction begun via Tx, then the invalidation is
+// held until the transaction is committed, so that the rows from before the
+// commit are not cached again in the meantime.
+func (s *Store[M]) invalidate(table string) {
+	if s.cfg.cache == nil {
+		return
+	}
+
+	if p, ok := txPending(s.DB); ok {
+		p.add(pendingChange{
+			cache: s.cfg.cache,
+			table: table,
+		})
+		return
+	}
+	s.cfg.cache.Invalidate(table)
+}
+
+type cacheEntry struct {
+	v       any
+	expires time.Time
+}
+
+// MemoryCache is an in-memory [Cache] where values expire after a fixed
+// duration. It is safe for concurrent use, and can be shared between multiple
+// stores.
+type MemoryCache struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	tables map[string]map[string]cacheEntry
+}
+
+var _ Cache = (*MemoryCache)(nil)
+
+// NewMemoryCache returns a new [MemoryCache] where values expire after the
+// given duration.
+func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		ttl:    ttl,
+		tables: make(map[string]map[string]cacheEntry),
+	}
+}
+
+// Get implements [Cache].
+func (c *MemoryCache) Get(table, key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.tables[table][key]
+
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(e.expires) {
+		delete(c.tables[table], key)
+		return nil, false
+	}
+	return e.v, true
+}
+
+// Set implements [Cache].
+func (c *MemoryCache) Set(table, key string, v any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, ok := c.tables[table]
+
+	if !ok {
+		entries = make(map[string]cacheEntry)
+		c.tables[table] = entries
+	}
+
+	entries[key] = cacheEntry{
+		v:       v,
+		expires: time.Now().Add(c.ttl),
+	}
+}
+
+// Invalidate implements [Cache].
+func (c *MemoryCache) Invalidate(table string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.tables, table)
+}
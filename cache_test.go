@@ -0,0 +1,178 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestStoreCache(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	rec := &metricsRecorder{}
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	}, WithCache(NewMemoryCache(time.Minute)), WithMetrics(rec))
+
+	m := &M{
+		ID:     1,
+		Str:    "string",
+		BigStr: "bigstring",
+		Blob:   []byte{},
+		Time:   time.Now(),
+	}
+
+	if err := store.Create(ctx, m); err != nil {
+		t.Fatalf("store.Create(ctx, m): %v\n", err)
+	}
+
+	selects := func() int {
+		n := 0
+
+		for _, op := range rec.ops {
+			if op == OpSelect {
+				n++
+			}
+		}
+		return n
+	}
+
+	for range 3 {
+		if _, _, err := store.Get(ctx, query.WhereEq("id", query.Arg(1))); err != nil {
+			t.Fatalf("store.Get(ctx, ...): %v\n", err)
+		}
+	}
+
+	if n := selects(); n != 1 {
+		t.Fatalf("selects() = %v, want = %v\n", n, 1)
+	}
+
+	if _, _, err := store.Get(ctx, query.WhereEq("id", query.Arg(2))); err != nil {
+		t.Fatalf("store.Get(ctx, ...): %v\n", err)
+	}
+
+	if n := selects(); n != 2 {
+		t.Fatalf("selects() = %v, want = %v\n", n, 2)
+	}
+
+	m.Str = "updated"
+
+	if _, err := store.Update(ctx, m); err != nil {
+		t.Fatalf("store.Update(ctx, m): %v\n", err)
+	}
+
+	got, _, err := store.Get(ctx, query.WhereEq("id", query.Arg(1)))
+
+	if err != nil {
+		t.Fatalf("store.Get(ctx, ...): %v\n", err)
+	}
+
+	if n := selects(); n != 3 {
+		t.Fatalf("selects() = %v, want = %v\n", n, 3)
+	}
+
+	if got.Str != "updated" {
+		t.Fatalf("got.Str = %v, want = %v\n", got.Str, "updated")
+	}
+}
+
+// invalidateRecorder records the tables invalidated in the cache it wraps.
+type invalidateRecorder struct {
+	*MemoryCache
+
+	tables []string
+}
+
+func (r *invalidateRecorder) Invalidate(table string) {
+	r.tables = append(r.tables, table)
+	r.MemoryCache.Invalidate(table)
+}
+
+func TestStoreCacheTx(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	cache := &invalidateRecorder{
+		MemoryCache: NewMemoryCache(time.Minute),
+	}
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	}, WithCache(cache))
+
+	m := &M{
+		ID:     1,
+		Str:    "string",
+		BigStr: "bigstring",
+		Blob:   []byte{},
+		Time:   time.Now(),
+	}
+
+	err := store.WithTx(ctx, func(s *Store[*M]) error {
+		if err := s.Create(ctx, m); err != nil {
+			return err
+		}
+
+		if len(cache.tables) != 0 {
+			t.Fatalf("cache.tables = %v, want = %v\n", cache.tables, []string{})
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("store.WithTx(ctx, ...): %v\n", err)
+	}
+
+	if len(cache.tables) != 1 || cache.tables[0] != m.Table() {
+		t.Fatalf("cache.tables = %v, want = %v\n", cache.tables, []string{m.Table()})
+	}
+
+	cache.tables = nil
+
+	errRollback := errors.New("rollback")
+
+	err = store.WithTx(ctx, func(s *Store[*M]) error {
+		m.Str = "updated"
+
+		if _, err := s.Update(ctx, m); err != nil {
+			return err
+		}
+		return errRollback
+	})
+
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("store.WithTx(ctx, ...): %v, want = %v\n", err, errRollback)
+	}
+
+	if len(cache.tables) != 0 {
+		t.Fatalf("cache.tables = %v, want = %v\n", cache.tables, []string{})
+	}
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	c := NewMemoryCache(time.Millisecond)
+
+	c.Set("models", "key", 1)
+
+	if _, ok := c.Get("models", "key"); !ok {
+		t.Fatalf("c.Get(%q, %q) = _, %v, want = _, %v\n", "models", "key", ok, true)
+	}
+
+	time.Sleep(time.Millisecond * 2)
+
+	if _, ok := c.Get("models", "key"); ok {
+		t.Fatalf("c.Get(%q, %q) = _, %v, want = _, %v\n", "models", "key", ok, false)
+	}
+}
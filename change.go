@@ -0,0 +1,222 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Change is a change made to the models in a table by a [Store]. Changes are
+// published to the subscribers of a [ChangeFeed].
+type Change struct {
+	Table string
+	Op    Op
+
+	// PrimaryKey is the primary key of the model that was changed. This is nil
+	// for changes made to every row matching a query, such as via
+	// [Store.UpdateMany], or [Store.Truncate].
+	PrimaryKey *PrimaryKey
+
+	// Params are the values of the params that were written, keyed by column.
+	// This is nil for deletes.
+	Params map[string]any
+}
+
+// ChangeFeed publishes the [Change] events emitted by the writes of a [Store]
+// to its subscribers. This can be used to keep search indexes or caches up to
+// date without relying on triggers in the database. A ChangeFeed is safe for
+// concurrent use, and can be shared between multiple stores.
+type ChangeFeed struct {
+	mu   sync.RWMutex
+	next int
+	subs map[int]func(ctx context.Context, c *Change)
+}
+
+// NewChangeFeed returns a new [ChangeFeed] with no subscribers.
+func NewChangeFeed() *ChangeFeed {
+	return &ChangeFeed{
+		subs: make(map[int]func(ctx context.Context, c *Change)),
+	}
+}
+
+// Subscribe adds the given function as a subscriber of the feed, and returns a
+// function for unsubscribing it. Subscribers are called synchronously with the
+// context of the write, so a slow subscriber will slow down the writes of the
+// store, and should hand the change off to a goroutine if need be. A
+// subscriber may unsubscribe from within its own callback.
+func (f *ChangeFeed) Subscribe(fn func(ctx context.Context, c *Change)) func() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := f.next
+	f.next++
+
+	f.subs[id] = fn
+
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		delete(f.subs, id)
+	}
+}
+
+// publish calls the subscribers of the feed with each of the given changes.
+// The subscribers are called in the order they subscribed, without the lock
+// held, so a subscriber can subscribe or unsubscribe from within its callback.
+func (f *ChangeFeed) publish(ctx context.Context, cc []*Change) {
+	f.mu.RLock()
+
+	subs := make([]func(ctx context.Context, c *Change), 0, len(f.subs))
+
+	for _, id := range slices.Sorted(maps.Keys(f.subs)) {
+		subs = append(subs, f.subs[id])
+	}
+
+	f.mu.RUnlock()
+
+	for _, c := range cc {
+		for _, fn := range subs {
+			fn(ctx, c)
+		}
+	}
+}
+
+// WithChangeFeed configures the [ChangeFeed] a [Store] publishes a [Change]
+// to for every model it creates, updates, or deletes.
+//
+// Changes made within a transaction begun via [Tx], or [Store.WithTx], are
+// only published once the transaction is committed, and are discarded if it
+// is rolled back. Changes made within any other transaction are published
+// immediately. Models created via [Store.CopyFrom] are not published, since
+// bulk loads would overwhelm the subscribers. Models skipped by
+// [Store.CreateIgnore] are still published, since it cannot be known which
+// models were skipped.
+func WithChangeFeed(f *ChangeFeed) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.changes = f
+	}
+}
+
+// pendingChanges are the changes made within a transaction begun via Tx, that
+// are waiting on the transaction to be committed. Each pending change is
+// either changes to publish to a ChangeFeed, or a table to invalidate in a
+// Cache.
+type pendingChanges struct {
+	mu      sync.Mutex
+	changes []pendingChange
+}
+
+type pendingChange struct {
+	ctx  context.Context
+	feed *ChangeFeed
+	cc   []*Change
+
+	cache Cache
+	table string
+}
+
+// txChanges holds the pendingChanges for each transaction begun via Tx.
+var txChanges sync.Map
+
+// txPending returns the pendingChanges of the given database if it is a
+// transaction begun via Tx.
+func txPending(db DB) (*pendingChanges, bool) {
+	tx, ok := db.(*sql.Tx)
+
+	if !ok {
+		return nil, false
+	}
+
+	v, ok := txChanges.Load(tx)
+
+	if !ok {
+		return nil, false
+	}
+	return v.(*pendingChanges), true
+}
+
+func (p *pendingChanges) add(pc pendingChange) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.changes = append(p.changes, pc)
+}
+
+func (p *pendingChanges) len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.changes)
+}
+
+// truncate discards the changes made after the first n, such as when a
+// savepoint is rolled back.
+func (p *pendingChanges) truncate(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.changes = p.changes[:n]
+}
+
+func (p *pendingChanges) publish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, pc := range p.changes {
+		if pc.feed != nil {
+			pc.feed.publish(pc.ctx, pc.cc)
+		}
+
+		if pc.cache != nil {
+			pc.cache.Invalidate(pc.table)
+		}
+	}
+}
+
+// changed publishes the given changes to the store's ChangeFeed, if any. If
+// the store is operating on a transaction begun via Tx, then the changes are
+// held until the transaction is committed.
+func (s *Store[M]) changed(ctx context.Context, cc ...*Change) {
+	if s.cfg.changes == nil || len(cc) == 0 {
+		return
+	}
+
+	if p, ok := txPending(s.DB); ok {
+		p.add(pendingChange{
+			ctx:  ctx,
+			feed: s.cfg.changes,
+			cc:   cc,
+		})
+		return
+	}
+	s.cfg.changes.publish(ctx, cc)
+}
+
+// modelChanges returns a Change for each of the given models, with the given
+// Params of each model as returned from prepareParams.
+func modelChanges[M Model](table string, op Op, mode paramMode, mm []M, params []Params) []*Change {
+	cc := make([]*Change, 0, len(mm))
+
+	for i, m := range mm {
+		c := &Change{
+			Table:      table,
+			Op:         op,
+			PrimaryKey: m.PrimaryKey(),
+		}
+
+		if params != nil {
+			c.Params = make(map[string]any)
+
+			for col, p := range params[i] {
+				if p.mode.has(mode) {
+					c.Params[col] = p.value
+				}
+			}
+		}
+		cc = append(cc, c)
+	}
+	return cc
+}
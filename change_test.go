@@ -0,0 +1,196 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestChangeFeed(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, taskSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", taskSchema, err)
+	}
+
+	feed := NewChangeFeed()
+
+	var changes []*Change
+
+	unsubscribe := feed.Subscribe(func(_ context.Context, c *Change) {
+		changes = append(changes, c)
+	})
+
+	store := NewStore(db, func() *Task {
+		return &Task{}
+	}, WithChangeFeed(feed))
+
+	task := &Task{ID: 1}
+
+	if err := store.Create(ctx, task); err != nil {
+		t.Fatalf("store.Create(ctx, task): %v\n", err)
+	}
+
+	task.Status = "done"
+
+	if _, err := store.Update(ctx, task); err != nil {
+		t.Fatalf("store.Update(ctx, task): %v\n", err)
+	}
+
+	fields := map[string]any{"status": "archived"}
+
+	if _, err := store.UpdateMany(ctx, fields, query.WhereEq("id", query.Arg(1))); err != nil {
+		t.Fatalf("store.UpdateMany(ctx, fields): %v\n", err)
+	}
+
+	if _, err := store.Delete(ctx, task); err != nil {
+		t.Fatalf("store.Delete(ctx, task): %v\n", err)
+	}
+
+	ops := make([]Op, 0, len(changes))
+
+	for _, c := range changes {
+		if c.Table != "tasks" {
+			t.Errorf("c.Table = %q, want = %q\n", c.Table, "tasks")
+		}
+		ops = append(ops, c.Op)
+	}
+
+	want := []Op{OpCreate, OpUpdate, OpUpdate, OpDelete}
+
+	if !slices.Equal(ops, want) {
+		t.Fatalf("ops = %v, want = %v\n", ops, want)
+	}
+
+	if changes[0].Params["status"] != "pending" {
+		t.Fatalf("changes[0].Params[%q] = %v, want = %v\n", "status", changes[0].Params["status"], "pending")
+	}
+
+	if _, ok := changes[1].Params["id"]; ok {
+		t.Fatalf("changes[1].Params has create only param %q\n", "id")
+	}
+
+	if changes[1].PrimaryKey.Values[0] != int64(1) {
+		t.Fatalf("changes[1].PrimaryKey.Values[0] = %v, want = %v\n", changes[1].PrimaryKey.Values[0], 1)
+	}
+
+	if changes[2].PrimaryKey != nil {
+		t.Fatalf("changes[2].PrimaryKey = %v, want = %v\n", changes[2].PrimaryKey, nil)
+	}
+
+	if changes[3].Params != nil {
+		t.Fatalf("changes[3].Params = %v, want = %v\n", changes[3].Params, nil)
+	}
+
+	unsubscribe()
+
+	if err := store.Create(ctx, &Task{ID: 2}); err != nil {
+		t.Fatalf("store.Create(ctx, &Task{}): %v\n", err)
+	}
+
+	if len(changes) != len(want) {
+		t.Fatalf("len(changes) = %v, want = %v\n", len(changes), len(want))
+	}
+}
+
+func TestChangeFeedUnsubscribe(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	feed := NewChangeFeed()
+
+	var (
+		n           int
+		unsubscribe func()
+	)
+
+	// Unsubscribing from within the callback should not deadlock, and should
+	// stop any further changes from being received.
+	unsubscribe = feed.Subscribe(func(_ context.Context, _ *Change) {
+		n++
+		unsubscribe()
+	})
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		feed.publish(ctx, []*Change{{Table: "tasks", Op: OpCreate}})
+		feed.publish(ctx, []*Change{{Table: "tasks", Op: OpUpdate}})
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("feed.publish(ctx, ...): deadlocked unsubscribing from callback")
+	}
+
+	if n != 1 {
+		t.Fatalf("n = %v, want = %v\n", n, 1)
+	}
+}
+
+func TestChangeFeedTx(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, taskSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", taskSchema, err)
+	}
+
+	feed := NewChangeFeed()
+
+	var changes []*Change
+
+	feed.Subscribe(func(_ context.Context, c *Change) {
+		changes = append(changes, c)
+	})
+
+	store := NewStore(db, func() *Task {
+		return &Task{}
+	}, WithChangeFeed(feed))
+
+	errRollback := errors.New("rollback")
+
+	err := Tx(ctx, db, func(tx *sql.Tx) error {
+		if err := store.With(tx).Create(ctx, &Task{ID: 1}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Tx(ctx, db): %v, want = %v\n", err, errRollback)
+	}
+
+	if len(changes) != 0 {
+		t.Fatalf("len(changes) = %v, want = %v\n", len(changes), 0)
+	}
+
+	err = Tx(ctx, db, func(tx *sql.Tx) error {
+		if err := store.With(tx).Create(ctx, &Task{ID: 1}); err != nil {
+			return err
+		}
+
+		// Nothing is published until the transaction is committed.
+		if len(changes) != 0 {
+			t.Errorf("len(changes) = %v, want = %v\n", len(changes), 0)
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Tx(ctx, db): %v\n", err)
+	}
+
+	if len(changes) != 1 {
+		t.Fatalf("len(changes) = %v, want = %v\n", len(changes), 1)
+	}
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the interface that wraps the Now method for getting the current
+// time. This is used by a [Store] for anything it timestamps, such as a
+// [DefaultParam] given [Now], so that tests can freeze time instead of
+// sleeping, or comparing times with a tolerance.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock is the [Clock] that returns the current system time. This is
+// the Clock used by a [Store] unless configured otherwise via [WithClock].
+var SystemClock Clock = systemClock{}
+
+// FrozenClock is a [Clock] that is frozen at a point in time, and only moves
+// when it is set or advanced. This is intended for use in tests. It is safe
+// for concurrent use.
+type FrozenClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+var _ Clock = (*FrozenClock)(nil)
+
+// NewFrozenClock returns a [FrozenClock] frozen at the given time.
+func NewFrozenClock(t time.Time) *FrozenClock {
+	return &FrozenClock{
+		t: t,
+	}
+}
+
+// Now implements [Clock].
+func (c *FrozenClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.t
+}
+
+// Set sets the time of the clock to the given time.
+func (c *FrozenClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.t = t
+}
+
+// Advance moves the time of the clock forward by the given duration.
+func (c *FrozenClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.t = c.t.Add(d)
+}
+
+// WithClock configures the [Clock] a [Store] uses for getting the current
+// time.
+func WithClock(c Clock) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.clock = c
+	}
+}
+
+type nowDefault struct{}
+
+// Now can be given as the default of a [DefaultParam], in which case the
+// default is the current time as given by the [Clock] of the [Store], for
+// example,
+//
+//	"created_at": database.DefaultParam(p.CreatedAt, database.Now),
+//
+// The time is given in UTC.
+var Now = nowDefault{}
+
+// getClock returns the Clock of the store, falling back to the SystemClock.
+func (cfg *storeConfig) getClock() Clock {
+	if cfg.clock == nil {
+		return SystemClock
+	}
+	return cfg.clock
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, taskSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", taskSchema, err)
+	}
+
+	clock := NewFrozenClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	store := NewStore(db, func() *Task {
+		return &Task{}
+	}, WithClock(clock))
+
+	task := &Task{ID: 1}
+
+	if err := store.Create(ctx, task); err != nil {
+		t.Fatalf("store.Create(ctx, task): %v\n", err)
+	}
+
+	if !task.CreatedAt.Equal(clock.Now()) {
+		t.Fatalf("task.CreatedAt = %v, want = %v\n", task.CreatedAt, clock.Now())
+	}
+
+	clock.Advance(time.Hour)
+
+	mem := NewMemoryStore(func() *Task {
+		return &Task{}
+	})
+	mem.SetClock(clock)
+
+	task = &Task{ID: 2}
+
+	if err := mem.Create(ctx, task); err != nil {
+		t.Fatalf("mem.Create(ctx, task): %v\n", err)
+	}
+
+	want := time.Date(2020, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	if !task.CreatedAt.Equal(want) {
+		t.Fatalf("task.CreatedAt = %v, want = %v\n", task.CreatedAt, want)
+	}
+}
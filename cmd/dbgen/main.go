@@ -0,0 +1,85 @@
+// Command dbgen generates Go models from the schema of a live SQLite database.
+//
+// Usage:
+//
+//	dbgen [-pkg name] [-o file] <database> [tables...]
+//
+// The models for every table in the database are generated, unless a list of
+// tables is given. The generated code is written to stdout, unless an output
+// file is given.
+//
+// Only SQLite is supported by this command, since it is the only driver this
+// module depends on. For PostgreSQL and MySQL, use the gen package from a
+// program that imports the respective driver.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/andrewpillar/database"
+	"github.com/andrewpillar/database/gen"
+
+	_ "modernc.org/sqlite"
+)
+
+func run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+
+	pkg := fs.String("pkg", "models", "the package name of the generated code")
+	out := fs.String("o", "", "the file to write the generated code to")
+
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s [-pkg name] [-o file] <database> [tables...]\n", args[0])
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		return fmt.Errorf("missing database")
+	}
+
+	db, err := sql.Open("sqlite", fs.Arg(0))
+
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	tables, err := gen.Inspect(ctx, db, database.SQLite, fs.Args()[1:]...)
+
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+
+	if *out != "" {
+		f, err := os.Create(*out)
+
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		w = f
+	}
+	return gen.Generate(w, *pkg, tables)
+}
+
+func main() {
+	if err := run(context.Background(), os.Args); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", os.Args[0], err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"database/sql"
+)
+
+// RowToFunc is a function that scans the current row of the given [Scanner]
+// into a value of type T. This is used by [CollectRows] and [CollectOneRow].
+type RowToFunc[T any] func(sc *Scanner) (T, error)
+
+// RowToStruct scans the current row into a struct of type T via
+// [Scanner.ScanStruct]. T is expected to be a struct type, and not a pointer.
+func RowToStruct[T any](sc *Scanner) (T, error) {
+	var t T
+
+	if err := sc.ScanStruct(&t); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+// RowToModel returns a [RowToFunc] that scans the current row into a new Model
+// returned from the given callback via [Scanner.Scan].
+func RowToModel[M Model](new func() M) RowToFunc[M] {
+	return func(sc *Scanner) (M, error) {
+		m := new()
+
+		if err := sc.Scan(m); err != nil {
+			var zero M
+			return zero, err
+		}
+		return m, nil
+	}
+}
+
+// CollectRows scans each of the given rows via the given function, returning
+// the results. The rows are closed once collected, for example,
+//
+//	rows, err := db.QueryContext(ctx, "SELECT * FROM users")
+//
+//	if err != nil {
+//	    // Handle error.
+//	}
+//
+//	uu, err := database.CollectRows(rows, database.RowToModel(func() *User {
+//	    return &User{}
+//	}))
+func CollectRows[T any](rows *sql.Rows, fn RowToFunc[T], opts ...ScannerOption) ([]T, error) {
+	defer rows.Close()
+
+	sc, err := NewScanner(rows, opts...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tt := make([]T, 0)
+
+	for rows.Next() {
+		t, err := fn(sc)
+
+		if err != nil {
+			return nil, err
+		}
+		tt = append(tt, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tt, nil
+}
+
+// CollectOneRow scans the first of the given rows via the given function,
+// returning the result. The rows are closed once collected. If there are no
+// rows then [sql.ErrNoRows] is returned.
+func CollectOneRow[T any](rows *sql.Rows, fn RowToFunc[T], opts ...ScannerOption) (T, error) {
+	defer rows.Close()
+
+	var zero T
+
+	sc, err := NewScanner(rows, opts...)
+
+	if err != nil {
+		return zero, err
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return zero, err
+		}
+		return zero, sql.ErrNoRows
+	}
+
+	t, err := fn(sc)
+
+	if err != nil {
+		return zero, err
+	}
+	return t, rows.Close()
+}
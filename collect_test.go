@@ -0,0 +1,58 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestCollectRows(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, userPostSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", userPostSchema, err)
+	}
+
+	stmt := "INSERT INTO users (id, email) VALUES (1, 'a@example.com'), (2, 'b@example.com')"
+
+	if _, err := db.ExecContext(ctx, stmt); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", stmt, err)
+	}
+
+	q := "SELECT * FROM users ORDER BY id"
+
+	rows, err := db.QueryContext(ctx, q)
+
+	if err != nil {
+		t.Fatalf("db.QueryContext(ctx, %q): %v\n", q, err)
+	}
+
+	uu, err := CollectRows(rows, RowToModel(func() *User {
+		return &User{}
+	}))
+
+	if err != nil {
+		t.Fatalf("CollectRows(rows, RowToModel(...)): %v\n", err)
+	}
+
+	if len(uu) != 2 {
+		t.Fatalf("len(uu) = %v, want = %v\n", len(uu), 2)
+	}
+
+	if uu[1].Email != "b@example.com" {
+		t.Fatalf("uu[1].Email = %q, want = %q\n", uu[1].Email, "b@example.com")
+	}
+
+	q = "SELECT email FROM users WHERE id = 3"
+
+	rows, err = db.QueryContext(ctx, q)
+
+	if err != nil {
+		t.Fatalf("db.QueryContext(ctx, %q): %v\n", q, err)
+	}
+
+	if _, err := CollectOneRow(rows, RowToStruct[User]); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("CollectOneRow(rows, RowToStruct[User]) = %v, want = %v\n", err, sql.ErrNoRows)
+	}
+}
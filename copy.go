@@ -0,0 +1,200 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+type preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// CopyFrom creates the models from the given iterator, returning the number of
+// models created. This is intended for bulk loading large numbers of models,
+// where holding every model in memory for [Store.Create] is not feasible. The
+// models are created within a single transaction if the store's [DB] is able
+// to begin one.
+//
+// If the store's [Dialect] is [Postgres], then the models are loaded via a
+// COPY ... FROM STDIN statement. This requires a driver that supports COPY
+// through prepared statements, which is only lib/pq. Other drivers, such as
+// pgx's stdlib package, return an error when the statement is prepared, so a
+// store using these should not be configured with the Postgres dialect for
+// CopyFrom. Otherwise, the models are created in chunks of multi-VALUES
+// INSERT statements, sized to the parameter limit of the dialect. The COPY
+// statement is observed like any other query of the store, though its plan is
+// never captured.
+//
+// Models that implement [Validator] are validated as they are read from the
+// iterator, and a [ValidationError] is returned for the first invalid model.
+func (s *Store[M]) CopyFrom(ctx context.Context, seq iter.Seq[M]) (int64, error) {
+	var n int64
+
+	err := s.atomic(ctx, func(s *Store[M]) error {
+		var err error
+
+		if _, ok := s.DB.(preparer); ok && s.cfg.dialect == Postgres {
+			n, err = s.copyIn(ctx, seq)
+			return err
+		}
+
+		n, err = s.copyChunked(ctx, seq)
+		return err
+	})
+
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func createCols(m Model) []string {
+	params := m.Params()
+	cols := make([]string, 0, len(params))
+
+	for name, param := range params.All() {
+		if param.mode.has(paramCreate) {
+			cols = append(cols, name)
+		}
+	}
+	return cols
+}
+
+func (s *Store[M]) copyChunked(ctx context.Context, seq iter.Seq[M]) (int64, error) {
+	var (
+		cols   []string
+		size   int
+		n      int64
+		chunk  []M
+		params []Params
+	)
+
+	for m := range seq {
+		p, err := s.cfg.prepareCreate(m)
+
+		if err != nil {
+			return n, err
+		}
+
+		if err := validate(ctx, m); err != nil {
+			return n, err
+		}
+
+		if cols == nil {
+			cols = createCols(m)
+			size = max(s.cfg.dialect.maxParams()/max(len(cols), 1), 1)
+			chunk = make([]M, 0, size)
+			params = make([]Params, 0, size)
+		}
+
+		chunk = append(chunk, m)
+		params = append(params, p)
+
+		if len(chunk) == size {
+			if _, err := s.create(ctx, noConflict, cols, chunk, params); err != nil {
+				return n, err
+			}
+
+			n += int64(len(chunk))
+			chunk = chunk[0:0]
+			params = params[0:0]
+		}
+	}
+
+	if len(chunk) > 0 {
+		if _, err := s.create(ctx, noConflict, cols, chunk, params); err != nil {
+			return n, err
+		}
+		n += int64(len(chunk))
+	}
+	return n, nil
+}
+
+func (s *Store[M]) copyIn(ctx context.Context, seq iter.Seq[M]) (int64, error) {
+	var (
+		cols []string
+		q    *query.Query
+		stmt *sql.Stmt
+		n    int64
+	)
+
+	start := time.Now()
+
+	err := func() error {
+		for m := range seq {
+			params, err := s.cfg.prepareCreate(m)
+
+			if err != nil {
+				return err
+			}
+
+			if err := validate(ctx, m); err != nil {
+				return err
+			}
+
+			if stmt == nil {
+				cols = createCols(m)
+				q = query.Copy(s.table, query.Columns(cols...))
+
+				var err error
+				stmt, err = s.DB.(preparer).PrepareContext(ctx, s.build(q))
+
+				if err != nil {
+					return err
+				}
+				defer stmt.Close()
+			}
+
+			vals := make([]any, 0, len(cols))
+
+			for _, col := range cols {
+				if _, ok := params[col].value.(query.Expr); ok {
+					return fmt.Errorf("cannot copy expression for param %s", col)
+				}
+
+				val, err := paramValue(s.cfg.dialect, s.cfg.keyring, s.cfg.mapper, m, col, params[col].value)
+
+				if err != nil {
+					return err
+				}
+				vals = append(vals, val)
+			}
+
+			if _, err := stmt.ExecContext(ctx, vals...); err != nil {
+				return err
+			}
+			n++
+		}
+
+		if stmt == nil {
+			return nil
+		}
+
+		// Calling Exec with no arguments flushes the buffered rows.
+		_, err := stmt.ExecContext(ctx)
+		return err
+	}()
+
+	if q != nil {
+		s.observe(ctx, s.DB, s.table, OpCreate, q, time.Since(start), err, false)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if q != nil && s.cfg.stats != nil {
+		s.cfg.stats.addRows(s.table, OpCreate, q, n)
+	}
+
+	if n > 0 {
+		wrote(ctx)
+		s.invalidate(s.table)
+	}
+	return n, nil
+}
@@ -0,0 +1,142 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestStoreCopyFrom(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	})
+
+	seq := func(yield func(*M) bool) {
+		for i := range 10 {
+			m := &M{
+				ID:   int64(i + 1),
+				Blob: []byte{},
+				Time: time.Now(),
+			}
+
+			if !yield(m) {
+				return
+			}
+		}
+	}
+
+	n, err := store.CopyFrom(ctx, seq)
+
+	if err != nil {
+		t.Fatalf("store.CopyFrom(ctx, seq): %v\n", err)
+	}
+
+	if n != 10 {
+		t.Fatalf("n = %v, want = %v\n", n, 10)
+	}
+
+	mm, err := store.Select(ctx, query.Columns("*"))
+
+	if err != nil {
+		t.Fatalf("store.Select(ctx, ...): %v\n", err)
+	}
+
+	if len(mm) != 10 {
+		t.Fatalf("len(mm) = %v, want = %v\n", len(mm), 10)
+	}
+}
+
+func TestStoreCopyFromChunked(t *testing.T) {
+	ctx := t.Context()
+
+	tests := []struct {
+		dialect Dialect
+		n       int
+		want    []int
+	}{
+		{SQLite, 5000, []int{4095, 905}},
+		// The recorder cannot prepare statements, so COPY is not used.
+		{Postgres, 20000, []int{8191, 8191, 3618}},
+	}
+
+	for _, test := range tests {
+		var rec execRecorder
+
+		store := NewStore[*M](&rec, func() *M {
+			return &M{}
+		}, WithDialect(test.dialect))
+
+		seq := func(yield func(*M) bool) {
+			for i := range test.n {
+				if !yield(&M{ID: int64(i)}) {
+					return
+				}
+			}
+		}
+
+		n, err := store.CopyFrom(ctx, seq)
+
+		if err != nil {
+			t.Fatalf("store.CopyFrom(ctx, seq): %v\n", err)
+		}
+
+		if n != int64(test.n) {
+			t.Fatalf("%s: n = %v, want = %v\n", test.dialect, n, test.n)
+		}
+
+		if len(rec.args) != len(test.want) {
+			t.Fatalf("%s: len(rec.args) = %v, want = %v\n", test.dialect, len(rec.args), len(test.want))
+		}
+
+		for i, args := range rec.args {
+			if n := len(args) / 8; n != test.want[i] {
+				t.Errorf("%s: rec.args[%v] = %v models, want = %v\n", test.dialect, i, n, test.want[i])
+			}
+		}
+	}
+}
+
+func TestStoreCopyFromObserve(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	var (
+		metrics metricsRecorder
+		events  eventRecorder
+	)
+
+	// SQLite cannot prepare a COPY statement, so the failed COPY is what is
+	// observed.
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	}, WithDialect(Postgres), WithMetrics(&metrics), WithLogger(&events))
+
+	seq := func(yield func(*M) bool) {
+		yield(&M{ID: 1, Blob: []byte{}, Time: time.Now()})
+	}
+
+	if _, err := store.CopyFrom(ctx, seq); err == nil {
+		t.Fatalf("store.CopyFrom(ctx, seq): expected error, got nil\n")
+	}
+
+	if len(metrics.ops) != 1 || metrics.ops[0] != OpCreate || metrics.errs[0] == nil {
+		t.Fatalf("metrics = %v %v, want = [%v] with error\n", metrics.ops, metrics.errs, OpCreate)
+	}
+
+	if len(events.events) != 1 {
+		t.Fatalf("len(events.events) = %v, want = %v\n", len(events.events), 1)
+	}
+
+	if q := events.events[0].Query; !strings.HasPrefix(q, "COPY models (") || !strings.HasSuffix(q, ") FROM STDIN") {
+		t.Fatalf("events.events[0].Query = %q, want COPY statement\n", q)
+	}
+}
@@ -3,27 +3,90 @@ package database
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"iter"
+	"maps"
+	"reflect"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/andrewpillar/database/query"
 )
 
+// Null represents a value that may be null. This wraps [sql.Null] so that it
+// can be used for scanning, as a query argument, and for encoding to and from
+// JSON.
 type Null[T any] struct {
 	sql.Null[T]
 }
 
+// Scan implements the [sql.Scanner] interface. In addition to what is
+// supported by [sql.Null], if T is a [time.Time] then timestamps stored as text
+// are parsed as per [DefaultTimeLayouts].
+func (n *Null[T]) Scan(src any) error {
+	err := n.Null.Scan(src)
+
+	if err == nil {
+		return nil
+	}
+
+	if v, ok := any(&n.V).(*time.Time); ok {
+		t, parsed, perr := parseTime(src, DefaultTimeLayouts)
+
+		if parsed && perr == nil {
+			*v = t
+			n.Valid = true
+			return nil
+		}
+	}
+	return err
+}
+
+// Value implements the [driver.Valuer] interface. Unlike [sql.Null], the
+// underlying value is converted to a [driver.Value], so types that are not
+// themselves valid driver values, such as int or a named string type, can be
+// passed as query arguments.
+func (n Null[T]) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(n.V)
+}
+
 // MarshalJSON returns the JSON representation of the null value. If the value
 // is null, then "null" is returned, otherwise the marshalled representation
 // of the underlying value is returned.
-func (n *Null[T]) MarshalJSON() ([]byte, error) {
+func (n Null[T]) MarshalJSON() ([]byte, error) {
 	if !n.Valid {
 		return []byte("null"), nil
 	}
 	return json.Marshal(n.V)
 }
 
+// UnmarshalJSON decodes the given JSON into the null value. If the JSON is
+// "null", then the value is set to null, otherwise the JSON is decoded into the
+// underlying value.
+func (n *Null[T]) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		var zero T
+
+		n.V = zero
+		n.Valid = false
+		return nil
+	}
+
+	if err := json.Unmarshal(b, &n.V); err != nil {
+		return err
+	}
+
+	n.Valid = true
+	return nil
+}
+
 // PrimaryKey represents the primary key of a model. This is typically used to
 // query individual models by their primary key. This also supports composite
 // keys too.
@@ -58,10 +121,21 @@ func (m paramMode) has(mask paramMode) bool {
 }
 
 // Param is the paramter of a model. This is used to determine what parameters
-// in a model can be created, or updated during model operations.
+// in a model can be created, or updated during model operations. If the value
+// of a Param is a [query.Expr], such as query.Lit("NOW()"), then the expression
+// is written into the query in place of a bound argument, and is evaluated by
+// the database.
 type Param struct {
 	mode  paramMode
 	value any
+	def   any
+
+	transforms []Transformer
+
+	// order is the position of the Param when declared via a
+	// [ParamsBuilder], starting at 1. This is zero for Params declared in a
+	// map literal.
+	order int
 }
 
 // MutableParam returns a [Param] that can be both created and updated on a
@@ -81,6 +155,28 @@ func CreateOnlyParam(v any) Param {
 	}
 }
 
+// DefaultParam returns a [Param] that can be both created and updated on a
+// model, like [MutableParam], only if v is the zero value of its type when the
+// model is created, then def is used instead. If def is a function that takes
+// no arguments and returns a single value, such as [time.Now], then it is called
+// each time a default is needed. If def is [Now], then the current time is
+// taken from the [Clock] of the [Store]. The default is also set on the struct
+// field of the model the Param maps to, for example,
+//
+//	func (p *Post) Params() database.Params {
+//	    return database.Params{
+//	        "status":     database.DefaultParam(p.Status, "draft"),
+//	        "created_at": database.DefaultParam(p.CreatedAt, database.Now),
+//	    }
+//	}
+func DefaultParam(v, def any) Param {
+	return Param{
+		mode:  paramCreate | paramUpdate,
+		value: v,
+		def:   def,
+	}
+}
+
 // UpdateOnlyParam returns a [Param] that can only be updated on a model.
 func UpdateOnlyParam(v any) Param {
 	return Param{
@@ -89,10 +185,216 @@ func UpdateOnlyParam(v any) Param {
 	}
 }
 
+// GeneratedParam returns a [Param] whose value is generated by the database,
+// such as a serial id, a column default, or a value maintained by a trigger.
+// The Param is never written by a create or update, but is still selected and
+// scanned. For dialects that support it, generated Params are returned via a
+// RETURNING clause when the model is created, and set on the model.
+func GeneratedParam(v any) Param {
+	return Param{
+		value: v,
+	}
+}
+
 // Params is a map of model parameters where the key is the respective column
-// name for that model's parameter in the database table.
+// name for that model's parameter in the database table. Since maps are
+// unordered, Params built via a [ParamsBuilder] record the order in which they
+// were declared, which is used when generating SQL.
 type Params map[string]Param
 
+// Columns returns the columns of the Params in a defined order. Params declared
+// via a [ParamsBuilder] are returned in the order they were declared, followed
+// by any other Params in alphabetical order.
+func (p Params) Columns() []string {
+	cols := make([]string, 0, len(p))
+
+	for col := range p {
+		cols = append(cols, col)
+	}
+
+	slices.SortFunc(cols, func(a, b string) int {
+		oa, ob := p[a].order, p[b].order
+
+		if oa != ob {
+			// Unordered Params sort after the ordered ones.
+			if oa == 0 {
+				return 1
+			}
+			if ob == 0 {
+				return -1
+			}
+			return oa - ob
+		}
+		return strings.Compare(a, b)
+	})
+	return cols
+}
+
+// All returns an iterator over the column and [Param] of each of the Params,
+// in the order returned by [Params.Columns].
+func (p Params) All() iter.Seq2[string, Param] {
+	return func(yield func(string, Param) bool) {
+		for _, col := range p.Columns() {
+			if !yield(col, p[col]) {
+				return
+			}
+		}
+	}
+}
+
+// ParamsBuilder builds [Params] that preserve the order in which each [Param]
+// was declared. This order is then used for the columns of any SQL that is
+// generated for the Model, for example,
+//
+//	func (p *Post) Params() database.Params {
+//	    return database.NewParams().
+//	        Add("id", database.CreateOnlyParam(p.ID)).
+//	        Add("title", database.MutableParam(p.Title)).
+//	        Add("content", database.MutableParam(p.Content)).
+//	        Params()
+//	}
+type ParamsBuilder struct {
+	params Params
+}
+
+// NewParams returns a new [ParamsBuilder].
+func NewParams() *ParamsBuilder {
+	return &ParamsBuilder{
+		params: make(Params),
+	}
+}
+
+// Add adds the given [Param] for the given column. This panics if a Param has
+// already been added for the column, since this would otherwise silently
+// overwrite the intent of the first Param.
+func (b *ParamsBuilder) Add(col string, p Param) *ParamsBuilder {
+	if _, ok := b.params[col]; ok {
+		panic("database: duplicate param " + col)
+	}
+
+	p.order = len(b.params) + 1
+	b.params[col] = p
+	return b
+}
+
+// Params returns the built [Params].
+func (b *ParamsBuilder) Params() Params {
+	return b.params
+}
+
+// defaultValue returns the default of the Param, calling it if it is a
+// function.
+func (p Param) defaultValue(clock Clock) any {
+	if _, ok := p.def.(nowDefault); ok {
+		return clock.Now().UTC()
+	}
+
+	rv := reflect.ValueOf(p.def)
+
+	if rv.Kind() == reflect.Func && rv.Type().NumIn() == 0 && rv.Type().NumOut() == 1 {
+		return rv.Call(nil)[0].Interface()
+	}
+	return p.def
+}
+
+// Transformer transforms the value of a [Param] before it is written to the
+// database, such as normalizing an email address, or hashing a password. The
+// returned value is what is written.
+type Transformer func(v any) (any, error)
+
+// Transform returns a copy of the Param that applies the given transformers, in
+// order, to its value whenever it is written to the database, for example,
+//
+//	func (u *User) Params() database.Params {
+//	    return database.Params{
+//	        "email": database.MutableParam(u.Email).Transform(database.TrimSpace, database.Lowercase),
+//	    }
+//	}
+//
+// The transformed value is also set on the struct field of the model the Param
+// maps to. Since a value read back from the database would be transformed again
+// when next written, transformers should be idempotent, or the Param should be
+// create only, as would be the case with hashing.
+func (p Param) Transform(fns ...Transformer) Param {
+	p.transforms = append(slices.Clip(p.transforms), fns...)
+	return p
+}
+
+// transform applies the transformers of the Param, in order, to the given
+// value for the given column.
+func (p Param) transform(col string, v any) (any, error) {
+	for _, fn := range p.transforms {
+		var err error
+
+		v, err = fn(v)
+
+		if err != nil {
+			return nil, fmt.Errorf("transform param %s: %w", col, err)
+		}
+	}
+	return v, nil
+}
+
+// TrimSpace is a [Transformer] that trims the leading and trailing whitespace
+// from string values. Values of any other type are returned as is.
+func TrimSpace(v any) (any, error) {
+	if s, ok := v.(string); ok {
+		return strings.TrimSpace(s), nil
+	}
+	return v, nil
+}
+
+// Lowercase is a [Transformer] that converts string values to lower case.
+// Values of any other type are returned as is.
+func Lowercase(v any) (any, error) {
+	if s, ok := v.(string); ok {
+		return strings.ToLower(s), nil
+	}
+	return v, nil
+}
+
+// prepareParams returns the Params of the given model, with the values that
+// would be written for the given mode. When creating, any [DefaultParam] that
+// is zero is given its default, with the current time taken from the given
+// Clock. The transformers of each Param are then applied. Any value that
+// changes is set on the struct field of the model the Param maps to via the
+// given Mapper, if any.
+func prepareParams(m Model, mode paramMode, clock Clock, mapper *Mapper) (Params, error) {
+	params := m.Params()
+
+	for col, p := range params {
+		if !p.mode.has(mode) || (p.def == nil && len(p.transforms) == 0) {
+			continue
+		}
+
+		v := p.value
+
+		if mode == paramCreate && p.def != nil {
+			if rv := reflect.ValueOf(v); !rv.IsValid() || rv.IsZero() {
+				v = p.defaultValue(clock)
+			}
+		}
+
+		v, err := p.transform(col, v)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Expressions are evaluated by the database, so there is nothing to
+		// set on the model.
+		if _, ok := v.(query.Expr); !ok {
+			if err := setColumn(mapper, m, col, v); err != nil && !errors.Is(err, errUnknownColumn) {
+				return nil, err
+			}
+		}
+
+		p.value = v
+		params[col] = p
+	}
+	return params, nil
+}
+
 // Model is the interface that represents data in a database table.  It wraps
 // three methods.
 //
@@ -137,37 +439,175 @@ func List[M Model](col string, mm ...M) query.Expr {
 //	posts.id, posts.user_id, posts.title, users.id AS users.id, users.email AS users.email
 //
 // Assuming that both the Post and User model have the above columns names.
-func Columns(primary Model, joins ...Model) query.Expr {
-	params := primary.Params()
-	table := primary.Table()
+// The columns can be narrowed via [ColumnsExpr.Only] and [ColumnsExpr.Except].
+func Columns(primary Model, joins ...Model) *ColumnsExpr {
+	return &ColumnsExpr{
+		primary: primary,
+		joins:   joins,
+	}
+}
+
+// ColumnsExpr is the column [query.Expr] returned from [Columns].
+type ColumnsExpr struct {
+	primary Model
+	joins   []Model
+	only    []string
+	except  []string
+}
+
+var _ query.Expr = (*ColumnsExpr)(nil)
+
+// columns returns the qualified columns of the expression's models, in the
+// order they are selected.
+func (e *ColumnsExpr) columns() []string {
+	cols := make([]string, 0)
+
+	for _, m := range append([]Model{e.primary}, e.joins...) {
+		table := m.Table()
+
+		for _, fld := range m.Params().Columns() {
+			cols = append(cols, fmt.Sprintf("%s.%s", table, fld))
+		}
+	}
+	return cols
+}
+
+// check panics if any of the given columns are not columns of the expression's
+// models, so that a misspelled column is not silently selected, or omitted.
+func (e *ColumnsExpr) check(cols []string) {
+	all := e.columns()
+	table := e.primary.Table()
+
+	for _, col := range cols {
+		if !slices.Contains(all, col) && !slices.Contains(all, table+"."+col) {
+			panic("database: unknown column " + col + " for " + table)
+		}
+	}
+}
+
+// Only returns a copy of the expression that selects only the given columns.
+// Columns of the primary Model can be given unqualified, whereas the columns
+// of joined models must be qualified with their table name, for example,
+//
+//	database.Columns(&Post{}, &User{}).Only("id", "title", "users.email")
+//
+// This panics if a column is not a column of the models.
+func (e *ColumnsExpr) Only(cols ...string) *ColumnsExpr {
+	e.check(cols)
+
+	cp := *e
+	cp.only = append(slices.Clone(e.only), cols...)
+	return &cp
+}
+
+// Except returns a copy of the expression that selects every column except the
+// given columns, such as columns that hold large amounts of data that would not
+// be needed in a list view, for example,
+//
+//	database.Columns(&Post{}).Except("content")
+//
+// Columns are given as per [ColumnsExpr.Only]. This panics if a column is not
+// a column of the models.
+func (e *ColumnsExpr) Except(cols ...string) *ColumnsExpr {
+	e.check(cols)
+
+	cp := *e
+	cp.except = append(slices.Clone(e.except), cols...)
+	return &cp
+}
+
+// includes reports whether the given column of the given table is selected.
+func (e *ColumnsExpr) includes(table, col string) bool {
+	names := []string{table + "." + col}
+
+	if table == e.primary.Table() {
+		names = append(names, col)
+	}
+
+	match := func(cols []string) bool {
+		for _, name := range names {
+			if slices.Contains(cols, name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(e.only) > 0 && !match(e.only) {
+		return false
+	}
+	return !match(e.except)
+}
+
+func (e *ColumnsExpr) expr() query.Expr {
+	table := e.primary.Table()
+	params := e.primary.Params()
 
 	cols := make([]string, 0, len(params))
 
-	for fld := range params {
-		cols = append(cols, fmt.Sprintf("%s.%s", table, fld))
+	for _, fld := range params.Columns() {
+		if e.includes(table, fld) {
+			cols = append(cols, fmt.Sprintf("%s.%s", table, fld))
+		}
 	}
 
-	if len(joins) == 0 {
+	if len(e.joins) == 0 {
 		return query.Columns(cols...)
 	}
 
-	exprs := []query.Expr{
-		query.Columns(cols...),
+	exprs := make([]query.Expr, 0, len(e.joins)+1)
+
+	if len(cols) > 0 {
+		exprs = append(exprs, query.Columns(cols...))
 	}
 
-	for _, m := range joins {
-		params := m.Params()
+	for _, m := range e.joins {
 		table := m.Table()
 
-		for fld := range params {
-			fullname := fmt.Sprintf("%s.%s", table, fld)
+		for _, fld := range m.Params().Columns() {
+			if e.includes(table, fld) {
+				fullname := fmt.Sprintf("%s.%s", table, fld)
 
-			exprs = append(exprs, query.ColumnAs(fullname, fullname))
+				exprs = append(exprs, query.ColumnAs(fullname, fullname))
+			}
 		}
 	}
 	return query.Exprs(exprs...)
 }
 
+func (e *ColumnsExpr) Args() []any   { return nil }
+func (e *ColumnsExpr) Build() string { return e.expr().Build() }
+
+// ColumnsAs returns the column [query.Expr] for the columns in the given joined
+// Model under the given table alias. Each column is prefixed with, and aliased
+// to, the alias, for example,
+//
+//	database.ColumnsAs(&User{}, "sender")
+//
+// would result in the following SQL code,
+//
+//	sender.id AS sender.id, sender.email AS sender.email
+//
+// This would be used alongside [JoinAs].
+func ColumnsAs(m Model, alias string) query.Expr {
+	return query.Exprs(joinColumns(m, alias)...)
+}
+
+// joinColumns returns the columns of the given joined Model, qualified with, and
+// aliased to, the given table name or alias so they can be scanned via a prefix
+// struct tag, such as `db:"users.*:*"`.
+func joinColumns(m Model, table string) []query.Expr {
+	params := m.Params()
+	exprs := make([]query.Expr, 0, len(params))
+
+	for _, fld := range params.Columns() {
+		fullname := fmt.Sprintf("%s.%s", table, fld)
+
+		exprs = append(exprs, query.ColumnAs(fullname, fullname))
+	}
+	return exprs
+}
+
 // Join returns a JOIN clause on the given [Model], using the given fields. The
 // given fields are expected to be foreign keys in the table onto which the data
 // is being joined. For example, assume a query is being written that wants to
@@ -201,220 +641,1343 @@ func Columns(primary Model, joins ...Model) query.Expr {
 //
 //	database.Join(&Table2{}, "t2_field_1", "t2_field_2")
 func Join(m Model, fields ...string) query.Option {
-	pk := m.PrimaryKey()
-	table := m.Table()
-
-	exprs := make([]query.Expr, 0, len(pk.Columns))
-
-	for i, col := range pk.Columns {
-		foreign := fields[i]
-		primary := fmt.Sprintf("%s.%s", table, col)
+	return joinAs(m, "", fields...)
+}
 
-		exprs = append(exprs, query.Eq(query.Ident(foreign), query.Ident(primary)))
-	}
-	return query.Join(table, query.And(exprs...))
+// JoinAs returns a JOIN clause on the given [Model] under the given table
+// alias, as per [Join]. This would be used alongside [ColumnsAs] for joining
+// the same table multiple times, for example,
+//
+//	q := query.Select(
+//	    query.Exprs(
+//	        database.Columns(&Message{}),
+//	        database.ColumnsAs(&User{}, "sender"),
+//	        database.ColumnsAs(&User{}, "recipient"),
+//	    ),
+//	    database.JoinAs(&User{}, "sender", "messages.sender_id"),
+//	    database.JoinAs(&User{}, "recipient", "messages.recipient_id"),
+//	)
+//
+// The columns of each joined Model would then be scanned via the struct tags
+// `db:"sender.*:*"` and `db:"recipient.*:*"` respectively.
+func JoinAs(m Model, alias string, fields ...string) query.Option {
+	return joinAs(m, alias, fields...)
 }
 
-// Store handles the create, read, update, and delete operations of the [Model].
-type Store[M Model] struct {
-	*sql.DB
+func joinAs(m Model, alias string, fields ...string) query.Option {
+	return joinOn(m, alias, fields, m.PrimaryKey().Columns)
+}
 
-	table string
-	new   func() M
+// JoinOn returns a JOIN clause on the given [Model], joining each of the given
+// fields against the column of the model at the same position, rather than
+// against its [PrimaryKey]. This allows for joining on a natural key, or any
+// other unique column, for example,
+//
+//	database.JoinOn(&User{}, []string{"invites.email"}, []string{"email"})
+//
+// would result in the following SQL code when built,
+//
+//	JOIN users ON invites.email = users.email
+//
+// This will panic if the number of fields and columns differ.
+func JoinOn(m Model, fields, cols []string) query.Option {
+	return joinOn(m, "", fields, cols)
 }
 
-// NewStore returns a new store for the given [Model]. This takes a database
-// connection and a callback function. The callback function is used for
-// instantiating new models whenever a model is queried from the database.
-func NewStore[M Model](db *sql.DB, new func() M) *Store[M] {
-	m := new()
+// JoinOnAs is like [JoinOn], only the model is joined under the given table
+// alias, as per [JoinAs].
+func JoinOnAs(m Model, alias string, fields, cols []string) query.Option {
+	return joinOn(m, alias, fields, cols)
+}
 
-	return &Store[M]{
-		DB:    db,
-		table: m.Table(),
-		new:   new,
+func joinOn(m Model, alias string, fields, cols []string) query.Option {
+	if len(fields) != len(cols) {
+		panic(fmt.Sprintf("database: cannot join %s, have %d fields for %d columns", m.Table(), len(fields), len(cols)))
 	}
-}
 
-type execFunc func(context.Context, string, ...any) (sql.Result, error)
+	prefix := m.Table()
 
-func (s *Store[M]) doCreate(ctx context.Context, execFn execFunc, mm ...M) error {
-	if len(mm) == 0 {
-		return nil
+	if alias != "" {
+		prefix = alias
 	}
 
-	m := mm[0]
+	exprs := make([]query.Expr, 0, len(cols))
 
-	params := m.Params()
-	cols := make([]string, 0, len(params))
+	for i, col := range cols {
+		exprs = append(exprs, query.Eq(query.Ident(fields[i]), query.Ident(prefix+"."+col)))
+	}
 
-	for name, param := range params {
-		if param.mode.has(paramCreate) {
-			cols = append(cols, name)
-		}
+	if alias != "" {
+		return query.JoinAs(m.Table(), alias, query.And(exprs...))
 	}
+	return query.Join(m.Table(), query.And(exprs...))
+}
 
-	opts := make([]query.Option, 0, len(mm))
-	vals := make([]any, 0)
+// DB is the interface that wraps the methods used for running queries against
+// a database. This is satisfied by [sql.DB], [sql.Tx], and [sql.Conn], which
+// allows for a [Store] to operate either on a database connection directly, or
+// within a transaction. The native interface of pgx is not supported, instead
+// pgx can be used via its database/sql adapter in the stdlib package.
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 
-	for _, m := range mm {
-		params := m.Params()
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 
-		for _, col := range cols {
-			vals = append(vals, params[col].value)
-		}
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
 
-		opts = append(opts, query.Values(vals...))
-		vals = vals[0:0]
-	}
+var (
+	_ DB = (*sql.DB)(nil)
+	_ DB = (*sql.Tx)(nil)
+	_ DB = (*sql.Conn)(nil)
+)
 
-	q := query.Insert(s.table, query.Columns(cols...), opts...)
+// Storer is the interface that wraps the create, read, update, and delete
+// operations of a [Model]. This is satisfied by [Store], and [MemoryStore],
+// so code that depends on Storer can be tested without a database.
+type Storer[M Model] interface {
+	Create(ctx context.Context, mm ...M) error
 
-	_, err := execFn(ctx, q.Build(), q.Args()...)
+	All(ctx context.Context, expr query.Expr, opts ...query.Option) iter.Seq2[M, error]
 
-	return err
-}
+	Select(ctx context.Context, expr query.Expr, opts ...query.Option) ([]M, error)
 
-// Create the given models.
-func (s *Store[M]) Create(ctx context.Context, mm ...M) error {
-	return s.doCreate(ctx, s.ExecContext, mm...)
-}
+	Get(ctx context.Context, opts ...query.Option) (M, bool, error)
 
-// CreateTx creates the given models using the given transaction.
-func (s *Store[M]) CreateTx(ctx context.Context, tx *sql.Tx, mm ...M) error {
-	return s.doCreate(ctx, tx.ExecContext, mm...)
-}
+	SelectAll(ctx context.Context, opts ...query.Option) ([]M, error)
 
-type queryFunc func(context.Context, string, ...any) (*sql.Rows, error)
+	SelectOne(ctx context.Context, expr query.Expr, opts ...query.Option) (M, bool, error)
 
-func (s *Store[M]) doSelect(ctx context.Context, queryFn queryFunc, expr query.Expr, opts ...query.Option) ([]M, error) {
-	opts = append([]query.Option{
-		query.From(s.table),
-	}, opts...)
+	Count(ctx context.Context, opts ...query.Option) (int64, error)
 
-	q := query.Select(expr, opts...)
+	Update(ctx context.Context, m M) (sql.Result, error)
 
-	rows, err := queryFn(ctx, q.Build(), q.Args()...)
+	UpdateMany(ctx context.Context, fields map[string]any, opts ...query.Option) (sql.Result, error)
 
-	if err != nil {
-		return nil, err
-	}
+	Delete(ctx context.Context, mm ...M) (sql.Result, error)
+}
 
-	defer rows.Close()
+var _ Storer[Model] = (*Store[Model])(nil)
 
-	sc, err := NewScanner(rows)
+// Store handles the create, read, update, and delete operations of the [Model].
+type Store[M Model] struct {
+	DB
+
+	table    string
+	part     string
+	new      func() M
+	cfg      storeConfig
+	preloads []preload[M]
+	scopes   []ScopeFunc
+	spans    []string
+}
 
-	if err != nil {
-		return nil, err
-	}
+type storeConfig struct {
+	dialect Dialect
+	metrics Metrics
+	stats   *QueryStats
+	logger  Logger
+	pool    *poolSampler
+	changes *ChangeFeed
+	clock   Clock
+	ids     IDGenerator
+	keyring *Keyring
 
-	mm := make([]M, 0)
+	partitions Partitioner
 
-	for rows.Next() {
-		m := s.new()
+	slowThreshold time.Duration
+	explainSlow   bool
 
-		if err := sc.Scan(m); err != nil {
-			return nil, err
-		}
-		mm = append(mm, m)
-	}
+	retry RetryPolicy
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+	replicas *replicaSet
+	cache    Cache
+	mapper   *Mapper
+
+	timeLayouts []string
+	strict      bool
+	direct      bool
+}
+
+// StoreOption is a function that configures a [Store] when it is created via
+// [NewStore].
+type StoreOption func(*storeConfig)
+
+// WithStrictColumns configures a [Store] to return a [ColumnScanError] for any
+// selected column that does not map to a field of the Model, as per
+// [UseStrictColumns].
+func WithStrictColumns() StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.strict = true
 	}
-	return mm, nil
 }
 
-// Select returns the models that match the given query options. The given
-// [query.Expr] should be the columns to select for the models.
-func (s *Store[M]) Select(ctx context.Context, expr query.Expr, opts ...query.Option) ([]M, error) {
-	return s.doSelect(ctx, s.QueryContext, expr, opts...)
+// WithDirectScan configures a [Store] to scan columns directly into the fields
+// of its Models where possible, as per [UseDirectScan].
+func WithDirectScan() StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.direct = true
+	}
 }
 
-func (s *Store[M]) doGet(ctx context.Context, queryFn queryFunc, opts ...query.Option) (M, bool, error) {
-	var zero M
+// WithDialect configures the [Dialect] of the database that the [Store]
+// operates on. The queries of the store are built with the placeholders of
+// the dialect, so for [MySQL] these are built with ? placeholders.
+func WithDialect(d Dialect) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.dialect = d
+	}
+}
 
-	opts = append(opts, query.Limit(1))
+// NewStore returns a new store for the given [Model]. This takes a database
+// connection and a callback function. The callback function is used for
+// instantiating new models whenever a model is queried from the database. Any
+// given options are applied to the store.
+func NewStore[M Model](db DB, new func() M, opts ...StoreOption) *Store[M] {
+	m := new()
 
-	mm, err := s.doSelect(ctx, queryFn, query.Columns("*"), opts...)
+	s := &Store[M]{
+		DB:    db,
+		table: m.Table(),
+		new:   new,
+	}
 
-	if err != nil {
-		return zero, false, err
+	for _, opt := range opts {
+		opt(&s.cfg)
 	}
 
-	if len(mm) == 0 {
-		return zero, false, nil
+	if s.cfg.pool != nil {
+		s.cfg.pool.db, _ = db.(*sql.DB)
 	}
-	return mm[0], true, nil
+	return s
 }
 
-// Get returns the first model that can be found that matches the given query
-// options, and whether or not it was found via the bool return value.
+// With returns a copy of the store that operates on the given [DB]. This would
+// typically be used for performing operations within a transaction, for
+// example,
+//
+//	tx, err := db.BeginTx(ctx, nil)
+//
+//	if err != nil {
+//	    // Handle error.
+//	}
+//
+//	defer tx.Rollback()
+//
+//	p, ok, err := posts.With(tx).Get(ctx, query.WhereEq("id", query.Arg(10)))
+func (s *Store[M]) With(db DB) *Store[M] {
+	s2 := *s
+	s2.DB = db
+	s2.cfg.replicas = nil
+
+	return &s2
+}
+
+// Create the given models. If the number of parameters needed to create all of
+// the models exceeds the limit for the store's [Dialect], then the models are
+// created in chunks across multiple INSERT statements. These statements are
+// run within a single transaction if the store's [DB] is able to begin one.
+//
+// If the model's [PrimaryKey] is a single column that is not created via its
+// Params, then it is assumed to be generated by the database. For dialects that
+// support it, the generated keys are returned via a RETURNING clause and set on
+// each model, in the order the models were given. For [SQLite] and [MySQL], an
+// integer primary key that is zero for every model is also treated as being
+// generated, and is left out of the INSERT. MySQL does not support RETURNING,
+// so the generated key is taken from the LastInsertId of the result instead.
+//
+// The columns of any [GeneratedParam], or of any Param whose value is a
+// [query.Expr], are also set on each model once created. For dialects that do
+// not support RETURNING, these are selected by the primary key of each model
+// within the same transaction as the INSERT.
+//
+// Any [DefaultParam] that is zero is given its default, and any [Transformer]
+// is applied, before the models are created. If the models implement
+// [Validator], then each model is validated before anything is created, and a
+// [ValidationError] is returned for the first model that is invalid.
+func (s *Store[M]) Create(ctx context.Context, mm ...M) error {
+	_, err := s.createAll(ctx, noConflict, mm)
+	return err
+}
+
+// conflict is the conflict resolution used when creating models, along with
+// the conflict target of an upsert.
+type conflict struct {
+	action conflictAction
+	target []string
+}
+
+type conflictAction uint
+
+const (
+	conflictAbort conflictAction = iota
+	conflictIgnore
+	conflictReplace
+	conflictUpdate
+)
+
+var noConflict conflict
+
+// CreateIgnore creates the given models like [Store.Create], only any model
+// that would violate a unique constraint, such as a duplicate primary key, is
+// skipped instead of failing. The number of models that were created is
+// returned, which makes this suitable for idempotent seeding, for example,
+//
+//	n, err := users.CreateIgnore(ctx, DefaultUsers...)
+//
+// For [SQLite] this is done via INSERT OR IGNORE, for [Postgres] via ON
+// CONFLICT DO NOTHING, and for [MySQL] via INSERT IGNORE. Since it cannot be
+// known which models were skipped, generated values are not set on the
+// models.
+func (s *Store[M]) CreateIgnore(ctx context.Context, mm ...M) (int64, error) {
+	return s.createAll(ctx, conflict{action: conflictIgnore}, mm)
+}
+
+// CreateReplace creates the given models like [Store.Create], only any
+// existing row that would violate a uniqueness constraint, such as a duplicate
+// primary key, is deleted before the model is inserted. This is only supported
+// by [SQLite], where it is done via INSERT OR REPLACE.
+func (s *Store[M]) CreateReplace(ctx context.Context, mm ...M) error {
+	if s.cfg.dialect != SQLite {
+		return fmt.Errorf("CreateReplace is not supported by dialect %s", s.cfg.dialect)
+	}
+	_, err := s.createAll(ctx, conflict{action: conflictReplace}, mm)
+	return err
+}
+
+// Upsert creates the given models like [Store.Create], only any model that
+// conflicts with an existing row on the given columns updates that row
+// instead. If no columns are given, then the columns of the [PrimaryKey] are
+// used. The columns of each model that are updated are those of its mutable
+// [Params], excluding the given columns.
+//
+// The models are upserted via a multi-row INSERT, chunked like [Store.Create],
+// so importing many models does not require a query per model, for example,
+//
+//	err := users.Upsert(ctx, []string{"email"}, uu...)
+//
+// would be built as,
+//
+//	INSERT INTO users (email, name) VALUES ($1, $2), ($3, $4), ...
+//	ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name
+//
+// For [MySQL] this is done via ON DUPLICATE KEY UPDATE, which does not take
+// the conflict columns, so the unique index that conflicts is used instead.
+// Since a row may or may not have been created for each model, generated
+// values are not set on the models.
+func (s *Store[M]) Upsert(ctx context.Context, target []string, mm ...M) error {
+	if len(mm) == 0 {
+		return nil
+	}
+
+	if len(target) == 0 {
+		pk := mm[0].PrimaryKey()
+
+		if pk == nil {
+			return errors.New("cannot upsert model without primary key")
+		}
+		target = pk.Columns
+	}
+
+	_, err := s.createAll(ctx, conflict{
+		action: conflictUpdate,
+		target: target,
+	}, mm)
+	return err
+}
+
+// onConflict returns the clause for upserting the given columns on the given
+// conflict target.
+func (s *Store[M]) onConflict(target, cols []string, params Params) query.Option {
+	update := make([]string, 0, len(cols))
+
+	for _, col := range cols {
+		if params[col].mode.has(paramUpdate) && !slices.Contains(target, col) {
+			update = append(update, col)
+		}
+	}
+
+	if len(update) == 0 {
+		return s.ignore(target...)
+	}
+
+	if s.cfg.dialect == MySQL {
+		return query.OnDuplicateKeyUpdate(update...)
+	}
+	return query.OnConflictDoUpdate(target, update...)
+}
+
+// ignore returns the option for skipping rows that conflict with existing
+// rows on the given conflict target in the store's dialect.
+func (s *Store[M]) ignore(target ...string) query.Option {
+	switch s.cfg.dialect {
+	case SQLite:
+		if len(target) == 0 {
+			return query.OrIgnore()
+		}
+	case MySQL:
+		return query.Ignore()
+	}
+	return query.OnConflictDoNothing(target...)
+}
+
+func (s *Store[M]) createAll(ctx context.Context, c conflict, mm []M) (int64, error) {
+	if len(mm) == 0 {
+		return 0, nil
+	}
+
+	params := make([]Params, 0, len(mm))
+
+	for _, m := range mm {
+		p, err := s.cfg.prepareCreate(m)
+
+		if err != nil {
+			return 0, err
+		}
+		params = append(params, p)
+	}
+
+	if err := validate(ctx, mm...); err != nil {
+		return 0, err
+	}
+
+	if s.cfg.partitions != nil {
+		var n int64
+
+		err := s.partitioned(ctx, mm, func(s *Store[M], idx []int) error {
+			created, err := s.createPrepared(ctx, c, pick(mm, idx), pick(params, idx))
+			n += created
+			return err
+		})
+		return n, err
+	}
+	return s.createPrepared(ctx, c, mm, params)
+}
+
+// createPrepared creates the given models, with the given Params of each model
+// as returned from prepareParams, in chunks that fit within the maximum number
+// of parameters of the dialect. The number of rows created is returned.
+func (s *Store[M]) createPrepared(ctx context.Context, c conflict, mm []M, params []Params) (int64, error) {
+	cols := createCols(mm[0])
+
+	size := len(mm)
+
+	if len(cols) > 0 {
+		size = max(s.cfg.dialect.maxParams()/len(cols), 1)
+	}
+
+	if len(mm) <= size {
+		n, err := s.create(ctx, c, cols, mm, params)
+
+		if err != nil {
+			return 0, err
+		}
+
+		s.changed(ctx, modelChanges(s.table, OpCreate, paramCreate, mm, params)...)
+		return n, nil
+	}
+
+	var n int64
+
+	createChunks := func(s *Store[M]) error {
+		n = 0
+
+		for i := 0; i < len(mm); i += size {
+			j := min(i+size, len(mm))
+
+			created, err := s.create(ctx, c, cols, mm[i:j], params[i:j])
+
+			if err != nil {
+				return err
+			}
+			n += created
+		}
+		return nil
+	}
+
+	if err := s.atomic(ctx, createChunks); err != nil {
+		return 0, err
+	}
+
+	s.changed(ctx, modelChanges(s.table, OpCreate, paramCreate, mm, params)...)
+	return n, nil
+}
+
+// create creates the given models, with the given Params of each model as
+// returned from prepareParams, using the given conflict resolution. The number
+// of rows created is returned.
+func (s *Store[M]) create(ctx context.Context, c conflict, cols []string, mm []M, params []Params) (int64, error) {
+	key, generated := generatedKey(mm[0], cols)
+
+	if !generated {
+		if key, generated = s.zeroKey(mm, cols); generated {
+			cols = slices.DeleteFunc(slices.Clone(cols), func(col string) bool {
+				return col == key
+			})
+		}
+	}
+
+	opts := make([]query.Option, 0, len(mm))
+	vals := make([]any, 0)
+
+	for i, m := range mm {
+		for _, col := range cols {
+			val, err := paramValue(s.cfg.dialect, s.cfg.keyring, s.cfg.mapper, m, col, params[i][col].value)
+
+			if err != nil {
+				return 0, err
+			}
+			vals = append(vals, val)
+		}
+
+		opts = append(opts, query.Values(vals...))
+		vals = vals[0:0]
+	}
+
+	switch c.action {
+	case conflictIgnore:
+		opts = append(opts, s.ignore())
+	case conflictReplace:
+		opts = append(opts, query.OrReplace())
+	case conflictUpdate:
+		opts = append(opts, s.onConflict(c.target, cols, params[0]))
+	}
+
+	q := query.Insert(s.target(), query.Columns(cols...), opts...)
+
+	returning := returningCols(params[0], paramCreate, key)
+
+	// Ignored rows are not returned, so the returned rows could not be matched
+	// back up to the models. Neither could the rows of an upsert, where the
+	// rows updated are not returned by MySQL, nor are they ordered by
+	// PostgreSQL.
+	if c.action == conflictIgnore || c.action == conflictUpdate {
+		return s.insert(ctx, q, key, false, mm)
+	}
+
+	if len(returning) == 0 {
+		return s.insert(ctx, q, key, generated, mm)
+	}
+
+	if s.cfg.dialect.returning() {
+		return s.queryReturning(ctx, OpCreate, query.Returning(returning...)(q), mm)
+	}
+
+	// Without a RETURNING clause a generated key can only be known for MySQL,
+	// and without the key the models cannot be selected.
+	if generated && s.cfg.dialect != MySQL {
+		return s.insert(ctx, q, key, generated, mm)
+	}
+
+	var n int64
+
+	err := s.atomic(ctx, func(s *Store[M]) error {
+		var err error
+
+		if n, err = s.insert(ctx, q, key, generated, mm); err != nil {
+			return err
+		}
+		return s.selectReturning(ctx, returning, mm)
+	})
+	return n, err
+}
+
+// insert runs the given INSERT query for the given models, returning the
+// number of rows affected. For [MySQL], the given generated key is set on each
+// model from the LastInsertId of the result.
+func (s *Store[M]) insert(ctx context.Context, q *query.Query, key string, generated bool, mm []M) (int64, error) {
+	res, err := s.exec(ctx, s.table, OpCreate, q)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if generated && s.cfg.dialect == MySQL {
+		if err := setInsertIds(s.cfg.mapper, res, key, mm); err != nil {
+			return 0, err
+		}
+	}
+	return res.RowsAffected()
+}
+
+// zeroKey returns the column of the primary key of the given models if the
+// store's [Dialect] is [SQLite] or [MySQL], and the primary key is a single
+// integer column that is zero for every model. Such a key is left out of the
+// INSERT so that the database generates it, as it would for an
+// auto-incrementing column.
+func (s *Store[M]) zeroKey(mm []M, cols []string) (string, bool) {
+	if s.cfg.dialect != SQLite && s.cfg.dialect != MySQL {
+		return "", false
+	}
+
+	var key string
+
+	for _, m := range mm {
+		pk := m.PrimaryKey()
+
+		if pk == nil || len(pk.Columns) != 1 || !slices.Contains(cols, pk.Columns[0]) {
+			return "", false
+		}
+
+		rv := reflect.ValueOf(pk.Values[0])
+
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if rv.Int() != 0 {
+				return "", false
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if rv.Uint() != 0 {
+				return "", false
+			}
+		default:
+			return "", false
+		}
+		key = pk.Columns[0]
+	}
+	return key, true
+}
+
+// setInsertIds sets the given key column on the given models from the
+// LastInsertId of the given result. For a multi-row INSERT, MySQL returns the
+// id of the first row, and the ids of the remaining rows are consecutive as
+// long as innodb_autoinc_lock_mode is not set to interleaved.
+func setInsertIds[M Model](mapper *Mapper, res sql.Result, key string, mm []M) error {
+	id, err := res.LastInsertId()
+
+	if err != nil {
+		return err
+	}
+
+	for i, m := range mm {
+		if err := setColumn(mapper, m, key, id+int64(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generatedKey returns the column of the given model's primary key if it is a
+// single column that is not one of the given columns being created, meaning
+// that it is generated by the database.
+func generatedKey(m Model, cols []string) (string, bool) {
+	pk := m.PrimaryKey()
+
+	if pk == nil || len(pk.Columns) != 1 {
+		return "", false
+	}
+
+	col := pk.Columns[0]
+
+	if slices.Contains(cols, col) {
+		return "", false
+	}
+	return col, true
+}
+
+// returningCols returns the columns of the given Params whose values are not
+// known until they are written for the given mode. These are the generated
+// Params, and the Params whose value is a [query.Expr], along with the given
+// generated key if any.
+func returningCols(params Params, mode paramMode, key string) []string {
+	cols := make([]string, 0)
+
+	for name, param := range params.All() {
+		if name == key {
+			continue
+		}
+
+		if param.mode == 0 {
+			cols = append(cols, name)
+			continue
+		}
+
+		if _, ok := param.value.(query.Expr); ok && param.mode.has(mode) {
+			cols = append(cols, name)
+		}
+	}
+
+	if key != "" {
+		cols = append([]string{key}, cols...)
+	}
+	return cols
+}
+
+// queryReturning runs the given query for the given operation, which is
+// expected to have a RETURNING clause. The returned rows are scanned into
+// each of the given models in the order they were written, and the number of
+// rows returned is returned.
+func (s *Store[M]) queryReturning(ctx context.Context, op Op, q *query.Query, mm []M) (int64, error) {
+	rows, err := s.query(ctx, s.table, op, q)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	sc, err := s.newScanner(rows.Rows)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+
+	for ; rows.Next(); n++ {
+		if n >= int64(len(mm)) {
+			return n, errors.New("more rows returned than models written")
+		}
+
+		if err := sc.Scan(mm[n]); err != nil {
+			return n, err
+		}
+	}
+	return n, rows.Err()
+}
+
+// selectReturning emulates a RETURNING clause for dialects that do not support
+// it, by selecting the given columns of the given models by their
+// [PrimaryKey] once they have been written, and setting them on each model.
+// This should be called within the same transaction as the write.
+func (s *Store[M]) selectReturning(ctx context.Context, cols []string, mm []M) error {
+	pk := mm[0].PrimaryKey()
+
+	if pk == nil {
+		return nil
+	}
+
+	cols = slices.DeleteFunc(slices.Clone(cols), func(col string) bool {
+		return slices.Contains(pk.Columns, col)
+	})
+
+	if len(cols) == 0 {
+		return nil
+	}
+
+	q := query.Select(
+		query.Columns(append(slices.Clone(pk.Columns), cols...)...),
+		query.From(s.target()),
+		whereKeys(mm),
+	)
+
+	rows, err := s.query(ctx, s.table, OpSelect, q)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	sc, err := s.newScanner(rows.Rows)
+
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]M, len(mm))
+
+	for _, m := range mm {
+		keys[fmt.Sprint(m.PrimaryKey().Values)] = m
+	}
+
+	for rows.Next() {
+		row := s.new()
+
+		if err := sc.Scan(row); err != nil {
+			return err
+		}
+
+		m, ok := keys[fmt.Sprint(row.PrimaryKey().Values)]
+
+		if !ok {
+			continue
+		}
+
+		if err := copyColumns(s.cfg.mapper, m, row, cols); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// CreateTx creates the given models using the given transaction.
+//
+// Deprecated: Use [Store.With] instead, for example s.With(tx).Create(ctx, mm...).
+func (s *Store[M]) CreateTx(ctx context.Context, tx *sql.Tx, mm ...M) error {
+	return s.With(tx).Create(ctx, mm...)
+}
+
+// All returns an iterator over the models that match the given query options.
+// Unlike [Store.Select], each model is scanned lazily as the iterator is
+// advanced, so large result sets can be worked through without holding every
+// model in memory. The given [query.Expr] should be the columns to select for
+// the models.
+//
+// If an error occurs then it is yielded along with the zero value of the model,
+// and iteration stops. The underlying rows are closed once iteration finishes,
+// or if the loop is broken out of early.
+func (s *Store[M]) All(ctx context.Context, expr query.Expr, opts ...query.Option) iter.Seq2[M, error] {
+	return s.all(ctx, s.selectQuery(ctx, expr, opts...))
+}
+
+func (s *Store[M]) selectQuery(ctx context.Context, expr query.Expr, opts ...query.Option) *query.Query {
+	opts = append([]query.Option{
+		query.From(s.table),
+	}, s.spanned(s.scoped(ctx, opts))...)
+
+	return query.Select(expr, opts...)
+}
+
+func (s *Store[M]) all(ctx context.Context, q *query.Query) iter.Seq2[M, error] {
+	return func(yield func(M, error) bool) {
+		var zero M
+
+		rows, err := s.query(ctx, s.table, OpSelect, q)
+
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		defer rows.Close()
+
+		sc, err := s.newScanner(rows.Rows)
+
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		var n int64
+
+		if s.cfg.stats != nil {
+			defer func() {
+				s.cfg.stats.addRows(s.table, OpSelect, q, n)
+			}()
+		}
+
+		for rows.Next() {
+			m := s.new()
+
+			if err := sc.Scan(m); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			n++
+
+			if !yield(m, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// Select returns the models that match the given query options. The given
+// [query.Expr] should be the columns to select for the models. Any loaders
+// given via [Store.Preload] are called on the selected models.
+//
+// If the store has a [Cache] configured, then the cache is consulted first, and
+// any models selected from the database are put in the cache.
+func (s *Store[M]) Select(ctx context.Context, expr query.Expr, opts ...query.Option) ([]M, error) {
+	q := s.selectQuery(ctx, expr, opts...)
+
+	mm, ok := s.cached(q)
+
+	if !ok {
+		mm = make([]M, 0)
+
+		for m, err := range s.all(ctx, q) {
+			if err != nil {
+				return nil, err
+			}
+			mm = append(mm, m)
+		}
+		s.cache(q, mm)
+	}
+
+	if err := s.doPreload(ctx, mm); err != nil {
+		return nil, err
+	}
+	return mm, nil
+}
+
+// SelectAll is like [Store.Select], only the columns to select are derived
+// from the Params of the store's [Model] via [Columns], with each column
+// prefixed with the model's table name. This avoids ambiguous column names when
+// the given query options join onto other tables.
+func (s *Store[M]) SelectAll(ctx context.Context, opts ...query.Option) ([]M, error) {
+	return s.Select(ctx, Columns(s.new()), opts...)
+}
+
+// Chunk pages through the models that match the given query options in
+// batches of the given size, invoking fn for each batch. Models are ordered by
+// their [PrimaryKey], and each page is queried from where the previous one left
+// off, so the full set of models is never held in memory at once. Paging stops
+// as soon as fn returns an error, and that error is returned.
+//
+// The given query options should not contain any ORDER BY or LIMIT clauses, as
+// these are added by Chunk itself.
+func (s *Store[M]) Chunk(ctx context.Context, size int, fn func([]M) error, opts ...query.Option) error {
+	if size <= 0 {
+		return errors.New("chunk size must be greater than zero")
+	}
+
+	pk := s.new().PrimaryKey()
+
+	if pk == nil || len(pk.Columns) == 0 {
+		return errors.New("cannot chunk model without primary key")
+	}
+
+	cols := make([]string, 0, len(pk.Columns))
+
+	for _, col := range pk.Columns {
+		cols = append(cols, s.table+"."+col)
+	}
+
+	var last []any
+
+	for {
+		pageopts := make([]query.Option, 0, len(opts)+3)
+		pageopts = append(pageopts, opts...)
+
+		if last != nil {
+			pageopts = append(pageopts, keysetWhere(cols, last))
+		}
+
+		pageopts = append(pageopts, query.OrderAsc(cols...), query.Limit(int64(size)))
+
+		mm, err := s.Select(ctx, query.Columns("*"), pageopts...)
+
+		if err != nil {
+			return err
+		}
+
+		if len(mm) == 0 {
+			return nil
+		}
+
+		if err := fn(mm); err != nil {
+			return err
+		}
+
+		if len(mm) < size {
+			return nil
+		}
+		last = mm[len(mm)-1].PrimaryKey().Values
+	}
+}
+
+// keysetWhere returns the WHERE clause for querying the rows that come after
+// the given values for the given columns. Composite keys are compared as a row
+// value.
+func keysetWhere(cols []string, vals []any) query.Option {
+	if len(cols) == 1 {
+		return query.WhereGt(cols[0], query.Arg(vals[0]))
+	}
+
+	idents := make([]any, 0, len(cols))
+
+	for _, col := range cols {
+		idents = append(idents, query.Ident(col))
+	}
+	return query.Where(query.Gt(query.List(idents...), query.List(vals...)))
+}
+
+// Get returns the first model that can be found that matches the given query
+// options, and whether or not it was found via the bool return value.
 func (s *Store[M]) Get(ctx context.Context, opts ...query.Option) (M, bool, error) {
-	return s.doGet(ctx, s.QueryContext, opts...)
+	return s.SelectOne(ctx, query.Columns("*"), opts...)
 }
 
-func (s *Store[M]) doUpdate(ctx context.Context, execFn execFunc, m M) (sql.Result, error) {
-	opts := make([]query.Option, 0)
+// SelectOne is like [Store.Get], only the given [query.Expr] is used as the
+// columns to select for the model, as with [Store.Select]. This allows for
+// joined relations to be hydrated when getting a single model, for example,
+//
+//	p, ok, err := posts.SelectOne(
+//	    ctx,
+//	    database.ColumnsRelated(&Post{User: &User{}}, "User"),
+//	    database.JoinRelated(&Post{}, "User"),
+//	    query.WhereEq("posts.id", query.Arg(id)),
+//	)
+func (s *Store[M]) SelectOne(ctx context.Context, expr query.Expr, opts ...query.Option) (M, bool, error) {
+	var zero M
 
-	params := m.Params()
+	opts = append(opts, query.Limit(1))
 
-	for name, param := range params {
-		if param.mode.has(paramUpdate) {
-			opts = append(opts, query.Set(name, query.Arg(param.value)))
+	mm, err := s.Select(ctx, expr, opts...)
+
+	if err != nil {
+		return zero, false, err
+	}
+
+	if len(mm) == 0 {
+		return zero, false, nil
+	}
+	return mm[0], true, nil
+}
+
+// ErrNotFound is the error matched by a [NotFoundError] via [errors.Is].
+var ErrNotFound = errors.New("not found")
+
+// NotFoundError records the table that was queried, and the filter used to
+// query it, when a model could not be found via [Store.GetOrErr].
+type NotFoundError struct {
+	Table string
+
+	// Filter is the WHERE clause that was used to look up the model, including
+	// any scopes of the store, without the WHERE keyword. This is empty if the
+	// model was looked up without a filter.
+	Filter string
+
+	// Args are the arguments of the Filter, in the order their placeholders
+	// appear.
+	Args []any
+}
+
+func (e *NotFoundError) Error() string {
+	if e.Filter == "" {
+		return e.Table + " not found"
+	}
+	return fmt.Sprintf("%s not found: %s", e.Table, e.Filter)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// notFound returns a NotFoundError for the filter of the given query, built
+// with the given style of placeholders.
+func notFound(table string, q *query.Query, p query.Placeholder) error {
+	filter, args := q.Filter(p)
+
+	return &NotFoundError{
+		Table:  table,
+		Filter: filter,
+		Args:   args,
+	}
+}
+
+// GetOrErr is like [Store.Get], only a [NotFoundError] is returned if the
+// model cannot be found, instead of a bool. This can be checked for via
+// [errors.Is] with [ErrNotFound], for example,
+//
+//	p, err := posts.GetOrErr(ctx, query.WhereEq("id", query.Arg(id)))
+//
+//	if err != nil {
+//	    if errors.Is(err, database.ErrNotFound) {
+//	        http.NotFound(w, r)
+//	        return
+//	    }
+//	    return err
+//	}
+func (s *Store[M]) GetOrErr(ctx context.Context, opts ...query.Option) (M, error) {
+	m, ok, err := s.Get(ctx, opts...)
+
+	if err != nil {
+		return m, err
+	}
+
+	if !ok {
+		q := s.selectQuery(ctx, query.Columns("*"), opts...)
+
+		return m, notFound(s.table, q, s.cfg.dialect.placeholder())
+	}
+	return m, nil
+}
+
+// Count returns the number of models that match the given query options.
+func (s *Store[M]) Count(ctx context.Context, opts ...query.Option) (int64, error) {
+	q := s.selectQuery(ctx, query.Count("*"), opts...)
+
+	rows, err := s.query(ctx, s.table, OpSelect, q)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	var n int64
+
+	if rows.Next() {
+		if err := rows.Scan(&n); err != nil {
+			return 0, err
 		}
 	}
+	return n, rows.Err()
+}
+
+// queryOp returns the [Op] of the given query based on its statement.
+func queryOp(q *query.Query) Op {
+	switch q.Statement() {
+	case "INSERT":
+		return OpCreate
+	case "UPDATE":
+		return OpUpdate
+	case "DELETE", "TRUNCATE TABLE":
+		return OpDelete
+	}
+	return OpSelect
+}
 
-	opts = append(opts, m.PrimaryKey().Where())
+// queryTable returns the table of the given query, falling back to the
+// store's table if the query has none.
+func (s *Store[M]) queryTable(q *query.Query) string {
+	if table := q.Table(); table != "" {
+		return table
+	}
+	return s.table
+}
+
+// Exec runs the given query against the store's database. Unlike calling
+// ExecContext directly, the query is retried as per the store's
+// [RetryPolicy], recorded to the store's [Metrics], and invalidates the
+// store's cache if it is a write. The table and [Op] of the query are taken
+// from the query itself.
+//
+// The changes made by the query are not published to any [ChangeFeed], since
+// the models affected by the query are not known.
+func (s *Store[M]) Exec(ctx context.Context, q *query.Query) (sql.Result, error) {
+	return s.exec(ctx, s.queryTable(q), queryOp(q), q)
+}
 
-	q := query.Update(s.table, opts...)
+// Query runs the given query against the store's database, returning the
+// resulting rows. As with [Store.Exec], the query is retried, recorded, and
+// routed to any of the store's replicas if it is a SELECT. Since the rows are
+// still open once returned, the plan of the query is not captured if it is
+// slow, as it would be via [WithSlowQuery].
+func (s *Store[M]) Query(ctx context.Context, q *query.Query) (*sql.Rows, error) {
+	rows, err := s.query(ctx, s.queryTable(q), queryOp(q), q)
 
-	return execFn(ctx, q.Build(), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	return rows.detach(), nil
 }
 
 // Update the given model on the model's [PrimaryKey] to determine which one
-// should be updated.
+// should be updated. Any [Transformer] of the model's Params is applied first.
+// If the model implements [Validator], then it is validated before anything is
+// updated, and a [ValidationError] is returned if it is invalid.
+//
+// The columns of any [GeneratedParam], or of any Param whose value is a
+// [query.Expr], are set on the model once updated, as per [Store.Create].
+//
+// For a partitioned store, [ErrPartitionChanged] is returned if the model is
+// not in the partition derived from it, as is the case when its partition
+// column has been changed.
 func (s *Store[M]) Update(ctx context.Context, m M) (sql.Result, error) {
-	return s.doUpdate(ctx, s.ExecContext, m)
+	if s.cfg.partitions != nil {
+		var res sql.Result
+
+		err := s.partitioned(ctx, []M{m}, func(s *Store[M], _ []int) error {
+			var err error
+			res, err = s.Update(ctx, m)
+			return err
+		})
+		return res, err
+	}
+
+	params, err := prepareParams(m, paramUpdate, s.cfg.getClock(), s.cfg.mapper)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validate(ctx, m); err != nil {
+		return nil, err
+	}
+
+	opts := make([]query.Option, 0)
+
+	for name, param := range params.All() {
+		if param.mode.has(paramUpdate) {
+			val, err := paramValue(s.cfg.dialect, s.cfg.keyring, s.cfg.mapper, m, name, param.value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if expr, ok := val.(query.Expr); ok {
+				opts = append(opts, query.Set(name, expr))
+				continue
+			}
+			opts = append(opts, query.Set(name, query.Arg(val)))
+		}
+	}
+
+	opts = append(opts, m.PrimaryKey().Where())
+
+	q := query.Update(s.target(), s.scoped(ctx, opts)...)
+
+	res, err := s.update(ctx, q, m, params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if s.part != "" {
+		if err := s.inPartition(ctx, res, m); err != nil {
+			return nil, err
+		}
+	}
+
+	s.changed(ctx, modelChanges(s.table, OpUpdate, paramUpdate, []M{m}, []Params{params})...)
+	return res, nil
+}
+
+// update runs the given UPDATE query for the given model, setting the columns
+// of its generated Params, and of its Params whose value is a [query.Expr], on
+// the model once updated.
+func (s *Store[M]) update(ctx context.Context, q *query.Query, m M, params Params) (sql.Result, error) {
+	returning := returningCols(params, paramUpdate, "")
+
+	if len(returning) == 0 {
+		return s.exec(ctx, s.table, OpUpdate, q)
+	}
+
+	if s.cfg.dialect.returning() {
+		n, err := s.queryReturning(ctx, OpUpdate, query.Returning(returning...)(q), []M{m})
+
+		if err != nil {
+			return nil, err
+		}
+		return returnedResult(n), nil
+	}
+
+	var res sql.Result
+
+	err := s.atomic(ctx, func(s *Store[M]) error {
+		var err error
+
+		if res, err = s.exec(ctx, s.table, OpUpdate, q); err != nil {
+			return err
+		}
+		return s.selectReturning(ctx, returning, []M{m})
+	})
+	return res, err
 }
 
+// returnedResult is the [sql.Result] of a query with a RETURNING clause, where
+// the number of rows affected is the number of rows returned.
+type returnedResult int64
+
+func (r returnedResult) LastInsertId() (int64, error) { return 0, nil }
+func (r returnedResult) RowsAffected() (int64, error) { return int64(r), nil }
+
 // UpdateTx updates the given model using the given transation, on the model's
 // [PrimaryKey] to determine which one should be updated.
+//
+// Deprecated: Use [Store.With] instead, for example s.With(tx).Update(ctx, m).
 func (s *Store[M]) UpdateTx(ctx context.Context, tx *sql.Tx, m M) (sql.Result, error) {
-	return s.doUpdate(ctx, tx.ExecContext, m)
+	return s.With(tx).Update(ctx, m)
 }
 
-func (s *Store[M]) doUpdateMany(ctx context.Context, execFn execFunc, fields map[string]any, opts ...query.Option) (sql.Result, error) {
-	setopts := make([]query.Option, 0)
-
+// CreateFromMap creates a new model from the given map of fields, and returns
+// it. Each field is set on a new model, converting the value to the type of
+// the model's field if need be, before it is created via [Store.Create], so
+// defaults, transformers, and validation are applied as usual. An error is
+// returned if a field is not a param of the model, or is not a param that
+// can be set during creation, such as a [GeneratedParam] or an
+// [UpdateOnlyParam].
+func (s *Store[M]) CreateFromMap(ctx context.Context, fields map[string]any) (M, error) {
 	m := s.new()
 	params := m.Params()
 
-	for fld, val := range fields {
-		if param, ok := params[fld]; ok {
-			if param.mode.has(paramUpdate) {
-				setopts = append(setopts, query.Set(fld, query.Arg(val)))
-			}
+	for _, fld := range slices.Sorted(maps.Keys(fields)) {
+		param, ok := params[fld]
+
+		if !ok {
+			return m, fmt.Errorf("%w %s", errUnknownColumn, fld)
 		}
-	}
 
-	q := query.Update(s.table, append(setopts, opts...)...)
+		if !param.mode.has(paramCreate) {
+			return m, fmt.Errorf("column %s cannot be set on create", fld)
+		}
+
+		if err := setColumn(s.cfg.mapper, m, fld, fields[fld]); err != nil {
+			return m, err
+		}
+	}
 
-	return execFn(ctx, q.Build(), q.Args()...)
+	if err := s.Create(ctx, m); err != nil {
+		return m, err
+	}
+	return m, nil
 }
 
 // UpdateMany updates all models in the database that match the given query
 // options using the given map of fields. Only the fields that exist in the
-// model and can be updated will be changed.
+// model and can be updated will be changed. Each value is written as it would
+// be by [Store.Update], so any [Transformer] of the field's Param is applied,
+// and the columns of fields with the "json", "encrypted", or "sensitive"
+// options are handled the same.
 func (s *Store[M]) UpdateMany(ctx context.Context, fields map[string]any, opts ...query.Option) (sql.Result, error) {
-	return s.doUpdateMany(ctx, s.ExecContext, fields, opts...)
+	setopts := make([]query.Option, 0)
+
+	m := s.new()
+	params := m.Params()
+
+	changed := make(map[string]any)
+
+	for fld, val := range fields {
+		param, ok := params[fld]
+
+		if !ok || !param.mode.has(paramUpdate) {
+			continue
+		}
+
+		val, err := param.transform(fld, val)
+
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := paramValue(s.cfg.dialect, s.cfg.keyring, s.cfg.mapper, m, fld, val)
+
+		if err != nil {
+			return nil, err
+		}
+
+		changed[fld] = val
+
+		if expr, ok := v.(query.Expr); ok {
+			setopts = append(setopts, query.Set(fld, expr))
+			continue
+		}
+		setopts = append(setopts, query.Set(fld, query.Arg(v)))
+	}
+
+	q := query.Update(s.table, s.scoped(ctx, append(setopts, opts...))...)
+
+	res, err := s.exec(ctx, s.table, OpUpdate, q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.changed(ctx, &Change{
+		Table:  s.table,
+		Op:     OpUpdate,
+		Params: changed,
+	})
+	return res, nil
 }
 
 // UpdateManyTx updates all models in the database that match the given query
 // options using the given map of fields using the given transaction. Only the
 // fields that exist in the model and can be updated will be changed.
+//
+// Deprecated: Use [Store.With] instead, for example
+// s.With(tx).UpdateMany(ctx, fields, opts...).
 func (s *Store[M]) UpdateManyTx(ctx context.Context, tx *sql.Tx, fields map[string]any, opts ...query.Option) (sql.Result, error) {
-	return s.doUpdateMany(ctx, tx.ExecContext, fields, opts...)
+	return s.With(tx).UpdateMany(ctx, fields, opts...)
 }
 
 type noResult struct{}
@@ -422,17 +1985,51 @@ type noResult struct{}
 func (r noResult) LastInsertId() (int64, error) { return 0, nil }
 func (r noResult) RowsAffected() (int64, error) { return 0, nil }
 
-func (s *Store[M]) doDelete(ctx context.Context, execFn execFunc, mm ...M) (sql.Result, error) {
+// Delete the given models. If no models are given, this is a no-op.
+func (s *Store[M]) Delete(ctx context.Context, mm ...M) (sql.Result, error) {
 	if len(mm) == 0 {
 		return noResult{}, nil
 	}
 
-	m := mm[0]
-	pk := m.PrimaryKey()
+	if s.cfg.partitions != nil {
+		var res partitionedResult
+
+		err := s.partitioned(ctx, mm, func(s *Store[M], idx []int) error {
+			r, err := s.Delete(ctx, pick(mm, idx)...)
+
+			if err != nil {
+				return err
+			}
+			res = append(res, r)
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	q := query.Delete(s.target(), s.scoped(ctx, []query.Option{whereKeys(mm)})...)
+
+	res, err := s.exec(ctx, s.table, OpDelete, q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.changed(ctx, modelChanges(s.table, OpDelete, 0, mm, nil)...)
+	return res, nil
+}
+
+// whereKeys returns a WHERE IN clause matching the [PrimaryKey] of each of the
+// given models.
+func whereKeys[M Model](mm []M) query.Option {
+	pk := mm[0].PrimaryKey()
 
 	col := "(" + strings.Join(pk.Columns, ", ") + ")"
 
-	vals := make([]any, 0)
+	vals := make([]any, 0, len(mm))
 
 	for _, m := range mm {
 		var val any
@@ -445,19 +2042,93 @@ func (s *Store[M]) doDelete(ctx context.Context, execFn execFunc, mm ...M) (sql.
 		}
 		vals = append(vals, val)
 	}
-
-	q := query.Delete(s.table, query.WhereIn(col, query.List(vals...)))
-
-	return execFn(ctx, q.Build(), q.Args()...)
-}
-
-// Delete the given models. If no models are given, this is a no-op.
-func (s *Store[M]) Delete(ctx context.Context, mm ...M) (sql.Result, error) {
-	return s.doDelete(ctx, s.ExecContext, mm...)
+	return query.WhereIn(col, query.List(vals...))
 }
 
 // DeleteTx deletes the given models using the given transaction. If no models
 // are given, then this is a no-op.
+//
+// Deprecated: Use [Store.With] instead, for example s.With(tx).Delete(ctx, mm...).
 func (s *Store[M]) DeleteTx(ctx context.Context, tx *sql.Tx, mm ...M) (sql.Result, error) {
-	return s.doDelete(ctx, tx.ExecContext, mm...)
+	return s.With(tx).Delete(ctx, mm...)
+}
+
+// Truncate deletes every model in the store's table. For [Postgres] and
+// [MySQL] this is done via TRUNCATE TABLE, otherwise via DELETE, since SQLite
+// has no TRUNCATE. This is primarily intended for test setup, and admin
+// tooling.
+//
+// If restart is true, then the identity of the table is reset too, so that
+// any generated keys start from the beginning again. For PostgreSQL this adds
+// RESTART IDENTITY to the TRUNCATE, and for SQLite this resets the table's
+// entry in sqlite_sequence. MySQL always resets the AUTO_INCREMENT counter on
+// TRUNCATE.
+//
+// This returns an error if the store has any scopes, since a TRUNCATE cannot
+// be scoped, use [Store.Unscoped] to truncate the whole table.
+func (s *Store[M]) Truncate(ctx context.Context, restart bool) error {
+	if len(s.scopes) > 0 {
+		return errors.New("cannot truncate scoped store")
+	}
+
+	if err := s.truncate(ctx, restart); err != nil {
+		return err
+	}
+
+	s.changed(ctx, &Change{
+		Table: s.table,
+		Op:    OpDelete,
+	})
+	return nil
+}
+
+func (s *Store[M]) truncate(ctx context.Context, restart bool) error {
+	switch s.cfg.dialect {
+	case Postgres:
+		opts := make([]query.Option, 0, 1)
+
+		if restart {
+			opts = append(opts, query.RestartIdentity())
+		}
+
+		_, err := s.exec(ctx, s.table, OpDelete, query.Truncate(s.table, opts...))
+		return err
+	case MySQL:
+		_, err := s.exec(ctx, s.table, OpDelete, query.Truncate(s.table))
+		return err
+	}
+
+	return s.atomic(ctx, func(s *Store[M]) error {
+		if _, err := s.exec(ctx, s.table, OpDelete, query.Delete(s.table)); err != nil {
+			return err
+		}
+
+		if !restart || s.cfg.dialect != SQLite {
+			return nil
+		}
+
+		// The sqlite_sequence table only exists if a table in the database
+		// has an AUTOINCREMENT column.
+		var n int64
+
+		q := query.Select(
+			query.Count("*"),
+			query.From("sqlite_master"),
+			query.WhereEq("type", query.Arg("table")),
+			query.WhereEq("name", query.Arg("sqlite_sequence")),
+		)
+
+		if err := q.QueryRow(ctx, s.DB).Scan(&n); err != nil {
+			return err
+		}
+
+		if n == 0 {
+			return nil
+		}
+
+		q = query.Delete("sqlite_sequence", query.WhereEq("name", query.Arg(s.table)))
+
+		_, err := s.exec(ctx, "sqlite_sequence", OpDelete, q)
+		return err
+	})
 }
@@ -1,11 +1,16 @@
 package database
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"net/url"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,38 +19,18 @@ import (
 	_ "modernc.org/sqlite"
 )
 
-var sqlitePragmas = [...]string{
-	"busy_timeout=5000",
-	"cache_size=1000000000",
-	"foreign_keys=true",
-	"journal_mode=WAL",
-	"synchronous=NORMAL",
-	"temp_store=memory",
-}
-
-func NewDB(t *testing.T) *sql.DB {
+func NewDB(t testing.TB) *sql.DB {
 	t.Helper()
 
 	name := fmt.Sprintf("%s.sqlite", t.Name())
 
-	url, err := url.Parse(name)
-
-	if err != nil {
-		t.Fatalf("url.Parse(%q): %v\n", name, err)
-	}
-
-	q := url.Query()
-
-	for _, pragma := range sqlitePragmas {
-		q.Add("_pragma", pragma)
-	}
-
-	url.RawQuery = q.Encode()
-
-	db, err := sql.Open("sqlite", url.String())
+	db, err := Open("sqlite", Config{
+		Name:    name,
+		Pragmas: DefaultSQLitePragmas.Pragmas(),
+	})
 
 	if err != nil {
-		t.Fatalf("sql.Open(%q, %q): %v\n", "sqlite", t.Name(), err)
+		t.Fatalf("Open(%q, %q): %v\n", "sqlite", name, err)
 	}
 
 	t.Cleanup(func() {
@@ -158,26 +143,12 @@ func TestStore(t *testing.T) {
 		query.From("models"),
 	)
 
-	rows, err := store.QueryContext(ctx, q.Build(), q.Args()...)
+	count, err := QueryValue[int64](ctx, store, SQLite, q)
 
 	if err != nil {
-		t.Fatalf("store.QueryContext(ctx, %q, q.Args()...): %v\n", q.Build(), err)
-	}
-
-	var count int64
-
-	if rows.Next() {
-		if err := rows.Scan(&count); err != nil {
-			t.Fatalf("rows.Scan(&count): %v\n", err)
-		}
+		t.Fatalf("QueryValue[int64](ctx, store, SQLite, q): %v\n", err)
 	}
 
-	if err := rows.Err(); err != nil {
-		t.Fatalf("rows.Err(): %v\n", err)
-	}
-
-	rows.Close()
-
 	if n := int64(cap(mm)); count != n {
 		t.Fatalf("count = %v, want = %v\n", count, n)
 	}
@@ -239,10 +210,10 @@ func TestStore(t *testing.T) {
 		t.Fatalf("store.Delete(ctx): %v\n", err)
 	}
 
-	rows2, err := store.QueryContext(ctx, q.Build(), q.Args()...)
+	rows2, err := store.Query(ctx, q)
 
 	if err != nil {
-		t.Fatalf("store.QueryContext(ctx, %q, q.Args()...): %v\n", q.Build(), err)
+		t.Fatalf("store.Query(ctx, q): %v\n", err)
 	}
 
 	if rows2.Next() {
@@ -492,29 +463,12 @@ func RandomUser(t *testing.T, users *Store[*User]) *User {
 		t.Fatalf("users.QueryContext(t.Context(), %q): %v\n", q, err)
 	}
 
-	defer rows.Close()
-
-	sc, err := NewScanner(rows)
+	u, err := CollectOneRow(rows, RowToModel(users.new))
 
 	if err != nil {
-		t.Fatalf("NewScanner(rows): %v\n", err)
-	}
-
-	uu := make([]*User, 0, 1)
-
-	for rows.Next() {
-		u := users.new()
-
-		if err := sc.Scan(u); err != nil {
-			t.Fatalf("sc.Scan(u): %v\n", err)
-		}
-		uu = append(uu, u)
-	}
-
-	if err := rows.Err(); err != nil {
-		t.Fatalf("rows.Err(): %v\n", err)
+		t.Fatalf("CollectOneRow(rows, RowToModel(users.new)): %v\n", err)
 	}
-	return uu[0]
+	return u
 }
 
 func TestRelations(t *testing.T) {
@@ -586,3 +540,1388 @@ func TestRelations(t *testing.T) {
 		}
 	}
 }
+
+func TestColumnsOnlyExcept(t *testing.T) {
+	p := &Post{User: &User{}}
+
+	tests := []struct {
+		expr query.Expr
+		want string
+	}{
+		{Columns(p), "posts.id, posts.title, posts.user_id"},
+		{Columns(p).Only("id", "title"), "posts.id, posts.title"},
+		{Columns(p).Except("title"), "posts.id, posts.user_id"},
+		{Columns(p).Except("posts.title").Except("user_id"), "posts.id"},
+		{
+			Columns(p, p.User).Only("id", "users.email"),
+			`posts.id, users.email AS "users.email"`,
+		},
+		{
+			Columns(p, p.User).Except("title", "users.id"),
+			`posts.id, posts.user_id, users.email AS "users.email"`,
+		},
+	}
+
+	for i, test := range tests {
+		if built := test.expr.Build(); built != test.want {
+			t.Fatalf("tests[%d] - expr.Build() = %q, want = %q\n", i, built, test.want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Columns(p).Only(%q): expected panic, got nil\n", "content")
+		}
+	}()
+
+	Columns(p).Only("content")
+}
+
+func TestStoreChunk(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	})
+
+	mm := make([]*M, 0, 10)
+
+	for i := 0; i < cap(mm); i++ {
+		mm = append(mm, &M{
+			ID:     int64(i),
+			Str:    "string",
+			BigStr: "bigstring",
+			Blob:   []byte{},
+			Time:   time.Now(),
+		})
+	}
+
+	if err := store.Create(ctx, mm...); err != nil {
+		t.Fatalf("store.Create(ctx, mm...): %v\n", err)
+	}
+
+	sizes := make([]int, 0)
+	ids := make([]int64, 0, len(mm))
+
+	err := store.Chunk(ctx, 3, func(chunk []*M) error {
+		sizes = append(sizes, len(chunk))
+
+		for _, m := range chunk {
+			ids = append(ids, m.ID)
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("store.Chunk(ctx, 3, fn): %v\n", err)
+	}
+
+	want := []int{3, 3, 3, 1}
+
+	if len(sizes) != len(want) {
+		t.Fatalf("len(sizes) = %v, want = %v\n", len(sizes), len(want))
+	}
+
+	for i := range want {
+		if sizes[i] != want[i] {
+			t.Errorf("sizes[%v] = %v, want = %v\n", i, sizes[i], want[i])
+		}
+	}
+
+	for i, id := range ids {
+		if id != int64(i) {
+			t.Errorf("ids[%v] = %v, want = %v\n", i, id, i)
+		}
+	}
+
+	calls := 0
+
+	err = store.Chunk(ctx, 5, func(chunk []*M) error {
+		calls++
+		return nil
+	}, query.WhereGeq("id", query.Arg(5)))
+
+	if err != nil {
+		t.Fatalf("store.Chunk(ctx, 5, fn, where): %v\n", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("calls = %v, want = %v\n", calls, 1)
+	}
+}
+
+func TestStoreAll(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	})
+
+	mm := make([]*M, 0, 10)
+
+	for i := 0; i < cap(mm); i++ {
+		mm = append(mm, &M{
+			ID:     int64(i),
+			Str:    "string",
+			BigStr: "bigstring",
+			Blob:   []byte{},
+			Time:   time.Now(),
+		})
+	}
+
+	if err := store.Create(ctx, mm...); err != nil {
+		t.Fatalf("store.Create(ctx, mm...): %v\n", err)
+	}
+
+	var i int64
+
+	for m, err := range store.All(ctx, query.Columns("*"), query.OrderAsc("id")) {
+		if err != nil {
+			t.Fatalf("store.All(ctx, query.Columns(%q)): %v\n", "*", err)
+		}
+
+		if m.ID != i {
+			t.Errorf("m.ID = %v, want = %v\n", m.ID, i)
+		}
+		i++
+	}
+
+	if i != int64(len(mm)) {
+		t.Fatalf("i = %v, want = %v\n", i, len(mm))
+	}
+
+	n := 0
+
+	for _, err := range store.All(ctx, query.Columns("*")) {
+		if err != nil {
+			t.Fatalf("store.All(ctx, query.Columns(%q)): %v\n", "*", err)
+		}
+
+		n++
+
+		if n == 3 {
+			break
+		}
+	}
+
+	// Make sure the rows were released after breaking out of the loop, by
+	// doing a write.
+	if _, err := store.Delete(ctx, mm...); err != nil {
+		t.Fatalf("store.Delete(ctx, mm...): %v\n", err)
+	}
+
+	for _, err := range store.All(ctx, query.Columns("nonexistent")) {
+		if err == nil {
+			t.Fatal("expected error for nonexistent column, got nil")
+		}
+	}
+}
+
+func TestStoreWith(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	})
+
+	tx, err := db.BeginTx(ctx, nil)
+
+	if err != nil {
+		t.Fatalf("db.BeginTx(ctx, nil): %v\n", err)
+	}
+
+	defer tx.Rollback()
+
+	txstore := store.With(tx)
+
+	m := &M{
+		ID:     1,
+		Str:    "string",
+		BigStr: "bigstring",
+		Blob:   []byte{},
+		Time:   time.Now(),
+	}
+
+	if err := txstore.Create(ctx, m); err != nil {
+		t.Fatalf("txstore.Create(ctx, m): %v\n", err)
+	}
+
+	if _, ok, err := txstore.Get(ctx, m.PrimaryKey().Where()); err != nil || !ok {
+		t.Fatalf("txstore.Get(ctx, m.PrimaryKey().Where()) = %v, %v, want = %v, %v\n", ok, err, true, nil)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("tx.Rollback(): %v\n", err)
+	}
+
+	if _, ok, err := store.Get(ctx, m.PrimaryKey().Where()); err != nil || ok {
+		t.Fatalf("store.Get(ctx, m.PrimaryKey().Where()) = %v, %v, want = %v, %v\n", ok, err, false, nil)
+	}
+}
+
+// execRecorder records the queries executed against it.
+type execRecorder struct {
+	queries []string
+	args    [][]any
+}
+
+func (r *execRecorder) ExecContext(_ context.Context, q string, args ...any) (sql.Result, error) {
+	r.queries = append(r.queries, q)
+	r.args = append(r.args, args)
+	return noResult{}, nil
+}
+
+func (r *execRecorder) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *execRecorder) QueryRowContext(context.Context, string, ...any) *sql.Row {
+	return nil
+}
+
+func TestStoreCreateChunked(t *testing.T) {
+	ctx := t.Context()
+
+	tests := []struct {
+		dialect Dialect
+		n       int
+		want    []int
+	}{
+		// Each model has 8 creatable params, allowing for 4095 models per
+		// statement in SQLite, and 8191 in PostgreSQL.
+		{SQLite, 4095, []int{4095}},
+		{SQLite, 5000, []int{4095, 905}},
+		{Postgres, 5000, []int{5000}},
+		{Postgres, 20000, []int{8191, 8191, 3618}},
+	}
+
+	for _, test := range tests {
+		var rec execRecorder
+
+		store := NewStore[*M](&rec, func() *M {
+			return &M{}
+		}, WithDialect(test.dialect))
+
+		mm := make([]*M, 0, test.n)
+
+		for i := 0; i < cap(mm); i++ {
+			mm = append(mm, &M{
+				ID: int64(i),
+			})
+		}
+
+		if err := store.Create(ctx, mm...); err != nil {
+			t.Fatalf("store.Create(ctx, mm...): %v\n", err)
+		}
+
+		if len(rec.args) != len(test.want) {
+			t.Fatalf("%s: len(rec.args) = %v, want = %v\n", test.dialect, len(rec.args), len(test.want))
+		}
+
+		for i, args := range rec.args {
+			if n := len(args) / 8; n != test.want[i] {
+				t.Errorf("%s: rec.args[%v] = %v models, want = %v\n", test.dialect, i, n, test.want[i])
+			}
+		}
+	}
+}
+
+type metricsRecorder struct {
+	tables []string
+	ops    []Op
+	errs   []error
+}
+
+func (r *metricsRecorder) Observe(table string, op Op, _ time.Duration, err error) {
+	r.tables = append(r.tables, table)
+	r.ops = append(r.ops, op)
+	r.errs = append(r.errs, err)
+}
+
+func TestStoreMetrics(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	var rec metricsRecorder
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	}, WithMetrics(&rec))
+
+	m := &M{
+		ID:     1,
+		Str:    "string",
+		BigStr: "bigstring",
+		Blob:   []byte{},
+		Time:   time.Now(),
+	}
+
+	if err := store.Create(ctx, m); err != nil {
+		t.Fatalf("store.Create(ctx, m): %v\n", err)
+	}
+
+	if _, _, err := store.Get(ctx, m.PrimaryKey().Where()); err != nil {
+		t.Fatalf("store.Get(ctx, m.PrimaryKey().Where()): %v\n", err)
+	}
+
+	if _, err := store.Update(ctx, m); err != nil {
+		t.Fatalf("store.Update(ctx, m): %v\n", err)
+	}
+
+	if _, err := store.Delete(ctx, m); err != nil {
+		t.Fatalf("store.Delete(ctx, m): %v\n", err)
+	}
+
+	store.Select(ctx, query.Columns("nonexistent"))
+
+	want := []Op{OpCreate, OpSelect, OpUpdate, OpDelete, OpSelect}
+
+	if !slices.Equal(rec.ops, want) {
+		t.Fatalf("rec.ops = %v, want = %v\n", rec.ops, want)
+	}
+
+	for i, table := range rec.tables {
+		if table != "models" {
+			t.Errorf("rec.tables[%v] = %q, want = %q\n", i, table, "models")
+		}
+	}
+
+	if rec.errs[len(rec.errs)-1] == nil {
+		t.Fatal("expected error for nonexistent column, got nil")
+	}
+}
+
+func TestStoreExecQuery(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	var rec metricsRecorder
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	}, WithMetrics(&rec))
+
+	q := query.Insert("models", query.Columns("id", "str", "bigstr", "int", "bigint", "bool", "blob", "time"), query.Values(1, "string", "bigstring", 0, 0, false, []byte{}, time.Now()))
+
+	if _, err := store.Exec(ctx, q); err != nil {
+		t.Fatalf("store.Exec(ctx, q): %v\n", err)
+	}
+
+	q = query.Update("models", query.Set("str", query.Arg("updated")), query.Where(query.Eq(query.Ident("id"), query.Arg(1))))
+
+	if _, err := store.Exec(ctx, q); err != nil {
+		t.Fatalf("store.Exec(ctx, q): %v\n", err)
+	}
+
+	q = query.Select(query.Columns("str"), query.From("models"))
+
+	rows, err := store.Query(ctx, q)
+
+	if err != nil {
+		t.Fatalf("store.Query(ctx, q): %v\n", err)
+	}
+
+	defer rows.Close()
+
+	var str string
+
+	if rows.Next() {
+		if err := rows.Scan(&str); err != nil {
+			t.Fatalf("rows.Scan(&str): %v\n", err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		t.Fatalf("rows.Err(): %v\n", err)
+	}
+
+	if str != "updated" {
+		t.Errorf("str = %q, want = %q\n", str, "updated")
+	}
+
+	want := []Op{OpCreate, OpUpdate, OpSelect}
+
+	if !slices.Equal(rec.ops, want) {
+		t.Fatalf("rec.ops = %v, want = %v\n", rec.ops, want)
+	}
+
+	for i, table := range rec.tables {
+		if table != "models" {
+			t.Errorf("rec.tables[%v] = %q, want = %q\n", i, table, "models")
+		}
+	}
+}
+
+type poolRecorder struct {
+	metricsRecorder
+
+	stats []sql.DBStats
+}
+
+func (r *poolRecorder) ObservePool(stats sql.DBStats) {
+	r.stats = append(r.stats, stats)
+}
+
+func TestStorePoolStats(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+	db.SetMaxOpenConns(2)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	var rec poolRecorder
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	}, WithMetrics(&rec), WithPoolStats(time.Hour))
+
+	for range 3 {
+		if _, err := store.Count(ctx); err != nil {
+			t.Fatalf("store.Count(ctx): %v\n", err)
+		}
+	}
+
+	// Only the first query is sampled, since the rest are within the
+	// interval.
+	if len(rec.stats) != 1 {
+		t.Fatalf("len(rec.stats) = %v, want = %v\n", len(rec.stats), 1)
+	}
+
+	if rec.stats[0].MaxOpenConnections != 2 {
+		t.Fatalf("rec.stats[0].MaxOpenConnections = %v, want = %v\n", rec.stats[0].MaxOpenConnections, 2)
+	}
+
+	if len(rec.ops) != 3 {
+		t.Fatalf("len(rec.ops) = %v, want = %v\n", len(rec.ops), 3)
+	}
+}
+
+type Generated struct {
+	ID      int64
+	Name    string
+	Version int
+}
+
+func (g *Generated) Table() string { return "generated" }
+
+func (g *Generated) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{g.ID},
+	}
+}
+
+func (g *Generated) Params() Params {
+	return Params{
+		"id":      GeneratedParam(g.ID),
+		"name":    MutableParam(g.Name),
+		"version": GeneratedParam(g.Version),
+	}
+}
+
+func TestStoreCreateReturning(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	schema := `CREATE TABLE generated (id INTEGER PRIMARY KEY, name TEXT NOT NULL, version INTEGER NOT NULL DEFAULT 1)`
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", schema, err)
+	}
+
+	store := NewStore(db, func() *Generated {
+		return &Generated{}
+	}, WithDialect(SQLite))
+
+	gg := []*Generated{
+		{Name: "foo"},
+		{Name: "bar"},
+		{Name: "baz"},
+	}
+
+	if err := store.Create(ctx, gg...); err != nil {
+		t.Fatalf("store.Create(ctx, gg...): %v\n", err)
+	}
+
+	gg[0].Name = "updated"
+	gg[0].Version = 10
+
+	if _, err := store.Update(ctx, gg[0]); err != nil {
+		t.Fatalf("store.Update(ctx, gg[0]): %v\n", err)
+	}
+
+	gg, err := store.SelectAll(ctx)
+
+	if err != nil {
+		t.Fatalf("store.SelectAll(ctx): %v\n", err)
+	}
+
+	for i, g := range gg {
+		if want := int64(i + 1); g.ID != want {
+			t.Errorf("gg[%d].ID = %v, want = %v\n", i, g.ID, want)
+		}
+
+		if g.Version != 1 {
+			t.Errorf("gg[%d].Version = %v, want = %v\n", i, g.Version, 1)
+		}
+	}
+}
+
+func TestStoreReturningEmulated(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	schema := `CREATE TABLE generated (id INTEGER PRIMARY KEY, name TEXT NOT NULL, version INTEGER NOT NULL DEFAULT 1)`
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", schema, err)
+	}
+
+	// SQLite is used in place of MySQL, which does not support RETURNING, so
+	// the generated columns are selected once the models are written. SQLite
+	// also accepts the ? placeholders that MySQL queries are built with.
+	store := NewStore(db, func() *Generated {
+		return &Generated{}
+	}, WithDialect(MySQL))
+
+	gg := []*Generated{
+		{Name: "foo"},
+		{Name: "bar"},
+	}
+
+	// SQLite gives the LastInsertId of the last row for multi-row inserts,
+	// so the models are created one at a time.
+	for i, g := range gg {
+		if err := store.Create(ctx, g); err != nil {
+			t.Fatalf("store.Create(ctx, gg[%d]): %v\n", i, err)
+		}
+
+		if want := int64(i + 1); g.ID != want {
+			t.Fatalf("gg[%d].ID = %v, want = %v\n", i, g.ID, want)
+		}
+
+		if g.Version != 1 {
+			t.Fatalf("gg[%d].Version = %v, want = %v\n", i, g.Version, 1)
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, "UPDATE generated SET version = 2 WHERE id = 1"); err != nil {
+		t.Fatalf("db.ExecContext(ctx): %v\n", err)
+	}
+
+	gg[0].Name = "updated"
+
+	res, err := store.Update(ctx, gg[0])
+
+	if err != nil {
+		t.Fatalf("store.Update(ctx, gg[0]): %v\n", err)
+	}
+
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Fatalf("res.RowsAffected() = %v, want = %v\n", n, 1)
+	}
+
+	if gg[0].Version != 2 {
+		t.Fatalf("gg[0].Version = %v, want = %v\n", gg[0].Version, 2)
+	}
+}
+
+type insertIdRecorder struct {
+	execRecorder
+
+	id int64
+}
+
+type insertIdResult int64
+
+func (r insertIdResult) LastInsertId() (int64, error) { return int64(r), nil }
+func (r insertIdResult) RowsAffected() (int64, error) { return 0, nil }
+
+func (r *insertIdRecorder) ExecContext(ctx context.Context, q string, args ...any) (sql.Result, error) {
+	r.execRecorder.ExecContext(ctx, q, args...)
+	return insertIdResult(r.id), nil
+}
+
+func TestStoreCreateZeroKey(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	}, WithDialect(SQLite))
+
+	for i := range 2 {
+		m := &M{
+			Blob: []byte{},
+			Time: time.Now(),
+		}
+
+		if err := store.Create(ctx, m); err != nil {
+			t.Fatalf("store.Create(ctx, m): %v\n", err)
+		}
+
+		if want := int64(i + 1); m.ID != want {
+			t.Fatalf("m.ID = %v, want = %v\n", m.ID, want)
+		}
+	}
+}
+
+func TestStoreGetOrErr(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	})
+
+	if err := store.Create(ctx, &M{ID: 1, Blob: []byte{}, Time: time.Now()}); err != nil {
+		t.Fatalf("store.Create(ctx, m): %v\n", err)
+	}
+
+	m, err := store.GetOrErr(ctx, query.WhereEq("id", query.Arg(1)))
+
+	if err != nil {
+		t.Fatalf("store.GetOrErr(ctx, ...): %v\n", err)
+	}
+
+	if m.ID != 1 {
+		t.Fatalf("m.ID = %v, want = %v\n", m.ID, 1)
+	}
+
+	_, err = store.GetOrErr(ctx, query.WhereEq("id", query.Arg(2)))
+
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("store.GetOrErr(ctx, ...) = %v, want = %v\n", err, ErrNotFound)
+	}
+
+	var nferr *NotFoundError
+
+	if !errors.As(err, &nferr) {
+		t.Fatalf("store.GetOrErr(ctx, ...) = %T, want = %T\n", err, nferr)
+	}
+
+	want := "(id = $1)"
+
+	if nferr.Table != "models" || nferr.Filter != want || !slices.Equal(nferr.Args, []any{2}) {
+		t.Fatalf("nferr = %+v, want = %+v\n", nferr, NotFoundError{Table: "models", Filter: want, Args: []any{2}})
+	}
+
+	// The filter includes the scopes of the store, since these were used in
+	// the query that found nothing.
+	scoped := store.Scope(func(context.Context) query.Option {
+		return query.WhereEq("int", query.Arg(10))
+	})
+
+	_, err = scoped.GetOrErr(ctx, query.WhereEq("id", query.Arg(1)))
+
+	if !errors.As(err, &nferr) {
+		t.Fatalf("scoped.GetOrErr(ctx, ...) = %v, want = %T\n", err, nferr)
+	}
+
+	want = "((id = $1) AND (int = $2))"
+
+	if nferr.Filter != want || !slices.Equal(nferr.Args, []any{1, 10}) {
+		t.Fatalf("nferr = %+v, want = %+v\n", nferr, NotFoundError{Table: "models", Filter: want, Args: []any{1, 10}})
+	}
+}
+
+func TestStoreCreateInsertId(t *testing.T) {
+	ctx := t.Context()
+
+	rec := insertIdRecorder{id: 10}
+
+	store := NewStore[*M](&rec, func() *M {
+		return &M{}
+	}, WithDialect(MySQL))
+
+	mm := []*M{{}, {}, {}}
+
+	if err := store.Create(ctx, mm...); err != nil {
+		t.Fatalf("store.Create(ctx, mm...): %v\n", err)
+	}
+
+	// The id column should be omitted, leaving 7 params per model.
+	if n := len(rec.args[0]); n != 7*len(mm) {
+		t.Fatalf("len(rec.args[0]) = %v, want = %v\n", n, 7*len(mm))
+	}
+
+	for i, m := range mm {
+		if want := int64(i + 10); m.ID != want {
+			t.Errorf("mm[%d].ID = %v, want = %v\n", i, m.ID, want)
+		}
+	}
+}
+
+func TestStoreTruncate(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	schema := `CREATE TABLE generated (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, version INTEGER NOT NULL DEFAULT 1)`
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", schema, err)
+	}
+
+	store := NewStore(db, func() *Generated {
+		return &Generated{}
+	}, WithDialect(SQLite))
+
+	tests := []struct {
+		restart bool
+		want    int64
+	}{
+		{false, 3},
+		{true, 1},
+	}
+
+	for _, test := range tests {
+		if err := store.Create(ctx, &Generated{Name: "foo"}, &Generated{Name: "bar"}); err != nil {
+			t.Fatalf("store.Create(ctx, ...): %v\n", err)
+		}
+
+		if err := store.Truncate(ctx, test.restart); err != nil {
+			t.Fatalf("store.Truncate(ctx, %v): %v\n", test.restart, err)
+		}
+
+		n, err := store.Count(ctx)
+
+		if err != nil {
+			t.Fatalf("store.Count(ctx): %v\n", err)
+		}
+
+		if n != 0 {
+			t.Fatalf("n = %v, want = %v\n", n, 0)
+		}
+
+		g := &Generated{Name: "baz"}
+
+		if err := store.Create(ctx, g); err != nil {
+			t.Fatalf("store.Create(ctx, g): %v\n", err)
+		}
+
+		if g.ID != test.want {
+			t.Fatalf("g.ID = %v, want = %v\n", g.ID, test.want)
+		}
+
+		if err := store.Truncate(ctx, true); err != nil {
+			t.Fatalf("store.Truncate(ctx, %v): %v\n", true, err)
+		}
+	}
+
+	var rec execRecorder
+
+	store = NewStore(&rec, func() *Generated {
+		return &Generated{}
+	}, WithDialect(Postgres))
+
+	if err := store.Truncate(ctx, true); err != nil {
+		t.Fatalf("store.Truncate(ctx, %v): %v\n", true, err)
+	}
+
+	if want := "TRUNCATE TABLE generated RESTART IDENTITY"; rec.queries[0] != want {
+		t.Fatalf("rec.queries[0] = %q, want = %q\n", rec.queries[0], want)
+	}
+}
+
+type Task struct {
+	ID        int64
+	Status    string
+	CreatedAt time.Time
+}
+
+func (t *Task) Table() string { return "tasks" }
+
+func (t *Task) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{t.ID},
+	}
+}
+
+func (t *Task) Params() Params {
+	return Params{
+		"id":         CreateOnlyParam(t.ID),
+		"status":     DefaultParam(t.Status, "pending"),
+		"created_at": DefaultParam(t.CreatedAt, Now),
+	}
+}
+
+const taskSchema = `CREATE TABLE IF NOT EXISTS tasks (
+	id         INTEGER NOT NULL,
+	status     TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+func TestDefaultParam(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, taskSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", taskSchema, err)
+	}
+
+	store := NewStore(db, func() *Task {
+		return &Task{}
+	})
+
+	done := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tt := []*Task{
+		{ID: 1},
+		{ID: 2, Status: "done", CreatedAt: done},
+	}
+
+	if err := store.Create(ctx, tt...); err != nil {
+		t.Fatalf("store.Create(ctx, tt...): %v\n", err)
+	}
+
+	if tt[0].Status != "pending" {
+		t.Fatalf("tt[0].Status = %v, want = %v\n", tt[0].Status, "pending")
+	}
+
+	if tt[0].CreatedAt.IsZero() {
+		t.Fatalf("tt[0].CreatedAt = %v, want non-zero\n", tt[0].CreatedAt)
+	}
+
+	got, err := store.SelectAll(ctx, query.OrderAsc("id"))
+
+	if err != nil {
+		t.Fatalf("store.SelectAll(ctx): %v\n", err)
+	}
+
+	if got[0].Status != "pending" {
+		t.Fatalf("got[0].Status = %v, want = %v\n", got[0].Status, "pending")
+	}
+
+	if !got[0].CreatedAt.Equal(tt[0].CreatedAt) {
+		t.Fatalf("got[0].CreatedAt = %v, want = %v\n", got[0].CreatedAt, tt[0].CreatedAt)
+	}
+
+	if got[1].Status != "done" || !got[1].CreatedAt.Equal(done) {
+		t.Fatalf("got[1] = %v, want = %v\n", got[1], tt[1])
+	}
+}
+
+func TestStoreCreateFromMap(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, taskSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", taskSchema, err)
+	}
+
+	store := NewStore(db, func() *Task {
+		return &Task{}
+	})
+
+	task, err := store.CreateFromMap(ctx, map[string]any{"id": 1})
+
+	if err != nil {
+		t.Fatalf("store.CreateFromMap(ctx, ...): %v\n", err)
+	}
+
+	if task.ID != 1 || task.Status != "pending" {
+		t.Fatalf("task = %+v, want = {ID:1 Status:pending}\n", task)
+	}
+
+	if _, ok, _ := store.Get(ctx, query.WhereEq("id", query.Arg(1))); !ok {
+		t.Fatalf("store.Get(ctx, ...): expected model, got none\n")
+	}
+
+	if _, err := store.CreateFromMap(ctx, map[string]any{"id": 2, "title": "foo"}); err == nil {
+		t.Fatalf("store.CreateFromMap(ctx, ...): expected error, got nil\n")
+	}
+
+	models := NewStore[*M](db, func() *M {
+		return &M{}
+	})
+
+	if _, err := models.CreateFromMap(ctx, map[string]any{"null_time": time.Now()}); err == nil {
+		t.Fatalf("models.CreateFromMap(ctx, ...): expected error, got nil\n")
+	}
+}
+
+func TestParamsBuilder(t *testing.T) {
+	params := NewParams().
+		Add("id", CreateOnlyParam(1)).
+		Add("title", MutableParam("hello")).
+		Add("content", MutableParam("world")).
+		Params()
+
+	params["body"] = MutableParam("")
+
+	want := []string{"id", "title", "content", "body"}
+
+	if cols := params.Columns(); !slices.Equal(cols, want) {
+		t.Fatalf("params.Columns() = %v, want = %v\n", cols, want)
+	}
+
+	i := 0
+
+	for col := range params.All() {
+		if col != want[i] {
+			t.Fatalf("params.All()[%d] = %v, want = %v\n", i, col, want[i])
+		}
+		i++
+	}
+
+	defer func() {
+		if v := recover(); v == nil {
+			t.Fatalf("NewParams().Add(%q, ...).Add(%q, ...): expected panic\n", "id", "id")
+		}
+	}()
+
+	NewParams().Add("id", CreateOnlyParam(1)).Add("id", MutableParam(1))
+}
+
+type Member struct {
+	ID       int64
+	Email    string
+	Password string
+}
+
+func (m *Member) Table() string { return "members" }
+
+func (m *Member) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{m.ID},
+	}
+}
+
+func hashPassword(v any) (any, error) {
+	s, ok := v.(string)
+
+	if !ok {
+		return nil, fmt.Errorf("cannot hash %T", v)
+	}
+
+	if s == "" {
+		return nil, errors.New("empty password")
+	}
+
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (m *Member) Params() Params {
+	return Params{
+		"id":       CreateOnlyParam(m.ID),
+		"email":    MutableParam(m.Email).Transform(TrimSpace, Lowercase),
+		"password": CreateOnlyParam(m.Password).Transform(hashPassword),
+	}
+}
+
+const memberSchema = `CREATE TABLE IF NOT EXISTS members (
+	id       INTEGER NOT NULL,
+	email    TEXT NOT NULL,
+	password TEXT NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+func TestParamTransform(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, memberSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", memberSchema, err)
+	}
+
+	store := NewStore(db, func() *Member {
+		return &Member{}
+	})
+
+	m := &Member{
+		ID:       1,
+		Email:    "  Me@Example.COM ",
+		Password: "secret",
+	}
+
+	if err := store.Create(ctx, m); err != nil {
+		t.Fatalf("store.Create(ctx, m): %v\n", err)
+	}
+
+	hash, _ := hashPassword("secret")
+
+	if m.Email != "me@example.com" {
+		t.Fatalf("m.Email = %q, want = %q\n", m.Email, "me@example.com")
+	}
+
+	if m.Password != hash {
+		t.Fatalf("m.Password = %q, want = %q\n", m.Password, hash)
+	}
+
+	m.Email = "New@Example.com"
+
+	if _, err := store.Update(ctx, m); err != nil {
+		t.Fatalf("store.Update(ctx, m): %v\n", err)
+	}
+
+	got, _, err := store.Get(ctx)
+
+	if err != nil {
+		t.Fatalf("store.Get(ctx): %v\n", err)
+	}
+
+	if got.Email != "new@example.com" {
+		t.Fatalf("got.Email = %q, want = %q\n", got.Email, "new@example.com")
+	}
+
+	// The password is create only, so it is not hashed again on update.
+	if got.Password != hash {
+		t.Fatalf("got.Password = %q, want = %q\n", got.Password, hash)
+	}
+
+	fields := map[string]any{
+		"email": " Many@Example.com",
+	}
+
+	if _, err := store.UpdateMany(ctx, fields); err != nil {
+		t.Fatalf("store.UpdateMany(ctx, fields): %v\n", err)
+	}
+
+	got, _, err = store.Get(ctx)
+
+	if err != nil {
+		t.Fatalf("store.Get(ctx): %v\n", err)
+	}
+
+	if got.Email != "many@example.com" {
+		t.Fatalf("got.Email = %q, want = %q\n", got.Email, "many@example.com")
+	}
+
+	if err := store.Create(ctx, &Member{ID: 2, Email: "you@example.com"}); err == nil {
+		t.Fatalf("store.Create(ctx, &Member{}): expected error, got nil\n")
+	}
+}
+
+type Launch struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt sql.NullTime
+}
+
+func (l *Launch) Table() string { return "launches" }
+
+func (l *Launch) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{l.ID},
+	}
+}
+
+func (l *Launch) Params() Params {
+	return Params{
+		"id":         CreateOnlyParam(l.ID),
+		"name":       MutableParam(l.Name),
+		"created_at": CreateOnlyParam(query.Lit("CURRENT_TIMESTAMP")),
+		"updated_at": UpdateOnlyParam(query.Lit("CURRENT_TIMESTAMP")),
+	}
+}
+
+const launchSchema = `CREATE TABLE IF NOT EXISTS launches (
+	id         INTEGER NOT NULL,
+	name       TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NULL,
+	PRIMARY KEY (id)
+);`
+
+func TestParamExpr(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, launchSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", launchSchema, err)
+	}
+
+	store := NewStore(db, func() *Launch {
+		return &Launch{}
+	})
+
+	l := &Launch{
+		ID:   1,
+		Name: "launch",
+	}
+
+	if err := store.Create(ctx, l); err != nil {
+		t.Fatalf("store.Create(ctx, l): %v\n", err)
+	}
+
+	if l.CreatedAt.IsZero() {
+		t.Fatalf("l.CreatedAt = %v, want = non-zero\n", l.CreatedAt)
+	}
+
+	got, _, err := store.Get(ctx)
+
+	if err != nil {
+		t.Fatalf("store.Get(ctx): %v\n", err)
+	}
+
+	if got.CreatedAt.IsZero() {
+		t.Fatalf("got.CreatedAt = %v, want = non-zero\n", got.CreatedAt)
+	}
+
+	if got.UpdatedAt.Valid {
+		t.Fatalf("got.UpdatedAt.Valid = %v, want = %v\n", got.UpdatedAt.Valid, false)
+	}
+
+	got.Name = "relaunch"
+
+	if _, err := store.Update(ctx, got); err != nil {
+		t.Fatalf("store.Update(ctx, got): %v\n", err)
+	}
+
+	got, _, err = store.Get(ctx)
+
+	if err != nil {
+		t.Fatalf("store.Get(ctx): %v\n", err)
+	}
+
+	if got.Name != "relaunch" {
+		t.Fatalf("got.Name = %q, want = %q\n", got.Name, "relaunch")
+	}
+
+	if !got.UpdatedAt.Valid {
+		t.Fatalf("got.UpdatedAt.Valid = %v, want = %v\n", got.UpdatedAt.Valid, true)
+	}
+}
+
+func TestCreateConflict(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, taskSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", taskSchema, err)
+	}
+
+	store := NewStore(db, func() *Task {
+		return &Task{}
+	}, WithDialect(SQLite))
+
+	if err := store.Create(ctx, &Task{ID: 1}); err != nil {
+		t.Fatalf("store.Create(ctx, &Task{}): %v\n", err)
+	}
+
+	if err := store.Create(ctx, &Task{ID: 1}); err == nil {
+		t.Fatalf("store.Create(ctx, &Task{}): expected error, got nil\n")
+	}
+
+	tt := []*Task{
+		{ID: 1, Status: "done"},
+		{ID: 2, Status: "done"},
+	}
+
+	n, err := store.CreateIgnore(ctx, tt...)
+
+	if err != nil {
+		t.Fatalf("store.CreateIgnore(ctx, tt...): %v\n", err)
+	}
+
+	if n != 1 {
+		t.Fatalf("store.CreateIgnore(ctx, tt...) = %v, want = %v\n", n, 1)
+	}
+
+	got, err := store.SelectAll(ctx, query.OrderAsc("id"))
+
+	if err != nil {
+		t.Fatalf("store.SelectAll(ctx): %v\n", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %v, want = %v\n", len(got), 2)
+	}
+
+	if got[0].Status != "pending" {
+		t.Fatalf("got[0].Status = %v, want = %v\n", got[0].Status, "pending")
+	}
+
+	if err := store.CreateReplace(ctx, tt[0]); err != nil {
+		t.Fatalf("store.CreateReplace(ctx, tt[0]): %v\n", err)
+	}
+
+	got, err = store.SelectAll(ctx, query.OrderAsc("id"))
+
+	if err != nil {
+		t.Fatalf("store.SelectAll(ctx): %v\n", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %v, want = %v\n", len(got), 2)
+	}
+
+	if got[0].Status != "done" {
+		t.Fatalf("got[0].Status = %v, want = %v\n", got[0].Status, "done")
+	}
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{Postgres, "INSERT INTO tasks (created_at, id, status) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING"},
+		{MySQL, "INSERT IGNORE INTO tasks (created_at, id, status) VALUES (?, ?, ?)"},
+	}
+
+	for _, test := range tests {
+		var rec execRecorder
+
+		store := NewStore[*Task](&rec, func() *Task {
+			return &Task{}
+		}, WithDialect(test.dialect))
+
+		if _, err := store.CreateIgnore(ctx, &Task{ID: 3}); err != nil {
+			t.Fatalf("%s: store.CreateIgnore(ctx, &Task{}): %v\n", test.dialect, err)
+		}
+
+		if len(rec.queries) != 1 || rec.queries[0] != test.want {
+			t.Fatalf("%s: rec.queries = %q, want = [%q]\n", test.dialect, rec.queries, test.want)
+		}
+	}
+}
+
+func TestStoreCreateIgnoreGenerated(t *testing.T) {
+	ctx := t.Context()
+
+	var rec execRecorder
+
+	store := NewStore[*Task](&rec, func() *Task {
+		return &Task{}
+	}, WithDialect(MySQL))
+
+	tt := []*Task{
+		{Status: "pending"},
+		{Status: "done"},
+	}
+
+	if _, err := store.CreateIgnore(ctx, tt...); err != nil {
+		t.Fatalf("store.CreateIgnore(ctx, tt...): %v\n", err)
+	}
+
+	// Which rows were ignored cannot be known, so the LastInsertId of the
+	// result cannot be matched up to the models.
+	for i, task := range tt {
+		if task.ID != 0 {
+			t.Fatalf("tt[%d].ID = %v, want = %v\n", i, task.ID, 0)
+		}
+	}
+}
+
+func TestStoreUpsert(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, taskSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", taskSchema, err)
+	}
+
+	store := NewStore(db, func() *Task {
+		return &Task{}
+	}, WithDialect(SQLite))
+
+	if err := store.Create(ctx, &Task{ID: 1}); err != nil {
+		t.Fatalf("store.Create(ctx, &Task{}): %v\n", err)
+	}
+
+	tt := []*Task{
+		{ID: 1, Status: "done"},
+		{ID: 2, Status: "running"},
+	}
+
+	if err := store.Upsert(ctx, nil, tt...); err != nil {
+		t.Fatalf("store.Upsert(ctx, nil, tt...): %v\n", err)
+	}
+
+	got, err := store.SelectAll(ctx, query.OrderAsc("id"))
+
+	if err != nil {
+		t.Fatalf("store.SelectAll(ctx): %v\n", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("len(got) = %v, want = %v\n", len(got), 2)
+	}
+
+	for i, want := range []string{"done", "running"} {
+		if got[i].Status != want {
+			t.Fatalf("got[%d].Status = %v, want = %v\n", i, got[i].Status, want)
+		}
+	}
+
+	t.Run("chunked", func(t *testing.T) {
+		tests := []struct {
+			dialect Dialect
+			n       int
+			want    string
+			queries int
+		}{
+			{SQLite, 5000, "ON CONFLICT (id) DO UPDATE SET", 2},
+			{Postgres, 5000, "ON CONFLICT (id) DO UPDATE SET", 1},
+			{MySQL, 5000, "ON DUPLICATE KEY UPDATE", 1},
+		}
+
+		for _, test := range tests {
+			var rec execRecorder
+
+			store := NewStore[*M](&rec, func() *M {
+				return &M{}
+			}, WithDialect(test.dialect))
+
+			mm := make([]*M, 0, test.n)
+
+			for i := 0; i < cap(mm); i++ {
+				mm = append(mm, &M{
+					ID: int64(i + 1),
+				})
+			}
+
+			if err := store.Upsert(ctx, nil, mm...); err != nil {
+				t.Fatalf("%s: store.Upsert(ctx, nil, mm...): %v\n", test.dialect, err)
+			}
+
+			if len(rec.queries) != test.queries {
+				t.Fatalf("%s: len(rec.queries) = %v, want = %v\n", test.dialect, len(rec.queries), test.queries)
+			}
+
+			for i, q := range rec.queries {
+				if !strings.Contains(q, test.want) {
+					t.Errorf("%s: rec.queries[%d] does not contain %q\n", test.dialect, i, test.want)
+				}
+			}
+		}
+	})
+}
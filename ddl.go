@@ -0,0 +1,376 @@
+package database
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ModelColumn is a column of the table of a [Model], as inferred by
+// [ModelColumns].
+type ModelColumn struct {
+	// Name is the name of the column.
+	Name string
+
+	// Type is the type of the column in the [Dialect], such as "BIGINT" or
+	// "TEXT".
+	Type string
+
+	// Nullable is whether the column allows NULL values.
+	Nullable bool
+
+	// PrimaryKey is whether the column is part of the Model's primary key.
+	PrimaryKey bool
+
+	// Identity is whether the column is a single integer primary key that is a
+	// [GeneratedParam], and so is generated by the database.
+	Identity bool
+}
+
+// ModelColumns returns the columns of the table of the given Model in the given
+// [Dialect]. A column is returned for each of the Model's [Params], with the
+// type of the column inferred from the type of the struct field the column
+// maps to, or from the type of the parameter's value if the column does not
+// map to a field. Columns are not nullable, unless the field is a pointer, a
+// slice, or a nullable type such as [Null] or [sql.NullString], and is not
+// part of the Model's [PrimaryKey].
+//
+// The columns are ordered by the primary key, followed by the order the Params
+// were declared in via a [ParamsBuilder], followed by the order of the struct
+// fields, followed by any remaining columns in alphabetical order.
+func ModelColumns(m Model, dialect Dialect) ([]*ModelColumn, error) {
+	params := m.Params()
+
+	if len(params) == 0 {
+		return nil, errors.New("model has no params")
+	}
+
+	rt := reflect.TypeOf(m)
+
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+
+	var fields *structFields
+
+	if rt.Kind() == reflect.Struct {
+		var err error
+
+		fields, err = typeFields(rt)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var pk []string
+
+	if key := m.PrimaryKey(); key != nil {
+		pk = key.Columns
+	}
+
+	names := ddlColumns(params, fields, pk)
+	cols := make([]*ModelColumn, 0, len(names))
+
+	for _, name := range names {
+		param := params[name]
+
+		var (
+			typ  reflect.Type
+			json bool
+		)
+
+		if fields != nil {
+			if fld, ok := fields.get(name); ok {
+				typ = fld.typ
+				json = fld.json
+			}
+		}
+
+		if typ == nil {
+			if param.value == nil {
+				return nil, fmt.Errorf("cannot infer type of column %s", name)
+			}
+			typ = reflect.TypeOf(param.value)
+		}
+
+		sqltyp, err := dialect.columnType(typ, json)
+
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", name, err)
+		}
+
+		primary := slices.Contains(pk, name)
+
+		cols = append(cols, &ModelColumn{
+			Name:       name,
+			Type:       sqltyp,
+			Nullable:   isNullable(typ) && !primary,
+			PrimaryKey: primary,
+			Identity:   len(pk) == 1 && primary && param.mode == 0 && isInteger(typ),
+		})
+	}
+	return cols, nil
+}
+
+// CreateTableSQL returns the CREATE TABLE statement for the given Model in the
+// given [Dialect]. The columns of the table are those returned by
+// [ModelColumns], and the primary key is declared from the Model's
+// [PrimaryKey].
+//
+// A single integer primary key that is a [GeneratedParam] is declared as an
+// identity column for [Postgres], and as AUTO_INCREMENT for [MySQL]. For
+// [SQLite], an INTEGER primary key is an alias for the rowid, and so is
+// generated anyway.
+//
+// This is intended for bootstrapping schemas, and for tests, and is not a
+// replacement for hand written migrations.
+func CreateTableSQL(m Model, dialect Dialect) (string, error) {
+	cols, err := ModelColumns(m, dialect)
+
+	if err != nil {
+		return "", err
+	}
+
+	width := 0
+	pk := make([]string, 0, 1)
+
+	for _, col := range cols {
+		width = max(width, len(col.Name))
+
+		if col.PrimaryKey {
+			pk = append(pk, col.Name)
+		}
+	}
+
+	defs := make([]string, 0, len(cols)+1)
+
+	for _, col := range cols {
+		typ := col.Type
+
+		if col.Identity {
+			switch dialect {
+			case Postgres:
+				typ += " GENERATED BY DEFAULT AS IDENTITY"
+			case MySQL:
+				typ += " AUTO_INCREMENT"
+			}
+		}
+
+		constraint := "NOT NULL"
+
+		if col.Nullable {
+			constraint = "NULL"
+		}
+
+		defs = append(defs, fmt.Sprintf("%-*s %s %s", width, col.Name, typ, constraint))
+	}
+
+	if len(pk) > 0 {
+		defs = append(defs, "PRIMARY KEY ("+strings.Join(pk, ", ")+")")
+	}
+	return "CREATE TABLE IF NOT EXISTS " + m.Table() + " (\n\t" + strings.Join(defs, ",\n\t") + "\n);", nil
+}
+
+// ddlColumns returns the columns of the given params, ordered by the primary
+// key, followed by the order the params were declared in via a [ParamsBuilder],
+// followed by the order of the struct fields they map to, followed by the
+// remaining columns in alphabetical order.
+func ddlColumns(params Params, fields *structFields, pk []string) []string {
+	rest := make([]string, 0, len(params))
+
+	for col := range params {
+		if !slices.Contains(pk, col) {
+			rest = append(rest, col)
+		}
+	}
+
+	// The index of the struct field each column maps to, columns that do
+	// not map to a field are sorted last, and columns of ordered params are
+	// sorted first.
+	index := make(map[string]int, len(rest))
+
+	for _, col := range rest {
+		index[col] = math.MaxInt
+
+		if order := params[col].order; order > 0 {
+			index[col] = order - 1 - len(params)
+			continue
+		}
+
+		if fields == nil {
+			continue
+		}
+
+		if fld, ok := fields.get(col); ok {
+			index[col] = slices.Index(fields.arr, fld)
+		}
+	}
+
+	slices.SortFunc(rest, func(a, b string) int {
+		if index[a] != index[b] {
+			return cmp.Compare(index[a], index[b])
+		}
+		return strings.Compare(a, b)
+	})
+
+	cols := make([]string, 0, len(params))
+
+	for _, col := range pk {
+		if _, ok := params[col]; ok {
+			cols = append(cols, col)
+		}
+	}
+	return append(cols, rest...)
+}
+
+// nullElem returns the type of the value held by the given nullable struct
+// type, such as [Null] or [sql.NullString]. These are structs with a Valid
+// field, and a single other field holding the value. If the type is not such
+// a struct, then nil is returned.
+func nullElem(rt reflect.Type) reflect.Type {
+	if rt.Kind() != reflect.Struct {
+		return nil
+	}
+
+	// Null embeds sql.Null, so unwrap it.
+	if rt.NumField() == 1 && rt.Field(0).Anonymous {
+		return nullElem(rt.Field(0).Type)
+	}
+
+	if rt.NumField() != 2 {
+		return nil
+	}
+
+	valid, ok := rt.FieldByName("Valid")
+
+	if !ok || valid.Type.Kind() != reflect.Bool {
+		return nil
+	}
+
+	for i := 0; i < rt.NumField(); i++ {
+		if sf := rt.Field(i); sf.Name != "Valid" {
+			return sf.Type
+		}
+	}
+	return nil
+}
+
+// isNullable reports whether the given type represents a nullable column.
+func isNullable(rt reflect.Type) bool {
+	switch rt.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return nullElem(rt) != nil
+}
+
+// isInteger reports whether the given type is an integer, or a pointer to or
+// nullable integer.
+func isInteger(rt reflect.Type) bool {
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+
+	if el := nullElem(rt); el != nil {
+		rt = el
+	}
+
+	switch rt.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// columnType returns the column type for the given Go type in the dialect. If
+// json is true, then the column is declared as holding JSON.
+func (d Dialect) columnType(rt reflect.Type, json bool) (string, error) {
+	if json {
+		switch d {
+		case Postgres:
+			return "JSONB", nil
+		case MySQL:
+			return "JSON", nil
+		default:
+			return "TEXT", nil
+		}
+	}
+
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+
+	if el := nullElem(rt); el != nil {
+		rt = el
+	}
+
+	if rt == timeType {
+		if d == MySQL {
+			return "DATETIME", nil
+		}
+		return "TIMESTAMP", nil
+	}
+
+	if isArray(rt) {
+		if d == Postgres {
+			el, err := d.columnType(rt.Elem(), false)
+
+			if err != nil {
+				return "", err
+			}
+			return el + "[]", nil
+		}
+
+		if d == MySQL {
+			return "JSON", nil
+		}
+		return "TEXT", nil
+	}
+
+	switch rt.Kind() {
+	case reflect.Bool:
+		return "BOOLEAN", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if d == SQLite {
+			return "INTEGER", nil
+		}
+		return "BIGINT", nil
+	case reflect.Float32, reflect.Float64:
+		switch d {
+		case Postgres:
+			return "DOUBLE PRECISION", nil
+		case MySQL:
+			return "DOUBLE", nil
+		default:
+			return "REAL", nil
+		}
+	case reflect.String:
+		if d == MySQL {
+			return "VARCHAR(255)", nil
+		}
+		return "TEXT", nil
+	case reflect.Slice:
+		// Only []byte reaches here, since other slices are arrays.
+		if d == Postgres {
+			return "BYTEA", nil
+		}
+		return "BLOB", nil
+	case reflect.Map, reflect.Struct:
+		switch d {
+		case Postgres:
+			return "JSONB", nil
+		case MySQL:
+			return "JSON", nil
+		default:
+			return "TEXT", nil
+		}
+	}
+	return "", fmt.Errorf("cannot map type %s to a column type", rt)
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestCreateTableSQL(t *testing.T) {
+	tests := []struct {
+		model   Model
+		dialect Dialect
+		want    string
+	}{
+		{
+			&Profile{},
+			SQLite,
+			`CREATE TABLE IF NOT EXISTS profiles (
+	id       INTEGER NOT NULL,
+	level    INTEGER NULL,
+	bio      TEXT NULL,
+	birthday TIMESTAMP NULL,
+	PRIMARY KEY (id)
+);`,
+		},
+		{
+			&Profile{},
+			Postgres,
+			`CREATE TABLE IF NOT EXISTS profiles (
+	id       BIGINT NOT NULL,
+	level    BIGINT NULL,
+	bio      TEXT NULL,
+	birthday TIMESTAMP NULL,
+	PRIMARY KEY (id)
+);`,
+		},
+		{
+			&Tagged{},
+			Postgres,
+			`CREATE TABLE IF NOT EXISTS tagged (
+	id     BIGINT NOT NULL,
+	tags   TEXT[] NULL,
+	scores BIGINT[] NOT NULL,
+	PRIMARY KEY (id)
+);`,
+		},
+		{
+			&Tagged{},
+			MySQL,
+			`CREATE TABLE IF NOT EXISTS tagged (
+	id     BIGINT NOT NULL,
+	tags   JSON NULL,
+	scores JSON NOT NULL,
+	PRIMARY KEY (id)
+);`,
+		},
+	}
+
+	for i, test := range tests {
+		ddl, err := CreateTableSQL(test.model, test.dialect)
+
+		if err != nil {
+			t.Fatalf("tests[%d] - CreateTableSQL(%T, %v): %v\n", i, test.model, test.dialect, err)
+		}
+
+		if ddl != test.want {
+			t.Fatalf("tests[%d] - CreateTableSQL(%T, %v) = %q, want = %q\n", i, test.model, test.dialect, ddl, test.want)
+		}
+	}
+}
+
+func TestCreateTableSQLRoundTrip(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	ddl, err := CreateTableSQL(&Profile{}, SQLite)
+
+	if err != nil {
+		t.Fatalf("CreateTableSQL(&Profile{}, SQLite): %v\n", err)
+	}
+
+	if _, err := db.ExecContext(ctx, ddl); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", ddl, err)
+	}
+
+	store := NewStore(db, func() *Profile {
+		return &Profile{}
+	}, WithDialect(SQLite))
+
+	want := &Profile{
+		ID:       1,
+		Bio:      Null[string]{sql.Null[string]{V: "hello", Valid: true}},
+		Birthday: Null[time.Time]{sql.Null[time.Time]{V: time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true}},
+	}
+
+	if err := store.Create(ctx, want); err != nil {
+		t.Fatalf("store.Create(ctx, want): %v\n", err)
+	}
+
+	got, ok, err := store.Get(ctx)
+
+	if err != nil {
+		t.Fatalf("store.Get(ctx): %v\n", err)
+	}
+
+	if !ok {
+		t.Fatalf("ok = %v, want = %v\n", ok, true)
+	}
+
+	if got.Level.Valid {
+		t.Fatalf("got.Level = %v, want = %v\n", got.Level, Null[Level]{})
+	}
+
+	if got.Bio != want.Bio {
+		t.Fatalf("got.Bio = %v, want = %v\n", got.Bio, want.Bio)
+	}
+
+	if !got.Birthday.V.Equal(want.Birthday.V) {
+		t.Fatalf("got.Birthday = %v, want = %v\n", got.Birthday, want.Birthday)
+	}
+}
@@ -0,0 +1,45 @@
+package database
+
+import "github.com/andrewpillar/database/query"
+
+// Dialect represents the SQL dialect of the database that a [Store] operates
+// on. This is used by the Store to account for the differences in behaviour
+// between databases. The zero value represents an unknown dialect, in which
+// case the Store will err on the side of caution.
+type Dialect uint
+
+//go:generate stringer -type Dialect -linecomment
+const (
+	Postgres Dialect = iota + 1 // postgres
+	SQLite                      // sqlite
+	MySQL                       // mysql
+)
+
+// maxParams returns the maximum number of bound parameters that can be used in
+// a single statement for the dialect.
+func (d Dialect) maxParams() int {
+	switch d {
+	case Postgres, MySQL:
+		return 65535
+	default:
+		// SQLite's SQLITE_MAX_VARIABLE_NUMBER since 3.32.0, this is also
+		// used for unknown dialects since it is the lowest of the limits.
+		return 32766
+	}
+}
+
+// returning reports whether the dialect supports the RETURNING clause on
+// INSERT statements.
+func (d Dialect) returning() bool {
+	return d == Postgres || d == SQLite
+}
+
+// placeholder returns the style of the placeholders the dialect accepts for the
+// arguments of a query. MySQL only accepts ? placeholders, whereas PostgreSQL
+// and SQLite accept the default $N placeholders.
+func (d Dialect) placeholder() query.Placeholder {
+	if d == MySQL {
+		return query.Question
+	}
+	return query.Dollar
+}
@@ -0,0 +1,199 @@
+package database
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// encryptedOption marks a struct field as being stored encrypted in its
+// column.
+const encryptedOption = "encrypted"
+
+// Key is an [cipher.AEAD], such as AES-GCM, used for encrypting columns, along
+// with the ID used to identify it in the values it encrypts.
+type Key struct {
+	ID   string
+	AEAD cipher.AEAD
+}
+
+// Keyring holds the keys used for encrypting and decrypting the columns of
+// struct fields with the "encrypted" option, for example,
+//
+//	type User struct {
+//	    ID    int64
+//	    Email string `db:"email,encrypted"`
+//	}
+//
+// Values are encrypted with the primary key of the Keyring when written, and
+// are stored as text prefixed with the ID of the key, so they can be
+// decrypted by whichever key encrypted them. This allows keys to be rotated by
+// making the new key primary, and keeping the old keys for decryption. Values
+// encrypted with an old key are encrypted with the primary key when they are
+// next written.
+//
+// Each value is encrypted with a random nonce, so encrypted columns cannot be
+// compared in a WHERE clause. A Keyring is safe for concurrent use.
+type Keyring struct {
+	primary Key
+	keys    map[string]cipher.AEAD
+}
+
+// ErrUnknownKey is the error returned when decrypting a value that was
+// encrypted by a key that is not in the [Keyring].
+var ErrUnknownKey = errors.New("unknown key")
+
+// NewKeyring returns a [Keyring] that encrypts with the given primary key, and
+// decrypts with the primary key and any of the given old keys, for example,
+//
+//	block, err := aes.NewCipher(secret)
+//
+//	if err != nil {
+//	    // Handle error.
+//	}
+//
+//	gcm, err := cipher.NewGCM(block)
+//
+//	if err != nil {
+//	    // Handle error.
+//	}
+//
+//	kr := database.NewKeyring(database.Key{ID: "2024-01", AEAD: gcm})
+//
+// This panics if a key ID is empty, contains a colon, or is given more than
+// once.
+func NewKeyring(primary Key, old ...Key) *Keyring {
+	kr := &Keyring{
+		primary: primary,
+		keys:    make(map[string]cipher.AEAD),
+	}
+
+	for _, k := range append([]Key{primary}, old...) {
+		if k.ID == "" || strings.Contains(k.ID, ":") {
+			panic("database: invalid key ID " + k.ID)
+		}
+
+		if _, ok := kr.keys[k.ID]; ok {
+			panic("database: duplicate key ID " + k.ID)
+		}
+		kr.keys[k.ID] = k.AEAD
+	}
+	return kr
+}
+
+// WithKeyring configures the [Keyring] a [Store] uses for encrypting and
+// decrypting the columns of struct fields with the "encrypted" option.
+func WithKeyring(kr *Keyring) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.keyring = kr
+	}
+}
+
+// UseKeyring configures the [Keyring] a [Scanner] uses for decrypting the
+// columns of struct fields with the "encrypted" option.
+func UseKeyring(kr *Keyring) ScannerOption {
+	return func(sc *Scanner) {
+		sc.keyring = kr
+	}
+}
+
+var errNoKeyring = errors.New("no keyring configured for encrypted column")
+
+// plaintext returns the bytes of the given value to encrypt. The value is
+// first converted to a [driver.Value], so pointers and [driver.Valuer]
+// implementations are handled as they would be by the driver.
+func plaintext(v any) ([]byte, error) {
+	val, err := driver.DefaultParameterConverter.ConvertValue(v)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot encrypt value of type %T: %w", v, err)
+	}
+
+	switch val := val.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return val, nil
+	case string:
+		return []byte(val), nil
+	case time.Time:
+		return []byte(val.Format(time.RFC3339Nano)), nil
+	}
+	return []byte(fmt.Sprint(val)), nil
+}
+
+// encrypt encrypts the given value with the primary key. NULL values are
+// returned as is.
+func (kr *Keyring) encrypt(v any) (any, error) {
+	if kr == nil {
+		return nil, errNoKeyring
+	}
+
+	b, err := plaintext(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if b == nil {
+		return nil, nil
+	}
+
+	aead := kr.primary.AEAD
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(b)+aead.Overhead())
+	rand.Read(nonce)
+
+	sealed := aead.Seal(nonce, nonce, b, nil)
+
+	return kr.primary.ID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt decrypts the given value with the key that encrypted it.
+func (kr *Keyring) decrypt(src any) ([]byte, error) {
+	if kr == nil {
+		return nil, errNoKeyring
+	}
+
+	var s string
+
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return nil, fmt.Errorf("cannot decrypt value of type %T", src)
+	}
+
+	id, enc, ok := strings.Cut(s, ":")
+
+	if !ok {
+		return nil, errors.New("malformed encrypted value")
+	}
+
+	aead, ok := kr.keys[id]
+
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownKey, id)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(enc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("malformed encrypted value")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
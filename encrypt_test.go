@@ -0,0 +1,241 @@
+package database
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andrewpillar/database/query"
+)
+
+const secretSchema = `CREATE TABLE IF NOT EXISTS secrets (
+	id    INTEGER NOT NULL,
+	email TEXT NULL,
+	age   TEXT NOT NULL,
+	ssn   TEXT NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+type Secret struct {
+	ID    int64
+	Email *string `db:"email,encrypted"`
+	Age   int     `db:"age,encrypted"`
+	SSN   string  `db:",encrypted"`
+}
+
+func (s *Secret) Table() string { return "secrets" }
+
+func (s *Secret) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{s.ID},
+	}
+}
+
+func (s *Secret) Params() Params {
+	return Params{
+		"id":    CreateOnlyParam(s.ID),
+		"email": MutableParam(s.Email),
+		"age":   MutableParam(s.Age),
+		"ssn":   MutableParam(s.SSN),
+	}
+}
+
+func NewKey(t *testing.T, id string, secret string) Key {
+	t.Helper()
+
+	block, err := aes.NewCipher([]byte(secret))
+
+	if err != nil {
+		t.Fatalf("aes.NewCipher(%q): %v\n", secret, err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+
+	if err != nil {
+		t.Fatalf("cipher.NewGCM(block): %v\n", err)
+	}
+
+	return Key{
+		ID:   id,
+		AEAD: gcm,
+	}
+}
+
+func TestEncryptedColumns(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, secretSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", secretSchema, err)
+	}
+
+	k1 := NewKey(t, "k1", "0123456789abcdef")
+	k2 := NewKey(t, "k2", "fedcba9876543210")
+
+	newSecret := func() *Secret {
+		return &Secret{}
+	}
+
+	secrets := NewStore(db, newSecret, WithKeyring(NewKeyring(k1)))
+
+	email := "me@example.com"
+
+	ss := []*Secret{
+		{ID: 1, Email: &email, Age: 42, SSN: "078-05-1120"},
+		{ID: 2, Age: 7},
+	}
+
+	if err := secrets.Create(ctx, ss...); err != nil {
+		t.Fatalf("secrets.Create(ctx, ss...): %v\n", err)
+	}
+
+	// The model is left as plaintext.
+	if *ss[0].Email != email {
+		t.Fatalf("ss[0].Email = %q, want = %q\n", *ss[0].Email, email)
+	}
+
+	rawColumn := func(col string, id int64) string {
+		var s *string
+
+		q := "SELECT " + col + " FROM secrets WHERE id = $1"
+
+		if err := db.QueryRowContext(ctx, q, id).Scan(&s); err != nil {
+			t.Fatalf("db.QueryRowContext(ctx, ...).Scan(&s): %v\n", err)
+		}
+
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+
+	rawEmail := func(id int64) string {
+		return rawColumn("email", id)
+	}
+
+	if raw := rawEmail(1); !strings.HasPrefix(raw, "k1:") || strings.Contains(raw, email) {
+		t.Fatalf("email = %q, want encrypted with k1\n", raw)
+	}
+
+	if raw := rawEmail(2); raw != "" {
+		t.Fatalf("email = %q, want NULL\n", raw)
+	}
+
+	// Fields tagged without a name are mapped to their column as when
+	// scanned.
+	if raw := rawColumn("ssn", 1); !strings.HasPrefix(raw, "k1:") || strings.Contains(raw, ss[0].SSN) {
+		t.Fatalf("ssn = %q, want encrypted with k1\n", raw)
+	}
+
+	get := func(t *testing.T, s *Store[*Secret], id int64) (*Secret, error) {
+		t.Helper()
+
+		m, ok, err := s.Get(ctx, query.WhereEq("id", query.Arg(id)))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			t.Fatalf("s.Get(ctx, ...): expected secret %v, got none\n", id)
+		}
+		return m, nil
+	}
+
+	t.Run("decrypt", func(t *testing.T) {
+		s, err := get(t, secrets, 1)
+
+		if err != nil {
+			t.Fatalf("get(t, secrets, 1): %v\n", err)
+		}
+
+		if s.Email == nil || *s.Email != email {
+			t.Fatalf("s.Email = %v, want = %q\n", s.Email, email)
+		}
+
+		if s.Age != 42 {
+			t.Fatalf("s.Age = %v, want = %v\n", s.Age, 42)
+		}
+
+		if s.SSN != "078-05-1120" {
+			t.Fatalf("s.SSN = %q, want = %q\n", s.SSN, "078-05-1120")
+		}
+
+		s, err = get(t, secrets, 2)
+
+		if err != nil {
+			t.Fatalf("get(t, secrets, 2): %v\n", err)
+		}
+
+		if s.Email != nil {
+			t.Fatalf("s.Email = %v, want = %v\n", s.Email, nil)
+		}
+	})
+
+	t.Run("update-many", func(t *testing.T) {
+		fields := map[string]any{
+			"email": "you@example.com",
+			"ssn":   "219-09-9999",
+		}
+
+		if _, err := secrets.UpdateMany(ctx, fields, query.WhereEq("id", query.Arg(2))); err != nil {
+			t.Fatalf("secrets.UpdateMany(ctx, fields, ...): %v\n", err)
+		}
+
+		for col, val := range fields {
+			if raw := rawColumn(col, 2); !strings.HasPrefix(raw, "k1:") || strings.Contains(raw, val.(string)) {
+				t.Fatalf("%s = %q, want encrypted with k1\n", col, raw)
+			}
+		}
+
+		s, err := get(t, secrets, 2)
+
+		if err != nil {
+			t.Fatalf("get(t, secrets, 2): %v\n", err)
+		}
+
+		if s.Email == nil || *s.Email != "you@example.com" {
+			t.Fatalf("s.Email = %v, want = %q\n", s.Email, "you@example.com")
+		}
+
+		if s.SSN != "219-09-9999" {
+			t.Fatalf("s.SSN = %q, want = %q\n", s.SSN, "219-09-9999")
+		}
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		rotated := NewStore(db, newSecret, WithKeyring(NewKeyring(k2, k1)))
+
+		s, err := get(t, rotated, 1)
+
+		if err != nil {
+			t.Fatalf("get(t, rotated, 1): %v\n", err)
+		}
+
+		if _, err := rotated.Update(ctx, s); err != nil {
+			t.Fatalf("rotated.Update(ctx, s): %v\n", err)
+		}
+
+		if raw := rawEmail(1); !strings.HasPrefix(raw, "k2:") {
+			t.Fatalf("email = %q, want encrypted with k2\n", raw)
+		}
+
+		// Keys removed from the keyring can no longer decrypt.
+		old := NewStore(db, newSecret, WithKeyring(NewKeyring(k1)))
+
+		if _, err := get(t, old, 1); !errors.Is(err, ErrUnknownKey) {
+			t.Fatalf("get(t, old, 1): err = %v, want = %v\n", err, ErrUnknownKey)
+		}
+	})
+
+	t.Run("no-keyring", func(t *testing.T) {
+		plain := NewStore(db, newSecret)
+
+		if err := plain.Create(ctx, &Secret{ID: 3}); err == nil {
+			t.Fatal("expected error creating encrypted column without keyring, got nil")
+		}
+	})
+}
@@ -0,0 +1,251 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// ExportFormat is the format rows are written in by [Store.Export].
+type ExportFormat uint
+
+const (
+	// CSV writes the rows as comma separated values, with the first record
+	// being the names of the columns.
+	CSV ExportFormat = iota + 1
+
+	// NDJSON writes each row as a JSON object on its own line, keyed by the
+	// names of the columns.
+	NDJSON
+)
+
+// exporter writes the rows of an export in a given [ExportFormat].
+type exporter interface {
+	header(cols []string) error
+
+	row(vals []any) error
+
+	flush() error
+}
+
+type csvExporter struct {
+	w   *csv.Writer
+	rec []string
+}
+
+func (e *csvExporter) header(cols []string) error {
+	e.rec = make([]string, len(cols))
+	return e.w.Write(cols)
+}
+
+func (e *csvExporter) row(vals []any) error {
+	for i, v := range vals {
+		e.rec[i] = csvValue(v)
+	}
+	return e.w.Write(e.rec)
+}
+
+func (e *csvExporter) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// csvValue formats the given value as a CSV field. NULL is written as an
+// empty field, and binary data is base64 encoded.
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+
+type ndjsonExporter struct {
+	w    *bufio.Writer
+	keys [][]byte
+}
+
+func (e *ndjsonExporter) header(cols []string) error {
+	e.keys = make([][]byte, len(cols))
+
+	for i, col := range cols {
+		b, err := json.Marshal(col)
+
+		if err != nil {
+			return err
+		}
+		e.keys[i] = b
+	}
+	return nil
+}
+
+// row writes the row as a JSON object with its keys in the order of the
+// columns, which encoding a map would not preserve.
+func (e *ndjsonExporter) row(vals []any) error {
+	e.w.WriteByte('{')
+
+	for i, v := range vals {
+		if i > 0 {
+			e.w.WriteByte(',')
+		}
+
+		b, err := json.Marshal(v)
+
+		if err != nil {
+			return err
+		}
+
+		e.w.Write(e.keys[i])
+		e.w.WriteByte(':')
+		e.w.Write(b)
+	}
+
+	e.w.WriteByte('}')
+	_, err := e.w.Write([]byte{'\n'})
+	return err
+}
+
+func (e *ndjsonExporter) flush() error {
+	return e.w.Flush()
+}
+
+// Export writes the models that match the given query options to the given
+// writer in the given format, for data dumps and download endpoints. The rows
+// are streamed as they are read from the database, so memory use does not
+// grow with the number of rows exported, for example,
+//
+//	w.Header().Set("Content-Type", "text/csv")
+//
+//	err := posts.Export(ctx, w, database.CSV, query.WhereEq("user_id", query.Arg(u.ID)))
+//
+// Every column of the store's table is exported, as they are stored in the
+// database. The values of columns that map to a field with the "sensitive"
+// option are written as [Redacted], and binary data is written as base64.
+// Exports bypass the store's cache, and do not preload any relations.
+func (s *Store[M]) Export(ctx context.Context, w io.Writer, format ExportFormat, opts ...query.Option) error {
+	var e exporter
+
+	switch format {
+	case CSV:
+		e = &csvExporter{w: csv.NewWriter(w)}
+	case NDJSON:
+		e = &ndjsonExporter{w: bufio.NewWriter(w)}
+	default:
+		return fmt.Errorf("unsupported export format %d", format)
+	}
+
+	q := s.selectQuery(ctx, query.Columns("*"), opts...)
+
+	rows, err := s.query(ctx, s.table, OpSelect, q)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+
+	if err != nil {
+		return err
+	}
+
+	types, err := rows.ColumnTypes()
+
+	if err != nil {
+		return err
+	}
+
+	sensitive, err := s.sensitiveColumns(cols)
+
+	if err != nil {
+		return err
+	}
+
+	if err := e.header(cols); err != nil {
+		return err
+	}
+
+	// The destination of each column is reused for every row, so that memory
+	// use stays constant.
+	raw := make([]any, len(cols))
+	dest := make([]any, len(cols))
+	vals := make([]any, len(cols))
+
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+
+		for i, v := range raw {
+			if sensitive[i] {
+				vals[i] = Redacted
+				continue
+			}
+			vals[i] = normalizeValue(types[i], v)
+		}
+
+		if err := e.row(vals); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return e.flush()
+}
+
+// sensitiveColumns reports whether each of the given columns maps to a field
+// of the store's model with the "sensitive" option.
+func (s *Store[M]) sensitiveColumns(cols []string) ([]bool, error) {
+	rt := reflect.TypeOf(s.new())
+
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+
+	sensitive := make([]bool, len(cols))
+
+	if rt.Kind() != reflect.Struct {
+		return sensitive, nil
+	}
+
+	fields, err := typeFields(rt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, col := range cols {
+		if fld, ok := fields.get(col); ok {
+			sensitive[i] = fld.sensitive
+		}
+	}
+	return sensitive, nil
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestStoreExport(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, credentialSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", credentialSchema, err)
+	}
+
+	store := NewStore[*Credential](db, func() *Credential {
+		return &Credential{}
+	})
+
+	cc := []*Credential{
+		{ID: 1, Email: "me@example.com", Password: "hunter2", Pin: 1234},
+		{ID: 2, Email: "\"quoted\", you@example.com", Password: "secret", Pin: 4321},
+	}
+
+	if err := store.Create(ctx, cc...); err != nil {
+		t.Fatalf("store.Create(ctx, cc...): %v\n", err)
+	}
+
+	tests := []struct {
+		format ExportFormat
+		opts   []query.Option
+		want   string
+	}{
+		{
+			CSV,
+			[]query.Option{query.OrderAsc("id")},
+			"id,email,password,pin\n" +
+				"1,me@example.com,[REDACTED],[REDACTED]\n" +
+				"2,\"\"\"quoted\"\", you@example.com\",[REDACTED],[REDACTED]\n",
+		},
+		{
+			NDJSON,
+			[]query.Option{query.OrderAsc("id")},
+			`{"id":1,"email":"me@example.com","password":"[REDACTED]","pin":"[REDACTED]"}` + "\n" +
+				`{"id":2,"email":"\"quoted\", you@example.com","password":"[REDACTED]","pin":"[REDACTED]"}` + "\n",
+		},
+		{
+			CSV,
+			[]query.Option{query.WhereEq("id", query.Arg(10))},
+			"id,email,password,pin\n",
+		},
+		{
+			NDJSON,
+			[]query.Option{query.WhereEq("id", query.Arg(10))},
+			"",
+		},
+	}
+
+	for i, test := range tests {
+		var buf bytes.Buffer
+
+		if err := store.Export(ctx, &buf, test.format, test.opts...); err != nil {
+			t.Fatalf("tests[%d] - store.Export(ctx, &buf, %v, ...): %v\n", i, test.format, err)
+		}
+
+		if s := buf.String(); s != test.want {
+			t.Errorf("tests[%d] - buf.String() = %q, want = %q\n", i, s, test.want)
+		}
+	}
+
+	if err := store.Export(ctx, &bytes.Buffer{}, 0); err == nil {
+		t.Fatal("expected error for unsupported export format, got nil")
+	}
+}
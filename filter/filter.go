@@ -0,0 +1,262 @@
+// Package filter converts the parameters of a URL query into [query.Option]
+// values, so that list endpoints can offer filtering driven by the user.
+// Only the fields, and the operators on those fields, that are given to a
+// [Filter] are converted. Values are always passed as arguments, and never
+// built into the query, for example,
+//
+//	f := filter.New(
+//	    filter.Field("status", filter.Eq, filter.In),
+//	    filter.Field("created_at", filter.Gt, filter.Lt),
+//	)
+//
+//	opts, err := f.Parse(r.URL.Query())
+//
+//	if err != nil {
+//	    // Handle error, typically with a 400.
+//	}
+//
+//	pp, err := posts.Select(ctx, query.Columns("*"), opts...)
+//
+// A parameter of the form field=value filters on equality, and a parameter of
+// the form field[op]=value filters via the given operator, for example,
+//
+//	?status=open&created_at[gt]=2020-01-01&user_id[in]=1,2,3
+//
+// Parameters that do not name a field of the filter are ignored, so that
+// other parameters, such as those for pagination, can be given alongside.
+// Sorting is parsed separately via [ParseSort].
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// Op is an operator that a field can be filtered by.
+type Op string
+
+const (
+	Eq    Op = "eq"   // =
+	NotEq Op = "ne"   // !=
+	Gt    Op = "gt"   // >
+	Geq   Op = "gte"  // >=
+	Lt    Op = "lt"   // <
+	Leq   Op = "lte"  // <=
+	Like  Op = "like" // LIKE, matching values that contain the value literally
+	In    Op = "in"   // IN, with the values separated by commas
+	IsNil Op = "null" // IS NULL if true, otherwise IS NOT NULL
+)
+
+// option returns the query option for filtering the given column by the
+// operator with the given value.
+func (op Op) option(col, val string) (query.Option, error) {
+	switch op {
+	case Eq:
+		return query.WhereEq(col, query.Arg(val)), nil
+	case NotEq:
+		return query.WhereNotEq(col, query.Arg(val)), nil
+	case Gt:
+		return query.WhereGt(col, query.Arg(val)), nil
+	case Geq:
+		return query.WhereGeq(col, query.Arg(val)), nil
+	case Lt:
+		return query.WhereLt(col, query.Arg(val)), nil
+	case Leq:
+		return query.WhereLeq(col, query.Arg(val)), nil
+	case Like:
+		// Wildcards in the value are escaped, so a user cannot give a pattern
+		// that scans more than the value they searched for.
+		return query.WhereLike(col, query.Arg("%"+query.EscapeLike(val)+"%")), nil
+	case In:
+		parts := strings.Split(val, ",")
+		vals := make([]any, 0, len(parts))
+
+		for _, part := range parts {
+			vals = append(vals, part)
+		}
+		return query.WhereIn(col, query.List(vals...)), nil
+	case IsNil:
+		switch val {
+		case "true", "1", "":
+			return query.WhereIsNil(col), nil
+		case "false", "0":
+			return query.WhereIsNotNil(col), nil
+		}
+		return nil, fmt.Errorf("invalid boolean %q", val)
+	}
+	return nil, fmt.Errorf("unknown operator %q", op)
+}
+
+// ErrOp is the error returned when a field is filtered by an operator that is
+// not allowed for it.
+var ErrOp = errors.New("operator not allowed")
+
+// Error records the parameter that could not be converted into a query
+// option.
+type Error struct {
+	Param string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("filter %s: %s", e.Param, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+type field struct {
+	col string
+	ops []Op
+}
+
+// Filter is the set of fields, and the operators on those fields, that the
+// parameters of a URL query can filter by.
+type Filter struct {
+	fields map[string]field
+}
+
+// Option configures a [Filter].
+type Option func(*Filter)
+
+// Field allows the given column to be filtered by the given operators. The
+// column is also the name of the parameter. If no operators are given, then
+// the column can only be filtered via [Eq].
+func Field(col string, ops ...Op) Option {
+	return FieldAs(col, col, ops...)
+}
+
+// FieldAs is like [Field], only the parameter is given the name, rather than
+// the column. This allows for columns to be given a different name in the
+// URL, for example FieldAs("author", "posts.user_id").
+func FieldAs(name, col string, ops ...Op) Option {
+	if len(ops) == 0 {
+		ops = []Op{Eq}
+	}
+
+	return func(f *Filter) {
+		f.fields[name] = field{
+			col: col,
+			ops: ops,
+		}
+	}
+}
+
+// New returns a new [Filter] with the given fields.
+func New(opts ...Option) *Filter {
+	f := &Filter{
+		fields: make(map[string]field),
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// parseParam parses the given parameter into its name and operator. If the
+// parameter has no operator, then [Eq] is returned.
+func parseParam(param string) (string, Op, bool) {
+	name, rest, ok := strings.Cut(param, "[")
+
+	if !ok {
+		return param, Eq, true
+	}
+
+	op, ok := strings.CutSuffix(rest, "]")
+
+	if !ok {
+		return "", "", false
+	}
+	return name, Op(op), true
+}
+
+// Parse converts the given parameters into query options. The options are
+// returned in the order of the sorted parameters, so the query they build is
+// stable. An [Error] is returned if a parameter filters a field by an
+// operator that is not allowed, or if its value is invalid for the operator.
+func (f *Filter) Parse(vals url.Values) ([]query.Option, error) {
+	opts := make([]query.Option, 0, len(vals))
+
+	for _, param := range slices.Sorted(maps.Keys(vals)) {
+		name, op, ok := parseParam(param)
+
+		if !ok {
+			continue
+		}
+
+		fld, ok := f.fields[name]
+
+		if !ok {
+			continue
+		}
+
+		if !slices.Contains(fld.ops, op) {
+			return nil, &Error{
+				Param: param,
+				Err:   fmt.Errorf("%w: %s", ErrOp, op),
+			}
+		}
+
+		for _, val := range vals[param] {
+			opt, err := op.option(fld.col, val)
+
+			if err != nil {
+				return nil, &Error{
+					Param: param,
+					Err:   err,
+				}
+			}
+			opts = append(opts, opt)
+		}
+	}
+	return opts, nil
+}
+
+// ErrSort is the error returned when sorting by a column that is not allowed.
+var ErrSort = errors.New("sort not allowed")
+
+// ParseSort parses the given comma separated list of columns into ORDER BY
+// options. A column prefixed with - is sorted in descending order, otherwise
+// ascending. Only the given columns can be sorted by, an [Error] is returned
+// for any other column, for example,
+//
+//	// ?sort=-created_at,title
+//	opts, err := filter.ParseSort(r.URL.Query().Get("sort"), "created_at", "title")
+//
+// An empty string returns no options.
+func ParseSort(s string, cols ...string) ([]query.Option, error) {
+	opts := make([]query.Option, 0)
+
+	for col := range strings.SplitSeq(s, ",") {
+		col = strings.TrimSpace(col)
+
+		if col == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(col, "-")
+		col = strings.TrimPrefix(col, "-")
+
+		if !slices.Contains(cols, col) {
+			return nil, &Error{
+				Param: "sort",
+				Err:   fmt.Errorf("%w: %s", ErrSort, col),
+			}
+		}
+
+		if desc {
+			opts = append(opts, query.OrderDesc(col))
+			continue
+		}
+		opts = append(opts, query.OrderAsc(col))
+	}
+	return opts, nil
+}
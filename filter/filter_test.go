@@ -0,0 +1,134 @@
+package filter
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestParse(t *testing.T) {
+	f := New(
+		Field("status", Eq, In),
+		Field("title", Like),
+		Field("created_at", Gt, Lt),
+		Field("deleted_at", IsNil),
+		FieldAs("author", "posts.user_id"),
+	)
+
+	tests := []struct {
+		params string
+		want   string
+		args   []any
+	}{
+		{"", "SELECT * FROM posts", nil},
+		{"page=2&sort=title", "SELECT * FROM posts", nil},
+		{"status=open", "SELECT * FROM posts WHERE (status = $1)", []any{"open"}},
+		{"status[in]=open,closed", "SELECT * FROM posts WHERE (status IN ($1, $2))", []any{"open", "closed"}},
+		{
+			"created_at[lt]=2021-01-01&created_at[gt]=2020-01-01",
+			"SELECT * FROM posts WHERE (created_at > $1 AND created_at < $2)",
+			[]any{"2020-01-01", "2021-01-01"},
+		},
+		{"deleted_at[null]=true", "SELECT * FROM posts WHERE (deleted_at IS NULL)", nil},
+		{"deleted_at[null]=false", "SELECT * FROM posts WHERE (deleted_at IS NOT NULL)", nil},
+		{"author=1", "SELECT * FROM posts WHERE (posts.user_id = $1)", []any{"1"}},
+		{"title[like]=go", "SELECT * FROM posts WHERE (title LIKE $1)", []any{"%go%"}},
+		{"title[like]=50%25_off", "SELECT * FROM posts WHERE (title LIKE $1)", []any{`%50\%\_off%`}},
+		{"status=1%3BDROP+TABLE+posts", "SELECT * FROM posts WHERE (status = $1)", []any{"1;DROP TABLE posts"}},
+	}
+
+	for i, test := range tests {
+		vals, err := url.ParseQuery(test.params)
+
+		if err != nil {
+			t.Fatalf("tests[%d] - url.ParseQuery(%q): %v\n", i, test.params, err)
+		}
+
+		opts, err := f.Parse(vals)
+
+		if err != nil {
+			t.Fatalf("tests[%d] - f.Parse(%q): %v\n", i, test.params, err)
+		}
+
+		q := query.Select(query.Columns("*"), append([]query.Option{query.From("posts")}, opts...)...)
+
+		if built := q.Build(); built != test.want {
+			t.Fatalf("tests[%d] - q.Build() = %q, want = %q\n", i, built, test.want)
+		}
+
+		if args := q.Args(); !slices.Equal(args, test.args) {
+			t.Fatalf("tests[%d] - q.Args() = %v, want = %v\n", i, args, test.args)
+		}
+	}
+}
+
+func TestParseErr(t *testing.T) {
+	f := New(
+		Field("status"),
+		Field("deleted_at", IsNil),
+	)
+
+	tests := []struct {
+		params string
+		err    error
+	}{
+		{"status[like]=%25open%25", ErrOp},
+		{"status[gt]=open", ErrOp},
+		{"deleted_at[null]=maybe", nil},
+	}
+
+	for i, test := range tests {
+		vals, err := url.ParseQuery(test.params)
+
+		if err != nil {
+			t.Fatalf("tests[%d] - url.ParseQuery(%q): %v\n", i, test.params, err)
+		}
+
+		_, err = f.Parse(vals)
+
+		var ferr *Error
+
+		if !errors.As(err, &ferr) {
+			t.Fatalf("tests[%d] - f.Parse(%q) = %v, want = %T\n", i, test.params, err, ferr)
+		}
+
+		if test.err != nil && !errors.Is(err, test.err) {
+			t.Fatalf("tests[%d] - f.Parse(%q) = %v, want = %v\n", i, test.params, err, test.err)
+		}
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		sort string
+		want string
+	}{
+		{"", "SELECT * FROM posts"},
+		{"title", "SELECT * FROM posts ORDER BY title ASC"},
+		{"-created_at,title", "SELECT * FROM posts ORDER BY created_at DESC, title ASC"},
+		{" -created_at, ,", "SELECT * FROM posts ORDER BY created_at DESC"},
+	}
+
+	for i, test := range tests {
+		opts, err := ParseSort(test.sort, "created_at", "title")
+
+		if err != nil {
+			t.Fatalf("tests[%d] - ParseSort(%q): %v\n", i, test.sort, err)
+		}
+
+		q := query.Select(query.Columns("*"), append([]query.Option{query.From("posts")}, opts...)...)
+
+		if built := q.Build(); built != test.want {
+			t.Fatalf("tests[%d] - q.Build() = %q, want = %q\n", i, built, test.want)
+		}
+	}
+
+	for _, sort := range []string{"password", "-title,(SELECT 1)"} {
+		if _, err := ParseSort(sort, "created_at", "title"); !errors.Is(err, ErrSort) {
+			t.Fatalf("ParseSort(%q) = %v, want = %v\n", sort, err, ErrSort)
+		}
+	}
+}
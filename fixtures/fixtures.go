@@ -0,0 +1,402 @@
+// Package fixtures loads test fixtures from JSON files into a database. Each
+// file is a JSON object mapping table names to the rows of that table. Rows
+// can be given as an array, or as an object of named rows, so that other rows
+// can reference them, for example,
+//
+//	{
+//	    "users": {
+//	        "alice": {"id": 1, "email": "alice@example.com"},
+//	        "bob":   {"id": 2, "email": "bob@example.com"}
+//	    },
+//	    "posts": [
+//	        {"id": 1, "user_id": "$users.alice.id", "title": "Hello"}
+//	    ]
+//	}
+//
+// A string value of the form $table.name.column is a reference, and is
+// replaced with the value of the column of the named row. The column must be
+// given in the referenced row, since it is resolved before anything is
+// inserted. A string that should start with a literal $ is escaped as $$.
+//
+// Rows are inserted as they are given, rather than via a [database.Store], so
+// fixtures can be given for any table, such as a pivot table without a Model.
+// Tables are inserted in the order they first appear in the files, so tables
+// should come after the tables they reference. Fixtures are reset between
+// tests via [Fixtures.Reset], which deletes the rows of every table in
+// reverse order, before inserting them again,
+//
+//	//go:embed testdata/fixtures.json
+//	var fixturesFS embed.FS
+//
+//	func TestPosts(t *testing.T) {
+//	    f, err := fixtures.Load(fixturesFS, "testdata/*.json")
+//
+//	    if err != nil {
+//	        t.Fatal(err)
+//	    }
+//
+//	    if err := f.Reset(t.Context(), db); err != nil {
+//	        t.Fatal(err)
+//	    }
+//	}
+package fixtures
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/andrewpillar/database"
+	"github.com/andrewpillar/database/query"
+)
+
+// Fixtures are the rows loaded from fixture files via [Load].
+type Fixtures struct {
+	// tables are the names of the tables in the order they first appeared.
+	tables []string
+	rows   map[string][]*row
+
+	// named are the named rows of each table.
+	named map[string]map[string]*row
+}
+
+type row struct {
+	name string
+
+	// cols are the values of the row as they were decoded, and vals are the
+	// values once resolved.
+	cols map[string]any
+	vals map[string]any
+}
+
+// Load loads the fixtures from the files of the given filesystem that match
+// any of the given patterns, as per [fs.Glob]. The files matched by each
+// pattern are loaded in lexical order.
+func Load(fsys fs.FS, patterns ...string) (*Fixtures, error) {
+	f := &Fixtures{
+		rows:  make(map[string][]*row),
+		named: make(map[string]map[string]*row),
+	}
+
+	for _, pattern := range patterns {
+		names, err := fs.Glob(fsys, pattern)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			b, err := fs.ReadFile(fsys, name)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if err := f.parse(b); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+
+	if err := f.resolve(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// delim reads the next token from the given decoder, and checks it is the
+// given delimiter.
+func delim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+
+	if err != nil {
+		return err
+	}
+
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %s, got %v", want, tok)
+	}
+	return nil
+}
+
+// parse parses the given fixture file. The file is decoded token by token, so
+// that the order of the tables and named rows is preserved.
+func (f *Fixtures) parse(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	if err := delim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+
+		if err != nil {
+			return err
+		}
+
+		table := tok.(string)
+
+		if _, ok := f.rows[table]; !ok {
+			f.tables = append(f.tables, table)
+			f.rows[table] = nil
+		}
+
+		tok, err = dec.Token()
+
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case json.Delim('['):
+			for dec.More() {
+				var cols map[string]any
+
+				if err := dec.Decode(&cols); err != nil {
+					return fmt.Errorf("%s: %w", table, err)
+				}
+				f.rows[table] = append(f.rows[table], &row{cols: cols})
+			}
+
+			if err := delim(dec, ']'); err != nil {
+				return err
+			}
+		case json.Delim('{'):
+			for dec.More() {
+				tok, err := dec.Token()
+
+				if err != nil {
+					return err
+				}
+
+				name := tok.(string)
+
+				var cols map[string]any
+
+				if err := dec.Decode(&cols); err != nil {
+					return fmt.Errorf("%s.%s: %w", table, name, err)
+				}
+
+				if f.named[table] == nil {
+					f.named[table] = make(map[string]*row)
+				}
+
+				if _, ok := f.named[table][name]; ok {
+					return fmt.Errorf("%s.%s: duplicate fixture", table, name)
+				}
+
+				r := &row{
+					name: name,
+					cols: cols,
+				}
+
+				f.rows[table] = append(f.rows[table], r)
+				f.named[table][name] = r
+			}
+
+			if err := delim(dec, '}'); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s: expected array or object of rows, got %v", table, tok)
+		}
+	}
+	return delim(dec, '}')
+}
+
+// value converts the given decoded JSON value into a value that can be given
+// to a driver. Numbers are converted to an int64 if they are integers,
+// otherwise a float64. Arrays and objects are encoded back into JSON.
+func value(v any) (any, error) {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case []any, map[string]any:
+		b, err := json.Marshal(v)
+
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	}
+	return v, nil
+}
+
+// maxDepth is the maximum depth of references to references, this guards
+// against cyclic references.
+const maxDepth = 16
+
+// resolve resolves the values of each row, replacing references with the
+// values they reference.
+func (f *Fixtures) resolve() error {
+	for _, table := range f.tables {
+		for _, r := range f.rows[table] {
+			r.vals = make(map[string]any, len(r.cols))
+
+			for col, v := range r.cols {
+				val, err := f.resolveValue(v, 0)
+
+				if err != nil {
+					return fmt.Errorf("%s.%s: %w", table, col, err)
+				}
+				r.vals[col] = val
+			}
+		}
+	}
+	return nil
+}
+
+func (f *Fixtures) resolveValue(v any, depth int) (any, error) {
+	s, ok := v.(string)
+
+	if !ok || !strings.HasPrefix(s, "$") {
+		return value(v)
+	}
+
+	if strings.HasPrefix(s, "$$") {
+		return s[1:], nil
+	}
+
+	if depth >= maxDepth {
+		return nil, fmt.Errorf("reference %q is too deep, or cyclic", s)
+	}
+
+	parts := strings.Split(s[1:], ".")
+
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid reference %q, expected $table.name.column", s)
+	}
+
+	r, ok := f.named[parts[0]][parts[1]]
+
+	if !ok {
+		return nil, fmt.Errorf("unknown fixture %s.%s", parts[0], parts[1])
+	}
+
+	ref, ok := r.cols[parts[2]]
+
+	if !ok {
+		return nil, fmt.Errorf("fixture %s.%s has no column %s", parts[0], parts[1], parts[2])
+	}
+	return f.resolveValue(ref, depth+1)
+}
+
+// Tables returns the names of the tables of the fixtures, in the order they
+// are inserted.
+func (f *Fixtures) Tables() []string {
+	return slices.Clone(f.tables)
+}
+
+// Get returns the value of the given column of the named row of the given
+// table, and whether it was found. This is useful for getting the primary key
+// of a fixture within a test.
+func (f *Fixtures) Get(table, name, col string) (any, bool) {
+	r, ok := f.named[table][name]
+
+	if !ok {
+		return nil, false
+	}
+
+	v, ok := r.vals[col]
+	return v, ok
+}
+
+// atomic calls fn within a transaction if the given database can begin one,
+// otherwise fn is called with the database as is.
+func atomic(ctx context.Context, db database.DB, fn func(db database.DB) error) error {
+	if _, ok := db.(*sql.Tx); ok {
+		return fn(db)
+	}
+
+	if b, ok := db.(database.Beginner); ok {
+		return database.Tx(ctx, b, func(tx *sql.Tx) error {
+			return fn(tx)
+		})
+	}
+	return fn(db)
+}
+
+// Insert inserts the rows of every table, in the order the tables appeared in
+// the fixture files. The rows are inserted within a single transaction if the
+// given database is able to begin one.
+func (f *Fixtures) Insert(ctx context.Context, db database.DB) error {
+	return atomic(ctx, db, func(db database.DB) error {
+		return f.insert(ctx, db)
+	})
+}
+
+func (f *Fixtures) insert(ctx context.Context, db database.DB) error {
+	for _, table := range f.tables {
+		for _, r := range f.rows[table] {
+			cols := make([]string, 0, len(r.vals))
+
+			for col := range r.vals {
+				cols = append(cols, col)
+			}
+
+			slices.Sort(cols)
+
+			vals := make([]any, 0, len(cols))
+
+			for _, col := range cols {
+				vals = append(vals, r.vals[col])
+			}
+
+			q := query.Insert(table, query.Columns(cols...), query.Values(vals...))
+
+			if _, err := q.Exec(ctx, db); err != nil {
+				if r.name != "" {
+					return fmt.Errorf("%s.%s: %w", table, r.name, err)
+				}
+				return fmt.Errorf("%s: %w", table, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Delete deletes every row of each table of the fixtures, in the reverse order
+// the tables are inserted, so that rows are deleted before the rows they
+// reference. Rows that were not inserted from the fixtures are deleted too.
+func (f *Fixtures) Delete(ctx context.Context, db database.DB) error {
+	return atomic(ctx, db, func(db database.DB) error {
+		return f.delete(ctx, db)
+	})
+}
+
+func (f *Fixtures) delete(ctx context.Context, db database.DB) error {
+	var errs []error
+
+	for _, table := range slices.Backward(f.tables) {
+		q := query.Delete(table)
+
+		if _, err := q.Exec(ctx, db); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", table, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Reset deletes every row of each table of the fixtures, and inserts the rows
+// of the fixtures again, within a single transaction if the given database is
+// able to begin one. This would be called at the start of each test that
+// relies on the fixtures.
+func (f *Fixtures) Reset(ctx context.Context, db database.DB) error {
+	return atomic(ctx, db, func(db database.DB) error {
+		if err := f.delete(ctx, db); err != nil {
+			return err
+		}
+		return f.insert(ctx, db)
+	})
+}
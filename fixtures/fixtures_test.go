@@ -0,0 +1,142 @@
+package fixtures
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `CREATE TABLE users (
+	id    INTEGER NOT NULL,
+	email TEXT NOT NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE TABLE posts (
+	id      INTEGER NOT NULL,
+	user_id INTEGER NOT NULL REFERENCES users (id),
+	title   TEXT NOT NULL,
+	tags    TEXT NULL,
+	PRIMARY KEY (id)
+);`
+
+var testFS = fstest.MapFS{
+	"testdata/01_users.json": {
+		Data: []byte(`{
+	"users": {
+		"alice": {"id": 1, "email": "alice@example.com"},
+		"bob":   {"id": 2, "email": "$$bob@example.com"}
+	}
+}`),
+	},
+	"testdata/02_posts.json": {
+		Data: []byte(`{
+	"posts": {
+		"hello": {"id": 10, "user_id": "$users.bob.id", "title": "Hello", "tags": ["go", "sql"]}
+	},
+	"users": [
+		{"id": 3, "email": "carol@example.com"}
+	]
+}`),
+	},
+}
+
+func openDB(t *testing.T) *sql.DB {
+	dsn := filepath.Join(t.TempDir(), "fixtures.sqlite") + "?_pragma=foreign_keys(1)"
+
+	db, err := sql.Open("sqlite", dsn)
+
+	if err != nil {
+		t.Fatalf("sql.Open(%q, %q): %v\n", "sqlite", dsn, err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.ExecContext(t.Context(), schema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, schema): %v\n", err)
+	}
+	return db
+}
+
+func TestFixtures(t *testing.T) {
+	ctx := t.Context()
+	db := openDB(t)
+
+	f, err := Load(testFS, "testdata/*.json")
+
+	if err != nil {
+		t.Fatalf("Load(testFS, %q): %v\n", "testdata/*.json", err)
+	}
+
+	if v, _ := f.Get("posts", "hello", "user_id"); v != int64(2) {
+		t.Fatalf("f.Get(%q, %q, %q) = %v, want = %v\n", "posts", "hello", "user_id", v, 2)
+	}
+
+	if v, _ := f.Get("users", "bob", "email"); v != "$bob@example.com" {
+		t.Fatalf("f.Get(%q, %q, %q) = %v, want = %v\n", "users", "bob", "email", v, "$bob@example.com")
+	}
+
+	// Reset twice, to check that the rows are deleted before being inserted
+	// again.
+	for range 2 {
+		if err := f.Reset(ctx, db); err != nil {
+			t.Fatalf("f.Reset(ctx, db): %v\n", err)
+		}
+	}
+
+	var n int64
+
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&n); err != nil {
+		t.Fatalf("db.QueryRowContext(ctx): %v\n", err)
+	}
+
+	if n != 3 {
+		t.Fatalf("users = %v, want = %v\n", n, 3)
+	}
+
+	var tags string
+
+	if err := db.QueryRowContext(ctx, "SELECT tags FROM posts WHERE id = 10").Scan(&tags); err != nil {
+		t.Fatalf("db.QueryRowContext(ctx): %v\n", err)
+	}
+
+	if tags != `["go","sql"]` {
+		t.Fatalf("tags = %q, want = %q\n", tags, `["go","sql"]`)
+	}
+
+	if err := f.Delete(ctx, db); err != nil {
+		t.Fatalf("f.Delete(ctx, db): %v\n", err)
+	}
+
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&n); err != nil {
+		t.Fatalf("db.QueryRowContext(ctx): %v\n", err)
+	}
+
+	if n != 0 {
+		t.Fatalf("users = %v, want = %v\n", n, 0)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []string{
+		`{"posts": [{"user_id": "$users.alice.id"}]}`,
+		`{"posts": [{"user_id": "$users.alice"}]}`,
+		`{"users": {"alice": {"id": "$users.alice.id"}}}`,
+		`{"users": {"alice": {"id": 1}, "alice": {"id": 2}}}`,
+		`{"users": "alice"}`,
+		`["users"]`,
+	}
+
+	for i, test := range tests {
+		fsys := fstest.MapFS{
+			"fixtures.json": {Data: []byte(test)},
+		}
+
+		if _, err := Load(fsys, "fixtures.json"); err == nil {
+			t.Errorf("tests[%d] - expected error, got nil\n", i)
+		}
+	}
+}
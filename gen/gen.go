@@ -0,0 +1,224 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/andrewpillar/database"
+)
+
+// initialisms are the common initialisms that are kept upper case in the Go
+// identifiers generated from column names, so "user_id" becomes UserID.
+var initialisms = map[string]struct{}{
+	"API":  {},
+	"DNS":  {},
+	"HTML": {},
+	"HTTP": {},
+	"ID":   {},
+	"IP":   {},
+	"JSON": {},
+	"SQL":  {},
+	"TLS":  {},
+	"URI":  {},
+	"URL":  {},
+	"UUID": {},
+	"XML":  {},
+}
+
+// Ident converts the given snake_case name into an exported Go identifier,
+// keeping common initialisms upper case, for example "user_id" becomes
+// UserID.
+func Ident(name string) string {
+	var buf strings.Builder
+
+	for word := range strings.FieldsFuncSeq(name, func(r rune) bool {
+		return r == '_' || r == '-' || r == ' ' || r == '.'
+	}) {
+		if _, ok := initialisms[strings.ToUpper(word)]; ok {
+			buf.WriteString(strings.ToUpper(word))
+			continue
+		}
+		buf.WriteString(database.PascalCase(word))
+	}
+
+	s := buf.String()
+
+	if s == "" || !unicode.IsLetter([]rune(s)[0]) {
+		s = "X" + s
+	}
+	return s
+}
+
+// Singular returns the singular form of the given table name, for example
+// "posts" becomes "post", and "categories" becomes "category". This only
+// accounts for regular plurals.
+func Singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"), strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "ss"), strings.HasSuffix(name, "us"):
+		return name
+	case strings.HasSuffix(name, "s"):
+		return strings.TrimSuffix(name, "s")
+	}
+	return name
+}
+
+// GoType returns the Go type for the given column. Nullable columns are typed
+// as a [database.Null] of the underlying type, except for slices, such as
+// []byte, which can be nil.
+func GoType(col *Column) string {
+	typ := goType(col.Type)
+
+	if col.Nullable && !strings.HasPrefix(typ, "[]") {
+		return "database.Null[" + typ + "]"
+	}
+	return typ
+}
+
+func goType(dbtype string) string {
+	t := strings.ToLower(strings.TrimSpace(dbtype))
+
+	// PostgreSQL array types are reported via their underlying type name,
+	// which is prefixed with an underscore, such as _int4.
+	if strings.HasPrefix(t, "_") {
+		return "[]" + goType(t[1:])
+	}
+
+	if strings.HasSuffix(t, "[]") {
+		return "[]" + goType(strings.TrimSuffix(t, "[]"))
+	}
+
+	// MySQL uses tinyint(1) for booleans.
+	if t == "tinyint(1)" {
+		return "bool"
+	}
+
+	if i := strings.IndexAny(t, "( "); i > 0 {
+		t = t[:i]
+	}
+
+	switch t {
+	case "bool", "boolean":
+		return "bool"
+	case "int", "integer", "int2", "int4", "int8", "smallint", "bigint", "mediumint", "tinyint",
+		"serial", "smallserial", "bigserial", "serial4", "serial8":
+		return "int64"
+	case "real", "float", "float4", "float8", "double", "numeric", "decimal":
+		return "float64"
+	case "bytea", "blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary":
+		return "[]byte"
+	case "date", "datetime", "time", "timestamp", "timestamptz", "timetz":
+		return "time.Time"
+	}
+	return "string"
+}
+
+// Generate writes the Go source code for the models of the given tables to the
+// given writer. Each table is generated as a struct named after the singular
+// form of the table, with a "db" struct tag for each column, and an
+// implementation of [database.Model]. Primary key columns are generated as
+// create only parameters, and every other column as a mutable parameter.
+func Generate(w io.Writer, pkg string, tables []*Table) error {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "// Code generated by dbgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+
+	usesTime := slices.ContainsFunc(tables, func(t *Table) bool {
+		return slices.ContainsFunc(t.Columns, func(c *Column) bool {
+			return strings.Contains(GoType(c), "time.Time")
+		})
+	})
+
+	buf.WriteString("import (\n")
+
+	if usesTime {
+		buf.WriteString("\t\"time\"\n\n")
+	}
+	buf.WriteString("\t\"github.com/andrewpillar/database\"\n)\n")
+
+	for _, t := range tables {
+		if err := generateModel(&buf, t); err != nil {
+			return err
+		}
+	}
+
+	b, err := format.Source(buf.Bytes())
+
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+func generateModel(buf *bytes.Buffer, t *Table) error {
+	if len(t.Columns) == 0 {
+		return fmt.Errorf("table %s has no columns", t.Name)
+	}
+
+	name := Ident(Singular(t.Name))
+	recv := strings.ToLower(name[:1])
+
+	fields := make(map[string]string, len(t.Columns))
+
+	fmt.Fprintf(buf, "\ntype %s struct {\n", name)
+
+	for _, col := range t.Columns {
+		fld := Ident(col.Name)
+
+		// Avoid clashes with the methods of the database.Model interface.
+		switch fld {
+		case "Table", "PrimaryKey", "Params":
+			fld += "_"
+		}
+
+		fields[col.Name] = fld
+
+		fmt.Fprintf(buf, "\t%s %s `db:%q`\n", fld, GoType(col), col.Name)
+	}
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(buf, "func (%s *%s) Table() string { return %q }\n\n", recv, name, t.Name)
+
+	if len(t.PrimaryKey) == 0 {
+		fmt.Fprintf(buf, "func (%s *%s) PrimaryKey() *database.PrimaryKey { return nil }\n\n", recv, name)
+	} else {
+		cols := make([]string, 0, len(t.PrimaryKey))
+		vals := make([]string, 0, len(t.PrimaryKey))
+
+		for _, col := range t.PrimaryKey {
+			cols = append(cols, fmt.Sprintf("%q", col))
+			vals = append(vals, recv+"."+fields[col])
+		}
+
+		fmt.Fprintf(buf, "func (%s *%s) PrimaryKey() *database.PrimaryKey {\n", recv, name)
+		buf.WriteString("\treturn &database.PrimaryKey{\n")
+		fmt.Fprintf(buf, "\t\tColumns: []string{%s},\n", strings.Join(cols, ", "))
+		fmt.Fprintf(buf, "\t\tValues:  []any{%s},\n", strings.Join(vals, ", "))
+		buf.WriteString("\t}\n}\n\n")
+	}
+
+	fmt.Fprintf(buf, "func (%s *%s) Params() database.Params {\n", recv, name)
+	buf.WriteString("\treturn database.Params{\n")
+
+	for _, col := range t.Columns {
+		param := "MutableParam"
+
+		if col.PrimaryKey {
+			param = "CreateOnlyParam"
+		}
+		fmt.Fprintf(buf, "\t\t%q: database.%s(%s.%s),\n", col.Name, param, recv, fields[col.Name])
+	}
+	buf.WriteString("\t}\n}\n")
+	return nil
+}
@@ -0,0 +1,123 @@
+package gen
+
+import (
+	"bytes"
+	"database/sql"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/andrewpillar/database"
+
+	_ "modernc.org/sqlite"
+)
+
+const schema = `CREATE TABLE IF NOT EXISTS users (
+	id         INTEGER NOT NULL,
+	email      VARCHAR UNIQUE NOT NULL,
+	avatar     BLOB NULL,
+	created_at TIMESTAMP NOT NULL,
+	deleted_at TIMESTAMP NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS post_categories (
+	category_id INTEGER NOT NULL,
+	post_id     INTEGER NOT NULL,
+	PRIMARY KEY (post_id, category_id)
+);`
+
+func TestGenerate(t *testing.T) {
+	ctx := t.Context()
+
+	db, err := sql.Open("sqlite", ":memory:")
+
+	if err != nil {
+		t.Fatalf("sql.Open(%q, %q): %v\n", "sqlite", ":memory:", err)
+	}
+
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", schema, err)
+	}
+
+	tables, err := Inspect(ctx, db, database.SQLite)
+
+	if err != nil {
+		t.Fatalf("Inspect(ctx, db, database.SQLite): %v\n", err)
+	}
+
+	if len(tables) != 2 {
+		t.Fatalf("len(tables) = %v, want = %v\n", len(tables), 2)
+	}
+
+	if pk := strings.Join(tables[0].PrimaryKey, ","); pk != "post_id,category_id" {
+		t.Fatalf("tables[0].PrimaryKey = %v, want = %v\n", pk, "post_id,category_id")
+	}
+
+	var buf bytes.Buffer
+
+	if err := Generate(&buf, "models", tables); err != nil {
+		t.Fatalf("Generate(&buf, %q, tables): %v\n", "models", err)
+	}
+
+	src := buf.String()
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "models.go", src, 0); err != nil {
+		t.Fatalf("parser.ParseFile(...): %v\n%s\n", err, src)
+	}
+
+	b, err := os.ReadFile("testdata/models.go.golden")
+
+	if err != nil {
+		t.Fatalf("os.ReadFile(%q): %v\n", "testdata/models.go.golden", err)
+	}
+
+	if src != string(b) {
+		t.Fatalf("Generate(&buf, %q, tables) =\n%s\nwant =\n%s\n", "models", src, b)
+	}
+}
+
+func TestGoType(t *testing.T) {
+	tests := []struct {
+		col  Column
+		want string
+	}{
+		{Column{Type: "integer"}, "int64"},
+		{Column{Type: "varchar(255)"}, "string"},
+		{Column{Type: "tinyint(1)"}, "bool"},
+		{Column{Type: "timestamp with time zone"}, "time.Time"},
+		{Column{Type: "numeric(10,2)", Nullable: true}, "database.Null[float64]"},
+		{Column{Type: "bytea", Nullable: true}, "[]byte"},
+		{Column{Type: "_int4"}, "[]int64"},
+		{Column{Type: "text[]"}, "[]string"},
+	}
+
+	for i, test := range tests {
+		if got := GoType(&test.col); got != test.want {
+			t.Fatalf("tests[%d] - GoType(%q) = %q, want = %q\n", i, test.col.Type, got, test.want)
+		}
+	}
+}
+
+func TestIdent(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"id", "ID"},
+		{"user_id", "UserID"},
+		{"avatar_url", "AvatarURL"},
+		{"created_at", "CreatedAt"},
+		{"2fa", "X2fa"},
+	}
+
+	for i, test := range tests {
+		if got := Ident(test.in); got != test.want {
+			t.Fatalf("tests[%d] - Ident(%q) = %q, want = %q\n", i, test.in, got, test.want)
+		}
+	}
+}
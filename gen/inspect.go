@@ -0,0 +1,270 @@
+// Package gen provides the generation of Go models from the schema of a live
+// database. The tables of the database are introspected via [Inspect], and the
+// Go source code for the models of those tables is generated via [Generate].
+// The schema of a database can also be checked against existing models via
+// [Diff] and [Verify].
+package gen
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/andrewpillar/database"
+)
+
+// Column is a single column of a [Table].
+type Column struct {
+	// Name is the name of the column.
+	Name string
+
+	// Type is the type of the column as reported by the database, such as
+	// "integer" or "varchar(255)".
+	Type string
+
+	// Nullable is whether the column allows NULL values.
+	Nullable bool
+
+	// PrimaryKey is whether the column is part of the table's primary key.
+	PrimaryKey bool
+}
+
+// Table is a table introspected from the database.
+type Table struct {
+	Name    string
+	Columns []*Column
+
+	// PrimaryKey is the list of columns that make up the table's primary key,
+	// in the order they are declared in the key.
+	PrimaryKey []string
+}
+
+// Inspect introspects the tables in the given database for the given
+// [database.Dialect]. If any tables are given, then only those tables are
+// introspected, otherwise every table in the current schema is. For
+// [database.Postgres] this is the schema returned by current_schema(), and for
+// [database.MySQL] this is the database returned by DATABASE().
+func Inspect(ctx context.Context, db database.DB, dialect database.Dialect, tables ...string) ([]*Table, error) {
+	var (
+		tt  []*Table
+		err error
+	)
+
+	switch dialect {
+	case database.Postgres:
+		tt, err = inspectPostgres(ctx, db)
+	case database.MySQL:
+		tt, err = inspectMySQL(ctx, db)
+	case database.SQLite:
+		tt, err = inspectSQLite(ctx, db)
+	default:
+		return nil, errors.New("unknown dialect")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tables) > 0 {
+		tt = slices.DeleteFunc(tt, func(t *Table) bool {
+			return !slices.Contains(tables, t.Name)
+		})
+	}
+	return tt, nil
+}
+
+// collectTables groups the columns from the given rows into tables. The rows
+// are expected to return the table name, column name, column type, whether the
+// column is nullable, and whether the column is part of the primary key, in
+// that order, sorted by table.
+func collectTables(rows *sql.Rows) ([]*Table, error) {
+	defer rows.Close()
+
+	tt := make([]*Table, 0)
+
+	var t *Table
+
+	for rows.Next() {
+		var (
+			table string
+			col   Column
+		)
+
+		if err := rows.Scan(&table, &col.Name, &col.Type, &col.Nullable, &col.PrimaryKey); err != nil {
+			return nil, err
+		}
+
+		if t == nil || t.Name != table {
+			t = &Table{Name: table}
+			tt = append(tt, t)
+		}
+		t.Columns = append(t.Columns, &col)
+
+		if col.PrimaryKey {
+			t.PrimaryKey = append(t.PrimaryKey, col.Name)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tt, nil
+}
+
+const postgresColumns = `SELECT c.table_name,
+	c.column_name,
+	CASE WHEN c.data_type = 'ARRAY' THEN c.udt_name ELSE c.data_type END,
+	c.is_nullable = 'YES',
+	EXISTS (
+		SELECT 1
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.key_column_usage kcu
+			ON kcu.constraint_name = tc.constraint_name
+			AND kcu.table_schema = tc.table_schema
+		WHERE tc.constraint_type = 'PRIMARY KEY'
+		AND tc.table_schema = c.table_schema
+		AND tc.table_name = c.table_name
+		AND kcu.column_name = c.column_name
+	)
+FROM information_schema.columns c
+JOIN information_schema.tables t
+	ON t.table_schema = c.table_schema
+	AND t.table_name = c.table_name
+WHERE c.table_schema = current_schema()
+AND t.table_type = 'BASE TABLE'
+ORDER BY c.table_name, c.ordinal_position`
+
+func inspectPostgres(ctx context.Context, db database.DB) ([]*Table, error) {
+	rows, err := db.QueryContext(ctx, postgresColumns)
+
+	if err != nil {
+		return nil, err
+	}
+	return collectTables(rows)
+}
+
+const mysqlColumns = `SELECT c.table_name,
+	c.column_name,
+	c.column_type,
+	c.is_nullable = 'YES',
+	c.column_key = 'PRI'
+FROM information_schema.columns c
+JOIN information_schema.tables t
+	ON t.table_schema = c.table_schema
+	AND t.table_name = c.table_name
+WHERE c.table_schema = DATABASE()
+AND t.table_type = 'BASE TABLE'
+ORDER BY c.table_name, c.ordinal_position`
+
+func inspectMySQL(ctx context.Context, db database.DB) ([]*Table, error) {
+	rows, err := db.QueryContext(ctx, mysqlColumns)
+
+	if err != nil {
+		return nil, err
+	}
+	return collectTables(rows)
+}
+
+const sqliteTables = `SELECT name
+FROM sqlite_master
+WHERE type = 'table'
+AND name NOT LIKE 'sqlite_%'
+ORDER BY name`
+
+func inspectSQLite(ctx context.Context, db database.DB) ([]*Table, error) {
+	rows, err := db.QueryContext(ctx, sqliteTables)
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0)
+
+	for rows.Next() {
+		var name string
+
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, name)
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tt := make([]*Table, 0, len(names))
+
+	for _, name := range names {
+		t, err := inspectSQLiteTable(ctx, db, name)
+
+		if err != nil {
+			return nil, err
+		}
+		tt = append(tt, t)
+	}
+	return tt, nil
+}
+
+func inspectSQLiteTable(ctx context.Context, db database.DB, name string) (*Table, error) {
+	q := fmt.Sprintf("PRAGMA table_info(%q)", name)
+
+	rows, err := db.QueryContext(ctx, q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	t := &Table{
+		Name: name,
+	}
+
+	// The position of each column in the primary key, starting at 1.
+	pks := make(map[string]int)
+
+	for rows.Next() {
+		var (
+			cid     int
+			col     Column
+			notnull bool
+			dflt    sql.NullString
+			pk      int
+		)
+
+		if err := rows.Scan(&cid, &col.Name, &col.Type, &notnull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+
+		col.Type = strings.ToLower(col.Type)
+		col.PrimaryKey = pk > 0
+
+		// SQLite allows NULL in primary key columns unless they are
+		// explicitly NOT NULL, however these are treated as non-nullable
+		// since a primary key would not typically be NULL.
+		col.Nullable = !notnull && !col.PrimaryKey
+
+		t.Columns = append(t.Columns, &col)
+
+		if col.PrimaryKey {
+			pks[col.Name] = pk
+			t.PrimaryKey = append(t.PrimaryKey, col.Name)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(t.PrimaryKey, func(a, b string) int {
+		return pks[a] - pks[b]
+	})
+	return t, nil
+}
@@ -0,0 +1,175 @@
+package gen
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/andrewpillar/database"
+)
+
+// MismatchKind is the kind of a [Mismatch] between a [database.Model] and the
+// schema of the database.
+type MismatchKind uint
+
+//go:generate stringer -type MismatchKind -linecomment
+const (
+	MissingTable  MismatchKind = iota + 1 // missing table
+	MissingColumn                         // missing column
+	TypeMismatch                          // type mismatch
+	NullMismatch                          // null mismatch
+)
+
+// Mismatch is a single difference between a [database.Model] and the schema
+// of the database.
+type Mismatch struct {
+	Kind   MismatchKind
+	Table  string
+	Column string
+
+	// Want is what the Model expects, and Got is what is in the database. For
+	// a [TypeMismatch] these are the column types, and for a [NullMismatch]
+	// these are either NULL or NOT NULL.
+	Want string
+	Got  string
+}
+
+func (m *Mismatch) String() string {
+	switch m.Kind {
+	case MissingTable:
+		return fmt.Sprintf("%s: %s", m.Table, m.Kind)
+	case MissingColumn:
+		return fmt.Sprintf("%s.%s: %s", m.Table, m.Column, m.Kind)
+	}
+	return fmt.Sprintf("%s.%s: %s, want %s, got %s", m.Table, m.Column, m.Kind, m.Want, m.Got)
+}
+
+// MismatchError is the error returned by [Verify] when the schema of the
+// database does not match the Models.
+type MismatchError struct {
+	Mismatches []*Mismatch
+}
+
+func (e *MismatchError) Error() string {
+	lines := make([]string, 0, len(e.Mismatches))
+
+	for _, m := range e.Mismatches {
+		lines = append(lines, m.String())
+	}
+	return "schema mismatch:\n\t" + strings.Join(lines, "\n\t")
+}
+
+// Diff introspects the schema of the given database via [Inspect] and returns
+// the differences between it and the given Models. The columns of each Model
+// are inferred via [database.ModelColumns]. The following are reported,
+//
+//   - A Model whose table does not exist.
+//   - A parameter of a Model referencing a column that does not exist.
+//   - A column whose type maps to a different Go type than the type of the
+//     field, as per [GoType]. So a VARCHAR column would match a TEXT column,
+//     whereas a TEXT column would not match a BIGINT column.
+//   - A nullable column for a field that cannot hold NULL.
+//
+// Columns in the database that are not referenced by a Model are not
+// reported, since a Model may only make use of a subset of a table.
+func Diff(ctx context.Context, db database.DB, dialect database.Dialect, models ...database.Model) ([]*Mismatch, error) {
+	names := make([]string, 0, len(models))
+
+	for _, m := range models {
+		names = append(names, m.Table())
+	}
+
+	tables, err := Inspect(ctx, db, dialect, names...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tab := make(map[string]*Table, len(tables))
+
+	for _, t := range tables {
+		tab[t.Name] = t
+	}
+
+	mismatches := make([]*Mismatch, 0)
+
+	for _, m := range models {
+		t, ok := tab[m.Table()]
+
+		if !ok {
+			mismatches = append(mismatches, &Mismatch{
+				Kind:  MissingTable,
+				Table: m.Table(),
+			})
+			continue
+		}
+
+		cols, err := database.ModelColumns(m, dialect)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.Table(), err)
+		}
+
+		dbcols := make(map[string]*Column, len(t.Columns))
+
+		for _, col := range t.Columns {
+			dbcols[col.Name] = col
+		}
+
+		for _, col := range cols {
+			dbcol, ok := dbcols[col.Name]
+
+			if !ok {
+				mismatches = append(mismatches, &Mismatch{
+					Kind:   MissingColumn,
+					Table:  t.Name,
+					Column: col.Name,
+				})
+				continue
+			}
+
+			if goType(col.Type) != goType(dbcol.Type) {
+				mismatches = append(mismatches, &Mismatch{
+					Kind:   TypeMismatch,
+					Table:  t.Name,
+					Column: col.Name,
+					Want:   col.Type,
+					Got:    dbcol.Type,
+				})
+			}
+
+			if dbcol.Nullable && !col.Nullable {
+				mismatches = append(mismatches, &Mismatch{
+					Kind:   NullMismatch,
+					Table:  t.Name,
+					Column: col.Name,
+					Want:   "NOT NULL",
+					Got:    "NULL",
+				})
+			}
+		}
+	}
+	return mismatches, nil
+}
+
+// Verify is like [Diff], only a [MismatchError] is returned if there are any
+// differences between the schema of the database and the Models. This would
+// typically be called in tests, or at startup, for example,
+//
+//	if err := gen.Verify(ctx, db, database.Postgres, &Post{}, &User{}); err != nil {
+//	    log.Fatalln(err)
+//	}
+func Verify(ctx context.Context, db database.DB, dialect database.Dialect, models ...database.Model) error {
+	mismatches, err := Diff(ctx, db, dialect, models...)
+
+	if err != nil {
+		return err
+	}
+
+	if len(mismatches) > 0 {
+		return &MismatchError{
+			Mismatches: mismatches,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package gen
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database"
+)
+
+type User struct {
+	ID        int64
+	Email     string
+	Avatar    []byte
+	CreatedAt time.Time
+	DeletedAt database.Null[time.Time]
+}
+
+func (u *User) Table() string { return "users" }
+
+func (u *User) PrimaryKey() *database.PrimaryKey {
+	return &database.PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{u.ID},
+	}
+}
+
+func (u *User) Params() database.Params {
+	return database.Params{
+		"id":         database.GeneratedParam(u.ID),
+		"email":      database.MutableParam(u.Email),
+		"avatar":     database.MutableParam(u.Avatar),
+		"created_at": database.CreateOnlyParam(u.CreatedAt),
+		"deleted_at": database.MutableParam(u.DeletedAt),
+	}
+}
+
+// StaleUser is a model that has drifted from the users table.
+type StaleUser struct {
+	ID        int64
+	Email     int64
+	Nickname  string
+	DeletedAt time.Time
+}
+
+func (u *StaleUser) Table() string { return "users" }
+
+func (u *StaleUser) PrimaryKey() *database.PrimaryKey {
+	return &database.PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{u.ID},
+	}
+}
+
+func (u *StaleUser) Params() database.Params {
+	return database.Params{
+		"id":         database.GeneratedParam(u.ID),
+		"email":      database.MutableParam(u.Email),
+		"nickname":   database.MutableParam(u.Nickname),
+		"deleted_at": database.MutableParam(u.DeletedAt),
+	}
+}
+
+type Comment struct {
+	ID int64
+}
+
+func (c *Comment) Table() string { return "comments" }
+
+func (c *Comment) PrimaryKey() *database.PrimaryKey { return nil }
+
+func (c *Comment) Params() database.Params {
+	return database.Params{
+		"id": database.GeneratedParam(c.ID),
+	}
+}
+
+func TestVerify(t *testing.T) {
+	ctx := t.Context()
+
+	db, err := sql.Open("sqlite", ":memory:")
+
+	if err != nil {
+		t.Fatalf("sql.Open(%q, %q): %v\n", "sqlite", ":memory:", err)
+	}
+
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", schema, err)
+	}
+
+	if err := Verify(ctx, db, database.SQLite, &User{}); err != nil {
+		t.Fatalf("Verify(ctx, db, database.SQLite, &User{}): %v\n", err)
+	}
+
+	err = Verify(ctx, db, database.SQLite, &StaleUser{}, &Comment{})
+
+	var merr *MismatchError
+
+	if !errors.As(err, &merr) {
+		t.Fatalf("Verify(ctx, db, database.SQLite, &StaleUser{}, &Comment{}): %v, want = %T\n", err, merr)
+	}
+
+	want := []string{
+		"users.email: type mismatch, want INTEGER, got varchar",
+		"users.nickname: missing column",
+		"users.deleted_at: null mismatch, want NOT NULL, got NULL",
+		"comments: missing table",
+	}
+
+	if len(merr.Mismatches) != len(want) {
+		t.Fatalf("len(merr.Mismatches) = %v, want = %v\n%v\n", len(merr.Mismatches), len(want), merr)
+	}
+
+	for i, m := range merr.Mismatches {
+		if s := m.String(); s != want[i] {
+			t.Fatalf("merr.Mismatches[%d] = %q, want = %q\n", i, s, want[i])
+		}
+	}
+}
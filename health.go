@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// HealthCheck is the result of checking the health of a database via
+// [Health].
+type HealthCheck struct {
+	// Latency is how long the round trip to the database took.
+	Latency time.Duration
+
+	// Stats are the statistics of the connection pool, these are only set if
+	// the database is a [sql.DB].
+	Stats *sql.DBStats
+
+	// Err is the error returned from the round trip, if any. The database is
+	// healthy if this is nil.
+	Err error
+}
+
+// Saturation returns the proportion of the connection pool that is in use,
+// between 0 and 1. This is 0 if there are no pool statistics, or if the pool
+// has no limit on the number of open connections.
+func (c *HealthCheck) Saturation() float64 {
+	if c.Stats == nil || c.Stats.MaxOpenConnections <= 0 {
+		return 0
+	}
+	return float64(c.Stats.InUse) / float64(c.Stats.MaxOpenConnections)
+}
+
+// Healthy reports whether the round trip to the database succeeded.
+func (c *HealthCheck) Healthy() bool {
+	return c.Err == nil
+}
+
+// MarshalJSON encodes the HealthCheck with its latency in milliseconds, and its
+// error as a string.
+func (c *HealthCheck) MarshalJSON() ([]byte, error) {
+	type pool struct {
+		Open       int     `json:"open"`
+		InUse      int     `json:"in_use"`
+		Idle       int     `json:"idle"`
+		MaxOpen    int     `json:"max_open"`
+		WaitCount  int64   `json:"wait_count"`
+		Saturation float64 `json:"saturation"`
+	}
+
+	v := struct {
+		Status  string  `json:"status"`
+		Latency float64 `json:"latency_ms"`
+		Pool    *pool   `json:"pool,omitempty"`
+		Error   string  `json:"error,omitempty"`
+	}{
+		Status:  "ok",
+		Latency: float64(c.Latency) / float64(time.Millisecond),
+	}
+
+	if c.Stats != nil {
+		v.Pool = &pool{
+			Open:       c.Stats.OpenConnections,
+			InUse:      c.Stats.InUse,
+			Idle:       c.Stats.Idle,
+			MaxOpen:    c.Stats.MaxOpenConnections,
+			WaitCount:  c.Stats.WaitCount,
+			Saturation: c.Saturation(),
+		}
+	}
+
+	if c.Err != nil {
+		v.Status = "unavailable"
+		v.Error = c.Err.Error()
+	}
+	return json.Marshal(v)
+}
+
+// Health checks the health of the given database by performing a round trip
+// via SELECT 1, and recording how long it took. If the database is a
+// [sql.DB], then the statistics of its connection pool are recorded too.
+func Health(ctx context.Context, db DB) *HealthCheck {
+	var (
+		c HealthCheck
+		n int
+	)
+
+	start := time.Now()
+	c.Err = db.QueryRowContext(ctx, "SELECT 1").Scan(&n)
+	c.Latency = time.Since(start)
+
+	if sqldb, ok := db.(*sql.DB); ok {
+		stats := sqldb.Stats()
+		c.Stats = &stats
+	}
+	return &c
+}
+
+// HealthHandler returns an [http.Handler] that checks the health of the given
+// database via [Health] on each request, and writes the [HealthCheck] as JSON.
+// The status is 200 if the database is healthy, otherwise 503. This is
+// intended for use as a readiness probe, for example,
+//
+//	http.Handle("/ready", database.HealthHandler(db, time.Second))
+//
+// The given timeout bounds how long the round trip can take before the
+// database is considered unhealthy, if zero, then the round trip is bound only
+// by the request.
+func HealthHandler(db DB, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if timeout > 0 {
+			var cancel context.CancelFunc
+
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		c := Health(ctx, db)
+
+		status := http.StatusOK
+
+		if !c.Healthy() {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(c)
+	})
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	db := NewDB(t)
+	db.SetMaxOpenConns(4)
+
+	c := Health(t.Context(), db)
+
+	if !c.Healthy() {
+		t.Fatalf("Health(ctx, db): %v\n", c.Err)
+	}
+
+	if c.Stats == nil {
+		t.Fatalf("c.Stats = %v, want = non-nil\n", c.Stats)
+	}
+
+	if c.Stats.MaxOpenConnections != 4 {
+		t.Fatalf("c.Stats.MaxOpenConnections = %v, want = %v\n", c.Stats.MaxOpenConnections, 4)
+	}
+
+	rec := httptest.NewRecorder()
+
+	HealthHandler(db, time.Second).ServeHTTP(rec, httptest.NewRequest("GET", "/ready", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rec.Code = %v, want = %v\n", rec.Code, http.StatusOK)
+	}
+
+	var body struct {
+		Status string
+		Pool   struct {
+			MaxOpen int `json:"max_open"`
+		}
+	}
+
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("json.Decode(rec.Body): %v\n", err)
+	}
+
+	if body.Status != "ok" {
+		t.Fatalf("body.Status = %q, want = %q\n", body.Status, "ok")
+	}
+
+	if body.Pool.MaxOpen != 4 {
+		t.Fatalf("body.Pool.MaxOpen = %v, want = %v\n", body.Pool.MaxOpen, 4)
+	}
+
+	db.Close()
+
+	rec = httptest.NewRecorder()
+
+	HealthHandler(db, 0).ServeHTTP(rec, httptest.NewRequest("GET", "/ready", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("rec.Code = %v, want = %v\n", rec.Code, http.StatusServiceUnavailable)
+	}
+}
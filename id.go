@@ -0,0 +1,249 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// IDGenerator is the interface that wraps the NewID method for generating the
+// primary key of a [Model] when it is created. This is used by a [Store]
+// configured via [WithIDGenerator].
+type IDGenerator interface {
+	NewID() any
+}
+
+// IDFunc is an adapter to allow the use of an ordinary function as an
+// [IDGenerator].
+type IDFunc func() any
+
+// NewID implements [IDGenerator].
+func (fn IDFunc) NewID() any { return fn() }
+
+// clockedIDGenerator is implemented by the time ordered generators, so a
+// [Store] can generate ids with its own [Clock] rather than the system time.
+type clockedIDGenerator interface {
+	newID(c Clock) any
+}
+
+// WithIDGenerator configures the [IDGenerator] a [Store] uses for the primary
+// keys of the models it creates. An id is only generated for a model with a
+// single column primary key whose value is zero, and is set on the struct
+// field of the model the column maps to. The time ordered generators, such as
+// [UUIDv7], take their time from the [Clock] of the Store, for example,
+//
+//	posts := database.NewStore(db, func() *Post {
+//	    return &Post{}
+//	}, database.WithIDGenerator(database.UUIDv7))
+//
+// Models whose primary key is generated by the database, such as via a
+// [GeneratedParam], should not be used with a generator.
+func WithIDGenerator(gen IDGenerator) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.ids = gen
+	}
+}
+
+// generateID sets the primary key of the given model to an id from the given
+// generator, if the model has a single column primary key that is zero. The
+// given Clock is used by the generator if it is time ordered. The column of
+// the primary key is mapped to its field via the given Mapper.
+func generateID(m Model, gen IDGenerator, c Clock, mapper *Mapper) error {
+	if gen == nil {
+		return nil
+	}
+
+	pk := m.PrimaryKey()
+
+	if pk == nil || len(pk.Columns) != 1 || len(pk.Values) != 1 {
+		return nil
+	}
+
+	if rv := reflect.ValueOf(pk.Values[0]); rv.IsValid() && !rv.IsZero() {
+		return nil
+	}
+	var id any
+
+	if g, ok := gen.(clockedIDGenerator); ok {
+		id = g.newID(c)
+	} else {
+		id = gen.NewID()
+	}
+	return setColumn(mapper, m, pk.Columns[0], id)
+}
+
+// prepareCreate generates the primary key of the given model, if configured
+// to, and prepares its params for creation.
+func (cfg *storeConfig) prepareCreate(m Model) (Params, error) {
+	if err := generateID(m, cfg.ids, cfg.getClock(), cfg.mapper); err != nil {
+		return nil, err
+	}
+	return prepareParams(m, paramCreate, cfg.getClock(), cfg.mapper)
+}
+
+// unixMilli returns the given time as milliseconds since the Unix epoch, no
+// earlier than the given last time, so that ids remain ordered should the
+// clock go backwards.
+func unixMilli(t time.Time, last int64) int64 {
+	return max(t.UnixMilli(), last)
+}
+
+type uuidv7 struct {
+	mu   sync.Mutex
+	last int64
+}
+
+// UUIDv7 is an [IDGenerator] that generates version 7 UUIDs, as per RFC 9562,
+// in their canonical string form. These are ordered by the time they were
+// generated in, so they index well as primary keys.
+var UUIDv7 IDGenerator = &uuidv7{}
+
+func (g *uuidv7) NewID() any { return g.newID(SystemClock) }
+
+func (g *uuidv7) newID(c Clock) any {
+	g.mu.Lock()
+	g.last = unixMilli(c.Now(), g.last)
+	ms := g.last
+	g.mu.Unlock()
+
+	var b [16]byte
+
+	rand.Read(b[6:])
+
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+	b[6] = b[6]&0x0f | 0x70
+	b[8] = b[8]&0x3f | 0x80
+
+	var buf [36]byte
+
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+
+	return string(buf[:])
+}
+
+// crockford is the Crockford base32 alphabet used for encoding ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+type ulid struct {
+	mu   sync.Mutex
+	last int64
+}
+
+// ULID is an [IDGenerator] that generates ULIDs, a 48 bit timestamp followed by
+// 80 random bits, encoded as a 26 character Crockford base32 string. Like
+// [UUIDv7], these are ordered by the time they were generated in.
+var ULID IDGenerator = &ulid{}
+
+func (g *ulid) NewID() any { return g.newID(SystemClock) }
+
+func (g *ulid) newID(c Clock) any {
+	g.mu.Lock()
+	g.last = unixMilli(c.Now(), g.last)
+	ms := g.last
+	g.mu.Unlock()
+
+	var b [16]byte
+
+	rand.Read(b[6:])
+
+	binary.BigEndian.PutUint16(b[4:], uint16(ms))
+	binary.BigEndian.PutUint32(b[0:], uint32(ms>>16))
+
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+
+	var buf [26]byte
+
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i] = crockford[lo&0x1f]
+
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(buf[:])
+}
+
+// SnowflakeEpoch is the epoch from which the timestamps of the ids generated
+// by a [Snowflake] are measured.
+var SnowflakeEpoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+const (
+	snowflakeNodeBits = 10
+	snowflakeSeqBits  = 12
+
+	snowflakeMaxNode = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSeq  = 1<<snowflakeSeqBits - 1
+)
+
+// Snowflake is an [IDGenerator] that generates 64 bit integer ids made up of
+// a 41 bit timestamp in milliseconds since [SnowflakeEpoch], a 10 bit node,
+// and a 12 bit sequence. This allows for up to 4096 ids per millisecond per
+// node, with each node generating ids independently. It is safe for concurrent
+// use.
+type Snowflake struct {
+	node int64
+
+	mu   sync.Mutex
+	last int64
+	seq  int64
+}
+
+var _ IDGenerator = (*Snowflake)(nil)
+
+// NewSnowflake returns a [Snowflake] for the given node. Each process
+// generating ids for the same table should be given a different node. This
+// panics if the node does not fit in 10 bits.
+func NewSnowflake(node int64) *Snowflake {
+	if node < 0 || node > snowflakeMaxNode {
+		panic("database: snowflake node out of range")
+	}
+
+	return &Snowflake{
+		node: node,
+	}
+}
+
+// NewID implements [IDGenerator]. The id returned is an int64.
+func (g *Snowflake) NewID() any { return g.newID(SystemClock) }
+
+func (g *Snowflake) newID(c Clock) any {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := unixMilli(c.Now(), g.last)
+
+	if ms == g.last {
+		g.seq = (g.seq + 1) & snowflakeMaxSeq
+
+		// The sequence has been exhausted for this millisecond, so move on to
+		// the next. This is not waited for, since a Clock that does not move,
+		// such as a FrozenClock, would never get there.
+		if g.seq == 0 {
+			ms++
+		}
+	} else {
+		g.seq = 0
+	}
+
+	g.last = ms
+
+	ts := ms - SnowflakeEpoch.UnixMilli()
+
+	return ts<<(snowflakeNodeBits+snowflakeSeqBits) | g.node<<snowflakeSeqBits | g.seq
+}
@@ -0,0 +1,199 @@
+package database
+
+import (
+	"crypto/rand"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestIDGenerators(t *testing.T) {
+	t.Run("UUIDv7", func(t *testing.T) {
+		re := regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$")
+
+		seen := make(map[string]struct{})
+
+		for i := 0; i < 1000; i++ {
+			id := UUIDv7.NewID().(string)
+
+			if !re.MatchString(id) {
+				t.Fatalf("UUIDv7.NewID() = %q, want version 7 UUID\n", id)
+			}
+
+			if _, ok := seen[id]; ok {
+				t.Fatalf("UUIDv7.NewID() = %q, duplicate id\n", id)
+			}
+			seen[id] = struct{}{}
+		}
+	})
+
+	t.Run("ULID", func(t *testing.T) {
+		seen := make(map[string]struct{})
+
+		for i := 0; i < 1000; i++ {
+			id := ULID.NewID().(string)
+
+			if len(id) != 26 {
+				t.Fatalf("len(ULID.NewID()) = %v, want = %v\n", len(id), 26)
+			}
+
+			if strings.Trim(id, crockford) != "" {
+				t.Fatalf("ULID.NewID() = %q, want Crockford base32\n", id)
+			}
+
+			// The first character only encodes the top 3 bits of the
+			// timestamp.
+			if id[0] > '7' {
+				t.Fatalf("ULID.NewID() = %q, want first character <= 7\n", id)
+			}
+
+			if _, ok := seen[id]; ok {
+				t.Fatalf("ULID.NewID() = %q, duplicate id\n", id)
+			}
+			seen[id] = struct{}{}
+		}
+	})
+
+	t.Run("Snowflake", func(t *testing.T) {
+		g := NewSnowflake(7)
+
+		var last int64
+
+		for i := 0; i < 10000; i++ {
+			id := g.NewID().(int64)
+
+			if id <= last {
+				t.Fatalf("g.NewID() = %v, want > %v\n", id, last)
+			}
+
+			if node := id >> snowflakeSeqBits & snowflakeMaxNode; node != 7 {
+				t.Fatalf("node = %v, want = %v\n", node, 7)
+			}
+			last = id
+		}
+	})
+
+	t.Run("Clock", func(t *testing.T) {
+		now := time.Date(2030, time.March, 1, 12, 0, 0, 0, time.UTC)
+		clock := NewFrozenClock(now)
+
+		if id := (&uuidv7{}).newID(clock).(string); !strings.HasPrefix(id, fmt.Sprintf("%08x-%04x", now.UnixMilli()>>16, now.UnixMilli()&0xffff)) {
+			t.Fatalf("uuidv7.newID(clock) = %q, want timestamp %v\n", id, now.UnixMilli())
+		}
+
+		// The first 10 characters of a ULID encode its timestamp.
+		var ts [10]byte
+
+		for i, ms := len(ts)-1, now.UnixMilli(); i >= 0; i, ms = i-1, ms>>5 {
+			ts[i] = crockford[ms&0x1f]
+		}
+
+		if id := (&ulid{}).newID(clock).(string); !strings.HasPrefix(id, string(ts[:])) {
+			t.Fatalf("ulid.newID(clock) = %q, want prefix %q\n", id, ts[:])
+		}
+
+		g := NewSnowflake(1)
+
+		var last int64
+
+		// More ids than fit in a millisecond, which should not stall on a
+		// clock that does not move.
+		for i := 0; i < 5000; i++ {
+			id := g.newID(clock).(int64)
+
+			if id <= last {
+				t.Fatalf("g.newID(clock) = %v, want > %v\n", id, last)
+			}
+			last = id
+		}
+
+		if ms := last>>(snowflakeNodeBits+snowflakeSeqBits) + SnowflakeEpoch.UnixMilli(); ms != now.UnixMilli()+1 {
+			t.Fatalf("ms = %v, want = %v\n", ms, now.UnixMilli()+1)
+		}
+	})
+}
+
+func TestWithIDGenerator(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, userPostSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", userPostSchema, err)
+	}
+
+	var next int64 = 100
+
+	users := NewStore(db, func() *User {
+		return &User{}
+	}, WithIDGenerator(IDFunc(func() any {
+		next++
+		return next
+	})))
+
+	uu := []*User{
+		{Email: rand.Text()},
+		{ID: 5, Email: rand.Text()},
+		{Email: rand.Text()},
+	}
+
+	if err := users.Create(ctx, uu...); err != nil {
+		t.Fatalf("users.Create(ctx, uu...): %v\n", err)
+	}
+
+	want := []int64{101, 5, 102}
+
+	for i, u := range uu {
+		if u.ID != want[i] {
+			t.Fatalf("uu[%d].ID = %v, want = %v\n", i, u.ID, want[i])
+		}
+
+		_, ok, err := users.Get(ctx, query.WhereEq("id", query.Arg(want[i])))
+
+		if err != nil {
+			t.Fatalf("users.Get(ctx, ...): %v\n", err)
+		}
+
+		if !ok {
+			t.Fatalf("users.Get(ctx, ...): expected user %v, got none\n", want[i])
+		}
+	}
+
+	t.Run("Clock", func(t *testing.T) {
+		now := time.Date(2030, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+		users := NewStore(db, func() *User {
+			return &User{}
+		}, WithIDGenerator(NewSnowflake(2)), WithClock(NewFrozenClock(now)))
+
+		u := &User{Email: rand.Text()}
+
+		if err := users.Create(ctx, u); err != nil {
+			t.Fatalf("users.Create(ctx, u): %v\n", err)
+		}
+
+		if ms := u.ID>>(snowflakeNodeBits+snowflakeSeqBits) + SnowflakeEpoch.UnixMilli(); ms != now.UnixMilli() {
+			t.Fatalf("ms = %v, want = %v\n", ms, now.UnixMilli())
+		}
+	})
+
+	t.Run("MemoryStore", func(t *testing.T) {
+		mem := NewMemoryStore(func() *User {
+			return &User{}
+		})
+		mem.SetIDGenerator(NewSnowflake(1))
+
+		u := &User{Email: rand.Text()}
+
+		if err := mem.Create(ctx, u); err != nil {
+			t.Fatalf("mem.Create(ctx, u): %v\n", err)
+		}
+
+		if u.ID == 0 {
+			t.Fatalf("u.ID = %v, want non-zero\n", u.ID)
+		}
+	})
+}
@@ -0,0 +1,239 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// ImportError records a record of a CSV import that could not be imported,
+// such as one that failed validation, along with the line it was on.
+type ImportError struct {
+	Line int
+	Err  error
+}
+
+func (e *ImportError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+func (e *ImportError) Unwrap() error { return e.Err }
+
+// importColumns returns the columns the given CSV header maps to, along with
+// the index of each column in the header. If the mapping is nil, then the
+// header is used as is, and any column that is not creatable is skipped.
+// Otherwise, only the columns of the header in the mapping are imported, and
+// each must be creatable.
+func importColumns(header []string, mapping map[string]string, creatable []string) ([]string, []int, error) {
+	var (
+		cols []string
+		idx  []int
+	)
+
+	for i, name := range header {
+		col := name
+
+		if mapping != nil {
+			var ok bool
+
+			if col, ok = mapping[name]; !ok || col == "" {
+				continue
+			}
+		}
+
+		if !slices.Contains(creatable, col) {
+			if mapping == nil {
+				continue
+			}
+			return nil, nil, fmt.Errorf("cannot import %s into column %s, not a creatable param", name, col)
+		}
+
+		cols = append(cols, col)
+		idx = append(idx, i)
+	}
+
+	if len(cols) == 0 {
+		return nil, nil, errors.New("no columns to import")
+	}
+	return cols, idx, nil
+}
+
+// binaryColumns reports whether each of the given columns maps to a []byte
+// field of the given model, the values of which are base64 encoded in a CSV,
+// as per [Store.Export].
+func binaryColumns(m Model, cols []string) []bool {
+	binary := make([]bool, len(cols))
+
+	fields, err := (&Scanner{}).getFields(reflect.ValueOf(m))
+
+	if err != nil {
+		return binary
+	}
+
+	for i, col := range cols {
+		if fld, ok := fields.get(col); ok && !fld.json {
+			binary[i] = fld.typ.Kind() == reflect.Slice && fld.typ.Elem().Kind() == reflect.Uint8
+		}
+	}
+	return binary
+}
+
+// Import creates models from the records of the given CSV, returning the
+// number of models created. The first record of the CSV is expected to be a
+// header, which is mapped to the columns of the model via the given mapping
+// of header names to column names, for example,
+//
+//	n, err := posts.Import(ctx, r, map[string]string{
+//	    "Title": "title",
+//	    "Body":  "content",
+//	})
+//
+// If the mapping is nil, then the header names are used as the column names,
+// and any that are not creatable params of the model are skipped, so that the
+// CSV written by [Store.Export] can be imported as is. Otherwise, only the
+// mapped headers are imported, and an error is returned if any of them map to
+// a column that is not creatable.
+//
+// Each record is scanned into a new model as per [Scanner.Scan], with empty
+// fields treated as NULL. Records that cannot be scanned, or that fail
+// validation if the model implements [Validator], are skipped and reported via
+// an [ImportError] once the remaining models have been created. The models are
+// created via [Store.CopyFrom], so if creating them fails then none of them are
+// created.
+func (s *Store[M]) Import(ctx context.Context, r io.Reader, mapping map[string]string) (int64, error) {
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	cols, idx, err := importColumns(header, mapping, createCols(s.new()))
+
+	if err != nil {
+		return 0, err
+	}
+
+	binary := binaryColumns(s.new(), cols)
+
+	var record []string
+
+	sc := &Scanner{
+		cols:        cols,
+		dest:        make([]any, 0, len(cols)),
+		mapper:      s.cfg.mapper,
+		timeLayouts: DefaultTimeLayouts,
+		strict:      s.cfg.strict,
+		plaintext:   true,
+	}
+
+	if s.cfg.timeLayouts != nil {
+		sc.timeLayouts = s.cfg.timeLayouts
+	}
+
+	sc.scanRow = func(dest ...any) error {
+		for i, d := range dest {
+			var v any
+
+			if field := record[idx[i]]; field != "" {
+				v = field
+
+				if binary[i] {
+					b, err := base64.StdEncoding.DecodeString(field)
+
+					if err != nil {
+						return fmt.Errorf("column %s: %w", cols[i], err)
+					}
+					v = b
+				}
+			}
+
+			switch d := d.(type) {
+			case *any:
+				*d = v
+			case sql.Scanner:
+				if err := d.Scan(v); err != nil {
+					return fmt.Errorf("column %s: %w", cols[i], err)
+				}
+			default:
+				return fmt.Errorf("cannot import column %s into %T", cols[i], d)
+			}
+		}
+		return nil
+	}
+
+	var (
+		errs    []error
+		readErr error
+	)
+
+	seq := func(yield func(M) bool) {
+		for {
+			rec, err := cr.Read()
+
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return
+				}
+
+				// Records with the wrong number of fields can be skipped,
+				// whereas any other error means the CSV cannot be read any
+				// further.
+				var perr *csv.ParseError
+
+				if errors.As(err, &perr) && errors.Is(perr.Err, csv.ErrFieldCount) {
+					errs = append(errs, &ImportError{Line: perr.Line, Err: perr.Err})
+					continue
+				}
+				readErr = err
+				return
+			}
+
+			line, _ := cr.FieldPos(0)
+
+			record = rec
+			m := s.new()
+
+			if err := sc.scan(m); err != nil {
+				errs = append(errs, &ImportError{Line: line, Err: err})
+				continue
+			}
+
+			if err := validate(ctx, m); err != nil {
+				errs = append(errs, &ImportError{Line: line, Err: err})
+				continue
+			}
+
+			if !yield(m) {
+				return
+			}
+		}
+	}
+
+	var n int64
+
+	err = s.atomic(ctx, func(s *Store[M]) error {
+		var err error
+
+		if n, err = s.CopyFrom(ctx, seq); err != nil {
+			return err
+		}
+		return readErr
+	})
+
+	if err != nil {
+		return 0, err
+	}
+	return n, errors.Join(errs...)
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestStoreImport(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, accountSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", accountSchema, err)
+	}
+
+	store := NewStore[*Account](db, func() *Account {
+		return &Account{}
+	})
+
+	csv := `Account ID,Email Address,Notes
+1,me@example.com,first
+2,example.com,invalid email
+3,you@example.com
+4,them@example.com,last
+`
+
+	mapping := map[string]string{
+		"Account ID":    "id",
+		"Email Address": "email",
+	}
+
+	n, err := store.Import(ctx, strings.NewReader(csv), mapping)
+
+	if n != 2 {
+		t.Fatalf("n = %v, want = %v\n", n, 2)
+	}
+
+	var ierr *ImportError
+
+	if !errors.As(err, &ierr) {
+		t.Fatalf("store.Import(ctx, r, mapping): %v, want = %T\n", err, ierr)
+	}
+
+	if ierr.Line != 3 {
+		t.Errorf("ierr.Line = %v, want = %v\n", ierr.Line, 3)
+	}
+
+	// The invalid email on line 3, and the missing field on line 4.
+	if errs := err.(interface{ Unwrap() []error }).Unwrap(); len(errs) != 2 {
+		t.Errorf("len(errs) = %v, want = %v\n", len(errs), 2)
+	}
+
+	if !errors.Is(err, errInvalidEmail) {
+		t.Errorf("errors.Is(%v, %v) = %v, want = %v\n", err, errInvalidEmail, false, true)
+	}
+
+	aa, err := store.Select(ctx, query.Columns("*"), query.OrderAsc("id"))
+
+	if err != nil {
+		t.Fatalf("store.Select(ctx, query.Columns(\"*\")): %v\n", err)
+	}
+
+	want := []string{"me@example.com", "them@example.com"}
+
+	if len(aa) != len(want) {
+		t.Fatalf("len(aa) = %v, want = %v\n", len(aa), len(want))
+	}
+
+	for i, a := range aa {
+		if a.Email != want[i] {
+			t.Errorf("aa[%d].Email = %q, want = %q\n", i, a.Email, want[i])
+		}
+	}
+
+	mapping["Notes"] = "notes"
+
+	if _, err := store.Import(ctx, strings.NewReader(csv), mapping); err == nil {
+		t.Fatal("expected error for column that is not creatable, got nil")
+	}
+}
+
+func TestStoreImportExport(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	})
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	mm := []*M{
+		{ID: 1, Str: "one", BigStr: "big one", Int: 1, BigInt: 10, Bool: true, Blob: []byte{0, 1, 2}, Time: now},
+		{ID: 2, Str: "two, with comma", BigStr: "big two", Int: 2, BigInt: 20, Blob: []byte("blob"), Time: now},
+	}
+
+	if err := store.Create(ctx, mm...); err != nil {
+		t.Fatalf("store.Create(ctx, mm...): %v\n", err)
+	}
+
+	var buf bytes.Buffer
+
+	if err := store.Export(ctx, &buf, CSV, query.OrderAsc("id")); err != nil {
+		t.Fatalf("store.Export(ctx, &buf, CSV): %v\n", err)
+	}
+
+	if err := store.Truncate(ctx, false); err != nil {
+		t.Fatalf("store.Truncate(ctx, false): %v\n", err)
+	}
+
+	n, err := store.Import(ctx, &buf, nil)
+
+	if err != nil {
+		t.Fatalf("store.Import(ctx, &buf, nil): %v\n", err)
+	}
+
+	if n != int64(len(mm)) {
+		t.Fatalf("n = %v, want = %v\n", n, len(mm))
+	}
+
+	mm2, err := store.Select(ctx, query.Columns("*"), query.OrderAsc("id"))
+
+	if err != nil {
+		t.Fatalf("store.Select(ctx, query.Columns(\"*\")): %v\n", err)
+	}
+
+	for i, m := range mm2 {
+		want := mm[i]
+
+		if m.Str != want.Str || m.BigStr != want.BigStr || m.Int != want.Int || m.BigInt != want.BigInt || m.Bool != want.Bool {
+			t.Errorf("mm2[%d] = %+v, want = %+v\n", i, m, want)
+		}
+
+		if !bytes.Equal(m.Blob, want.Blob) {
+			t.Errorf("mm2[%d].Blob = %v, want = %v\n", i, m.Blob, want.Blob)
+		}
+
+		if !m.Time.Equal(want.Time) {
+			t.Errorf("mm2[%d].Time = %v, want = %v\n", i, m.Time, want.Time)
+		}
+	}
+}
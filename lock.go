@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// GetForUpdate is like [Store.Get], only the returned model is locked via a
+// FOR UPDATE clause until the end of the current transaction. This would be
+// used for read-modify-write flows, where the store is bound to a transaction
+// via [Store.With] or [Store.WithTx], for example,
+//
+//	err := posts.WithTx(ctx, func(posts *database.Store[*Post]) error {
+//	    p, ok, err := posts.GetForUpdate(ctx, query.WhereEq("id", query.Arg(id)))
+//
+//	    if err != nil {
+//	        return err
+//	    }
+//
+//	    if !ok {
+//	        return errors.New("post not found")
+//	    }
+//
+//	    p.Views++
+//
+//	    _, err = posts.Update(ctx, p)
+//	    return err
+//	})
+//
+// The query is always run against the primary database, and bypasses any
+// [Cache]. SQLite does not support row locks, as a write transaction locks the
+// entire database, so no clause is added for the [SQLite] dialect.
+func (s *Store[M]) GetForUpdate(ctx context.Context, opts ...query.Option) (M, bool, error) {
+	return s.getLocked(ctx, query.ForUpdate(), opts...)
+}
+
+// GetForUpdateSkipLocked is like [Store.GetForUpdate], only a model that is
+// already locked is skipped, rather than waited on. This is useful for
+// implementing work queues.
+func (s *Store[M]) GetForUpdateSkipLocked(ctx context.Context, opts ...query.Option) (M, bool, error) {
+	return s.getLocked(ctx, query.ForUpdateSkipLocked(), opts...)
+}
+
+// GetForUpdateNoWait is like [Store.GetForUpdate], only an error is returned
+// if the model is already locked, rather than waiting on it.
+func (s *Store[M]) GetForUpdateNoWait(ctx context.Context, opts ...query.Option) (M, bool, error) {
+	return s.getLocked(ctx, query.ForUpdateNoWait(), opts...)
+}
+
+func (s *Store[M]) getLocked(ctx context.Context, lock query.Option, opts ...query.Option) (M, bool, error) {
+	var zero M
+
+	opts = append(opts, query.Limit(1))
+
+	if s.cfg.dialect != SQLite {
+		opts = append(opts, lock)
+	}
+
+	mm := make([]M, 0, 1)
+
+	for m, err := range s.all(ReadPrimary(ctx), s.selectQuery(ctx, query.Columns("*"), opts...)) {
+		if err != nil {
+			return zero, false, err
+		}
+		mm = append(mm, m)
+	}
+
+	if len(mm) == 0 {
+		return zero, false, nil
+	}
+
+	if err := s.doPreload(ctx, mm); err != nil {
+		return zero, false, err
+	}
+	return mm[0], true, nil
+}
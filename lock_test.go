@@ -0,0 +1,74 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestStoreGetForUpdate(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	var rec eventRecorder
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	}, WithDialect(SQLite), WithLogger(&rec))
+
+	m := &M{
+		ID:   1,
+		Blob: []byte("blob"),
+		Time: time.Now(),
+	}
+
+	if err := store.Create(ctx, m); err != nil {
+		t.Fatalf("store.Create(ctx, m): %v\n", err)
+	}
+
+	err := store.WithTx(ctx, func(store *Store[*M]) error {
+		m, ok, err := store.GetForUpdate(ctx, query.WhereEq("id", query.Arg(1)))
+
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			t.Fatalf("store.GetForUpdate(ctx, ...): expected model, got none\n")
+		}
+
+		m.Str = "updated"
+
+		_, err = store.Update(ctx, m)
+		return err
+	})
+
+	if err != nil {
+		t.Fatalf("store.WithTx(ctx, ...): %v\n", err)
+	}
+
+	for _, ev := range rec.events {
+		if strings.Contains(ev.Query, "FOR UPDATE") {
+			t.Fatalf("ev.Query = %q, expected no FOR UPDATE for sqlite\n", ev.Query)
+		}
+	}
+
+	// Ensure the clause is added for other dialects.
+	store = NewStore[*M](db, func() *M {
+		return &M{}
+	}, WithDialect(Postgres), WithLogger(&rec))
+
+	store.GetForUpdateNoWait(ctx, query.WhereEq("id", query.Arg(1)))
+
+	ev := rec.events[len(rec.events)-1]
+
+	if !strings.HasSuffix(ev.Query, "FOR UPDATE NOWAIT") {
+		t.Fatalf("ev.Query = %q, expected FOR UPDATE NOWAIT\n", ev.Query)
+	}
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// QueryEvent describes a query that has been run by a [Store].
+type QueryEvent struct {
+	// Table is the table the query was run against.
+	Table string
+
+	// Op is the operation performed by the query.
+	Op Op
+
+	// Query is the built SQL code of the query.
+	Query string
+
+	// Args are the arguments bound to the query.
+	Args []any
+
+	// Duration is how long the query took to run.
+	Duration time.Duration
+
+	// Err is the error returned from running the query, if any.
+	Err error
+
+	// Slow is whether the query took longer than the threshold configured via
+	// [WithSlowQuery].
+	Slow bool
+
+	// Plan is the plan of the query, this is only set for slow queries if
+	// explaining them was configured via [WithSlowQuery].
+	Plan string
+}
+
+// Logger is the interface used by a [Store] for logging the queries it runs.
+//
+// LogQuery is called after every query run by the Store with the
+// [QueryEvent] describing that query.
+type Logger interface {
+	LogQuery(ctx context.Context, ev *QueryEvent)
+}
+
+// WithLogger configures the [Logger] a [Store] logs its queries to.
+func WithLogger(l Logger) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.logger = l
+	}
+}
+
+type slogLogger struct {
+	log *slog.Logger
+}
+
+// SlogLogger returns a [Logger] that logs queries to the given [slog.Logger].
+// Queries are logged at the debug level, unless the query was slow in which
+// case they are logged at the warn level along with any plan, or the query
+// failed in which case they are logged at the error level.
+func SlogLogger(l *slog.Logger) Logger {
+	return &slogLogger{
+		log: l,
+	}
+}
+
+func (l *slogLogger) LogQuery(ctx context.Context, ev *QueryEvent) {
+	level := slog.LevelDebug
+
+	attrs := []slog.Attr{
+		slog.String("table", ev.Table),
+		slog.String("op", ev.Op.String()),
+		slog.String("query", ev.Query),
+		slog.Any("args", ev.Args),
+		slog.Duration("duration", ev.Duration),
+	}
+
+	if ev.Slow {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.Bool("slow", true))
+
+		if ev.Plan != "" {
+			attrs = append(attrs, slog.String("plan", ev.Plan))
+		}
+	}
+
+	if ev.Err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.Any("error", ev.Err))
+	}
+	l.log.LogAttrs(ctx, level, "query", attrs...)
+}
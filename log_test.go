@@ -0,0 +1,203 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestSlogLogger(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	var buf strings.Builder
+
+	log := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	}, WithLogger(SlogLogger(log)))
+
+	m := &M{
+		ID:     1,
+		Str:    "string",
+		BigStr: "bigstring",
+		Blob:   []byte{},
+		Time:   time.Now(),
+	}
+
+	if err := store.Create(ctx, m); err != nil {
+		t.Fatalf("store.Create(ctx, m): %v\n", err)
+	}
+
+	store.Select(ctx, query.Columns("nonexistent"))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if len(lines) != 2 {
+		t.Fatalf("len(lines) = %v, want = %v\n%s", len(lines), 2, buf.String())
+	}
+
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{lines[0], []string{"level=DEBUG", "table=models", "op=create", `query="INSERT INTO models`, "duration="}},
+		{lines[1], []string{"level=ERROR", "op=select", `query="SELECT nonexistent FROM models"`, "error="}},
+	}
+
+	for _, test := range tests {
+		for _, want := range test.want {
+			if !strings.Contains(test.line, want) {
+				t.Errorf("%q does not contain %q\n", test.line, want)
+			}
+		}
+	}
+}
+
+type eventRecorder struct {
+	events []*QueryEvent
+}
+
+func (r *eventRecorder) LogQuery(_ context.Context, ev *QueryEvent) {
+	r.events = append(r.events, ev)
+}
+
+func TestSlowQuery(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	var rec eventRecorder
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	}, WithDialect(SQLite), WithLogger(&rec), WithSlowQuery(time.Nanosecond, true))
+
+	if _, err := store.Select(ctx, query.Columns("*"), query.WhereEq("str", query.Arg("string"))); err != nil {
+		t.Fatalf("store.Select(ctx, query.Columns(%q)): %v\n", "*", err)
+	}
+
+	if len(rec.events) != 1 {
+		t.Fatalf("len(rec.events) = %v, want = %v\n", len(rec.events), 1)
+	}
+
+	ev := rec.events[0]
+
+	if !ev.Slow {
+		t.Fatalf("ev.Slow = %v, want = %v\n", ev.Slow, true)
+	}
+
+	if !strings.Contains(ev.Plan, "SCAN models") {
+		t.Fatalf("ev.Plan = %q, want plan containing %q\n", ev.Plan, "SCAN models")
+	}
+
+	rec.events = rec.events[:0]
+
+	store = NewStore[*M](db, func() *M {
+		return &M{}
+	}, WithLogger(&rec), WithSlowQuery(time.Hour, true))
+
+	if _, err := store.Select(ctx, query.Columns("*")); err != nil {
+		t.Fatalf("store.Select(ctx, query.Columns(%q)): %v\n", "*", err)
+	}
+
+	if ev := rec.events[0]; ev.Slow || ev.Plan != "" {
+		t.Fatalf("ev.Slow, ev.Plan = %v, %q, want = %v, %q\n", ev.Slow, ev.Plan, false, "")
+	}
+}
+
+func TestSlowQueryPlanConn(t *testing.T) {
+	// The plan is captured on the connection that ran the query, so this
+	// would block on a single connection were the rows still open.
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	db := NewDB(t)
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	var rec eventRecorder
+
+	opts := []StoreOption{
+		WithDialect(SQLite),
+		WithLogger(&rec),
+		WithSlowQuery(time.Nanosecond, true),
+	}
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	}, opts...)
+
+	tx, err := db.BeginTx(ctx, nil)
+
+	if err != nil {
+		t.Fatalf("db.BeginTx(ctx, nil): %v\n", err)
+	}
+
+	if _, err := store.With(tx).Select(ctx, query.Columns("*")); err != nil {
+		t.Fatalf("store.With(tx).Select(ctx, query.Columns(%q)): %v\n", "*", err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("tx.Rollback(): %v\n", err)
+	}
+
+	if _, err := store.Select(ctx, query.Columns("*")); err != nil {
+		t.Fatalf("store.Select(ctx, query.Columns(%q)): %v\n", "*", err)
+	}
+
+	// The models table only exists on the replica, so the plan can only be
+	// captured if the query is explained on the replica that ran it.
+	replica, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "replica.sqlite"))
+
+	if err != nil {
+		t.Fatalf("sql.Open(%q, %q): %v\n", "sqlite", "replica.sqlite", err)
+	}
+
+	defer replica.Close()
+
+	if _, err := replica.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("replica.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	if _, err := db.ExecContext(ctx, "DROP TABLE models"); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", "DROP TABLE models", err)
+	}
+
+	store = NewStore[*M](db, func() *M {
+		return &M{}
+	}, append(opts, WithReplicas(replica))...)
+
+	if _, err := store.Select(ctx, query.Columns("*")); err != nil {
+		t.Fatalf("store.Select(ctx, query.Columns(%q)): %v\n", "*", err)
+	}
+
+	if len(rec.events) != 3 {
+		t.Fatalf("len(rec.events) = %v, want = %v\n", len(rec.events), 3)
+	}
+
+	for i, ev := range rec.events {
+		if !strings.Contains(ev.Plan, "SCAN models") {
+			t.Errorf("rec.events[%d].Plan = %q, want plan containing %q\n", i, ev.Plan, "SCAN models")
+		}
+	}
+}
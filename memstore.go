@@ -0,0 +1,839 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// MemoryStore is an in-memory implementation of [Storer], where models are
+// held in a map keyed on their [PrimaryKey]. This is intended for unit testing
+// code that depends on a Storer, without needing a database.
+//
+// Only a subset of queries are understood by a MemoryStore. The query options
+// given to it may only contain WHERE clauses conjoined with AND, using the
+// comparison operators, IN, NOT IN, IS NULL, and IS NOT NULL, with values given
+// via [query.Arg] or [query.List], followed by optional ORDER BY, LIMIT, and
+// OFFSET clauses. Columns are compared against the values returned from the
+// model's Params. Any other query results in an error.
+//
+// The models returned from a MemoryStore are the same models that were given
+// to it, and are not copied.
+type MemoryStore[M Model] struct {
+	mu     sync.Mutex
+	table  string
+	seq    int64
+	keys   []string
+	models map[string]M
+	clock  Clock
+	ids    IDGenerator
+}
+
+var _ Storer[Model] = (*MemoryStore[Model])(nil)
+
+// SetClock sets the [Clock] the store uses for getting the current time, as
+// per [WithClock].
+func (s *MemoryStore[M]) SetClock(c Clock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.clock = c
+}
+
+// SetIDGenerator sets the [IDGenerator] the store uses for the primary keys of
+// the models it creates, as per [WithIDGenerator].
+func (s *MemoryStore[M]) SetIDGenerator(gen IDGenerator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ids = gen
+}
+
+func (s *MemoryStore[M]) getClock() Clock {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.clock == nil {
+		return SystemClock
+	}
+	return s.clock
+}
+
+// NewMemoryStore returns a new [MemoryStore] containing the given models. The
+// given callback is used to determine the table of the models, as it is with
+// [NewStore].
+func NewMemoryStore[M Model](new func() M, mm ...M) *MemoryStore[M] {
+	s := &MemoryStore[M]{
+		table:  new().Table(),
+		models: make(map[string]M),
+	}
+
+	if err := s.Create(context.Background(), mm...); err != nil {
+		panic("database: " + err.Error())
+	}
+	return s
+}
+
+func memKey(pk *PrimaryKey) (string, error) {
+	vals := make([]any, 0, len(pk.Values))
+
+	for _, v := range pk.Values {
+		val, err := driver.DefaultParameterConverter.ConvertValue(v)
+
+		if err != nil {
+			return "", err
+		}
+		vals = append(vals, val)
+	}
+	return fmt.Sprint(vals...), nil
+}
+
+// nextId sets the primary key of the given model if the primary key is a
+// single integer column that has not been set, mimicking an auto-incrementing
+// primary key.
+func (s *MemoryStore[M]) nextId(m M, pk *PrimaryKey) error {
+	if len(pk.Values) != 1 {
+		return nil
+	}
+
+	rv := reflect.ValueOf(pk.Values[0])
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if id := rv.Int(); id != 0 {
+			s.seq = max(s.seq, id)
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	s.seq++
+
+	if err := setColumn(nil, m, pk.Columns[0], s.seq); err != nil {
+		return err
+	}
+	pk.Values[0] = s.seq
+	return nil
+}
+
+// Create stores the given models. If a model has a single primary key that is
+// zero, then it is given an id from the store's [IDGenerator], or the next id
+// in the sequence if no generator is set and the key is an integer. An error
+// is returned if a model with the same primary key already exists. Defaults
+// and transformers are applied, and models that implement [Validator] are
+// validated first, as per [Store.Create].
+func (s *MemoryStore[M]) Create(ctx context.Context, mm ...M) error {
+	s.mu.Lock()
+	gen := s.ids
+	s.mu.Unlock()
+
+	for _, m := range mm {
+		if err := generateID(m, gen, s.getClock(), nil); err != nil {
+			return err
+		}
+
+		if _, err := prepareParams(m, paramCreate, s.getClock(), nil); err != nil {
+			return err
+		}
+	}
+
+	if err := validate(ctx, mm...); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range mm {
+		pk := m.PrimaryKey()
+
+		if pk == nil {
+			return errors.New("model has no primary key")
+		}
+
+		if err := s.nextId(m, pk); err != nil {
+			return err
+		}
+
+		key, err := memKey(pk)
+
+		if err != nil {
+			return err
+		}
+
+		if _, ok := s.models[key]; ok {
+			return fmt.Errorf("duplicate primary key %v", pk.Values)
+		}
+
+		s.keys = append(s.keys, key)
+		s.models[key] = m
+	}
+	return nil
+}
+
+// All returns an iterator over the models that match the given query options.
+// The given [query.Expr] is ignored, as whole models are always returned.
+func (s *MemoryStore[M]) All(ctx context.Context, expr query.Expr, opts ...query.Option) iter.Seq2[M, error] {
+	return func(yield func(M, error) bool) {
+		mm, err := s.Select(ctx, expr, opts...)
+
+		if err != nil {
+			var zero M
+			yield(zero, err)
+			return
+		}
+
+		for _, m := range mm {
+			if !yield(m, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Select returns the models that match the given query options. The given
+// [query.Expr] is ignored, as whole models are always returned.
+func (s *MemoryStore[M]) Select(ctx context.Context, expr query.Expr, opts ...query.Option) ([]M, error) {
+	opts = append([]query.Option{
+		query.From(s.table),
+	}, opts...)
+
+	q := query.Select(expr, opts...)
+
+	mq, err := parseMemQuery(q.Build(), q.Args())
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mm := make([]M, 0)
+
+	for _, key := range s.keys {
+		m := s.models[key]
+
+		ok, err := mq.match(m)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			mm = append(mm, m)
+		}
+	}
+
+	if err := memSort(mq, mm); err != nil {
+		return nil, err
+	}
+
+	if mq.offset > 0 {
+		mm = mm[min(mq.offset, len(mm)):]
+	}
+
+	if mq.limit >= 0 {
+		mm = mm[:min(mq.limit, len(mm))]
+	}
+	return mm, nil
+}
+
+// SelectAll returns the models that match the given query options.
+func (s *MemoryStore[M]) SelectAll(ctx context.Context, opts ...query.Option) ([]M, error) {
+	return s.Select(ctx, query.Columns("*"), opts...)
+}
+
+// Get returns the first model that matches the given query options, and
+// whether or not it was found.
+func (s *MemoryStore[M]) Get(ctx context.Context, opts ...query.Option) (M, bool, error) {
+	return s.SelectOne(ctx, query.Columns("*"), opts...)
+}
+
+// SelectOne is like [MemoryStore.Get]. The given [query.Expr] is ignored, as
+// whole models are always returned.
+func (s *MemoryStore[M]) SelectOne(ctx context.Context, expr query.Expr, opts ...query.Option) (M, bool, error) {
+	var zero M
+
+	opts = append(opts, query.Limit(1))
+
+	mm, err := s.Select(ctx, expr, opts...)
+
+	if err != nil {
+		return zero, false, err
+	}
+
+	if len(mm) == 0 {
+		return zero, false, nil
+	}
+	return mm[0], true, nil
+}
+
+// GetOrErr is like [MemoryStore.Get], only a [NotFoundError] is returned if
+// the model cannot be found, as per [Store.GetOrErr].
+func (s *MemoryStore[M]) GetOrErr(ctx context.Context, opts ...query.Option) (M, error) {
+	m, ok, err := s.Get(ctx, opts...)
+
+	if err != nil {
+		return m, err
+	}
+
+	if !ok {
+		q := query.Select(query.Columns("*"), append([]query.Option{query.From(s.table)}, opts...)...)
+
+		return m, notFound(s.table, q, query.Dollar)
+	}
+	return m, nil
+}
+
+// Count returns the number of models that match the given query options.
+func (s *MemoryStore[M]) Count(ctx context.Context, opts ...query.Option) (int64, error) {
+	mm, err := s.Select(ctx, query.Columns("*"), opts...)
+
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(mm)), nil
+}
+
+type memResult int64
+
+func (r memResult) LastInsertId() (int64, error) {
+	return 0, errors.New("LastInsertId is not supported by memory store")
+}
+
+func (r memResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+// Update replaces the stored model with the same [PrimaryKey] as the given
+// model. Transformers are applied, and if the model implements [Validator],
+// then it is validated first.
+func (s *MemoryStore[M]) Update(ctx context.Context, m M) (sql.Result, error) {
+	if _, err := prepareParams(m, paramUpdate, s.getClock(), nil); err != nil {
+		return nil, err
+	}
+
+	if err := validate(ctx, m); err != nil {
+		return nil, err
+	}
+
+	pk := m.PrimaryKey()
+
+	if pk == nil {
+		return nil, errors.New("model has no primary key")
+	}
+
+	key, err := memKey(pk)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.models[key]; !ok {
+		return memResult(0), nil
+	}
+
+	s.models[key] = m
+	return memResult(1), nil
+}
+
+// UpdateMany sets the given fields on the models that match the given query
+// options. The fields are set on the models via reflection, in the same way a
+// [Scanner] maps columns to struct fields, once any transformers of the
+// fields' Params are applied.
+func (s *MemoryStore[M]) UpdateMany(ctx context.Context, fields map[string]any, opts ...query.Option) (sql.Result, error) {
+	mm, err := s.Select(ctx, query.Columns("*"), opts...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, m := range mm {
+		params := m.Params()
+
+		for col, v := range fields {
+			if p, ok := params[col]; ok {
+				if v, err = p.transform(col, v); err != nil {
+					return nil, err
+				}
+			}
+
+			if err := setColumn(nil, m, col, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return memResult(len(mm)), nil
+}
+
+// Delete removes the given models from the store, on each model's
+// [PrimaryKey].
+func (s *MemoryStore[M]) Delete(ctx context.Context, mm ...M) (sql.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+
+	for _, m := range mm {
+		pk := m.PrimaryKey()
+
+		if pk == nil {
+			return nil, errors.New("model has no primary key")
+		}
+
+		key, err := memKey(pk)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := s.models[key]; !ok {
+			continue
+		}
+
+		delete(s.models, key)
+		s.keys = slices.DeleteFunc(s.keys, func(k string) bool {
+			return k == key
+		})
+		n++
+	}
+	return memResult(n), nil
+}
+
+type memCond struct {
+	col  string
+	op   string
+	args []any
+}
+
+type memOrder struct {
+	col  string
+	desc bool
+}
+
+// memQuery is the parsed form of a query given to a [MemoryStore].
+type memQuery struct {
+	conds  []memCond
+	order  []memOrder
+	limit  int
+	offset int
+}
+
+var (
+	memCmpRe  = regexp.MustCompile(`^([\w.]+) (=|!=|>=|<=|>|<) \$(\d+)$`)
+	memInRe   = regexp.MustCompile(`^([\w.]+) (IN|NOT IN) \((.*)\)$`)
+	memNullRe = regexp.MustCompile(`^([\w.]+) (IS NULL|IS NOT NULL)$`)
+	memArgRe  = regexp.MustCompile(`^\$(\d+)$`)
+)
+
+func memArg(s string, args []any) (any, error) {
+	m := memArgRe.FindStringSubmatch(s)
+
+	if m == nil {
+		return nil, fmt.Errorf("unsupported value %s", s)
+	}
+
+	i, _ := strconv.Atoi(m[1])
+
+	if i < 1 || i > len(args) {
+		return nil, fmt.Errorf("missing argument %s", s)
+	}
+	return args[i-1], nil
+}
+
+// splitTop splits the given string on sep, ignoring any occurrences of sep
+// within parentheses.
+func splitTop(s, sep string) []string {
+	parts := make([]string, 0)
+
+	depth := 0
+	start := 0
+
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+
+		if depth == 0 && strings.HasPrefix(s[i:], sep) {
+			parts = append(parts, s[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func parseMemCond(s string, args []any) (memCond, error) {
+	if m := memCmpRe.FindStringSubmatch(s); m != nil {
+		arg, err := memArg("$"+m[3], args)
+
+		if err != nil {
+			return memCond{}, err
+		}
+		return memCond{col: m[1], op: m[2], args: []any{arg}}, nil
+	}
+
+	if m := memInRe.FindStringSubmatch(s); m != nil {
+		cond := memCond{col: m[1], op: m[2]}
+
+		for _, item := range strings.Split(m[3], ", ") {
+			arg, err := memArg(item, args)
+
+			if err != nil {
+				return memCond{}, err
+			}
+			cond.args = append(cond.args, arg)
+		}
+		return cond, nil
+	}
+
+	if m := memNullRe.FindStringSubmatch(s); m != nil {
+		return memCond{col: m[1], op: m[2]}, nil
+	}
+	return memCond{}, fmt.Errorf("unsupported condition %q", s)
+}
+
+func parseMemQuery(s string, args []any) (*memQuery, error) {
+	_, rest, ok := strings.Cut(s, " FROM ")
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported query %q", s)
+	}
+
+	mq := &memQuery{
+		limit: -1,
+	}
+
+	// Skip over the table name.
+	if i := strings.Index(rest, " "); i >= 0 {
+		rest = rest[i:]
+	} else {
+		rest = ""
+	}
+
+	if after, ok := strings.CutPrefix(rest, " WHERE "); ok {
+		// The WHERE clause is wrapped in parentheses, so find the closing
+		// parenthesis for the clause.
+		end := -1
+		depth := 0
+
+		for i := 0; i < len(after) && end < 0; i++ {
+			switch after[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+
+				if depth == 0 {
+					end = i + 1
+				}
+			}
+		}
+
+		if end < 0 {
+			return nil, fmt.Errorf("unsupported query %q", s)
+		}
+
+		where := after[:end]
+		rest = after[end:]
+
+		where = strings.TrimSuffix(strings.TrimPrefix(where, "("), ")")
+
+		if len(splitTop(where, " OR ")) > 1 {
+			return nil, fmt.Errorf("unsupported condition %q", where)
+		}
+
+		for _, cond := range splitTop(where, " AND ") {
+			c, err := parseMemCond(cond, args)
+
+			if err != nil {
+				return nil, err
+			}
+			mq.conds = append(mq.conds, c)
+		}
+	}
+
+	if after, ok := strings.CutPrefix(rest, " ORDER BY "); ok {
+		order := after
+		rest = ""
+
+		for _, kw := range []string{" LIMIT ", " OFFSET "} {
+			if i := strings.Index(order, kw); i >= 0 {
+				rest = order[i:]
+				order = order[:i]
+				break
+			}
+		}
+
+		var desc bool
+
+		cols := strings.Split(order, ", ")
+
+		// Columns without a direction take the direction of the next column
+		// that has one, so work backwards.
+		for i := len(cols) - 1; i >= 0; i-- {
+			col := cols[i]
+
+			if c, ok := strings.CutSuffix(col, " DESC"); ok {
+				col = c
+				desc = true
+			} else if c, ok := strings.CutSuffix(col, " ASC"); ok {
+				col = c
+				desc = false
+			}
+			mq.order = append([]memOrder{{col: col, desc: desc}}, mq.order...)
+		}
+	}
+
+	if after, ok := strings.CutPrefix(rest, " LIMIT "); ok {
+		n, tail, _ := strings.Cut(after, " ")
+
+		i, err := strconv.Atoi(n)
+
+		if err != nil {
+			return nil, fmt.Errorf("unsupported limit %q", n)
+		}
+
+		mq.limit = i
+		rest = ""
+
+		if tail != "" {
+			rest = " " + tail
+		}
+	}
+
+	if after, ok := strings.CutPrefix(rest, " OFFSET "); ok {
+		i, err := strconv.Atoi(after)
+
+		if err != nil {
+			return nil, fmt.Errorf("unsupported offset %q", after)
+		}
+
+		mq.offset = i
+		rest = ""
+	}
+
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("unsupported query %q", s)
+	}
+	return mq, nil
+}
+
+func memValue(m Model, col string) (any, error) {
+	if i := strings.LastIndex(col, "."); i >= 0 {
+		col = col[i+1:]
+	}
+
+	p, ok := m.Params()[col]
+
+	if !ok {
+		return nil, fmt.Errorf("unknown column %s", col)
+	}
+	return driver.DefaultParameterConverter.ConvertValue(p.value)
+}
+
+// memCompare compares the two values, returning false if they are of types
+// that cannot be compared.
+func memCompare(a, b any) (int, bool) {
+	switch a := a.(type) {
+	case int64:
+		switch b := b.(type) {
+		case int64:
+			return cmpOrdered(a, b), true
+		case float64:
+			return cmpOrdered(float64(a), b), true
+		}
+	case float64:
+		switch b := b.(type) {
+		case int64:
+			return cmpOrdered(a, float64(b)), true
+		case float64:
+			return cmpOrdered(a, b), true
+		}
+	case string:
+		switch b := b.(type) {
+		case string:
+			return strings.Compare(a, b), true
+		case []byte:
+			return strings.Compare(a, string(b)), true
+		}
+	case []byte:
+		switch b := b.(type) {
+		case string:
+			return bytes.Compare(a, []byte(b)), true
+		case []byte:
+			return bytes.Compare(a, b), true
+		}
+	case bool:
+		if b, ok := b.(bool); ok {
+			if a == b {
+				return 0, true
+			}
+			if !a {
+				return -1, true
+			}
+			return 1, true
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b), true
+		}
+	}
+	return 0, false
+}
+
+func cmpOrdered[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (mq *memQuery) match(m Model) (bool, error) {
+	for _, cond := range mq.conds {
+		val, err := memValue(m, cond.col)
+
+		if err != nil {
+			return false, err
+		}
+
+		switch cond.op {
+		case "IS NULL":
+			if val != nil {
+				return false, nil
+			}
+			continue
+		case "IS NOT NULL":
+			if val == nil {
+				return false, nil
+			}
+			continue
+		case "IN", "NOT IN":
+			found := false
+
+			for _, arg := range cond.args {
+				arg, err := driver.DefaultParameterConverter.ConvertValue(arg)
+
+				if err != nil {
+					return false, err
+				}
+
+				if c, ok := memCompare(val, arg); ok && c == 0 {
+					found = true
+					break
+				}
+			}
+
+			if found != (cond.op == "IN") {
+				return false, nil
+			}
+			continue
+		}
+
+		arg, err := driver.DefaultParameterConverter.ConvertValue(cond.args[0])
+
+		if err != nil {
+			return false, err
+		}
+
+		c, ok := memCompare(val, arg)
+
+		if !ok {
+			return false, nil
+		}
+
+		var match bool
+
+		switch cond.op {
+		case "=":
+			match = c == 0
+		case "!=":
+			match = c != 0
+		case ">":
+			match = c > 0
+		case ">=":
+			match = c >= 0
+		case "<":
+			match = c < 0
+		case "<=":
+			match = c <= 0
+		}
+
+		if !match {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func memSort[M Model](mq *memQuery, mm []M) error {
+	if len(mq.order) == 0 {
+		return nil
+	}
+
+	type sortable struct {
+		m    M
+		vals []any
+	}
+
+	ss := make([]sortable, 0, len(mm))
+
+	for _, m := range mm {
+		vals := make([]any, 0, len(mq.order))
+
+		for _, o := range mq.order {
+			val, err := memValue(m, o.col)
+
+			if err != nil {
+				return err
+			}
+			vals = append(vals, val)
+		}
+		ss = append(ss, sortable{m: m, vals: vals})
+	}
+
+	slices.SortStableFunc(ss, func(a, b sortable) int {
+		for i, o := range mq.order {
+			c, _ := memCompare(a.vals[i], b.vals[i])
+
+			if o.desc {
+				c = -c
+			}
+
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+
+	for i, s := range ss {
+		mm[i] = s.m
+	}
+	return nil
+}
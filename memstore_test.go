@@ -0,0 +1,118 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := t.Context()
+
+	var store Storer[*M] = NewMemoryStore(func() *M {
+		return &M{}
+	}, &M{ID: 10, Str: "foo", Int: 3})
+
+	mm := []*M{
+		{Str: "bar", Int: 1},
+		{Str: "baz", Int: 2},
+	}
+
+	if err := store.Create(ctx, mm...); err != nil {
+		t.Fatalf("store.Create(ctx, mm...): %v\n", err)
+	}
+
+	if mm[0].ID != 11 {
+		t.Fatalf("mm[0].ID = %v, want = %v\n", mm[0].ID, 11)
+	}
+
+	if err := store.Create(ctx, &M{ID: 10}); err == nil {
+		t.Fatalf("store.Create(ctx, &M{ID: 10}): expected error, got nil\n")
+	}
+
+	m, ok, err := store.Get(ctx, query.WhereEq("str", query.Arg("baz")))
+
+	if err != nil {
+		t.Fatalf("store.Get(ctx, ...): %v\n", err)
+	}
+
+	if !ok {
+		t.Fatalf("store.Get(ctx, ...): expected model, got none\n")
+	}
+
+	if m.ID != 12 {
+		t.Fatalf("m.ID = %v, want = %v\n", m.ID, 12)
+	}
+
+	if _, err := NewMemoryStore(func() *M { return &M{} }).GetOrErr(ctx); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("store.GetOrErr(ctx) = %v, want = %v\n", err, ErrNotFound)
+	}
+
+	tests := []struct {
+		opts []query.Option
+		want []int64
+	}{
+		{nil, []int64{10, 11, 12}},
+		{[]query.Option{query.OrderAsc("int")}, []int64{11, 12, 10}},
+		{[]query.Option{query.WhereGt("int", query.Arg(1)), query.OrderDesc("id")}, []int64{12, 10}},
+		{[]query.Option{query.WhereIn("str", query.List("foo", "bar"))}, []int64{10, 11}},
+		{[]query.Option{query.OrderAsc("id"), query.Limit(1), query.Offset(1)}, []int64{11}},
+	}
+
+	for i, test := range tests {
+		mm, err := store.Select(ctx, query.Columns("*"), test.opts...)
+
+		if err != nil {
+			t.Fatalf("tests[%d] - store.Select(ctx, ...): %v\n", i, err)
+		}
+
+		ids := make([]int64, 0, len(mm))
+
+		for _, m := range mm {
+			ids = append(ids, m.ID)
+		}
+
+		if len(ids) != len(test.want) {
+			t.Fatalf("tests[%d] - ids = %v, want = %v\n", i, ids, test.want)
+		}
+
+		for j := range ids {
+			if ids[j] != test.want[j] {
+				t.Fatalf("tests[%d] - ids = %v, want = %v\n", i, ids, test.want)
+			}
+		}
+	}
+
+	if _, err := store.Select(ctx, query.Columns("*"), query.OrWhereEq("id", query.Arg(1)), query.OrWhereEq("id", query.Arg(2))); err == nil {
+		t.Fatalf("store.Select(ctx, ...): expected error, got nil\n")
+	}
+
+	if _, err := store.UpdateMany(ctx, map[string]any{"str": "updated"}, query.WhereLt("id", query.Arg(12))); err != nil {
+		t.Fatalf("store.UpdateMany(ctx, ...): %v\n", err)
+	}
+
+	if mm[0].Str != "updated" {
+		t.Fatalf("mm[0].Str = %v, want = %v\n", mm[0].Str, "updated")
+	}
+
+	res, err := store.Delete(ctx, mm...)
+
+	if err != nil {
+		t.Fatalf("store.Delete(ctx, mm...): %v\n", err)
+	}
+
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Fatalf("res.RowsAffected() = %v, want = %v\n", n, 2)
+	}
+
+	mm, err = store.Select(ctx, query.Columns("*"))
+
+	if err != nil {
+		t.Fatalf("store.Select(ctx, ...): %v\n", err)
+	}
+
+	if len(mm) != 1 {
+		t.Fatalf("len(mm) = %v, want = %v\n", len(mm), 1)
+	}
+}
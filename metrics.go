@@ -0,0 +1,237 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// Op represents an operation performed by a [Store].
+type Op uint
+
+//go:generate stringer -type Op -linecomment
+const (
+	OpCreate Op = iota + 1 // create
+	OpSelect               // select
+	OpUpdate               // update
+	OpDelete               // delete
+)
+
+// Metrics is the interface used for recording metrics about the operations
+// performed by a [Store].
+//
+// Observe is called after every query run by the Store, with the table and
+// [Op] of the query, how long the query took to run, and any error that
+// occurred.
+type Metrics interface {
+	Observe(table string, op Op, d time.Duration, err error)
+}
+
+// WithMetrics configures the [Metrics] a [Store] records its operations to.
+func WithMetrics(m Metrics) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.metrics = m
+	}
+}
+
+// PoolMetrics is the interface used for recording the statistics of the
+// connection pool of a [Store]. If the [Metrics] of a Store also implements
+// PoolMetrics, then ObservePool is given the statistics of the pool as sampled
+// via [WithPoolStats].
+type PoolMetrics interface {
+	ObservePool(stats sql.DBStats)
+}
+
+// WithPoolStats configures a [Store] to sample the statistics of its
+// connection pool at most once per the given interval, and record them to its
+// [Metrics] if it implements [PoolMetrics]. This makes pool exhaustion, such as
+// a rising wait count, visible alongside the metrics of each query.
+//
+// The pool is sampled after a query is run, so no background goroutine is
+// needed, but no samples are taken whilst the Store is idle. The pool is only
+// sampled if the Store was created with a [sql.DB], and copies of the Store
+// made via [Store.With] sample the same pool.
+func WithPoolStats(interval time.Duration) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.pool = &poolSampler{
+			interval: interval,
+		}
+	}
+}
+
+// poolSampler samples the statistics of a connection pool at most once per
+// interval.
+type poolSampler struct {
+	db       *sql.DB
+	interval time.Duration
+
+	// last is the time of the last sample, in nanoseconds since the Unix
+	// epoch.
+	last atomic.Int64
+}
+
+func (p *poolSampler) sample(m PoolMetrics) {
+	now := time.Now().UnixNano()
+	last := p.last.Load()
+
+	if now-last < int64(p.interval) || !p.last.CompareAndSwap(last, now) {
+		return
+	}
+	m.ObservePool(p.db.Stats())
+}
+
+// observe records the given query that was run against the given DB. If the
+// query is slow, and the store is configured to explain slow queries, then
+// the plan is captured on the same DB, so this must only be called once any
+// rows returned from the query are closed. The plan is not captured if
+// explain is false.
+func (s *Store[M]) observe(ctx context.Context, db DB, table string, op Op, q *query.Query, d time.Duration, err error, explain bool) {
+	if s.cfg.metrics != nil {
+		s.cfg.metrics.Observe(table, op, d, err)
+
+		if pm, ok := s.cfg.metrics.(PoolMetrics); ok && s.cfg.pool != nil && s.cfg.pool.db != nil {
+			s.cfg.pool.sample(pm)
+		}
+	}
+
+	if s.cfg.stats != nil {
+		s.cfg.stats.observe(table, op, q, d, err)
+	}
+
+	if s.cfg.logger != nil {
+		ev := &QueryEvent{
+			Table:    table,
+			Op:       op,
+			Query:    s.build(q),
+			Args:     q.Args(),
+			Duration: d,
+			Err:      err,
+			Slow:     s.isSlow(d),
+		}
+
+		if ev.Slow && s.cfg.explainSlow && explain && err == nil {
+			plan, err := s.explain(ctx, db, op, q)
+
+			if err != nil {
+				plan = "explain failed: " + err.Error()
+			}
+			ev.Plan = plan
+		}
+		s.cfg.logger.LogQuery(ctx, ev)
+	}
+}
+
+// build builds the given query with the placeholders of the store's
+// [Dialect].
+func (s *Store[M]) build(q *query.Query) string {
+	return q.BuildWith(s.cfg.dialect.placeholder())
+}
+
+// exec runs the given query against the given table, recording the operation.
+// The query is retried as per the store's [RetryPolicy].
+func (s *Store[M]) exec(ctx context.Context, table string, op Op, q *query.Query) (sql.Result, error) {
+	var res sql.Result
+
+	err := s.retry(ctx, func() error {
+		start := time.Now()
+
+		var err error
+		res, err = s.ExecContext(ctx, s.build(q), q.Args()...)
+
+		s.observe(ctx, s.DB, table, op, q, time.Since(start), err, true)
+		return err
+	})
+
+	if err == nil {
+		wrote(ctx)
+		s.invalidate(table)
+
+		if s.cfg.stats != nil {
+			if n, err := res.RowsAffected(); err == nil {
+				s.cfg.stats.addRows(table, op, q, n)
+			}
+		}
+	}
+	return res, err
+}
+
+// storeRows are the rows returned from a query run via [Store.query]. The
+// query is observed once the rows are closed, so the plan of a slow query is
+// not captured on a connection that is still busy with the rows.
+type storeRows struct {
+	*sql.Rows
+
+	observe func(explain bool)
+}
+
+// Close closes the rows, and observes the query that returned them.
+func (r *storeRows) Close() error {
+	err := r.Rows.Close()
+	r.done(true)
+	return err
+}
+
+// detach observes the query without capturing its plan, and returns the rows
+// as is. This is used when the rows are given to the caller, since it cannot
+// be known when they are closed.
+func (r *storeRows) detach() *sql.Rows {
+	r.done(false)
+	return r.Rows
+}
+
+func (r *storeRows) done(explain bool) {
+	if r.observe != nil {
+		r.observe(explain)
+		r.observe = nil
+	}
+}
+
+// query runs the given query against the given table, recording the operation
+// once the returned rows are closed. The query is retried as per the store's
+// [RetryPolicy]. Selects are routed to any of the store's replicas, whereas
+// writes, such as an INSERT with a RETURNING clause, are not.
+func (s *Store[M]) query(ctx context.Context, table string, op Op, q *query.Query) (*storeRows, error) {
+	var (
+		rows *sql.Rows
+		d    time.Duration
+	)
+
+	db := s.DB
+
+	if op == OpSelect {
+		db = s.reader(ctx)
+	}
+
+	err := s.retry(ctx, func() error {
+		start := time.Now()
+
+		var err error
+		rows, err = db.QueryContext(ctx, s.build(q), q.Args()...)
+
+		d = time.Since(start)
+
+		if err != nil {
+			s.observe(ctx, db, table, op, q, d, err, false)
+		}
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if op != OpSelect {
+		wrote(ctx)
+		s.invalidate(table)
+	}
+
+	return &storeRows{
+		Rows: rows,
+		observe: func(explain bool) {
+			s.observe(ctx, db, table, op, q, d, nil, explain)
+		},
+	}, nil
+}
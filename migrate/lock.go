@@ -0,0 +1,193 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/andrewpillar/database"
+)
+
+// Option is a function that configures how migrations are run via [Migrate]
+// and [Rollback].
+type Option func(*config)
+
+type config struct {
+	dialect database.Dialect
+	clock   database.Clock
+}
+
+// WithDialect configures the [database.Dialect] of the database the migrations
+// are run against. This determines how the migration lock is taken, and the
+// placeholders used when recording applied migrations.
+func WithDialect(d database.Dialect) Option {
+	return func(cfg *config) {
+		cfg.dialect = d
+	}
+}
+
+// WithClock configures the [database.Clock] used for timestamping when each
+// migration was applied.
+func WithClock(c database.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = c
+	}
+}
+
+func newConfig(opts []Option) *config {
+	cfg := config{
+		clock: database.SystemClock,
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &cfg
+}
+
+// LockTable is the name of the table used for locking migrations for
+// databases that do not support advisory locks.
+const LockTable = Table + "_lock"
+
+const lockSchema = `CREATE TABLE IF NOT EXISTS ` + LockTable + ` (
+	id        INTEGER NOT NULL,
+	locked_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+// lockPoll is how often an attempt is made to take the lock row whilst it is
+// held by another runner.
+const lockPoll = 100 * time.Millisecond
+
+// lockKey is the key of the advisory lock taken for [database.Postgres].
+var lockKey = func() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(Table))
+	return int64(h.Sum64())
+}()
+
+// lockRow is the row in the lock table that is held whilst migrations are run.
+type lockRow struct {
+	ID       int64
+	LockedAt time.Time
+}
+
+func (l *lockRow) Table() string { return LockTable }
+
+func (l *lockRow) PrimaryKey() *database.PrimaryKey {
+	return &database.PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{l.ID},
+	}
+}
+
+func (l *lockRow) Params() database.Params {
+	return database.Params{
+		"id":        database.CreateOnlyParam(l.ID),
+		"locked_at": database.CreateOnlyParam(l.LockedAt),
+	}
+}
+
+// withLock calls fn whilst holding the migration lock, so that multiple
+// runners starting at the same time do not race each other. If the given
+// database is a [sql.DB], then a single connection is taken from it for the
+// duration of fn, since an advisory lock is held by the connection that took
+// it.
+//
+// For [database.Postgres] the lock is taken via pg_advisory_lock. For every
+// other dialect, a row is inserted into the [LockTable], and deleted once fn
+// returns. If the runner holding the lock row dies, then the row will need to
+// be deleted manually before migrations can be run again.
+func withLock(ctx context.Context, db DB, cfg *config, fn func(db DB) error) error {
+	if sqldb, ok := db.(*sql.DB); ok {
+		conn, err := sqldb.Conn(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		defer conn.Close()
+
+		db = conn
+	}
+
+	var (
+		unlock func(ctx context.Context) error
+		err    error
+	)
+
+	if cfg.dialect == database.Postgres {
+		unlock, err = advisoryLock(ctx, db)
+	} else {
+		unlock, err = rowLock(ctx, db, cfg)
+	}
+
+	if err != nil {
+		return fmt.Errorf("lock migrations: %w", err)
+	}
+
+	err = fn(db)
+
+	// Release the lock even if the context has been cancelled.
+	if unlockErr := unlock(context.WithoutCancel(ctx)); unlockErr != nil {
+		return errors.Join(err, fmt.Errorf("unlock migrations: %w", unlockErr))
+	}
+	return err
+}
+
+func advisoryLock(ctx context.Context, db DB) (func(ctx context.Context) error, error) {
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("SELECT pg_advisory_lock(%d)", lockKey)); err != nil {
+		return nil, err
+	}
+
+	unlock := func(ctx context.Context) error {
+		_, err := db.ExecContext(ctx, fmt.Sprintf("SELECT pg_advisory_unlock(%d)", lockKey))
+		return err
+	}
+	return unlock, nil
+}
+
+func rowLock(ctx context.Context, db DB, cfg *config) (func(ctx context.Context) error, error) {
+	if _, err := db.ExecContext(ctx, lockSchema); err != nil {
+		return nil, err
+	}
+
+	store := database.NewStore(db, func() *lockRow {
+		return &lockRow{}
+	}, database.WithDialect(cfg.dialect))
+
+	row := &lockRow{
+		ID: 1,
+	}
+
+	for {
+		row.LockedAt = cfg.clock.Now().UTC()
+
+		// The insert is ignored if it conflicts with the lock row of another
+		// runner, so any error returned is not due to the lock being held.
+		n, err := store.CreateIgnore(ctx, row)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if n > 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockPoll):
+		}
+	}
+
+	unlock := func(ctx context.Context) error {
+		_, err := store.Delete(ctx, row)
+		return err
+	}
+	return unlock, nil
+}
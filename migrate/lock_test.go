@@ -0,0 +1,174 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database"
+)
+
+func TestMigrateConcurrent(t *testing.T) {
+	ctx := t.Context()
+	db := openDB(t)
+
+	mm, err := Load(migrations, "migrations")
+
+	if err != nil {
+		t.Fatalf("Load(migrations, %q): %v\n", "migrations", err)
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		total int
+		errs  []error
+	)
+
+	for range 4 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ran, err := Migrate(ctx, db, mm)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			total += len(ran)
+
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		t.Fatalf("Migrate(ctx, db, mm): %v\n", err)
+	}
+
+	if total != len(mm) {
+		t.Fatalf("total = %v, want = %v\n", total, len(mm))
+	}
+}
+
+func TestMigrateLocked(t *testing.T) {
+	ctx := t.Context()
+	db := openDB(t)
+
+	mm, err := Load(migrations, "migrations")
+
+	if err != nil {
+		t.Fatalf("Load(migrations, %q): %v\n", "migrations", err)
+	}
+
+	if _, err := db.ExecContext(ctx, lockSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", lockSchema, err)
+	}
+
+	// Simulate another runner holding the lock.
+	if _, err := db.ExecContext(ctx, "INSERT INTO "+LockTable+" (id, locked_at) VALUES (1, CURRENT_TIMESTAMP)"); err != nil {
+		t.Fatalf("db.ExecContext(ctx, ...): %v\n", err)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 3*lockPoll)
+	defer cancel()
+
+	if _, err := Migrate(timeout, db, mm); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Migrate(timeout, db, mm): %v, want = %v\n", err, context.DeadlineExceeded)
+	}
+
+	go func() {
+		time.Sleep(2 * lockPoll)
+		db.ExecContext(ctx, "DELETE FROM "+LockTable)
+	}()
+
+	ran, err := Migrate(ctx, db, mm)
+
+	if err != nil {
+		t.Fatalf("Migrate(ctx, db, mm): %v\n", err)
+	}
+
+	if len(ran) != len(mm) {
+		t.Fatalf("len(ran) = %v, want = %v\n", len(ran), len(mm))
+	}
+
+	var n int
+
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+LockTable).Scan(&n); err != nil {
+		t.Fatalf("db.QueryRowContext(ctx, ...).Scan(&n): %v\n", err)
+	}
+
+	if n != 0 {
+		t.Fatalf("n = %v, want = %v\n", n, 0)
+	}
+}
+
+func TestRowLockDialect(t *testing.T) {
+	ctx := t.Context()
+
+	var rec execRecorder
+
+	unlock, err := rowLock(ctx, &rec, newConfig([]Option{WithDialect(database.MySQL)}))
+
+	if err != nil {
+		t.Fatalf("rowLock(ctx, &rec, cfg): %v\n", err)
+	}
+
+	if err := unlock(ctx); err != nil {
+		t.Fatalf("unlock(ctx): %v\n", err)
+	}
+
+	want := []string{
+		lockSchema,
+		"INSERT IGNORE INTO " + LockTable + " (id, locked_at) VALUES (?, ?)",
+		"DELETE FROM " + LockTable + " WHERE ((id) IN (?))",
+	}
+
+	if len(rec.queries) != len(want) {
+		t.Fatalf("rec.queries = %q, want = %q\n", rec.queries, want)
+	}
+
+	for i, q := range rec.queries {
+		if q != want[i] {
+			t.Fatalf("rec.queries[%d] = %q, want = %q\n", i, q, want[i])
+		}
+	}
+}
+
+func TestMigrateLockError(t *testing.T) {
+	ctx := t.Context()
+	db := openDB(t)
+
+	mm, err := Load(migrations, "migrations")
+
+	if err != nil {
+		t.Fatalf("Load(migrations, %q): %v\n", "migrations", err)
+	}
+
+	// A lock table the lock row cannot be inserted into, so taking the lock
+	// fails for a reason other than it being held.
+	schema := "CREATE TABLE " + LockTable + " (id INTEGER NOT NULL, locked_at TIMESTAMP NOT NULL, owner TEXT NOT NULL)"
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", schema, err)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 3*lockPoll)
+	defer cancel()
+
+	_, err = Migrate(timeout, db, mm)
+
+	if err == nil {
+		t.Fatalf("Migrate(timeout, db, mm): expected error, got nil\n")
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Migrate(timeout, db, mm): %v, want lock error\n", err)
+	}
+}
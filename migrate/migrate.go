@@ -0,0 +1,401 @@
+// Package migrate provides versioned schema migrations. Migrations are loaded
+// from a filesystem, typically an [embed.FS], via [Load], and are applied and
+// rolled back via [Migrate] and [Rollback]. The migrations that have been
+// applied are tracked in a schema_migrations table, the state of which can be
+// inspected via [Status].
+//
+// Each migration is applied within its own transaction, alongside the
+// recording of the migration in the tracking table. Be aware that MySQL
+// implicitly commits the transaction on most DDL statements, so a migration
+// that fails part way through may need manually cleaning up.
+//
+// Migrations are run whilst holding a lock, so that multiple instances of an
+// application starting at the same time do not race each other. For
+// PostgreSQL an advisory lock is used, otherwise a row in the
+// schema_migrations_lock table is used.
+package migrate
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewpillar/database"
+	"github.com/andrewpillar/database/query"
+)
+
+// Migration is a single versioned migration. The Up SQL applies the
+// migration, and the Down SQL reverts it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load loads the migrations from the given directory of the filesystem. Each
+// migration is a pair of files named after the version and name of the
+// migration, for example,
+//
+//	0001_create_users.up.sql
+//	0001_create_users.down.sql
+//
+// The down file is optional, however a migration without one cannot be rolled
+// back. Files without an .up.sql or .down.sql suffix are ignored. The returned
+// migrations are sorted by version.
+func Load(fsys fs.FS, dir string) ([]*Migration, error) {
+	ents, err := fs.ReadDir(fsys, dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[int64]*Migration)
+
+	for _, ent := range ents {
+		if ent.IsDir() {
+			continue
+		}
+
+		name := ent.Name()
+
+		var up bool
+
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			up = true
+			name = strings.TrimSuffix(name, ".up.sql")
+		case strings.HasSuffix(name, ".down.sql"):
+			name = strings.TrimSuffix(name, ".down.sql")
+		default:
+			continue
+		}
+
+		num, label, _ := strings.Cut(name, "_")
+
+		version, err := strconv.ParseInt(num, 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %q: %w", ent.Name(), err)
+		}
+
+		b, err := fs.ReadFile(fsys, path.Join(dir, ent.Name()))
+
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := versions[version]
+
+		if !ok {
+			m = &Migration{
+				Version: version,
+				Name:    label,
+			}
+			versions[version] = m
+		}
+
+		if m.Name != label {
+			return nil, fmt.Errorf("migration version %d has conflicting names %q and %q", version, m.Name, label)
+		}
+
+		if up {
+			m.Up = string(b)
+		} else {
+			m.Down = string(b)
+		}
+	}
+
+	mm := make([]*Migration, 0, len(versions))
+
+	for _, m := range versions {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration version %d has no up migration", m.Version)
+		}
+		mm = append(mm, m)
+	}
+
+	slices.SortFunc(mm, func(a, b *Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return mm, nil
+}
+
+// DB is the interface that wraps the methods used for running migrations. This
+// is satisfied by [sql.DB] and [sql.Conn].
+type DB interface {
+	database.DB
+	database.Beginner
+}
+
+// Table is the name of the table used for tracking applied migrations.
+const Table = "schema_migrations"
+
+const tableSchema = `CREATE TABLE IF NOT EXISTS ` + Table + ` (
+	version    BIGINT NOT NULL,
+	name       VARCHAR(255) NOT NULL,
+	applied_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (version)
+);`
+
+// record is the row of an applied migration in the tracking table.
+type record struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+func (r *record) Table() string { return Table }
+
+func (r *record) PrimaryKey() *database.PrimaryKey {
+	return &database.PrimaryKey{
+		Columns: []string{"version"},
+		Values:  []any{r.Version},
+	}
+}
+
+func (r *record) Params() database.Params {
+	return database.Params{
+		"version":    database.CreateOnlyParam(r.Version),
+		"name":       database.CreateOnlyParam(r.Name),
+		"applied_at": database.CreateOnlyParam(r.AppliedAt),
+	}
+}
+
+func newRecordStore(db database.DB, cfg *config) *database.Store[*record] {
+	return database.NewStore(db, func() *record {
+		return &record{}
+	}, database.WithDialect(cfg.dialect))
+}
+
+// applied returns the records of the applied migrations, ordered by version.
+// The tracking table is created if it does not exist.
+func applied(ctx context.Context, db DB, cfg *config) ([]*record, error) {
+	if _, err := db.ExecContext(ctx, tableSchema); err != nil {
+		return nil, err
+	}
+	return newRecordStore(db, cfg).SelectAll(ctx, query.OrderAsc("version"))
+}
+
+func validate(mm []*Migration) error {
+	seen := make(map[int64]struct{}, len(mm))
+
+	for _, m := range mm {
+		if _, ok := seen[m.Version]; ok {
+			return fmt.Errorf("duplicate migration version %d", m.Version)
+		}
+		seen[m.Version] = struct{}{}
+	}
+	return nil
+}
+
+// Migrate applies every migration that has not yet been applied, in order of
+// version. The migrations that were applied are returned. If a migration fails,
+// then the migrations applied before it remain applied, and are returned along
+// with the error.
+//
+// The migrations are run whilst holding a lock, so multiple instances of an
+// application starting at the same time do not race each other applying
+// migrations. The lock taken depends on the [database.Dialect] configured via
+// [WithDialect].
+func Migrate(ctx context.Context, db DB, mm []*Migration, opts ...Option) ([]*Migration, error) {
+	if err := validate(mm); err != nil {
+		return nil, err
+	}
+
+	var ran []*Migration
+
+	cfg := newConfig(opts)
+
+	err := withLock(ctx, db, cfg, func(db DB) error {
+		var err error
+
+		ran, err = migrate(ctx, db, mm, cfg)
+		return err
+	})
+	return ran, err
+}
+
+func migrate(ctx context.Context, db DB, mm []*Migration, cfg *config) ([]*Migration, error) {
+	rr, err := applied(ctx, db, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(map[int64]struct{}, len(rr))
+
+	for _, r := range rr {
+		done[r.Version] = struct{}{}
+	}
+
+	pending := make([]*Migration, 0, len(mm))
+
+	for _, m := range mm {
+		if _, ok := done[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+
+	slices.SortFunc(pending, func(a, b *Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	ran := make([]*Migration, 0, len(pending))
+
+	for _, m := range pending {
+		err := database.Tx(ctx, db, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+				return err
+			}
+
+			r := &record{
+				Version:   m.Version,
+				Name:      m.Name,
+				AppliedAt: cfg.clock.Now().UTC(),
+			}
+			return newRecordStore(tx, cfg).Create(ctx, r)
+		})
+
+		if err != nil {
+			return ran, fmt.Errorf("migration %d %s: %w", m.Version, m.Name, err)
+		}
+		ran = append(ran, m)
+	}
+	return ran, nil
+}
+
+// ErrNoDown is returned by [Rollback] when a migration that needs rolling back
+// has no down migration.
+var ErrNoDown = errors.New("no down migration")
+
+// Rollback reverts the n most recently applied migrations, in reverse order of
+// version. The migrations that were rolled back are returned. If a migration
+// that has been applied is not in the given migrations, or has no down
+// migration, then an error is returned. The migrations are rolled back whilst
+// holding a lock, as per [Migrate].
+func Rollback(ctx context.Context, db DB, mm []*Migration, n int, opts ...Option) ([]*Migration, error) {
+	if err := validate(mm); err != nil {
+		return nil, err
+	}
+
+	var reverted []*Migration
+
+	cfg := newConfig(opts)
+
+	err := withLock(ctx, db, cfg, func(db DB) error {
+		var err error
+
+		reverted, err = rollback(ctx, db, mm, n, cfg)
+		return err
+	})
+	return reverted, err
+}
+
+func rollback(ctx context.Context, db DB, mm []*Migration, n int, cfg *config) ([]*Migration, error) {
+	rr, err := applied(ctx, db, cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[int64]*Migration, len(mm))
+
+	for _, m := range mm {
+		versions[m.Version] = m
+	}
+
+	slices.Reverse(rr)
+
+	if n < len(rr) {
+		rr = rr[:n]
+	}
+
+	reverted := make([]*Migration, 0, len(rr))
+
+	for _, r := range rr {
+		m, ok := versions[r.Version]
+
+		if !ok {
+			return reverted, fmt.Errorf("migration %d %s: unknown migration", r.Version, r.Name)
+		}
+
+		if m.Down == "" {
+			return reverted, fmt.Errorf("migration %d %s: %w", m.Version, m.Name, ErrNoDown)
+		}
+
+		err := database.Tx(ctx, db, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, m.Down); err != nil {
+				return err
+			}
+
+			_, err := newRecordStore(tx, cfg).Delete(ctx, r)
+			return err
+		})
+
+		if err != nil {
+			return reverted, fmt.Errorf("migration %d %s: %w", m.Version, m.Name, err)
+		}
+		reverted = append(reverted, m)
+	}
+	return reverted, nil
+}
+
+// MigrationStatus is the status of a single [Migration].
+type MigrationStatus struct {
+	*Migration
+
+	// Applied is whether the migration has been applied.
+	Applied bool
+
+	// AppliedAt is the time the migration was applied, this is the zero time
+	// if the migration has not been applied.
+	AppliedAt time.Time
+}
+
+// Status returns the status of each of the given migrations, in order of
+// version.
+func Status(ctx context.Context, db DB, mm []*Migration, opts ...Option) ([]*MigrationStatus, error) {
+	if err := validate(mm); err != nil {
+		return nil, err
+	}
+
+	rr, err := applied(ctx, db, newConfig(opts))
+
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(map[int64]*record, len(rr))
+
+	for _, r := range rr {
+		done[r.Version] = r
+	}
+
+	statuses := make([]*MigrationStatus, 0, len(mm))
+
+	for _, m := range mm {
+		st := &MigrationStatus{
+			Migration: m,
+		}
+
+		if r, ok := done[m.Version]; ok {
+			st.Applied = true
+			st.AppliedAt = r.AppliedAt
+		}
+		statuses = append(statuses, st)
+	}
+
+	slices.SortFunc(statuses, func(a, b *MigrationStatus) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return statuses, nil
+}
@@ -0,0 +1,281 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/andrewpillar/database"
+
+	_ "modernc.org/sqlite"
+)
+
+var migrations = fstest.MapFS{
+	"migrations/0001_create_users.up.sql": {
+		Data: []byte(`CREATE TABLE users (
+	id       INTEGER NOT NULL,
+	username VARCHAR NOT NULL,
+	PRIMARY KEY (id)
+);`),
+	},
+	"migrations/0001_create_users.down.sql": {
+		Data: []byte(`DROP TABLE users;`),
+	},
+	"migrations/0002_create_posts.up.sql": {
+		Data: []byte(`CREATE TABLE posts (
+	id      INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE INDEX posts_user_id ON posts (user_id);`),
+	},
+	"migrations/0002_create_posts.down.sql": {
+		Data: []byte(`DROP TABLE posts;`),
+	},
+	"migrations/0003_add_email.up.sql": {
+		Data: []byte(`ALTER TABLE users ADD COLUMN email VARCHAR NULL;`),
+	},
+	"migrations/readme.md": {
+		Data: []byte("Not a migration."),
+	},
+}
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	// Set a busy timeout so concurrent runners wait on each other's writes,
+	// rather than failing with SQLITE_BUSY.
+	dsn := filepath.Join(t.TempDir(), "db.sqlite") + "?_pragma=busy_timeout(5000)"
+
+	db, err := sql.Open("sqlite", dsn)
+
+	if err != nil {
+		t.Fatalf("sql.Open(%q, %q): %v\n", "sqlite", dsn, err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+	return db
+}
+
+func TestLoad(t *testing.T) {
+	mm, err := Load(migrations, "migrations")
+
+	if err != nil {
+		t.Fatalf("Load(migrations, %q): %v\n", "migrations", err)
+	}
+
+	if len(mm) != 3 {
+		t.Fatalf("len(mm) = %v, want = %v\n", len(mm), 3)
+	}
+
+	for i, name := range []string{"create_users", "create_posts", "add_email"} {
+		if mm[i].Version != int64(i+1) {
+			t.Fatalf("mm[%d].Version = %v, want = %v\n", i, mm[i].Version, i+1)
+		}
+
+		if mm[i].Name != name {
+			t.Fatalf("mm[%d].Name = %v, want = %v\n", i, mm[i].Name, name)
+		}
+	}
+
+	if mm[2].Down != "" {
+		t.Fatalf("mm[2].Down = %q, want = %q\n", mm[2].Down, "")
+	}
+
+	invalid := fstest.MapFS{
+		"migrations/0001_create_users.down.sql": {
+			Data: []byte(`DROP TABLE users;`),
+		},
+	}
+
+	if _, err := Load(invalid, "migrations"); err == nil {
+		t.Fatalf("Load(invalid, %q): expected error, got nil\n", "migrations")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := t.Context()
+	db := openDB(t)
+
+	mm, err := Load(migrations, "migrations")
+
+	if err != nil {
+		t.Fatalf("Load(migrations, %q): %v\n", "migrations", err)
+	}
+
+	ran, err := Migrate(ctx, db, mm[:2])
+
+	if err != nil {
+		t.Fatalf("Migrate(ctx, db, mm[:2]): %v\n", err)
+	}
+
+	if len(ran) != 2 {
+		t.Fatalf("len(ran) = %v, want = %v\n", len(ran), 2)
+	}
+
+	ran, err = Migrate(ctx, db, mm)
+
+	if err != nil {
+		t.Fatalf("Migrate(ctx, db, mm): %v\n", err)
+	}
+
+	if len(ran) != 1 || ran[0].Version != 3 {
+		t.Fatalf("ran = %v, want = %v\n", ran, mm[2:])
+	}
+
+	if _, err := db.ExecContext(ctx, "INSERT INTO users (id, username, email) VALUES (1, 'me', 'me@example.com')"); err != nil {
+		t.Fatalf("db.ExecContext(ctx, ...): %v\n", err)
+	}
+
+	statuses, err := Status(ctx, db, mm)
+
+	if err != nil {
+		t.Fatalf("Status(ctx, db, mm): %v\n", err)
+	}
+
+	for i, st := range statuses {
+		if !st.Applied {
+			t.Fatalf("statuses[%d].Applied = %v, want = %v\n", i, st.Applied, true)
+		}
+
+		if st.AppliedAt.IsZero() {
+			t.Fatalf("statuses[%d].AppliedAt = %v, want non-zero\n", i, st.AppliedAt)
+		}
+	}
+
+	// The most recent migration has no down migration.
+	if _, err := Rollback(ctx, db, mm, 1); !errors.Is(err, ErrNoDown) {
+		t.Fatalf("Rollback(ctx, db, mm, 1): %v, want = %v\n", err, ErrNoDown)
+	}
+
+	if _, err := db.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = 3"); err != nil {
+		t.Fatalf("db.ExecContext(ctx, ...): %v\n", err)
+	}
+
+	reverted, err := Rollback(ctx, db, mm, 1)
+
+	if err != nil {
+		t.Fatalf("Rollback(ctx, db, mm, 1): %v\n", err)
+	}
+
+	if len(reverted) != 1 || reverted[0].Version != 2 {
+		t.Fatalf("reverted = %v, want = %v\n", reverted, mm[1:2])
+	}
+
+	if _, err := db.ExecContext(ctx, "SELECT * FROM posts"); err == nil {
+		t.Fatalf("db.ExecContext(ctx, %q): expected error, got nil\n", "SELECT * FROM posts")
+	}
+
+	statuses, err = Status(ctx, db, mm)
+
+	if err != nil {
+		t.Fatalf("Status(ctx, db, mm): %v\n", err)
+	}
+
+	for i, want := range []bool{true, false, false} {
+		if statuses[i].Applied != want {
+			t.Fatalf("statuses[%d].Applied = %v, want = %v\n", i, statuses[i].Applied, want)
+		}
+	}
+}
+
+func TestMigrateFailure(t *testing.T) {
+	ctx := t.Context()
+	db := openDB(t)
+
+	mm := []*Migration{
+		{Version: 1, Name: "create_users", Up: "CREATE TABLE users (id INTEGER NOT NULL);"},
+		{Version: 2, Name: "broken", Up: "CREATE TABLE users (id INTEGER NOT NULL);"},
+	}
+
+	ran, err := Migrate(ctx, db, mm)
+
+	if err == nil {
+		t.Fatalf("Migrate(ctx, db, mm): expected error, got nil\n")
+	}
+
+	if len(ran) != 1 {
+		t.Fatalf("len(ran) = %v, want = %v\n", len(ran), 1)
+	}
+
+	statuses, err := Status(ctx, db, mm)
+
+	if err != nil {
+		t.Fatalf("Status(ctx, db, mm): %v\n", err)
+	}
+
+	if statuses[1].Applied {
+		t.Fatalf("statuses[1].Applied = %v, want = %v\n", statuses[1].Applied, false)
+	}
+}
+
+// execRecorder records the queries executed against it.
+type execRecorder struct {
+	queries []string
+}
+
+type execResult struct{}
+
+func (execResult) LastInsertId() (int64, error) { return 0, nil }
+func (execResult) RowsAffected() (int64, error) { return 1, nil }
+
+func (r *execRecorder) ExecContext(_ context.Context, q string, _ ...any) (sql.Result, error) {
+	r.queries = append(r.queries, q)
+	return execResult{}, nil
+}
+
+func (r *execRecorder) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *execRecorder) QueryRowContext(context.Context, string, ...any) *sql.Row {
+	return nil
+}
+
+func (r *execRecorder) BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestRecordStoreDialect(t *testing.T) {
+	ctx := t.Context()
+
+	var rec execRecorder
+
+	store := newRecordStore(&rec, newConfig([]Option{WithDialect(database.MySQL)}))
+
+	r := &record{
+		Version:   1,
+		Name:      "create_users",
+		AppliedAt: time.Now(),
+	}
+
+	if err := store.Create(ctx, r); err != nil {
+		t.Fatalf("store.Create(ctx, r): %v\n", err)
+	}
+
+	if _, err := store.Delete(ctx, r); err != nil {
+		t.Fatalf("store.Delete(ctx, r): %v\n", err)
+	}
+
+	want := []string{
+		"INSERT INTO schema_migrations (applied_at, name, version) VALUES (?, ?, ?)",
+		"DELETE FROM schema_migrations WHERE ((version) IN (?))",
+	}
+
+	if len(rec.queries) != len(want) {
+		t.Fatalf("rec.queries = %q, want = %q\n", rec.queries, want)
+	}
+
+	for i, q := range rec.queries {
+		if q != want[i] {
+			t.Fatalf("rec.queries[%d] = %q, want = %q\n", i, q, want[i])
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// MorphTyper is the interface that wraps the MorphType method. This would be
+// implemented by a [Model] that is the owner of a polymorphic relation, to
+// give the value that is stored in the type column for that Model. If a Model
+// does not implement this, then its table name is used instead.
+type MorphTyper interface {
+	MorphType() string
+}
+
+// morphType returns the value stored in the type column of a polymorphic
+// relation for the given Model.
+func morphType(m Model) string {
+	if t, ok := m.(MorphTyper); ok {
+		return t.MorphType()
+	}
+	return m.Table()
+}
+
+// MorphMany returns a [Relation] where many of the given Model refer to the
+// Model declaring the relation via a pair of columns, one holding the type of
+// the owner, as per [MorphTyper], and the other holding its primary key. This
+// allows for a Model, such as a comment, to belong to models from different
+// tables, for example,
+//
+//	func (p *Post) Relations() []database.Relation {
+//	    return []database.Relation{
+//	        database.MorphMany("Comments", &Comment{}, "subject_type", "subject_id"),
+//	    }
+//	}
+//
+// Polymorphic relations are only supported for owners with a single column
+// primary key. The named struct field should be a slice of the given Model's
+// type.
+func MorphMany(name string, m Model, typeCol, idCol string) Relation {
+	return Relation{
+		Name:  name,
+		kind:  morphMany,
+		model: m,
+		keys:  []string{typeCol, idCol},
+	}
+}
+
+// MorphOne is like [MorphMany], only a single Model refers to the Model
+// declaring the relation. The named struct field should be a pointer to the
+// given Model's type.
+func MorphOne(name string, m Model, typeCol, idCol string) Relation {
+	return Relation{
+		Name:  name,
+		kind:  morphOne,
+		model: m,
+		keys:  []string{typeCol, idCol},
+	}
+}
+
+// MorphTo returns a [Relation] where the Model declaring the relation refers to
+// one of the given models via the given type and id columns. This is the
+// inverse of [MorphMany] and [MorphOne]. The type column determines which of
+// the given models is loaded, for example,
+//
+//	func (c *Comment) Relations() []database.Relation {
+//	    return []database.Relation{
+//	        database.MorphTo("Subject", "subject_type", "subject_id", &Post{}, &Video{}),
+//	    }
+//	}
+//
+// The named struct field should be of an interface type that each of the
+// given models implement, such as [Model].
+func MorphTo(name, typeCol, idCol string, mm ...Model) Relation {
+	return Relation{
+		Name:   name,
+		kind:   morphTo,
+		keys:   []string{typeCol, idCol},
+		morphs: mm,
+	}
+}
+
+// WhereMorph returns the WHERE clauses matching the given type and id columns
+// against the given owner [Model], for example,
+//
+//	cc, err := comments.Select(ctx, query.Columns("*"), database.WhereMorph(p, "subject_type", "subject_id"))
+func WhereMorph(m Model, typeCol, idCol string) query.Option {
+	return query.Options(
+		query.WhereEq(typeCol, query.Arg(morphType(m))),
+		query.WhereEq(idCol, query.Arg(m.PrimaryKey().Values[0])),
+	)
+}
+
+// JoinMorph returns a JOIN clause on the given owner [Model], matching the
+// given type and id columns of the table being joined onto, for example,
+//
+//	q := query.Select(
+//	    query.Columns("comments.*"),
+//	    query.From("comments"),
+//	    database.JoinMorph(&Post{}, "comments.subject_type", "comments.subject_id"),
+//	)
+//
+// would result in the following SQL code when built,
+//
+//	SELECT comments.* FROM comments JOIN posts ON comments.subject_type = $1 AND comments.subject_id = posts.id
+func JoinMorph(m Model, typeCol, idCol string) query.Option {
+	table := m.Table()
+	pk := m.PrimaryKey()
+
+	return query.Join(table, query.And(
+		query.Eq(query.Ident(typeCol), query.Arg(morphType(m))),
+		query.Eq(query.Ident(idCol), query.Ident(table+"."+pk.Columns[0])),
+	))
+}
+
+// loadMorph loads the models of a MorphMany or MorphOne relation into the
+// given owners.
+func (r Relation) loadMorph(ctx context.Context, s *Store[Model], parents []Model) error {
+	typeCol, idCol := r.keys[0], r.keys[1]
+
+	keys := make([][]any, 0, len(parents))
+	seen := make(map[string]struct{})
+
+	for _, p := range parents {
+		vals := p.PrimaryKey().Values[:1]
+		key := keyOf(vals)
+
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			keys = append(keys, vals)
+		}
+	}
+
+	children, err := selectModels(
+		ctx,
+		s,
+		newModelFunc(r.model),
+		query.WhereEq(typeCol, query.Arg(morphType(parents[0]))),
+		whereKeysIn([]string{idCol}, keys),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	tab := make(map[string][]Model)
+
+	for _, c := range children {
+		vals, err := paramValues(c, []string{idCol})
+
+		if err != nil {
+			return err
+		}
+
+		key := keyOf(vals)
+		tab[key] = append(tab[key], c)
+	}
+
+	for _, p := range parents {
+		if err := r.assign(p, tab[keyOf(p.PrimaryKey().Values[:1])]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadMorphTo loads the owners of a MorphTo relation into the given models,
+// with a query for each type of owner.
+func (r Relation) loadMorphTo(ctx context.Context, s *Store[Model], parents []Model) error {
+	cols := r.keys
+
+	// The types of the owners in the order they were first seen, and the ids
+	// of the owners of each type.
+	types := make([]string, 0)
+	ids := make(map[string][][]any)
+	seen := make(map[string]struct{})
+
+	parentKeys := make([][2]string, 0, len(parents))
+
+	for _, p := range parents {
+		vals, err := paramValues(p, cols)
+
+		if err != nil {
+			return err
+		}
+
+		typ := keyOf(vals[:1])
+		id := keyOf(vals[1:])
+
+		parentKeys = append(parentKeys, [2]string{typ, id})
+
+		if vals[1] == nil {
+			continue
+		}
+
+		if _, ok := ids[typ]; !ok {
+			types = append(types, typ)
+		}
+
+		if _, ok := seen[typ+"\x00"+id]; !ok {
+			seen[typ+"\x00"+id] = struct{}{}
+			ids[typ] = append(ids[typ], vals[1:])
+		}
+	}
+
+	// Table of owner types to the ids of the owners.
+	tab := make(map[string]map[string]Model)
+
+	for _, typ := range types {
+		var owner Model
+
+		for _, m := range r.morphs {
+			if morphType(m) == typ {
+				owner = m
+				break
+			}
+		}
+
+		if owner == nil {
+			return fmt.Errorf("relation %s has no model for type %q", r.Name, typ)
+		}
+
+		owners, err := selectModels(ctx, s, newModelFunc(owner), whereKeysIn(owner.PrimaryKey().Columns[:1], ids[typ]))
+
+		if err != nil {
+			return err
+		}
+
+		tab[typ] = make(map[string]Model)
+
+		for _, o := range owners {
+			tab[typ][keyOf(o.PrimaryKey().Values[:1])] = o
+		}
+	}
+
+	for i, p := range parents {
+		var owners []Model
+
+		if o, ok := tab[parentKeys[i][0]][parentKeys[i][1]]; ok {
+			owners = append(owners, o)
+		}
+
+		if err := r.assign(p, owners); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/andrewpillar/database/query"
+)
+
+const morphSchema = `
+CREATE TABLE IF NOT EXISTS photos (
+	id    INTEGER NOT NULL,
+	title TEXT NOT NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS videos (
+	id    INTEGER NOT NULL,
+	title TEXT NOT NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS comments (
+	id           INTEGER NOT NULL,
+	subject_type TEXT NOT NULL,
+	subject_id   INTEGER NOT NULL,
+	body         TEXT NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+type Photo struct {
+	ID       int64
+	Title    string
+	Comments []*Comment
+}
+
+func (p *Photo) Table() string { return "photos" }
+
+func (p *Photo) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{p.ID},
+	}
+}
+
+func (p *Photo) Params() Params {
+	return Params{
+		"id":    CreateOnlyParam(p.ID),
+		"title": MutableParam(p.Title),
+	}
+}
+
+func (p *Photo) Relations() []Relation {
+	return []Relation{
+		MorphMany("Comments", &Comment{}, "subject_type", "subject_id"),
+	}
+}
+
+type Video struct {
+	ID      int64
+	Title   string
+	Comment *Comment
+}
+
+func (v *Video) Table() string { return "videos" }
+
+// MorphType gives videos a type other than their table name, to test that
+// MorphTyper is respected.
+func (v *Video) MorphType() string { return "video" }
+
+func (v *Video) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{v.ID},
+	}
+}
+
+func (v *Video) Params() Params {
+	return Params{
+		"id":    CreateOnlyParam(v.ID),
+		"title": MutableParam(v.Title),
+	}
+}
+
+func (v *Video) Relations() []Relation {
+	return []Relation{
+		MorphOne("Comment", &Comment{}, "subject_type", "subject_id"),
+	}
+}
+
+type Comment struct {
+	ID          int64
+	SubjectType string
+	SubjectID   int64
+	Body        string
+	Subject     Model
+}
+
+func (c *Comment) Table() string { return "comments" }
+
+func (c *Comment) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{c.ID},
+	}
+}
+
+func (c *Comment) Params() Params {
+	return Params{
+		"id":           CreateOnlyParam(c.ID),
+		"subject_type": CreateOnlyParam(c.SubjectType),
+		"subject_id":   CreateOnlyParam(c.SubjectID),
+		"body":         MutableParam(c.Body),
+	}
+}
+
+func (c *Comment) Relations() []Relation {
+	return []Relation{
+		MorphTo("Subject", "subject_type", "subject_id", &Photo{}, &Video{}),
+	}
+}
+
+func TestMorph(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, morphSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", morphSchema, err)
+	}
+
+	photos := NewStore(db, func() *Photo {
+		return &Photo{}
+	})
+
+	videos := NewStore(db, func() *Video {
+		return &Video{}
+	})
+
+	comments := NewStore(db, func() *Comment {
+		return &Comment{}
+	})
+
+	if err := photos.Create(ctx, &Photo{ID: 1, Title: "Sunset"}, &Photo{ID: 2, Title: "Sunrise"}); err != nil {
+		t.Fatalf("photos.Create(ctx, ...): %v\n", err)
+	}
+
+	if err := videos.Create(ctx, &Video{ID: 1, Title: "Timelapse"}); err != nil {
+		t.Fatalf("videos.Create(ctx, ...): %v\n", err)
+	}
+
+	cc := []*Comment{
+		{ID: 1, SubjectType: "photos", SubjectID: 1, Body: "Nice"},
+		{ID: 2, SubjectType: "photos", SubjectID: 1, Body: "Lovely"},
+		{ID: 3, SubjectType: "video", SubjectID: 1, Body: "Cool"},
+		{ID: 4, SubjectType: "photos", SubjectID: 2, Body: "Early"},
+	}
+
+	if err := comments.Create(ctx, cc...); err != nil {
+		t.Fatalf("comments.Create(ctx, cc...): %v\n", err)
+	}
+
+	pp, err := photos.Load("Comments").Select(ctx, query.Columns("*"), query.OrderAsc("id"))
+
+	if err != nil {
+		t.Fatalf("photos.Load(%q).Select(ctx, ...): %v\n", "Comments", err)
+	}
+
+	for i, want := range []int{2, 1} {
+		if l := len(pp[i].Comments); l != want {
+			t.Fatalf("len(pp[%d].Comments) = %v, want = %v\n", i, l, want)
+		}
+	}
+
+	v, _, err := videos.Load("Comment").Get(ctx)
+
+	if err != nil {
+		t.Fatalf("videos.Load(%q).Get(ctx): %v\n", "Comment", err)
+	}
+
+	if v.Comment == nil || v.Comment.Body != "Cool" {
+		t.Fatalf("v.Comment = %v, want = %v\n", v.Comment, cc[2])
+	}
+
+	cc, err = comments.Load("Subject").Select(ctx, query.Columns("*"), query.OrderAsc("id"))
+
+	if err != nil {
+		t.Fatalf("comments.Load(%q).Select(ctx, ...): %v\n", "Subject", err)
+	}
+
+	want := []string{"Sunset", "Sunset", "Timelapse", "Sunrise"}
+
+	for i, c := range cc {
+		var title string
+
+		switch s := c.Subject.(type) {
+		case *Photo:
+			title = s.Title
+		case *Video:
+			title = s.Title
+		default:
+			t.Fatalf("cc[%d].Subject = %T, want = *Photo or *Video\n", i, c.Subject)
+		}
+
+		if title != want[i] {
+			t.Fatalf("cc[%d].Subject.Title = %q, want = %q\n", i, title, want[i])
+		}
+	}
+
+	cc, err = comments.Select(ctx, query.Columns("*"), WhereMorph(&Photo{ID: 1}, "subject_type", "subject_id"))
+
+	if err != nil {
+		t.Fatalf("comments.Select(ctx, ...): %v\n", err)
+	}
+
+	if len(cc) != 2 {
+		t.Fatalf("len(cc) = %v, want = %v\n", len(cc), 2)
+	}
+
+	cc, err = comments.Select(
+		ctx,
+		query.Columns("comments.*"),
+		JoinMorph(&Video{}, "comments.subject_type", "comments.subject_id"),
+		query.WhereEq("videos.title", query.Arg("Timelapse")),
+	)
+
+	if err != nil {
+		t.Fatalf("comments.Select(ctx, ...): %v\n", err)
+	}
+
+	if len(cc) != 1 || cc[0].ID != 3 {
+		t.Fatalf("cc = %v, want = %v\n", cc, []int64{3})
+	}
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"unicode"
+)
+
+// SnakeCase converts the given Go identifier into snake_case, for example
+// "CreatedAt" becomes "created_at", and "UserID" becomes "user_id". Runs of
+// upper case letters are treated as a single word, so "HTTPServer" becomes
+// "http_server". Any "." separators are kept as is, so "users.CreatedAt"
+// becomes "users.created_at".
+func SnakeCase(s string) string {
+	rr := []rune(s)
+
+	var buf strings.Builder
+
+	for i, r := range rr {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := rr[i-1]
+
+			lower := unicode.IsLower(prev) || unicode.IsDigit(prev)
+			acronym := unicode.IsUpper(prev) && i+1 < len(rr) && unicode.IsLower(rr[i+1])
+
+			if prev != '.' && prev != '_' && (lower || acronym) {
+				buf.WriteByte('_')
+			}
+		}
+		buf.WriteRune(unicode.ToLower(r))
+	}
+	return buf.String()
+}
+
+// Mapper maps the names of struct fields to column names, and vice versa. This
+// is used by a [Scanner] for matching the columns of a row to the fields of a
+// struct, for databases whose column names follow a convention that the
+// default matching does not account for. Either function may be nil.
+//
+// Column maps the given struct field name to its column name. This is not
+// applied to fields that have a "db" struct tag.
+//
+// Field maps the given column name to its struct field name. The returned name
+// is matched case insensitively against the struct fields.
+type Mapper struct {
+	Column func(field string) string
+
+	Field func(column string) string
+}
+
+// SnakeCaseMapper is a [Mapper] that maps struct fields to snake_case columns
+// via [SnakeCase], and snake_case columns to struct fields via [PascalCase].
+var SnakeCaseMapper = Mapper{
+	Column: SnakeCase,
+	Field:  PascalCase,
+}
+
+// PascalCase converts the given snake_case name into PascalCase, for example
+// "created_at" becomes "CreatedAt". Any "." separators are kept as is, so
+// "users.created_at" becomes "users.CreatedAt".
+func PascalCase(s string) string {
+	var buf strings.Builder
+
+	if i := strings.LastIndex(s, "."); i >= 0 {
+		buf.WriteString(s[:i+1])
+		s = s[i+1:]
+	}
+
+	upper := true
+
+	for _, r := range s {
+		if r == '_' {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// get returns the field from the given fields that the given column maps to.
+func (m *Mapper) get(fields *structFields, col string) (*structField, bool) {
+	if m == nil {
+		return nil, false
+	}
+
+	if m.Field != nil {
+		if fld, ok := fields.get(m.Field(col)); ok {
+			return fld, true
+		}
+	}
+
+	if m.Column != nil {
+		for _, fld := range fields.arr {
+			if fld.tagged {
+				continue
+			}
+
+			if m.Column(fld.name) == col {
+				return fld, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// lookup returns the field from the given fields that the given column maps
+// to, trying the Mapper first before falling back to the default matching.
+// This is how columns are resolved to fields when both scanning and writing
+// models.
+func (m *Mapper) lookup(fields *structFields, col string) (*structField, bool) {
+	if fld, ok := m.get(fields, col); ok {
+		return fld, true
+	}
+	return fields.get(col)
+}
+
+// WithMapper configures the [Mapper] a [Store] uses when scanning its models,
+// as per [UseMapper].
+func WithMapper(m Mapper) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.mapper = &m
+	}
+}
+
+func (s *Store[M]) newScanner(rows *sql.Rows) (*Scanner, error) {
+	sc, err := NewScanner(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sc.mapper = s.cfg.mapper
+	sc.strict = s.cfg.strict
+	sc.direct = s.cfg.direct
+	sc.keyring = s.cfg.keyring
+
+	if s.cfg.timeLayouts != nil {
+		sc.timeLayouts = s.cfg.timeLayouts
+	}
+	return sc, nil
+}
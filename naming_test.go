@@ -0,0 +1,257 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestSnakeCase(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"ID", "id"},
+		{"CreatedAt", "created_at"},
+		{"UserID", "user_id"},
+		{"HTTPServer", "http_server"},
+		{"Version2Name", "version2_name"},
+		{"users.CreatedAt", "users.created_at"},
+		{"already_snake", "already_snake"},
+	}
+
+	for _, test := range tests {
+		if got := SnakeCase(test.in); got != test.want {
+			t.Errorf("SnakeCase(%q) = %q, want = %q\n", test.in, got, test.want)
+		}
+	}
+}
+
+func TestPascalCase(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"id", "Id"},
+		{"created_at", "CreatedAt"},
+		{"users.created_at", "users.CreatedAt"},
+	}
+
+	for _, test := range tests {
+		if got := PascalCase(test.in); got != test.want {
+			t.Errorf("PascalCase(%q) = %q, want = %q\n", test.in, got, test.want)
+		}
+	}
+}
+
+// Snaked has no struct tags, so its columns are mapped to its fields via the
+// snake case fallback.
+type Snaked struct {
+	ID        int64
+	FullName  string
+	UpdatedAt sql.Null[time.Time]
+}
+
+func (s *Snaked) Table() string { return "snaked" }
+
+func (s *Snaked) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{s.ID},
+	}
+}
+
+func (s *Snaked) Params() Params {
+	return Params{
+		"id":         CreateOnlyParam(s.ID),
+		"full_name":  MutableParam(s.FullName),
+		"updated_at": MutableParam(s.UpdatedAt),
+	}
+}
+
+func TestStoreSnakeCase(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	schema := `CREATE TABLE snaked (id INTEGER PRIMARY KEY, full_name TEXT NOT NULL, updated_at TIMESTAMP NULL)`
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", schema, err)
+	}
+
+	store := NewStore(db, func() *Snaked {
+		return &Snaked{}
+	})
+
+	want := &Snaked{
+		ID:       1,
+		FullName: "Ken Thompson",
+	}
+
+	want.UpdatedAt.V = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	want.UpdatedAt.Valid = true
+
+	if err := store.Create(ctx, want); err != nil {
+		t.Fatalf("store.Create(ctx, want): %v\n", err)
+	}
+
+	got, ok, err := store.Get(ctx)
+
+	if err != nil {
+		t.Fatalf("store.Get(ctx): %v\n", err)
+	}
+
+	if !ok {
+		t.Fatalf("store.Get(ctx): expected model, got none\n")
+	}
+
+	if got.FullName != want.FullName {
+		t.Fatalf("got.FullName = %q, want = %q\n", got.FullName, want.FullName)
+	}
+
+	if !got.UpdatedAt.Valid || !got.UpdatedAt.V.Equal(want.UpdatedAt.V) {
+		t.Fatalf("got.UpdatedAt = %v, want = %v\n", got.UpdatedAt, want.UpdatedAt)
+	}
+}
+
+type Prefixed struct {
+	ID       int64
+	FullName string
+	Alias    string `db:"nickname"`
+}
+
+func (p *Prefixed) Table() string { return "prefixed" }
+
+func (p *Prefixed) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"p_id"},
+		Values:  []any{p.ID},
+	}
+}
+
+func (p *Prefixed) Params() Params {
+	return Params{
+		"p_id":        CreateOnlyParam(p.ID),
+		"p_full_name": MutableParam(p.FullName),
+		"nickname":    MutableParam(p.Alias),
+	}
+}
+
+func TestStoreMapper(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	schema := `CREATE TABLE prefixed (p_id INTEGER PRIMARY KEY, p_full_name TEXT NOT NULL, nickname TEXT NOT NULL)`
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", schema, err)
+	}
+
+	store := NewStore(db, func() *Prefixed {
+		return &Prefixed{}
+	}, WithMapper(Mapper{
+		Column: func(field string) string {
+			return "p_" + SnakeCase(field)
+		},
+	}))
+
+	want := &Prefixed{
+		ID:       1,
+		FullName: "Ken Thompson",
+		Alias:    "ken",
+	}
+
+	if err := store.Create(ctx, want); err != nil {
+		t.Fatalf("store.Create(ctx, want): %v\n", err)
+	}
+
+	got, ok, err := store.Get(ctx)
+
+	if err != nil {
+		t.Fatalf("store.Get(ctx): %v\n", err)
+	}
+
+	if !ok {
+		t.Fatalf("store.Get(ctx): expected model, got none\n")
+	}
+
+	if *got != *want {
+		t.Fatalf("got = %v, want = %v\n", *got, *want)
+	}
+}
+
+type PrefixedVersion struct {
+	ID       int64
+	FullName string
+	Version  int
+}
+
+func (p *PrefixedVersion) Table() string { return "prefixed_versions" }
+
+func (p *PrefixedVersion) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"p_id"},
+		Values:  []any{p.ID},
+	}
+}
+
+func (p *PrefixedVersion) Params() Params {
+	return Params{
+		"p_id":        CreateOnlyParam(p.ID),
+		"p_full_name": MutableParam(p.FullName).Transform(TrimSpace),
+		"p_version":   GeneratedParam(p.Version),
+	}
+}
+
+func TestStoreMapperWrite(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	schema := `CREATE TABLE prefixed_versions (p_id INTEGER PRIMARY KEY, p_full_name TEXT NOT NULL, p_version INTEGER NOT NULL DEFAULT 1)`
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", schema, err)
+	}
+
+	// MySQL is used so the generated key is set from the LastInsertId, and
+	// the generated version is selected once the model is written.
+	store := NewStore(db, func() *PrefixedVersion {
+		return &PrefixedVersion{}
+	}, WithDialect(MySQL), WithMapper(Mapper{
+		Column: func(field string) string {
+			return "p_" + SnakeCase(field)
+		},
+	}))
+
+	p := &PrefixedVersion{
+		FullName: "  Ken Thompson  ",
+	}
+
+	if err := store.Create(ctx, p); err != nil {
+		t.Fatalf("store.Create(ctx, p): %v\n", err)
+	}
+
+	want := PrefixedVersion{
+		ID:       1,
+		FullName: "Ken Thompson",
+		Version:  1,
+	}
+
+	if *p != want {
+		t.Fatalf("p = %v, want = %v\n", *p, want)
+	}
+
+	got, ok, err := store.Get(ctx)
+
+	if err != nil {
+		t.Fatalf("store.Get(ctx): %v\n", err)
+	}
+
+	if !ok {
+		t.Fatalf("store.Get(ctx): expected model, got none\n")
+	}
+
+	if *got != want {
+		t.Fatalf("got = %v, want = %v\n", *got, want)
+	}
+}
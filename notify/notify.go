@@ -0,0 +1,186 @@
+// Package notify provides helpers for PostgreSQL's LISTEN and NOTIFY, so that
+// events, such as a model being changed by a [database.Store], can be
+// published to other processes.
+//
+// Notifications are sent via [Notify], which only needs a [database.DB],
+//
+//	if err := posts.Create(ctx, p); err != nil {
+//	    // Handle error.
+//	}
+//
+//	if err := notify.Notify(ctx, db, "posts", strconv.FormatInt(p.ID, 10)); err != nil {
+//	    // Handle error.
+//	}
+//
+// Receiving notifications is not something that database/sql supports, so a
+// [Listener] is given a [WaitFunc] that waits for a notification on the
+// underlying driver connection. For example, with pgx,
+//
+//	wait := func(ctx context.Context, conn any) (*notify.Notification, error) {
+//	    n, err := conn.(*stdlib.Conn).Conn().WaitForNotification(ctx)
+//
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return &notify.Notification{PID: n.PID, Channel: n.Channel, Payload: n.Payload}, nil
+//	}
+//
+//	l, err := notify.Listen(ctx, db, wait, "posts")
+//
+//	if err != nil {
+//	    // Handle error.
+//	}
+//
+//	defer l.Close()
+//
+//	for n := range l.Notifications() {
+//	    // Handle notification.
+//	}
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"sync"
+
+	"github.com/andrewpillar/database"
+	"github.com/andrewpillar/database/query"
+)
+
+// Notification is a notification received on a channel.
+type Notification struct {
+	// PID is the process ID of the server that sent the notification.
+	PID     uint32
+	Channel string
+	Payload string
+}
+
+// Notify sends a notification with the given payload on the given channel via
+// pg_notify. If db is a transaction, then the notification is only delivered
+// once the transaction is committed.
+func Notify(ctx context.Context, db database.DB, channel, payload string) error {
+	q := query.Select(query.Call("pg_notify", query.Arg(channel), query.Arg(payload)))
+
+	_, err := q.Exec(ctx, db)
+	return err
+}
+
+// WaitFunc waits for the next notification on the given driver connection, as
+// passed to the callback of [sql.Conn.Raw]. This should return once ctx is
+// cancelled.
+type WaitFunc func(ctx context.Context, conn any) (*Notification, error)
+
+// Listener delivers the notifications received on a connection to a Go
+// channel. This is created via [Listen].
+type Listener struct {
+	conn   *sql.Conn
+	cancel context.CancelFunc
+	c      chan *Notification
+	done   chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// quoteIdent quotes the given channel name, so that it is not case folded and
+// matches the channel given to pg_notify.
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// Listen takes a connection from the given database, and listens on each of
+// the given channels. Notifications are waited on via the given [WaitFunc],
+// and delivered to the channel returned from [Listener.Notifications]. The
+// Listener stops when the given context is cancelled, or when it is closed.
+func Listen(ctx context.Context, db *sql.DB, wait WaitFunc, channels ...string) (*Listener, error) {
+	conn, err := db.Conn(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ch := range channels {
+		if _, err := conn.ExecContext(ctx, "LISTEN "+quoteIdent(ch)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	l := &Listener{
+		conn:   conn,
+		cancel: cancel,
+		c:      make(chan *Notification),
+		done:   make(chan struct{}),
+	}
+
+	go l.run(ctx, wait)
+
+	return l, nil
+}
+
+func (l *Listener) run(ctx context.Context, wait WaitFunc) {
+	defer close(l.done)
+	defer close(l.c)
+
+	for {
+		var n *Notification
+
+		err := l.conn.Raw(func(conn any) error {
+			var err error
+
+			n, err = wait(ctx, conn)
+			return err
+		})
+
+		if err != nil {
+			if ctx.Err() == nil {
+				l.mu.Lock()
+				l.err = err
+				l.mu.Unlock()
+			}
+			return
+		}
+
+		select {
+		case l.c <- n:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Notifications returns the channel on which notifications are delivered. The
+// channel is closed once the Listener stops, after which [Listener.Err] can be
+// checked for the error that stopped it.
+func (l *Listener) Notifications() <-chan *Notification {
+	return l.c
+}
+
+// Err returns the error returned from the [WaitFunc] that stopped the
+// Listener, if any. This is nil if the Listener was stopped by closing it, or
+// by cancelling its context.
+func (l *Listener) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.err
+}
+
+// Close stops the Listener, and closes its connection. The connection is
+// discarded rather than returned to the pool, so that it does not go on
+// receiving notifications for the channels that were listened on.
+func (l *Listener) Close() error {
+	l.cancel()
+	<-l.done
+
+	// Returning ErrBadConn from Raw marks the connection as bad, so it is
+	// closed rather than put back into the pool.
+	l.conn.Raw(func(any) error {
+		return driver.ErrBadConn
+	})
+	return l.conn.Close()
+}
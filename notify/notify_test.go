@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// testConn is a driver connection that records the statements executed on it,
+// and receives notifications on a Go channel.
+type testConn struct {
+	mu    sync.Mutex
+	stmts []string
+	args  [][]driver.NamedValue
+
+	notifications chan *Notification
+	closed        bool
+}
+
+func (c *testConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *testConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *testConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	return nil
+}
+
+func (c *testConn) ExecContext(_ context.Context, q string, args []driver.NamedValue) (driver.Result, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stmts = append(c.stmts, q)
+	c.args = append(c.args, args)
+
+	return driver.RowsAffected(0), nil
+}
+
+type testConnector struct {
+	conn *testConn
+}
+
+func (c testConnector) Connect(context.Context) (driver.Conn, error) { return c.conn, nil }
+func (c testConnector) Driver() driver.Driver                        { return nil }
+
+func newTestDB(t *testing.T) (*sql.DB, *testConn) {
+	conn := &testConn{
+		notifications: make(chan *Notification),
+	}
+
+	db := sql.OpenDB(testConnector{conn: conn})
+	t.Cleanup(func() { db.Close() })
+
+	return db, conn
+}
+
+func testWait(ctx context.Context, conn any) (*Notification, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case n := <-conn.(*testConn).notifications:
+		return n, nil
+	}
+}
+
+func TestNotify(t *testing.T) {
+	db, conn := newTestDB(t)
+
+	if err := Notify(t.Context(), db, "posts", "1"); err != nil {
+		t.Fatalf("Notify(ctx, db, %q, %q): %v\n", "posts", "1", err)
+	}
+
+	want := "SELECT pg_notify($1, $2)"
+
+	if conn.stmts[0] != want {
+		t.Fatalf("conn.stmts[0] = %q, want = %q\n", conn.stmts[0], want)
+	}
+
+	args := []any{conn.args[0][0].Value, conn.args[0][1].Value}
+
+	if !slices.Equal(args, []any{"posts", "1"}) {
+		t.Fatalf("args = %v, want = %v\n", args, []any{"posts", "1"})
+	}
+}
+
+func TestListener(t *testing.T) {
+	db, conn := newTestDB(t)
+
+	l, err := Listen(t.Context(), db, testWait, "posts", `Post"s`)
+
+	if err != nil {
+		t.Fatalf("Listen(ctx, db, testWait): %v\n", err)
+	}
+
+	want := []string{`LISTEN "posts"`, `LISTEN "Post""s"`}
+
+	if !slices.Equal(conn.stmts, want) {
+		t.Fatalf("conn.stmts = %q, want = %q\n", conn.stmts, want)
+	}
+
+	n := &Notification{
+		PID:     1,
+		Channel: "posts",
+		Payload: "10",
+	}
+
+	conn.notifications <- n
+
+	if got := <-l.Notifications(); got != n {
+		t.Fatalf("<-l.Notifications() = %v, want = %v\n", got, n)
+	}
+
+	l.Close()
+
+	if _, ok := <-l.Notifications(); ok {
+		t.Fatalf("<-l.Notifications(): expected closed channel\n")
+	}
+
+	if err := l.Err(); err != nil {
+		t.Fatalf("l.Err() = %v, want = %v\n", err, nil)
+	}
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	if !conn.closed {
+		t.Fatalf("conn.closed = %v, want = %v\n", conn.closed, true)
+	}
+}
+
+func TestListenerErr(t *testing.T) {
+	db, _ := newTestDB(t)
+
+	errWait := errors.New("connection lost")
+
+	wait := func(context.Context, any) (*Notification, error) {
+		return nil, errWait
+	}
+
+	l, err := Listen(t.Context(), db, wait, "posts")
+
+	if err != nil {
+		t.Fatalf("Listen(ctx, db, wait): %v\n", err)
+	}
+
+	defer l.Close()
+
+	if _, ok := <-l.Notifications(); ok {
+		t.Fatalf("<-l.Notifications(): expected closed channel\n")
+	}
+
+	if err := l.Err(); !errors.Is(err, errWait) {
+		t.Fatalf("l.Err() = %v, want = %v\n", err, errWait)
+	}
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type Level int
+
+type Profile struct {
+	ID       int64
+	Level    Null[Level]
+	Bio      Null[string]
+	Birthday Null[time.Time]
+}
+
+func (p *Profile) Table() string { return "profiles" }
+
+func (p *Profile) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{p.ID},
+	}
+}
+
+func (p *Profile) Params() Params {
+	return Params{
+		"id":       CreateOnlyParam(p.ID),
+		"level":    MutableParam(p.Level),
+		"bio":      MutableParam(p.Bio),
+		"birthday": MutableParam(p.Birthday),
+	}
+}
+
+const profileSchema = `CREATE TABLE IF NOT EXISTS profiles (
+	id       INTEGER NOT NULL,
+	level    INTEGER NULL,
+	bio      TEXT NULL,
+	birthday TEXT NULL,
+	PRIMARY KEY (id)
+);`
+
+func TestNull(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, profileSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", profileSchema, err)
+	}
+
+	store := NewStore(db, func() *Profile {
+		return &Profile{}
+	})
+
+	var p Profile
+
+	payload := `{"ID": 1, "Level": 3, "Bio": null, "Birthday": "2000-01-02T00:00:00Z"}`
+
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		t.Fatalf("json.Unmarshal(%q, &p): %v\n", payload, err)
+	}
+
+	if err := store.Create(ctx, &p); err != nil {
+		t.Fatalf("store.Create(ctx, &p): %v\n", err)
+	}
+
+	p2, ok, err := store.Get(ctx)
+
+	if err != nil {
+		t.Fatalf("store.Get(ctx): %v\n", err)
+	}
+
+	if !ok {
+		t.Fatalf("ok = %v, want = %v\n", ok, true)
+	}
+
+	if !p2.Level.Valid || p2.Level.V != 3 {
+		t.Fatalf("p2.Level = %v, want = %v\n", p2.Level, 3)
+	}
+
+	if p2.Bio.Valid {
+		t.Fatalf("p2.Bio.Valid = %v, want = %v\n", p2.Bio.Valid, false)
+	}
+
+	want := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	if !p2.Birthday.Valid || !p2.Birthday.V.Equal(want) {
+		t.Fatalf("p2.Birthday = %v, want = %v\n", p2.Birthday, want)
+	}
+
+	b, err := json.Marshal(p2)
+
+	if err != nil {
+		t.Fatalf("json.Marshal(p2): %v\n", err)
+	}
+
+	if s := `{"ID":1,"Level":3,"Bio":null,"Birthday":"2000-01-02T00:00:00Z"}`; string(b) != s {
+		t.Fatalf("json.Marshal(p2) = %s, want = %s\n", b, s)
+	}
+}
@@ -0,0 +1,315 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// Partitioner derives the physical tables of a table that is partitioned by
+// time, such as events_2024_06 for the events table.
+//
+// Partition returns the partition of the given table that the given model is
+// written to.
+//
+// Partitions returns the partitions of the given table that hold the rows
+// between the given times.
+type Partitioner interface {
+	Partition(table string, m Model) (string, error)
+
+	Partitions(table string, from, to time.Time) []string
+}
+
+type timePartitioner struct {
+	col    string
+	layout string
+	trunc  func(t time.Time) time.Time
+	next   func(t time.Time) time.Time
+}
+
+// DailyPartitions returns a [Partitioner] that partitions a table by the day
+// of the given time column, for example events_2024_06_01.
+func DailyPartitions(col string) Partitioner {
+	return &timePartitioner{
+		col:    col,
+		layout: "2006_01_02",
+		trunc: func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		},
+		next: func(t time.Time) time.Time {
+			return t.AddDate(0, 0, 1)
+		},
+	}
+}
+
+// MonthlyPartitions returns a [Partitioner] that partitions a table by the
+// month of the given time column, for example events_2024_06.
+func MonthlyPartitions(col string) Partitioner {
+	return &timePartitioner{
+		col:    col,
+		layout: "2006_01",
+		trunc: func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		},
+		next: func(t time.Time) time.Time {
+			return t.AddDate(0, 1, 0)
+		},
+	}
+}
+
+// YearlyPartitions returns a [Partitioner] that partitions a table by the
+// year of the given time column, for example events_2024.
+func YearlyPartitions(col string) Partitioner {
+	return &timePartitioner{
+		col:    col,
+		layout: "2006",
+		trunc: func(t time.Time) time.Time {
+			return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		},
+		next: func(t time.Time) time.Time {
+			return t.AddDate(1, 0, 0)
+		},
+	}
+}
+
+func (p *timePartitioner) name(table string, t time.Time) string {
+	return table + "_" + t.UTC().Format(p.layout)
+}
+
+func (p *timePartitioner) Partition(table string, m Model) (string, error) {
+	param, ok := m.Params()[p.col]
+
+	if !ok {
+		return "", fmt.Errorf("partition column %s: %w", p.col, errUnknownColumn)
+	}
+
+	val, err := driver.DefaultParameterConverter.ConvertValue(param.value)
+
+	if err != nil {
+		return "", fmt.Errorf("partition column %s: %w", p.col, err)
+	}
+
+	t, ok := val.(time.Time)
+
+	if !ok {
+		return "", fmt.Errorf("partition column %s: cannot partition by value of type %T", p.col, val)
+	}
+	return p.name(table, t), nil
+}
+
+func (p *timePartitioner) Partitions(table string, from, to time.Time) []string {
+	tables := []string{p.name(table, from)}
+
+	for t := p.next(p.trunc(from.UTC())); !t.After(to.UTC()); t = p.next(t) {
+		tables = append(tables, p.name(table, t))
+	}
+	return tables
+}
+
+// ErrPartitionChanged is returned when a model updated in a partitioned
+// [Store] is not in the partition derived from it, as is the case when its
+// partition column has been changed. A model is moved to another partition by
+// deleting it and creating it again.
+var ErrPartitionChanged = errors.New("model not in partition")
+
+// WithPartitions configures a [Store] to write its models to the partitions
+// of its table as derived by the given [Partitioner]. Models are created,
+// updated, and deleted in the partition of each model, whereas models are
+// selected from the table itself, unless the partitions to select from are
+// given via [Store.Between]. The [Change] of each write, and the metrics of
+// each query, are reported against the table rather than the partition.
+//
+// UpdateMany and Truncate operate on the table itself, so would only affect
+// the partitions if the database routes these to the partitions, as is the
+// case with the declarative partitioning of PostgreSQL.
+func WithPartitions(p Partitioner) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.partitions = p
+	}
+}
+
+// Between returns a copy of the partitioned store that selects models from
+// the partitions that hold the rows between the given times. The partitions
+// are combined via UNION ALL into a common table expression of the same name
+// as the table, so the given query options can refer to the table as normal,
+// for example,
+//
+//	ee, err := events.Between(from, to).Select(ctx, query.Columns("*"), query.WhereEq("kind", query.Arg("login")))
+//
+// would be built as,
+//
+//	WITH events AS (SELECT * FROM events_2024_05 UNION ALL SELECT * FROM events_2024_06)
+//	SELECT * FROM events WHERE (kind = $1)
+//
+// Only the partitions are narrowed down by the given times, not the rows
+// within them. This panics if the store was not configured via
+// [WithPartitions].
+func (s *Store[M]) Between(from, to time.Time) *Store[M] {
+	if s.cfg.partitions == nil {
+		panic("database: store is not partitioned")
+	}
+
+	s2 := *s
+	s2.spans = s.cfg.partitions.Partitions(s.table, from, to)
+	return &s2
+}
+
+// spanned returns the given query options with the common table expression
+// for the partitions the store spans, if any.
+func (s *Store[M]) spanned(opts []query.Option) []query.Option {
+	if len(s.spans) == 0 {
+		return opts
+	}
+
+	qq := make([]*query.Query, 0, len(s.spans))
+
+	for _, table := range s.spans {
+		qq = append(qq, query.Select(query.Columns("*"), query.From(table)))
+	}
+	return append(opts, query.With(s.table, query.UnionAll(qq...)))
+}
+
+// partition returns a copy of the store that writes to the given partition
+// of its table.
+func (s *Store[M]) partition(table string) *Store[M] {
+	s2 := *s
+	s2.part = table
+	s2.spans = nil
+	s2.cfg.partitions = nil
+
+	return &s2
+}
+
+// target returns the table the store writes to, which is the partition of
+// its table if it is for one.
+func (s *Store[M]) target() string {
+	if s.part != "" {
+		return s.part
+	}
+	return s.table
+}
+
+// inPartition checks that the given model, updated with the given result, is
+// in the partition of the store. The model is looked up only if no rows were
+// affected, since some databases do not count rows that were left unchanged.
+func (s *Store[M]) inPartition(ctx context.Context, res sql.Result, m M) error {
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+
+	q := query.Select(
+		query.Count("*"),
+		query.From(s.part),
+		m.PrimaryKey().Where(),
+	)
+
+	// The partition is checked on the primary, since a replica may not yet
+	// have the row.
+	rows, err := s.query(ReadPrimary(ctx), s.table, OpSelect, q)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	var n int64
+
+	if rows.Next() {
+		if err := rows.Scan(&n); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return fmt.Errorf("update %s: %w %s", s.table, ErrPartitionChanged, s.part)
+	}
+	return nil
+}
+
+// partitioned groups the given models by their partition, and calls fn with
+// the store for each partition along with the indexes of the models in it.
+// Writes to multiple partitions are done atomically.
+func (s *Store[M]) partitioned(ctx context.Context, mm []M, fn func(s *Store[M], idx []int) error) error {
+	tables := make([]string, 0, 1)
+	groups := make(map[string][]int)
+
+	for i, m := range mm {
+		table, err := s.cfg.partitions.Partition(s.table, m)
+
+		if err != nil {
+			return err
+		}
+
+		if _, ok := groups[table]; !ok {
+			tables = append(tables, table)
+		}
+		groups[table] = append(groups[table], i)
+	}
+
+	write := func(s *Store[M]) error {
+		for _, table := range tables {
+			if err := fn(s.partition(table), groups[table]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(tables) > 1 {
+		if err := s.atomic(ctx, write); err != nil {
+			return err
+		}
+	} else if err := write(s); err != nil {
+		return err
+	}
+
+	// The cache is keyed by the table the models are selected from, not the
+	// partitions they are written to.
+	s.invalidate(s.table)
+	return nil
+}
+
+// pick returns the elements of the given slice at the given indexes.
+func pick[T any](vv []T, idx []int) []T {
+	picked := make([]T, 0, len(idx))
+
+	for _, i := range idx {
+		picked = append(picked, vv[i])
+	}
+	return picked
+}
+
+// partitionedResult is the [sql.Result] of writes made across multiple
+// partitions.
+type partitionedResult []sql.Result
+
+func (r partitionedResult) LastInsertId() (int64, error) {
+	if len(r) == 0 {
+		return 0, nil
+	}
+	return r[len(r)-1].LastInsertId()
+}
+
+func (r partitionedResult) RowsAffected() (int64, error) {
+	var n int64
+
+	for _, res := range r {
+		affected, err := res.RowsAffected()
+
+		if err != nil {
+			return 0, err
+		}
+		n += affected
+	}
+	return n, nil
+}
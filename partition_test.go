@@ -0,0 +1,233 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+const visitSchema = `CREATE TABLE IF NOT EXISTS %s (
+	id         INTEGER NOT NULL,
+	path       TEXT NOT NULL,
+	visited_at DATETIME NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+type Visit struct {
+	ID        int64
+	Path      string
+	VisitedAt time.Time
+}
+
+func (v *Visit) Table() string { return "visits" }
+
+func (v *Visit) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{v.ID},
+	}
+}
+
+func (v *Visit) Params() Params {
+	return Params{
+		"id":         CreateOnlyParam(v.ID),
+		"path":       MutableParam(v.Path),
+		"visited_at": CreateOnlyParam(v.VisitedAt),
+	}
+}
+
+func TestPartitions(t *testing.T) {
+	tests := []struct {
+		p        Partitioner
+		from, to time.Time
+		want     []string
+	}{
+		{
+			MonthlyPartitions("visited_at"),
+			time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+			[]string{"visits_2024_05", "visits_2024_06", "visits_2024_07"},
+		},
+		{
+			MonthlyPartitions("visited_at"),
+			time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 5, 21, 0, 0, 0, 0, time.UTC),
+			[]string{"visits_2024_05"},
+		},
+		{
+			DailyPartitions("visited_at"),
+			time.Date(2024, 2, 28, 12, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			[]string{"visits_2024_02_28", "visits_2024_02_29", "visits_2024_03_01"},
+		},
+		{
+			YearlyPartitions("visited_at"),
+			time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			[]string{"visits_2023", "visits_2024"},
+		},
+	}
+
+	for i, test := range tests {
+		tables := test.p.Partitions("visits", test.from, test.to)
+
+		if !slices.Equal(tables, test.want) {
+			t.Errorf("tests[%d] - tables = %v, want = %v\n", i, tables, test.want)
+		}
+	}
+}
+
+func TestStorePartitions(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	for _, table := range []string{"visits", "visits_2024_05", "visits_2024_06"} {
+		schema := fmt.Sprintf(visitSchema, table)
+
+		if _, err := db.ExecContext(ctx, schema); err != nil {
+			t.Fatalf("db.ExecContext(ctx, %q): %v\n", schema, err)
+		}
+	}
+
+	feed := NewChangeFeed()
+
+	var changed []string
+
+	feed.Subscribe(func(_ context.Context, c *Change) {
+		changed = append(changed, c.Table)
+	})
+
+	var rec metricsRecorder
+
+	store := NewStore(db, func() *Visit {
+		return &Visit{}
+	}, WithPartitions(MonthlyPartitions("visited_at")), WithChangeFeed(feed), WithMetrics(&rec))
+
+	may := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+	jun := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	vv := []*Visit{
+		{ID: 1, Path: "/", VisitedAt: may},
+		{ID: 2, Path: "/about", VisitedAt: jun},
+		{ID: 3, Path: "/", VisitedAt: jun},
+	}
+
+	if err := store.Create(ctx, vv...); err != nil {
+		t.Fatalf("store.Create(ctx, vv...): %v\n", err)
+	}
+
+	count := func(t *testing.T, table string) int {
+		t.Helper()
+
+		var n int
+
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&n); err != nil {
+			t.Fatalf("db.QueryRowContext(ctx, ...).Scan(&n): %v\n", err)
+		}
+		return n
+	}
+
+	for table, want := range map[string]int{"visits": 0, "visits_2024_05": 1, "visits_2024_06": 2} {
+		if n := count(t, table); n != want {
+			t.Fatalf("count(t, %q) = %v, want = %v\n", table, n, want)
+		}
+	}
+
+	t.Run("between", func(t *testing.T) {
+		tests := []struct {
+			from, to time.Time
+			want     []int64
+		}{
+			{may, jun, []int64{1, 3}},
+			{jun, jun, []int64{3}},
+		}
+
+		for i, test := range tests {
+			mm, err := store.Between(test.from, test.to).Select(ctx, query.Columns("*"), query.WhereEq("path", query.Arg("/")), query.OrderAsc("id"))
+
+			if err != nil {
+				t.Fatalf("tests[%d] - store.Between(...).Select(ctx, ...): %v\n", i, err)
+			}
+
+			ids := make([]int64, 0, len(mm))
+
+			for _, m := range mm {
+				ids = append(ids, m.ID)
+			}
+
+			if !slices.Equal(ids, test.want) {
+				t.Fatalf("tests[%d] - ids = %v, want = %v\n", i, ids, test.want)
+			}
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		vv[1].Path = "/contact"
+
+		res, err := store.Update(ctx, vv[1])
+
+		if err != nil {
+			t.Fatalf("store.Update(ctx, vv[1]): %v\n", err)
+		}
+
+		if n, _ := res.RowsAffected(); n != 1 {
+			t.Fatalf("res.RowsAffected() = %v, want = %v\n", n, 1)
+		}
+
+		v, ok, err := store.Between(jun, jun).Get(ctx, query.WhereEq("id", query.Arg(2)))
+
+		if err != nil {
+			t.Fatalf("store.Between(jun, jun).Get(ctx, ...): %v\n", err)
+		}
+
+		if !ok || v.Path != "/contact" {
+			t.Fatalf("v = %v, want path = %q\n", v, "/contact")
+		}
+	})
+
+	t.Run("partition changed", func(t *testing.T) {
+		v := *vv[0]
+		v.VisitedAt = jun
+
+		if _, err := store.Update(ctx, &v); !errors.Is(err, ErrPartitionChanged) {
+			t.Fatalf("store.Update(ctx, &v) = %v, want = %v\n", err, ErrPartitionChanged)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		res, err := store.Delete(ctx, vv[0], vv[2])
+
+		if err != nil {
+			t.Fatalf("store.Delete(ctx, ...): %v\n", err)
+		}
+
+		if n, _ := res.RowsAffected(); n != 2 {
+			t.Fatalf("res.RowsAffected() = %v, want = %v\n", n, 2)
+		}
+
+		n, err := store.Between(may, jun).Count(ctx)
+
+		if err != nil {
+			t.Fatalf("store.Between(may, jun).Count(ctx): %v\n", err)
+		}
+
+		if n != 1 {
+			t.Fatalf("store.Between(may, jun).Count(ctx) = %v, want = %v\n", n, 1)
+		}
+	})
+
+	for _, table := range append(changed, rec.tables...) {
+		if table != "visits" {
+			t.Fatalf("table = %q, want = %q\n", table, "visits")
+		}
+	}
+
+	if len(changed) != 6 {
+		t.Fatalf("len(changed) = %v, want = %v\n", len(changed), 6)
+	}
+}
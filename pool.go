@@ -0,0 +1,70 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// PoolConfig is the configuration of the connection pool of a [sql.DB].
+//
+// MaxOpen is the maximum number of open connections to the database, where
+// zero means no limit.
+//
+// MaxIdle is the maximum number of idle connections kept in the pool, where
+// zero means no idle connections are kept.
+//
+// MaxLifetime is the maximum amount of time a connection may be reused, where
+// zero means connections are never closed due to their age.
+//
+// MaxIdleTime is the maximum amount of time a connection may be idle, where
+// zero means connections are never closed due to being idle.
+type PoolConfig struct {
+	MaxOpen     int
+	MaxIdle     int
+	MaxLifetime time.Duration
+	MaxIdleTime time.Duration
+}
+
+// DefaultPoolConfig returns the recommended [PoolConfig] for the given
+// driver.
+//
+// For PostgreSQL and MySQL, up to 25 connections are kept open, and are
+// recycled every 30 minutes, or after 5 minutes of being idle, so that
+// connections are not held open against a server that may be restarted or
+// sits behind a load balancer.
+//
+// For SQLite, up to 8 connections are kept open, and are never recycled,
+// since they are to a local file. Only a single connection can write at a
+// time, so a larger pool would only contend for the write lock.
+func DefaultPoolConfig(driver string) PoolConfig {
+	if d, _ := driverDialect(driver); d == SQLite {
+		return PoolConfig{
+			MaxOpen: 8,
+			MaxIdle: 8,
+		}
+	}
+
+	return PoolConfig{
+		MaxOpen:     25,
+		MaxIdle:     25,
+		MaxLifetime: 30 * time.Minute,
+		MaxIdleTime: 5 * time.Minute,
+	}
+}
+
+// ConfigurePool configures the connection pool of the given database with
+// the given [PoolConfig], for example,
+//
+//	db, err := sql.Open(driver, dsn)
+//
+//	if err != nil {
+//	    // Handle error.
+//	}
+//
+//	database.ConfigurePool(db, database.DefaultPoolConfig(driver))
+func ConfigurePool(db *sql.DB, cfg PoolConfig) {
+	db.SetMaxOpenConns(cfg.MaxOpen)
+	db.SetMaxIdleConns(cfg.MaxIdle)
+	db.SetConnMaxLifetime(cfg.MaxLifetime)
+	db.SetConnMaxIdleTime(cfg.MaxIdleTime)
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// SQLitePragmas is the typed configuration of the pragmas of an SQLite
+// database. Fields that are the zero value are left as the database's
+// default.
+//
+// BusyTimeout is how long a connection waits for a lock to be released before
+// returning SQLITE_BUSY.
+//
+// CacheSize is the size of the page cache. A positive value is the number of
+// pages, and a negative value is the size in KiB.
+//
+// JournalMode is the journal mode of the database, such as "WAL".
+//
+// Synchronous is how often the database is synced to disk, such as "NORMAL".
+//
+// TempStore is where temporary tables and indexes are kept, such as "memory".
+type SQLitePragmas struct {
+	BusyTimeout time.Duration
+	CacheSize   int
+	ForeignKeys bool
+	JournalMode string
+	Synchronous string
+	TempStore   string
+}
+
+// DefaultSQLitePragmas are the pragmas recommended for an SQLite database that
+// is accessed concurrently, such as by a web application. Foreign keys are
+// enforced, and the database uses WAL mode so readers do not block the writer.
+var DefaultSQLitePragmas = SQLitePragmas{
+	BusyTimeout: 5 * time.Second,
+	CacheSize:   1000000000,
+	ForeignKeys: true,
+	JournalMode: "WAL",
+	Synchronous: "NORMAL",
+	TempStore:   "memory",
+}
+
+// Pragmas returns the pragmas as a list of name=value strings, as used by
+// [Config]. This allows for the pragmas to be folded into the DSN of the
+// database so they are run on every new connection, for example,
+//
+//	db, err := database.Open("sqlite", database.Config{
+//	    Name:    "blog.sqlite",
+//	    Pragmas: database.DefaultSQLitePragmas.Pragmas(),
+//	})
+func (p SQLitePragmas) Pragmas() []string {
+	pragmas := make([]string, 0, 6)
+
+	// The busy timeout is set first so the remaining pragmas wait on any
+	// lock, such as when changing the journal mode.
+	if p.BusyTimeout > 0 {
+		pragmas = append(pragmas, "busy_timeout="+strconv.FormatInt(p.BusyTimeout.Milliseconds(), 10))
+	}
+
+	if p.CacheSize != 0 {
+		pragmas = append(pragmas, "cache_size="+strconv.Itoa(p.CacheSize))
+	}
+
+	if p.ForeignKeys {
+		pragmas = append(pragmas, "foreign_keys=true")
+	}
+
+	if p.JournalMode != "" {
+		pragmas = append(pragmas, "journal_mode="+p.JournalMode)
+	}
+
+	if p.Synchronous != "" {
+		pragmas = append(pragmas, "synchronous="+p.Synchronous)
+	}
+
+	if p.TempStore != "" {
+		pragmas = append(pragmas, "temp_store="+p.TempStore)
+	}
+	return pragmas
+}
+
+// Apply runs the pragmas on the given database. Pragmas such as journal_mode
+// are persisted in the database file, whereas others such as busy_timeout and
+// foreign_keys only apply to the connection they are run on. For a [sql.DB]
+// this would be a single connection from its pool, so such pragmas should be
+// folded into the DSN via [SQLitePragmas.Pragmas] instead, or applied to a
+// [sql.Conn].
+func (p SQLitePragmas) Apply(ctx context.Context, db DB) error {
+	for _, pragma := range p.Pragmas() {
+		if _, err := db.ExecContext(ctx, "PRAGMA "+pragma); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+// Package prometheus provides an implementation of [database.Metrics] that
+// exposes the metrics of a [database.Store] in the Prometheus text exposition
+// format.
+//
+// The [Collector] records a counter of the queries run by a Store, and a
+// histogram of their latencies, both labelled by table and operation,
+//
+//	collector := prometheus.New("myapp")
+//
+//	posts := database.NewStore(db, func() *Post {
+//	    return &Post{}
+//	}, database.WithMetrics(collector))
+//
+//	http.Handle("/metrics", collector)
+//
+// The statistics of the connection pool are also recorded as gauges, if the
+// Store is configured to sample them via [database.WithPoolStats].
+package prometheus
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewpillar/database"
+)
+
+// DefaultBuckets are the default histogram buckets, in seconds, used for
+// recording query latencies.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type series struct {
+	table string
+	op    string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// Collector records the metrics of the operations performed by a
+// [database.Store]. It is safe for concurrent use, and can be shared between
+// multiple stores.
+type Collector struct {
+	mu sync.Mutex
+
+	namespace string
+	buckets   []float64
+	queries   map[series][2]uint64
+	latencies map[series]*histogram
+
+	// pool is the last sample of the statistics of the connection pool, this
+	// is nil if the pool has not been sampled.
+	pool *sql.DBStats
+}
+
+var (
+	_ database.Metrics     = (*Collector)(nil)
+	_ database.PoolMetrics = (*Collector)(nil)
+)
+
+// New returns a new [Collector]. The given namespace is used to prefix the
+// names of the metrics, and can be empty. The given buckets are used for the
+// latency histogram, if none are given then [DefaultBuckets] are used.
+func New(namespace string, buckets ...float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return &Collector{
+		namespace: namespace,
+		buckets:   buckets,
+		queries:   make(map[series][2]uint64),
+		latencies: make(map[series]*histogram),
+	}
+}
+
+// Observe implements [database.Metrics].
+func (c *Collector) Observe(table string, op database.Op, d time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := series{
+		table: table,
+		op:    op.String(),
+	}
+
+	counts := c.queries[s]
+
+	if err != nil {
+		counts[1]++
+	} else {
+		counts[0]++
+	}
+	c.queries[s] = counts
+
+	h, ok := c.latencies[s]
+
+	if !ok {
+		h = &histogram{
+			counts: make([]uint64, len(c.buckets)),
+		}
+		c.latencies[s] = h
+	}
+
+	secs := d.Seconds()
+
+	for i, le := range c.buckets {
+		if secs <= le {
+			h.counts[i]++
+		}
+	}
+
+	h.sum += secs
+	h.count++
+}
+
+// ObservePool implements [database.PoolMetrics].
+func (c *Collector) ObservePool(stats sql.DBStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pool = &stats
+}
+
+func (c *Collector) name(s string) string {
+	if c.namespace == "" {
+		return s
+	}
+	return c.namespace + "_" + s
+}
+
+func sortedSeries[V any](m map[series]V) []series {
+	ss := make([]series, 0, len(m))
+
+	for s := range m {
+		ss = append(ss, s)
+	}
+
+	slices.SortFunc(ss, func(a, b series) int {
+		if n := strings.Compare(a.table, b.table); n != 0 {
+			return n
+		}
+		return strings.Compare(a.op, b.op)
+	})
+	return ss
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// WriteTo writes the recorded metrics to the given writer in the Prometheus
+// text exposition format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var buf strings.Builder
+
+	queries := c.name("queries_total")
+
+	fmt.Fprintf(&buf, "# HELP %s Total number of queries run by the store.\n", queries)
+	fmt.Fprintf(&buf, "# TYPE %s counter\n", queries)
+
+	for _, s := range sortedSeries(c.queries) {
+		counts := c.queries[s]
+
+		fmt.Fprintf(&buf, "%s{table=%q,op=%q,status=\"ok\"} %d\n", queries, s.table, s.op, counts[0])
+		fmt.Fprintf(&buf, "%s{table=%q,op=%q,status=\"error\"} %d\n", queries, s.table, s.op, counts[1])
+	}
+
+	duration := c.name("query_duration_seconds")
+
+	fmt.Fprintf(&buf, "# HELP %s Latency of the queries run by the store.\n", duration)
+	fmt.Fprintf(&buf, "# TYPE %s histogram\n", duration)
+
+	for _, s := range sortedSeries(c.latencies) {
+		h := c.latencies[s]
+
+		for i, le := range c.buckets {
+			fmt.Fprintf(&buf, "%s_bucket{table=%q,op=%q,le=%q} %d\n", duration, s.table, s.op, formatFloat(le), h.counts[i])
+		}
+
+		fmt.Fprintf(&buf, "%s_bucket{table=%q,op=%q,le=\"+Inf\"} %d\n", duration, s.table, s.op, h.count)
+		fmt.Fprintf(&buf, "%s_sum{table=%q,op=%q} %s\n", duration, s.table, s.op, formatFloat(h.sum))
+		fmt.Fprintf(&buf, "%s_count{table=%q,op=%q} %d\n", duration, s.table, s.op, h.count)
+	}
+
+	if c.pool != nil {
+		gauges := []struct {
+			name string
+			help string
+			val  int
+		}{
+			{"pool_open_connections", "Number of open connections in the pool.", c.pool.OpenConnections},
+			{"pool_in_use_connections", "Number of connections in the pool currently in use.", c.pool.InUse},
+			{"pool_idle_connections", "Number of idle connections in the pool.", c.pool.Idle},
+			{"pool_max_open_connections", "Maximum number of open connections in the pool.", c.pool.MaxOpenConnections},
+		}
+
+		for _, g := range gauges {
+			name := c.name(g.name)
+
+			fmt.Fprintf(&buf, "# HELP %s %s\n", name, g.help)
+			fmt.Fprintf(&buf, "# TYPE %s gauge\n", name)
+			fmt.Fprintf(&buf, "%s %d\n", name, g.val)
+		}
+
+		waits := c.name("pool_wait_count_total")
+
+		fmt.Fprintf(&buf, "# HELP %s Total number of connections waited for.\n", waits)
+		fmt.Fprintf(&buf, "# TYPE %s counter\n", waits)
+		fmt.Fprintf(&buf, "%s %d\n", waits, c.pool.WaitCount)
+
+		wait := c.name("pool_wait_duration_seconds_total")
+
+		fmt.Fprintf(&buf, "# HELP %s Total time spent waiting for connections.\n", wait)
+		fmt.Fprintf(&buf, "# TYPE %s counter\n", wait)
+		fmt.Fprintf(&buf, "%s %s\n", wait, formatFloat(c.pool.WaitDuration.Seconds()))
+	}
+
+	n, err := io.WriteString(w, buf.String())
+
+	return int64(n), err
+}
+
+// ServeHTTP writes the recorded metrics in the Prometheus text exposition
+// format, allowing for the Collector to be used as a scrape target.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
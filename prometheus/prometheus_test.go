@@ -0,0 +1,90 @@
+package prometheus
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database"
+)
+
+func TestCollector(t *testing.T) {
+	c := New("test", 0.1, 1)
+
+	c.Observe("posts", database.OpSelect, 50*time.Millisecond, nil)
+	c.Observe("posts", database.OpSelect, 500*time.Millisecond, nil)
+	c.Observe("posts", database.OpCreate, 2*time.Second, errors.New("error"))
+
+	var buf strings.Builder
+
+	if _, err := c.WriteTo(&buf); err != nil {
+		t.Fatalf("c.WriteTo(&buf): %v\n", err)
+	}
+
+	out := buf.String()
+
+	tests := []string{
+		`test_queries_total{table="posts",op="select",status="ok"} 2`,
+		`test_queries_total{table="posts",op="create",status="error"} 1`,
+		`test_query_duration_seconds_bucket{table="posts",op="select",le="0.1"} 1`,
+		`test_query_duration_seconds_bucket{table="posts",op="select",le="1"} 2`,
+		`test_query_duration_seconds_bucket{table="posts",op="create",le="1"} 0`,
+		`test_query_duration_seconds_bucket{table="posts",op="create",le="+Inf"} 1`,
+		`test_query_duration_seconds_sum{table="posts",op="select"} 0.55`,
+		`test_query_duration_seconds_count{table="posts",op="select"} 2`,
+	}
+
+	for _, want := range tests {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("output missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestCollectorPool(t *testing.T) {
+	c := New("test")
+
+	var buf strings.Builder
+
+	if _, err := c.WriteTo(&buf); err != nil {
+		t.Fatalf("c.WriteTo(&buf): %v\n", err)
+	}
+
+	if strings.Contains(buf.String(), "pool") {
+		t.Fatalf("output has pool metrics before pool observed\n%s", buf.String())
+	}
+
+	c.ObservePool(sql.DBStats{
+		MaxOpenConnections: 10,
+		OpenConnections:    4,
+		InUse:              3,
+		Idle:               1,
+		WaitCount:          7,
+		WaitDuration:       1500 * time.Millisecond,
+	})
+
+	buf.Reset()
+
+	if _, err := c.WriteTo(&buf); err != nil {
+		t.Fatalf("c.WriteTo(&buf): %v\n", err)
+	}
+
+	out := buf.String()
+
+	tests := []string{
+		`test_pool_open_connections 4`,
+		`test_pool_in_use_connections 3`,
+		`test_pool_idle_connections 1`,
+		`test_pool_max_open_connections 10`,
+		`test_pool_wait_count_total 7`,
+		`test_pool_wait_duration_seconds_total 1.5`,
+	}
+
+	for _, want := range tests {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("output missing %q\n%s", want, out)
+		}
+	}
+}
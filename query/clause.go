@@ -2,6 +2,7 @@ package query
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -20,6 +21,10 @@ const (
 	_returningClause                       // RETURNING
 	_setClause                             // SET
 	_joinClause                            // JOIN
+	_forClause                             // FOR
+	_restartClause                         // RESTART
+	_groupClause                           // GROUP BY
+	_conflictClause                        // ON
 )
 
 type clause interface {
@@ -81,6 +86,61 @@ func WhereLike(col string, expr Expr) Option {
 	return where("AND", Like(Ident(col), expr))
 }
 
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escapes the wildcards % and _ in the given string, along with the
+// escape character \, so that the string is matched literally by LIKE.
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+type groupExpr struct {
+	expr Expr
+}
+
+func (e groupExpr) Args() []any   { return e.expr.Args() }
+func (e groupExpr) Build() string { return "(" + e.expr.Build() + ")" }
+
+// Search matches rows where any of the given columns contains the given term,
+// case insensitively via ILIKE. The wildcards in the term are escaped via
+// [EscapeLike], so the term is matched literally. The columns are grouped in
+// parentheses, so the search can be combined with other WHERE clauses, for
+// example,
+//
+//	query.Select(
+//	    query.Columns("*"),
+//	    query.From("posts"),
+//	    query.WhereEq("user_id", query.Arg(1)),
+//	    query.Search("golang", "title", "content"),
+//	)
+//
+// would be built as,
+//
+//	SELECT * FROM posts WHERE (user_id = $1 AND (title ILIKE $2 OR content ILIKE $3))
+//
+// If the term is empty, then the query is not modified. Since ILIKE is only
+// supported by PostgreSQL, this cannot be used with other databases.
+func Search(term string, cols ...string) Option {
+	if term == "" || len(cols) == 0 {
+		return func(q *Query) *Query {
+			return q
+		}
+	}
+
+	pattern := "%" + EscapeLike(term) + "%"
+
+	conds := make([]Expr, 0, len(cols))
+
+	for _, col := range cols {
+		conds = append(conds, ILike(Ident(col), Arg(pattern)))
+	}
+
+	if len(conds) == 1 {
+		return where("AND", conds[0])
+	}
+	return where("AND", groupExpr{expr: Or(conds...)})
+}
+
 func WhereIsNot(col string, expr Expr) Option {
 	return where("AND", IsNot(Ident(col), expr))
 }
@@ -101,6 +161,23 @@ func WhereNotIn(col string, expr Expr) Option {
 	return where("AND", NotIn(Ident(col), expr))
 }
 
+// WhereExists matches rows for which the given subquery returns any rows, for
+// example,
+//
+//	query.WhereExists(query.Select(
+//	    query.Lit(1),
+//	    query.From("post_tags"),
+//	    query.Where(query.Eq(query.Ident("post_tags.post_id"), query.Ident("posts.id"))),
+//	))
+func WhereExists(q *Query) Option {
+	return where("AND", Exists(q))
+}
+
+// WhereNotExists matches rows for which the given subquery returns no rows.
+func WhereNotExists(q *Query) Option {
+	return where("AND", NotExists(q))
+}
+
 func OrWhere(expr Expr) Option {
 	return where("OR", expr)
 }
@@ -153,6 +230,158 @@ func OrWhereNotIn(col string, expr Expr) Option {
 	return OrWhere(NotIn(Ident(col), expr))
 }
 
+func OrWhereExists(q *Query) Option {
+	return OrWhere(Exists(q))
+}
+
+func OrWhereNotExists(q *Query) Option {
+	return OrWhere(NotExists(q))
+}
+
+// Restrict ANDs the WHERE clauses added by the given options with the WHERE
+// clauses of the query as a whole, however those clauses are conjoined. This
+// ensures that the rows matched by the query are always restricted by the
+// given options, even if the query has OR clauses, for example,
+//
+//	query.Select(
+//	    query.Columns("*"),
+//	    query.From("posts"),
+//	    query.WhereEq("user_id", query.Arg(1)),
+//	    query.OrWhereEq("featured", query.Arg(true)),
+//	    query.Restrict(query.WhereEq("org_id", query.Arg(10))),
+//	)
+//
+// would be built as,
+//
+//	SELECT * FROM posts WHERE (((user_id = $1) OR (featured = $2)) AND (org_id = $3))
+//
+// Unlike the other WHERE options, this should be given after all of the other
+// WHERE clauses of the query. This panics if the given options add anything
+// other than WHERE clauses.
+func Restrict(opts ...Option) Option {
+	return func(q *Query) *Query {
+		scratch := &Query{
+			stmt: q.stmt,
+		}
+
+		for _, opt := range opts {
+			scratch = opt(scratch)
+		}
+
+		if len(scratch.clauses) == 0 {
+			return q
+		}
+
+		for _, cl := range scratch.clauses {
+			if cl.kind() != _whereClause {
+				panic("query: Restrict options may only add WHERE clauses")
+			}
+		}
+
+		expr := foldWhere(scratch.clauses)
+
+		first, last := -1, -1
+
+		for i, cl := range q.clauses {
+			if cl.kind() == _whereClause {
+				if first < 0 {
+					first = i
+				}
+				last = i
+			}
+		}
+
+		if first < 0 {
+			// Insert the WHERE clause before any of the clauses that would
+			// follow it.
+			i := slices.IndexFunc(q.clauses, func(cl clause) bool {
+				switch cl.kind() {
+				case _groupClause, _orderClause, _limitClause, _offsetClause, _forClause, _unionClause, _returningClause:
+					return true
+				}
+				return false
+			})
+
+			if i < 0 {
+				i = len(q.clauses)
+			}
+
+			n := boundArgs(q.clauses[:i])
+
+			q.clauses = slices.Insert(q.clauses, i, clause(&whereClause{
+				conj: "AND",
+				expr: expr,
+			}))
+			q.args = slices.Insert(q.args, n, expr.Args()...)
+			return q
+		}
+
+		n := boundArgs(q.clauses[:last+1])
+
+		// Any other clauses between the WHERE clauses are kept before the
+		// grouped clause.
+		rest := make([]clause, 0)
+		where := make([]clause, 0, last-first+1)
+
+		for _, cl := range q.clauses[first : last+1] {
+			if cl.kind() == _whereClause {
+				where = append(where, cl)
+				continue
+			}
+			rest = append(rest, cl)
+		}
+
+		cl := &whereClause{
+			conj: "AND",
+			expr: And(groupExpr{expr: foldWhere(where)}, groupExpr{expr: expr}),
+		}
+
+		q.clauses = slices.Replace(q.clauses, first, last+1, append(rest, cl)...)
+		q.args = slices.Insert(q.args, n, expr.Args()...)
+		return q
+	}
+}
+
+// foldWhere folds the given WHERE clauses into a single expression, grouping
+// each clause so they are conjoined in the order given.
+func foldWhere(clauses []clause) Expr {
+	var expr Expr
+
+	for _, cl := range clauses {
+		wc := cl.(*whereClause)
+
+		if expr == nil {
+			expr = wc.expr
+			continue
+		}
+
+		expr = &andOrExpr{
+			conj:  " " + wc.conj + " ",
+			conds: []Expr{groupExpr{expr: expr}, groupExpr{expr: wc.expr}},
+		}
+	}
+	return expr
+}
+
+// boundArgs returns the number of arguments bound by the given clauses.
+func boundArgs(clauses []clause) int {
+	n := 0
+
+	for _, cl := range clauses {
+		switch v := cl.(type) {
+		case *whereClause:
+			n += len(v.expr.Args())
+		case *joinClause:
+			n += len(v.expr.Args())
+		case *valuesClause:
+			n += len(v.args)
+		case *setClause:
+			n += v.nargs
+		}
+	}
+	return n
+}
+
 func (c *whereClause) Args() []any      { return nil }
 func (c *whereClause) Build() string    { return c.expr.Build() }
 func (c *whereClause) kind() clauseKind { return _whereClause }
@@ -226,6 +455,41 @@ func (c offsetClause) Args() []any      { return nil }
 func (c offsetClause) Build() string    { return strconv.FormatInt(c.n, 10) }
 func (c offsetClause) kind() clauseKind { return _offsetClause }
 
+type groupClause struct {
+	cols []string
+}
+
+// GroupBy groups the rows of a SELECT by the given columns. Since GROUP BY
+// must come before ORDER BY, LIMIT, and OFFSET, the clause is placed before
+// any of these clauses that have already been given to the query. This allows
+// for a GROUP BY to be added to a query built from options given elsewhere.
+func GroupBy(cols ...string) Option {
+	return func(q *Query) *Query {
+		i := slices.IndexFunc(q.clauses, func(cl clause) bool {
+			switch cl.kind() {
+			case _orderClause, _limitClause, _offsetClause, _forClause, _unionClause:
+				return true
+			}
+			return false
+		})
+
+		if i < 0 {
+			i = len(q.clauses)
+		}
+
+		var cl clause = &groupClause{
+			cols: cols,
+		}
+
+		q.clauses = slices.Insert(q.clauses, i, cl)
+		return q
+	}
+}
+
+func (c *groupClause) Args() []any      { return nil }
+func (c *groupClause) Build() string    { return strings.Join(c.cols, ", ") }
+func (c *groupClause) kind() clauseKind { return _groupClause }
+
 type orderClause struct {
 	cols []string
 	dir  string
@@ -256,13 +520,85 @@ func (c *orderClause) Build() string    { return strings.Join(c.cols, ", ") + "
 func (c *orderClause) kind() clauseKind { return _orderClause }
 
 type unionClause struct {
-	q *Query
+	q   *Query
+	all bool
 }
 
 func (c *unionClause) Args() []any      { return nil }
 func (c *unionClause) Build() string    { return c.q.buildInitial() }
 func (c *unionClause) kind() clauseKind { return _unionClause }
 
+// conflictClause is the clause of an INSERT query that handles rows that
+// conflict with existing rows, built after the ON keyword.
+type conflictClause struct {
+	action string
+}
+
+func onConflict(target []string, action string) Option {
+	if len(target) > 0 {
+		action = "(" + strings.Join(target, ", ") + ") " + action
+	}
+	return on("CONFLICT " + action)
+}
+
+func on(action string) Option {
+	return func(q *Query) *Query {
+		q.clauses = append(q.clauses, &conflictClause{
+			action: action,
+		})
+		return q
+	}
+}
+
+// OnConflictDoNothing adds an ON CONFLICT DO NOTHING clause to an INSERT query
+// for the given conflict target. If no columns are given then no conflict
+// target is built into the clause. This is supported by PostgreSQL and
+// SQLite.
+func OnConflictDoNothing(target ...string) Option {
+	return onConflict(target, "DO NOTHING")
+}
+
+// OnConflictDoUpdate adds an ON CONFLICT DO UPDATE clause to an INSERT query
+// for the given conflict target, setting each of the given columns to the
+// value that would have been inserted, for example,
+//
+//	query.Insert(
+//	    "users",
+//	    query.Columns("id", "email"),
+//	    query.Values(1, "me@example.com"),
+//	    query.OnConflictDoUpdate([]string{"id"}, "email"),
+//	)
+//
+// would be built as,
+//
+//	INSERT INTO users (id, email) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email
+//
+// This is supported by PostgreSQL and SQLite.
+func OnConflictDoUpdate(target []string, cols ...string) Option {
+	set := make([]string, 0, len(cols))
+
+	for _, col := range cols {
+		set = append(set, col+" = EXCLUDED."+col)
+	}
+	return onConflict(target, "DO UPDATE SET "+strings.Join(set, ", "))
+}
+
+// OnDuplicateKeyUpdate adds an ON DUPLICATE KEY UPDATE clause to an INSERT
+// query, setting each of the given columns to the value that would have been
+// inserted. This is the MySQL equivalent of [OnConflictDoUpdate].
+func OnDuplicateKeyUpdate(cols ...string) Option {
+	set := make([]string, 0, len(cols))
+
+	for _, col := range cols {
+		set = append(set, col+" = VALUES("+col+")")
+	}
+	return on("DUPLICATE KEY UPDATE " + strings.Join(set, ", "))
+}
+
+func (c *conflictClause) Args() []any      { return nil }
+func (c *conflictClause) Build() string    { return c.action }
+func (c *conflictClause) kind() clauseKind { return _conflictClause }
+
 type returningClause struct {
 	cols []string
 }
@@ -283,14 +619,18 @@ func (c *returningClause) kind() clauseKind { return _returningClause }
 type setClause struct {
 	col  string
 	expr Expr
+
+	// nargs is the number of arguments bound by the original expression.
+	nargs int
 }
 
 func Set(col string, expr Expr) Option {
 	return func(q *Query) *Query {
 		if q.stmt == updateStmt {
 			q.clauses = append(q.clauses, &setClause{
-				col:  col,
-				expr: Lit(expr.Build()),
+				col:   col,
+				expr:  Lit(expr.Build()),
+				nargs: len(expr.Args()),
 			})
 			q.args = append(q.args, expr.Args()...)
 		}
@@ -307,25 +647,33 @@ type valuesClause struct {
 	args  []any
 }
 
+// Values adds a VALUES clause for the given values to an INSERT query. Each
+// value is bound to a parameter, unless it is an [Expr], in which case the
+// expression is built into the query, along with any arguments of its own. This
+// allows for server side expressions to be given as values, for example,
+//
+//	query.Insert("posts", query.Columns("title", "created_at"), query.Values("Hello", query.Lit("NOW()")))
 func Values(vals ...any) Option {
-	tmp := make([]any, 0, len(vals))
+	items := make([]string, 0, len(vals))
+	args := make([]any, 0, len(vals))
 
 	for _, val := range vals {
-		tmp = append(tmp, val)
+		if expr, ok := val.(Expr); ok {
+			items = append(items, expr.Build())
+			args = append(args, expr.Args()...)
+			continue
+		}
+
+		items = append(items, "?")
+		args = append(args, val)
 	}
 
 	return func(q *Query) *Query {
-		items := make([]string, 0, len(tmp))
-
-		for range tmp {
-			items = append(items, "?")
-		}
-
 		q.clauses = append(q.clauses, &valuesClause{
 			items: items,
-			args:  tmp,
+			args:  args,
 		})
-		q.args = append(q.args, tmp...)
+		q.args = append(q.args, args...)
 		return q
 	}
 }
@@ -335,20 +683,123 @@ func (c *valuesClause) Build() string    { return "(" + strings.Join(c.items, ",
 func (c *valuesClause) kind() clauseKind { return _valuesClause }
 
 type joinClause struct {
+	// typ is the type of the join, such as LEFT or RIGHT, this is empty for
+	// an inner join.
+	typ   string
 	table string
+	alias string
 	expr  Expr
 }
 
-func Join(table string, expr Expr) Option {
+func join(typ, table, alias string, expr Expr) Option {
 	return func(q *Query) *Query {
 		q.clauses = append(q.clauses, &joinClause{
+			typ:   typ,
 			table: table,
+			alias: alias,
 			expr:  expr,
 		})
+		q.args = append(q.args, expr.Args()...)
+
 		return q
 	}
 }
 
-func (c *joinClause) Args() []any      { return nil }
-func (c *joinClause) Build() string    { return fmt.Sprintf("%s ON %s", c.table, c.expr.Build()) }
+func Join(table string, expr Expr) Option {
+	return join("", table, "", expr)
+}
+
+// JoinAs joins the given table under the given alias. This allows for the same
+// table to be joined multiple times, for example,
+//
+//	query.Select(
+//	    query.Columns("*"),
+//	    query.From("messages"),
+//	    query.JoinAs("users", "sender", query.Eq(query.Ident("messages.sender_id"), query.Ident("sender.id"))),
+//	    query.JoinAs("users", "recipient", query.Eq(query.Ident("messages.recipient_id"), query.Ident("recipient.id"))),
+//	)
+func JoinAs(table, alias string, expr Expr) Option {
+	return join("", table, alias, expr)
+}
+
+func LeftJoin(table string, expr Expr) Option {
+	return join("LEFT", table, "", expr)
+}
+
+// LeftJoinAs is like [JoinAs], only a LEFT JOIN is performed.
+func LeftJoinAs(table, alias string, expr Expr) Option {
+	return join("LEFT", table, alias, expr)
+}
+
+func RightJoin(table string, expr Expr) Option {
+	return join("RIGHT", table, "", expr)
+}
+
+// RightJoinAs is like [JoinAs], only a RIGHT JOIN is performed.
+func RightJoinAs(table, alias string, expr Expr) Option {
+	return join("RIGHT", table, alias, expr)
+}
+
+func (c *joinClause) Args() []any { return nil }
+
+func (c *joinClause) Build() string {
+	table := c.table
+
+	if c.alias != "" {
+		table += " AS " + c.alias
+	}
+	return fmt.Sprintf("%s ON %s", table, c.expr.Build())
+}
+
 func (c *joinClause) kind() clauseKind { return _joinClause }
+
+type forClause struct {
+	lock string
+}
+
+func lock(s string) Option {
+	return func(q *Query) *Query {
+		q.clauses = append(q.clauses, forClause{
+			lock: s,
+		})
+		return q
+	}
+}
+
+// ForUpdate adds a FOR UPDATE clause to the query, locking the selected rows
+// until the end of the current transaction.
+func ForUpdate() Option {
+	return lock("UPDATE")
+}
+
+// ForUpdateSkipLocked adds a FOR UPDATE SKIP LOCKED clause to the query, so
+// that rows which are already locked are skipped rather than waited on.
+func ForUpdateSkipLocked() Option {
+	return lock("UPDATE SKIP LOCKED")
+}
+
+// ForUpdateNoWait adds a FOR UPDATE NOWAIT clause to the query, so that an
+// error is returned if any of the rows are already locked, rather than waiting
+// on them.
+func ForUpdateNoWait() Option {
+	return lock("UPDATE NOWAIT")
+}
+
+func (c forClause) Args() []any      { return nil }
+func (c forClause) Build() string    { return c.lock }
+func (c forClause) kind() clauseKind { return _forClause }
+
+type restartClause struct{}
+
+// RestartIdentity adds a RESTART IDENTITY clause to a TRUNCATE query, which
+// resets the sequences of the truncated table. This is specific to PostgreSQL.
+func RestartIdentity() Option {
+	return func(q *Query) *Query {
+		q.clauses = append(q.clauses, restartClause{})
+		return q
+	}
+}
+
+func (c restartClause) Args() []any      { return nil }
+func (c restartClause) Build() string    { return "IDENTITY" }
+func (c restartClause) kind() clauseKind { return _restartClause }
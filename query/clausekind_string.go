@@ -18,11 +18,15 @@ func _() {
 	_ = x[_returningClause-8]
 	_ = x[_setClause-9]
 	_ = x[_joinClause-10]
+	_ = x[_forClause-11]
+	_ = x[_restartClause-12]
+	_ = x[_groupClause-13]
+	_ = x[_conflictClause-14]
 }
 
-const _clauseKind_name = "FROMLIMITOFFSETORDER BYUNIONVALUESWHERERETURNINGSETJOIN"
+const _clauseKind_name = "FROMLIMITOFFSETORDER BYUNIONVALUESWHERERETURNINGSETJOINFORRESTARTGROUP BYON"
 
-var _clauseKind_index = [...]uint8{0, 4, 9, 15, 23, 28, 34, 39, 48, 51, 55}
+var _clauseKind_index = [...]uint8{0, 4, 9, 15, 23, 28, 34, 39, 48, 51, 55, 58, 65, 73, 75}
 
 func (i clauseKind) String() string {
 	i -= 1
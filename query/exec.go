@@ -0,0 +1,47 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Executor is the interface used for running a built [Query]. This is
+// implemented by [sql.DB], [sql.Tx], and [sql.Conn]. Queries are run with
+// [Dollar] placeholders, as built via [Query.Build], so for databases that do
+// not accept these, such as MySQL, the query should be built via
+// [Query.BuildWith] and run directly.
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+var (
+	_ Executor = (*sql.DB)(nil)
+	_ Executor = (*sql.Tx)(nil)
+	_ Executor = (*sql.Conn)(nil)
+)
+
+// Exec builds the query and runs it against the given executor, for queries
+// that return no rows, for example,
+//
+//	q := query.Delete("posts", query.WhereEq("id", query.Arg(10)))
+//
+//	res, err := q.Exec(ctx, db)
+func (q *Query) Exec(ctx context.Context, db Executor) (sql.Result, error) {
+	return db.ExecContext(ctx, q.Build(), q.Args()...)
+}
+
+// QueryRows builds the query and runs it against the given executor,
+// returning the resulting rows.
+func (q *Query) QueryRows(ctx context.Context, db Executor) (*sql.Rows, error) {
+	return db.QueryContext(ctx, q.Build(), q.Args()...)
+}
+
+// QueryRow builds the query and runs it against the given executor, returning
+// at most one row.
+func (q *Query) QueryRow(ctx context.Context, db Executor) *sql.Row {
+	return db.QueryRowContext(ctx, q.Build(), q.Args()...)
+}
@@ -0,0 +1,77 @@
+package query
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func Test_QueryExec(t *testing.T) {
+	ctx := t.Context()
+
+	db, err := sql.Open("sqlite", ":memory:")
+
+	if err != nil {
+		t.Fatalf("sql.Open(%q, %q): %v\n", "sqlite", ":memory:", err)
+	}
+
+	defer db.Close()
+
+	// Each connection to an in-memory database has its own database, so
+	// limit the pool to a single connection.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.ExecContext(ctx, "CREATE TABLE posts (id INTEGER, title TEXT)"); err != nil {
+		t.Fatalf("db.ExecContext(ctx, ...): %v\n", err)
+	}
+
+	for i, title := range []string{"first", "second"} {
+		q := Insert("posts", Columns("id", "title"), Values(i+1, title))
+
+		if _, err := q.Exec(ctx, db); err != nil {
+			t.Fatalf("q.Exec(ctx, db): %v\n", err)
+		}
+	}
+
+	var title string
+
+	q := Select(Columns("title"), From("posts"), WhereEq("id", Arg(2)))
+
+	if err := q.QueryRow(ctx, db).Scan(&title); err != nil {
+		t.Fatalf("q.QueryRow(ctx, db).Scan(&title): %v\n", err)
+	}
+
+	if title != "second" {
+		t.Errorf("title = %q, want = %q\n", title, "second")
+	}
+
+	q = Select(Columns("id"), From("posts"), OrderAsc("id"))
+
+	rows, err := q.QueryRows(ctx, db)
+
+	if err != nil {
+		t.Fatalf("q.QueryRows(ctx, db): %v\n", err)
+	}
+
+	defer rows.Close()
+
+	var ids []int64
+
+	for rows.Next() {
+		var id int64
+
+		if err := rows.Scan(&id); err != nil {
+			t.Fatalf("rows.Scan(&id): %v\n", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		t.Fatalf("rows.Err(): %v\n", err)
+	}
+
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("ids = %v, want = %v\n", ids, []int64{1, 2})
+	}
+}
@@ -165,6 +165,15 @@ func Lit(val any) Expr {
 func (e litExpr) Args() []any   { return nil }
 func (e litExpr) Build() string { return fmt.Sprintf("%v", e.val) }
 
+// Default returns the DEFAULT keyword as an expression. This would be used as
+// a value in an INSERT or UPDATE query for setting a column to its default.
+// This is not supported by SQLite.
+func Default() Expr {
+	return litExpr{
+		val: "DEFAULT",
+	}
+}
+
 type callExpr struct {
 	name string
 	args []Expr
@@ -180,6 +189,36 @@ func Sum(expr Expr) Expr {
 	}
 }
 
+// Avg returns the AVG aggregate call expression on the given column.
+func Avg(expr Expr) Expr {
+	return &callExpr{
+		name: "AVG",
+		args: []Expr{
+			expr,
+		},
+	}
+}
+
+// Min returns the MIN aggregate call expression on the given column.
+func Min(expr Expr) Expr {
+	return &callExpr{
+		name: "MIN",
+		args: []Expr{
+			expr,
+		},
+	}
+}
+
+// Max returns the MAX aggregate call expression on the given column.
+func Max(expr Expr) Expr {
+	return &callExpr{
+		name: "MAX",
+		args: []Expr{
+			expr,
+		},
+	}
+}
+
 func Lower(expr Expr) Expr {
 	return &callExpr{
 		name: "LOWER",
@@ -203,6 +242,16 @@ func Count(cols ...string) Expr {
 	}
 }
 
+// Call returns a call expression of the function with the given name and
+// arguments, for example Call("pg_notify", Arg("posts"), Arg("1")) would be
+// built as pg_notify($1, $2).
+func Call(name string, args ...Expr) Expr {
+	return &callExpr{
+		name: name,
+		args: args,
+	}
+}
+
 func (e *callExpr) Args() []any {
 	args := make([]any, 0)
 
@@ -331,6 +380,15 @@ func Like(a, b Expr) Expr {
 	}
 }
 
+// ILike a ILIKE b, this is only supported by PostgreSQL.
+func ILike(a, b Expr) Expr {
+	return &opExpr{
+		left:  a,
+		op:    "ILIKE",
+		right: b,
+	}
+}
+
 // Is a IS b
 func Is(a, b Expr) Expr {
 	return &opExpr{
@@ -396,6 +454,30 @@ type asClause struct {
 	out string
 }
 
+type existsExpr struct {
+	op string
+	q  *Query
+}
+
+// Exists EXISTS (q)
+func Exists(q *Query) Expr {
+	return &existsExpr{
+		op: "EXISTS",
+		q:  q,
+	}
+}
+
+// NotExists NOT EXISTS (q)
+func NotExists(q *Query) Expr {
+	return &existsExpr{
+		op: "NOT EXISTS",
+		q:  q,
+	}
+}
+
+func (e *existsExpr) Args() []any   { return e.q.Args() }
+func (e *existsExpr) Build() string { return fmt.Sprintf("%s (%s)", e.op, e.q.buildInitial()) }
+
 // As specifies an AS expression on the given expression. For example,
 //
 //	query.As(query.Count("id"), "id_count")
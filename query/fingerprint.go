@@ -0,0 +1,41 @@
+package query
+
+import (
+	"encoding/hex"
+	"hash/fnv"
+	"regexp"
+)
+
+var (
+	// reArgList matches a parenthesized list of placeholders, such as the
+	// list of an IN, or a row of VALUES.
+	reArgList = regexp.MustCompile(`\(\?(?:, \?)*\)`)
+
+	// reArgRows matches consecutive lists of placeholders, once collapsed by
+	// reArgList, such as the rows of a multi-row INSERT.
+	reArgRows = regexp.MustCompile(`\(\?\)(?:, \(\?\))+`)
+)
+
+// normalize returns the built query with any lists of placeholders collapsed
+// into a single placeholder.
+func normalize(q *Query) string {
+	s := reArgList.ReplaceAllLiteralString(q.buildInitial(), "(?)")
+	return reArgRows.ReplaceAllLiteralString(s, "(?)")
+}
+
+// Fingerprint returns an identifier for the shape of the given query. The
+// arguments of the query are ignored, as is the length of any list of
+// arguments, such as that of an IN or a multi-row INSERT, so that the
+// following queries would have the same fingerprint,
+//
+//	query.Select(query.Columns("*"), query.From("posts"), query.Where(query.In(query.Ident("id"), query.List(1, 2))))
+//	query.Select(query.Columns("*"), query.From("posts"), query.Where(query.In(query.Ident("id"), query.List(3, 4, 5))))
+//
+// The fingerprint is a short hex string, making it suitable as the label of a
+// metric, or as a cache key.
+func Fingerprint(q *Query) string {
+	h := fnv.New64a()
+	h.Write([]byte(normalize(q)))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
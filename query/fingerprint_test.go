@@ -0,0 +1,53 @@
+package query
+
+import "testing"
+
+func Test_Fingerprint(t *testing.T) {
+	tests := []struct {
+		a, b *Query
+		same bool
+	}{
+		{
+			Select(Columns("*"), From("posts"), WhereEq("id", Arg(1))),
+			Select(Columns("*"), From("posts"), WhereEq("id", Arg(2))),
+			true,
+		},
+		{
+			Select(Columns("*"), From("posts"), Where(In(Ident("id"), List(1, 2)))),
+			Select(Columns("*"), From("posts"), Where(In(Ident("id"), List(3, 4, 5)))),
+			true,
+		},
+		{
+			Insert("posts", Columns("id", "title"), Values(1, "first")),
+			Insert("posts", Columns("id", "title"), Values(1, "first"), Values(2, "second")),
+			true,
+		},
+		{
+			Select(Columns("*"), From("posts"), WhereEq("id", Arg(1))),
+			Select(Columns("*"), From("posts"), WhereEq("user_id", Arg(1))),
+			false,
+		},
+		{
+			Select(Columns("*"), From("posts"), Where(In(Ident("id"), List(1, 2)))),
+			Select(Columns("*"), From("posts"), Where(NotIn(Ident("id"), List(1, 2)))),
+			false,
+		},
+		{
+			Insert("posts", Columns("id"), Values(1)),
+			Insert("users", Columns("id"), Values(1)),
+			false,
+		},
+	}
+
+	for i, test := range tests {
+		a, b := Fingerprint(test.a), Fingerprint(test.b)
+
+		if len(a) != 16 {
+			t.Errorf("tests[%d] - len(Fingerprint(a)) = %v, want = %v\n", i, len(a), 16)
+		}
+
+		if same := a == b; same != test.same {
+			t.Errorf("tests[%d] - Fingerprint(a) == Fingerprint(b) = %v, want = %v\n\t%s\n\t%s\n", i, same, test.same, normalize(test.a), normalize(test.b))
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package query
 
 import (
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -15,6 +17,8 @@ const (
 	updateStmt                                // UPDATE
 	selectDistinctStmt                        // SELECT DISTINCT
 	selectDistinctOnStmt                      // SELECT DISTINCT ON
+	truncateStmt                              // TRUNCATE TABLE
+	copyStmt                                  // COPY
 )
 
 type Query struct {
@@ -23,6 +27,24 @@ type Query struct {
 	exprs   []Expr
 	clauses []clause
 	args    []any
+
+	// conflict is the conflict resolution of an INSERT, as set via OrAbort,
+	// OrIgnore, or OrReplace.
+	conflict string
+
+	// ignore is whether an INSERT ignores rows that would violate a
+	// constraint, as set via Ignore.
+	ignore bool
+
+	// ctes are the common table expressions of the query, as set via With and
+	// WithRecursive.
+	ctes      []cte
+	recursive bool
+}
+
+type cte struct {
+	name string
+	q    *Query
 }
 
 type Option func(*Query) *Query
@@ -52,6 +74,67 @@ func Insert(table string, expr Expr, opts ...Option) *Query {
 	return q
 }
 
+// InsertMap returns an INSERT query for the given table, with the columns and
+// values derived from the keys and values of the given map. The columns are
+// sorted, so the built query is stable. As with [Values], a value that is an
+// [Expr] is built into the query, for example,
+//
+//	query.InsertMap("post_tags", map[string]any{
+//	    "post_id": 1,
+//	    "name":    "golang",
+//	})
+func InsertMap(table string, m map[string]any, opts ...Option) *Query {
+	cols := slices.Sorted(maps.Keys(m))
+	vals := make([]any, 0, len(cols))
+
+	for _, col := range cols {
+		vals = append(vals, m[col])
+	}
+
+	opts = append([]Option{
+		Values(vals...),
+	}, opts...)
+
+	return Insert(table, Columns(cols...), opts...)
+}
+
+func or(conflict string) Option {
+	return func(q *Query) *Query {
+		q.conflict = conflict
+		return q
+	}
+}
+
+// OrAbort sets the conflict resolution of an INSERT query to ABORT, giving
+// INSERT OR ABORT. This is the default behaviour, and is specific to SQLite.
+func OrAbort() Option {
+	return or("ABORT")
+}
+
+// OrIgnore sets the conflict resolution of an INSERT query to IGNORE, giving
+// INSERT OR IGNORE. Rows that would violate a constraint are skipped rather
+// than failing the query. This is specific to SQLite.
+func OrIgnore() Option {
+	return or("IGNORE")
+}
+
+// OrReplace sets the conflict resolution of an INSERT query to REPLACE, giving
+// INSERT OR REPLACE. Existing rows that would violate a uniqueness constraint
+// are deleted before the new row is inserted. This is specific to SQLite.
+func OrReplace() Option {
+	return or("REPLACE")
+}
+
+// Ignore makes an INSERT query skip rows that would violate a constraint
+// rather than failing, giving INSERT IGNORE. This is specific to MySQL, see
+// [OrIgnore] for SQLite.
+func Ignore() Option {
+	return func(q *Query) *Query {
+		q.ignore = true
+		return q
+	}
+}
+
 func Select(expr Expr, opts ...Option) *Query {
 	q := &Query{
 		stmt:  selectStmt,
@@ -103,18 +186,97 @@ func Update(table string, opts ...Option) *Query {
 	return q
 }
 
+// Truncate returns a TRUNCATE TABLE query for the given table. Not all
+// databases support TRUNCATE, such as SQLite, in which case a DELETE query
+// should be used instead.
+func Truncate(table string, opts ...Option) *Query {
+	q := &Query{
+		stmt:  truncateStmt,
+		table: table,
+	}
+
+	for _, opt := range opts {
+		q = opt(q)
+	}
+	return q
+}
+
+// Copy returns a COPY ... FROM STDIN query for bulk loading rows into the given
+// columns of the table, for example,
+//
+//	q := query.Copy("posts", query.Columns("title", "content"))
+//
+// would result in the following SQL code when built,
+//
+//	COPY posts (title, content) FROM STDIN
+//
+// This is only supported by PostgreSQL, and the rows themselves are sent by
+// the driver.
+func Copy(table string, expr Expr) *Query {
+	return &Query{
+		stmt:  copyStmt,
+		table: table,
+		exprs: []Expr{expr},
+	}
+}
+
 func Union(queries ...*Query) *Query {
+	return union(false, queries)
+}
+
+// UnionAll is like [Union], only the queries are combined via UNION ALL, so
+// duplicate rows are not removed.
+func UnionAll(queries ...*Query) *Query {
+	return union(true, queries)
+}
+
+func union(all bool, queries []*Query) *Query {
 	var union Query
 
 	for _, q := range queries {
-		union.args = append(union.args, q.args...)
+		union.args = append(union.args, q.Args()...)
 		union.clauses = append(union.clauses, &unionClause{
-			q: q,
+			q:   q,
+			all: all,
 		})
 	}
 	return &union
 }
 
+// With adds a common table expression of the given name to the query. The
+// expression is built before the statement of the query, regardless of the
+// order the option is given in, for example,
+//
+//	query.Select(
+//	    query.Columns("*"),
+//	    query.From("recent"),
+//	    query.With("recent", query.Select(query.Columns("*"), query.From("posts"), query.OrderDesc("id"), query.Limit(10))),
+//	)
+//
+// would be built as,
+//
+//	WITH recent AS (SELECT * FROM posts ORDER BY id DESC LIMIT 10) SELECT * FROM recent
+func With(name string, q *Query) Option {
+	return func(q2 *Query) *Query {
+		q2.ctes = append(q2.ctes, cte{
+			name: name,
+			q:    q,
+		})
+		return q2
+	}
+}
+
+// WithRecursive is like [With], only the query is built with WITH RECURSIVE,
+// so that the given query can refer to itself. This would typically be a
+// [Union] of the initial query, and the recursive query.
+func WithRecursive(name string, q *Query) Option {
+	return func(q2 *Query) *Query {
+		q2 = With(name, q)(q2)
+		q2.recursive = true
+		return q2
+	}
+}
+
 func Options(opts ...Option) Option {
 	return func(q *Query) *Query {
 		for _, opt := range opts {
@@ -124,7 +286,67 @@ func Options(opts ...Option) Option {
 	}
 }
 
-func (q *Query) Args() []any { return q.args }
+// Statement returns the statement of the query, such as SELECT or INSERT. An
+// empty string is returned for a query without a statement, such as a
+// [Union].
+func (q *Query) Statement() string {
+	if q.stmt == 0 {
+		return ""
+	}
+	return q.stmt.String()
+}
+
+// Table returns the table the query operates on. This is the table given to
+// [Insert], [Update], [Delete], [Truncate], or [Copy], or the first table in
+// the FROM clause of a [Select]. An empty string is returned if the query has
+// no table.
+func (q *Query) Table() string {
+	if q.table != "" {
+		return q.table
+	}
+
+	for _, cl := range q.clauses {
+		if from, ok := cl.(*fromClause); ok {
+			return from.table
+		}
+	}
+	return ""
+}
+
+// Args returns the arguments of the query, in the order their placeholders
+// appear in the built query.
+func (q *Query) Args() []any {
+	args := make([]any, 0, len(q.args))
+
+	for _, cte := range q.ctes {
+		args = append(args, cte.q.Args()...)
+	}
+
+	for _, expr := range q.exprs {
+		args = append(args, expr.Args()...)
+	}
+	return append(args, q.args...)
+}
+
+// Filter returns the WHERE clause of the query, without the WHERE keyword,
+// along with its arguments. The clause is built with the given style of
+// placeholders for its arguments. An empty string is returned if the query has
+// no WHERE clause.
+func (q *Query) Filter(p Placeholder) (string, []any) {
+	var where Query
+
+	for _, cl := range q.clauses {
+		if v, ok := cl.(*whereClause); ok {
+			where.clauses = append(where.clauses, v)
+			where.args = append(where.args, v.expr.Args()...)
+		}
+	}
+
+	if len(where.clauses) == 0 {
+		return "", nil
+	}
+	return strings.TrimPrefix(where.BuildWith(p), _whereClause.String()+" "), where.Args()
+}
 
 func (q *Query) conj(cl clause) string {
 	if cl == nil {
@@ -135,8 +357,11 @@ func (q *Query) conj(cl clause) string {
 	case *whereClause:
 		return " " + v.conj + " "
 	case *unionClause:
+		if v.all {
+			return " " + cl.kind().String() + " ALL "
+		}
 		return " " + cl.kind().String() + " "
-	case *setClause, *valuesClause, *orderClause:
+	case *setClause, *valuesClause, *orderClause, *groupClause:
 		return ", "
 	default:
 		return " "
@@ -149,12 +374,41 @@ func (q *Query) conj(cl clause) string {
 func (q *Query) buildInitial() string {
 	var buf strings.Builder
 
+	if len(q.ctes) > 0 {
+		buf.WriteString("WITH ")
+
+		if q.recursive {
+			buf.WriteString("RECURSIVE ")
+		}
+
+		for i, cte := range q.ctes {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+
+			buf.WriteString(cte.name)
+			buf.WriteString(" AS (")
+			buf.WriteString(cte.q.buildInitial())
+			buf.WriteByte(')')
+		}
+		buf.WriteByte(' ')
+	}
+
 	if q.stmt > 0 {
 		buf.WriteString(q.stmt.String())
 	}
 
 	switch q.stmt {
 	case insertStmt:
+		if q.conflict != "" {
+			buf.WriteString(" OR ")
+			buf.WriteString(q.conflict)
+		}
+
+		if q.ignore {
+			buf.WriteString(" IGNORE")
+		}
+
 		buf.WriteString(" INTO ")
 		buf.WriteString(q.table)
 	case updateStmt:
@@ -165,6 +419,20 @@ func (q *Query) buildInitial() string {
 		buf.WriteString(" FROM ")
 		buf.WriteString(q.table)
 		buf.WriteByte(' ')
+	case truncateStmt:
+		buf.WriteByte(' ')
+		buf.WriteString(q.table)
+
+		if len(q.clauses) > 0 {
+			buf.WriteByte(' ')
+		}
+	case copyStmt:
+		buf.WriteByte(' ')
+		buf.WriteString(q.table)
+		buf.WriteString(" (")
+		buf.WriteString(q.exprs[0].Build())
+		buf.WriteString(") FROM STDIN")
+		return buf.String()
 	}
 
 	for i, expr := range q.exprs {
@@ -183,7 +451,10 @@ func (q *Query) buildInitial() string {
 		if q.stmt == selectDistinctOnStmt && i == 0 {
 			continue
 		}
-		buf.WriteByte(' ')
+
+		if i < len(q.exprs)-1 || len(q.clauses) > 0 {
+			buf.WriteByte(' ')
+		}
 	}
 
 	clauses := make(map[clauseKind]struct{})
@@ -201,7 +472,17 @@ func (q *Query) buildInitial() string {
 
 		kind := cl.kind()
 
-		if kind != _unionClause {
+		if kind == _joinClause {
+			if typ := cl.(*joinClause).typ; typ != "" {
+				buf.WriteString(typ)
+				buf.WriteByte(' ')
+			}
+
+			// Each join is its own clause, so the string of the clause kind is
+			// written for every join.
+			buf.WriteString(kind.String())
+			buf.WriteByte(' ')
+		} else if kind != _unionClause {
 			// Write the string of the clause kind only once, this avoids multiple
 			// clause strings being built into the query.
 			if _, ok := clauses[kind]; !ok {
@@ -256,9 +537,37 @@ func (q *Query) buildInitial() string {
 	return buf.String()
 }
 
+// Placeholder is the style of the placeholders that the arguments of a query
+// are given as when built via [Query.BuildWith].
+type Placeholder uint
+
+const (
+	// Dollar placeholders are numbered, such as $1 and $2. These are used by
+	// PostgreSQL and SQLite.
+	Dollar Placeholder = iota
+
+	// Question placeholders are positional, and are given as ?. These are used
+	// by MySQL.
+	Question
+)
+
+// Build builds the query, with [Dollar] placeholders for its arguments.
 func (q *Query) Build() string {
+	return q.BuildWith(Dollar)
+}
+
+// BuildWith builds the query with the given style of placeholders for its
+// arguments. This would be used when building queries for a database that does
+// not accept the default [Dollar] placeholders, for example,
+//
+//	q.BuildWith(query.Question)
+func (q *Query) BuildWith(p Placeholder) string {
 	s := q.buildInitial()
 
+	if p == Question {
+		return s
+	}
+
 	query := make([]byte, 0, len(s))
 	param := int64(0)
 
@@ -1,6 +1,9 @@
 package query
 
-import "testing"
+import (
+	"slices"
+	"testing"
+)
 
 func Test_Query(t *testing.T) {
 	tests := []struct {
@@ -13,6 +16,140 @@ func Test_Query(t *testing.T) {
 			1,
 			Select(Sum(Ident("size")), From("files"), WhereEq("user_id", Arg(1))),
 		},
+		{
+			"SELECT AVG(size) FROM files",
+			0,
+			Select(Avg(Ident("size")), From("files")),
+		},
+		{
+			"SELECT MIN(size) FROM files WHERE (user_id = $1)",
+			1,
+			Select(Min(Ident("size")), From("files"), WhereEq("user_id", Arg(1))),
+		},
+		{
+			"SELECT MAX(size) FROM files",
+			0,
+			Select(Max(Ident("size")), From("files")),
+		},
+		{
+			"SELECT user_id, COUNT(*) FROM posts WHERE (deleted_at IS NULL) GROUP BY user_id ORDER BY user_id ASC LIMIT 10",
+			0,
+			Select(
+				Exprs(Ident("user_id"), Count("*")),
+				From("posts"),
+				WhereIsNil("deleted_at"),
+				OrderAsc("user_id"),
+				Limit(10),
+				GroupBy("user_id"),
+			),
+		},
+		{
+			"SELECT user_id, tag, COUNT(*) FROM posts GROUP BY user_id, tag",
+			0,
+			Select(Exprs(Ident("user_id"), Ident("tag"), Count("*")), From("posts"), GroupBy("user_id"), GroupBy("tag")),
+		},
+		{
+			"SELECT * FROM posts WHERE (user_id = $1 AND (title ILIKE $2 OR content ILIKE $3))",
+			3,
+			Select(
+				Columns("*"),
+				From("posts"),
+				WhereEq("user_id", Arg(1)),
+				Search("golang", "title", "content"),
+			),
+		},
+		{
+			"SELECT * FROM posts WHERE (title ILIKE $1)",
+			1,
+			Select(Columns("*"), From("posts"), Search("100%", "title")),
+		},
+		{
+			"SELECT * FROM posts",
+			0,
+			Select(Columns("*"), From("posts"), Search("", "title", "content")),
+		},
+		{
+			"SELECT * FROM posts WHERE (user_id = $1 AND EXISTS (SELECT 1 FROM post_tags WHERE (post_tags.post_id = posts.id AND name = $2)))",
+			2,
+			Select(
+				Columns("*"),
+				From("posts"),
+				WhereEq("user_id", Arg(1)),
+				WhereExists(Select(
+					Lit(1),
+					From("post_tags"),
+					Where(Eq(Ident("post_tags.post_id"), Ident("posts.id"))),
+					WhereEq("name", Arg("golang")),
+				)),
+			),
+		},
+		{
+			"SELECT * FROM users WHERE (NOT EXISTS (SELECT 1 FROM posts WHERE (posts.user_id = users.id)))",
+			0,
+			Select(
+				Columns("*"),
+				From("users"),
+				WhereNotExists(Select(Lit(1), From("posts"), Where(Eq(Ident("posts.user_id"), Ident("users.id"))))),
+			),
+		},
+		{
+			"SELECT * FROM posts WHERE (((user_id = $1) OR (featured = $2)) AND (org_id = $3))",
+			3,
+			Select(
+				Columns("*"),
+				From("posts"),
+				WhereEq("user_id", Arg(1)),
+				OrWhereEq("featured", Arg(true)),
+				Restrict(WhereEq("org_id", Arg(10))),
+			),
+		},
+		{
+			"SELECT * FROM posts WHERE (org_id = $1) ORDER BY id DESC LIMIT 5",
+			1,
+			Select(Columns("*"), From("posts"), OrderDesc("id"), Limit(5), Restrict(WhereEq("org_id", Arg(10)))),
+		},
+		{
+			"UPDATE posts SET title = $1 WHERE ((id = $2) AND (org_id = $3))",
+			3,
+			Update("posts", Set("title", Arg("title")), WhereEq("id", Arg(1)), Restrict(WhereEq("org_id", Arg(10)))),
+		},
+		{
+			"DELETE FROM posts WHERE (org_id = $1)",
+			1,
+			Delete("posts", Restrict(WhereEq("org_id", Arg(10)))),
+		},
+		{
+			"SELECT * FROM posts",
+			0,
+			Select(Columns("*"), From("posts"), Restrict()),
+		},
+		{
+			"WITH recent AS (SELECT * FROM posts WHERE (user_id = $1) ORDER BY id DESC LIMIT 10) SELECT * FROM recent WHERE (title LIKE $2)",
+			2,
+			Select(
+				Columns("*"),
+				From("recent"),
+				WhereLike("title", Arg("%go%")),
+				With("recent", Select(Columns("*"), From("posts"), WhereEq("user_id", Arg(1)), OrderDesc("id"), Limit(10))),
+			),
+		},
+		{
+			"WITH RECURSIVE tree AS (SELECT * FROM comments WHERE (parent_id = $1) UNION SELECT comments.* FROM comments JOIN tree ON comments.parent_id = tree.id) SELECT * FROM tree",
+			1,
+			Select(
+				Columns("*"),
+				From("tree"),
+				WithRecursive("tree", Union(
+					Select(Columns("*"), From("comments"), WhereEq("parent_id", Arg(1))),
+					Select(Columns("comments.*"), From("comments"), Join("tree", Eq(Ident("comments.parent_id"), Ident("tree.id")))),
+				)),
+			),
+		},
+		{
+			"SELECT pg_notify($1, $2)",
+			2,
+			Select(Call("pg_notify", Arg("posts"), Arg("1"))),
+		},
 		{
 			"SELECT COUNT(*) FROM files",
 			0,
@@ -44,6 +181,32 @@ func Test_Query(t *testing.T) {
 				WhereIsNil("deleted_at"),
 			),
 		},
+		{
+			"TRUNCATE TABLE posts",
+			0,
+			Truncate("posts"),
+		},
+		{
+			"TRUNCATE TABLE posts RESTART IDENTITY",
+			0,
+			Truncate("posts", RestartIdentity()),
+		},
+		{
+			"COPY posts (title, content) FROM STDIN",
+			0,
+			Copy("posts", Columns("title", "content")),
+		},
+		{
+			"SELECT * FROM posts WHERE (id = $1) LIMIT 1 FOR UPDATE SKIP LOCKED",
+			1,
+			Select(
+				Columns("*"),
+				From("posts"),
+				WhereEq("id", Arg(1)),
+				Limit(1),
+				ForUpdateSkipLocked(),
+			),
+		},
 		{
 			"SELECT * FROM posts WHERE (title LIKE $1) LIMIT 25 OFFSET 2",
 			1,
@@ -148,6 +311,61 @@ func Test_Query(t *testing.T) {
 				OrWhereEq("user_id", Arg(2)),
 			),
 		},
+		{
+			"WITH events AS (SELECT * FROM events_2024_05 UNION ALL SELECT * FROM events_2024_06) SELECT * FROM events WHERE (kind = $1)",
+			1,
+			Select(
+				Columns("*"),
+				From("events"),
+				With("events", UnionAll(
+					Select(Columns("*"), From("events_2024_05")),
+					Select(Columns("*"), From("events_2024_06")),
+				)),
+				WhereEq("kind", Arg("login")),
+			),
+		},
+		{
+			"INSERT INTO users (id, email) VALUES ($1, $2), ($3, $4) ON CONFLICT (id) DO UPDATE SET email = EXCLUDED.email RETURNING id",
+			4,
+			Insert(
+				"users",
+				Columns("id", "email"),
+				Values(1, "me@example.com"),
+				Values(2, "you@example.com"),
+				OnConflictDoUpdate([]string{"id"}, "email"),
+				Returning("id"),
+			),
+		},
+		{
+			"INSERT IGNORE INTO post_tags (post_id, name) VALUES ($1, $2)",
+			2,
+			Insert(
+				"post_tags",
+				Columns("post_id", "name"),
+				Values(1, "golang"),
+				Ignore(),
+			),
+		},
+		{
+			"INSERT INTO post_tags (post_id, name) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+			2,
+			Insert(
+				"post_tags",
+				Columns("post_id", "name"),
+				Values(1, "golang"),
+				OnConflictDoNothing(),
+			),
+		},
+		{
+			"INSERT INTO users (id, email) VALUES ($1, $2) ON DUPLICATE KEY UPDATE email = VALUES(email), updated_at = VALUES(updated_at)",
+			2,
+			Insert(
+				"users",
+				Columns("id", "email"),
+				Values(1, "me@example.com"),
+				OnDuplicateKeyUpdate("email", "updated_at"),
+			),
+		},
 		{
 			"INSERT INTO users (email, username, password) VALUES ($1, $2, $3)",
 			3,
@@ -167,6 +385,31 @@ func Test_Query(t *testing.T) {
 				Returning("id", "created_at"),
 			),
 		},
+		{
+			"INSERT OR IGNORE INTO users (email, username) VALUES ($1, $2)",
+			2,
+			Insert(
+				"users",
+				Columns("email", "username"),
+				Values("email@domain.com", "user"),
+				OrIgnore(),
+			),
+		},
+		{
+			"INSERT OR REPLACE INTO users (email, username) VALUES ($1, $2)",
+			2,
+			Insert(
+				"users",
+				Columns("email", "username"),
+				Values("email@domain.com", "user"),
+				OrReplace(),
+			),
+		},
+		{
+			"INSERT OR ABORT INTO users (email) VALUES ($1)",
+			1,
+			Insert("users", Columns("email"), Values("email@domain.com"), OrAbort()),
+		},
 		{
 			"INSERT INTO posts (title, body) VALUES ($1, $2), ($3, $4), ($5, $6)",
 			6,
@@ -178,6 +421,39 @@ func Test_Query(t *testing.T) {
 				Values("post 3", "post 3"),
 			),
 		},
+		{
+			"INSERT INTO posts (title, body, created_at) VALUES ($1, DEFAULT, NOW())",
+			1,
+			Insert(
+				"posts",
+				Columns("title", "body", "created_at"),
+				Values("post 1", Default(), Lit("NOW()")),
+			),
+		},
+		{
+			"INSERT INTO post_tags (created_at, name, post_id) VALUES (NOW(), $1, $2)",
+			2,
+			InsertMap("post_tags", map[string]any{
+				"post_id":    1,
+				"name":       "golang",
+				"created_at": Lit("NOW()"),
+			}),
+		},
+		{
+			"INSERT OR IGNORE INTO post_tags (name, post_id) VALUES ($1, $2)",
+			2,
+			InsertMap("post_tags", map[string]any{"post_id": 1, "name": "golang"}, OrIgnore()),
+		},
+		{
+			"UPDATE posts SET updated_at = NOW(), body = DEFAULT WHERE (id = $1)",
+			1,
+			Update(
+				"posts",
+				Set("updated_at", Lit("NOW()")),
+				Set("body", Default()),
+				WhereEq("id", Arg(1)),
+			),
+		},
 		{
 			"DELETE FROM users WHERE (id = $1)",
 			1,
@@ -458,6 +734,37 @@ func Test_Query(t *testing.T) {
 				)),
 			),
 		},
+		{
+			"SELECT * FROM messages JOIN users AS sender ON messages.sender_id = sender.id JOIN users AS recipient ON messages.recipient_id = recipient.id",
+			0,
+			Select(
+				Columns("*"),
+				From("messages"),
+				Join("users AS sender", Eq(Ident("messages.sender_id"), Ident("sender.id"))),
+				Join("users AS recipient", Eq(Ident("messages.recipient_id"), Ident("recipient.id"))),
+			),
+		},
+		{
+			"SELECT * FROM messages JOIN users AS sender ON messages.sender_id = sender.id LEFT JOIN users AS recipient ON messages.recipient_id = recipient.id",
+			0,
+			Select(
+				Columns("*"),
+				From("messages"),
+				JoinAs("users", "sender", Eq(Ident("messages.sender_id"), Ident("sender.id"))),
+				LeftJoinAs("users", "recipient", Eq(Ident("messages.recipient_id"), Ident("recipient.id"))),
+			),
+		},
+		{
+			"SELECT * FROM posts LEFT JOIN users ON posts.user_id = users.id AND users.email = $1 RIGHT JOIN tags ON tags.post_id = posts.id WHERE (posts.id = $2)",
+			2,
+			Select(
+				Columns("*"),
+				From("posts"),
+				LeftJoin("users", And(Eq(Ident("posts.user_id"), Ident("users.id")), Eq(Ident("users.email"), Arg("me@example.com")))),
+				RightJoin("tags", Eq(Ident("tags.post_id"), Ident("posts.id"))),
+				WhereEq("posts.id", Arg(1)),
+			),
+		},
 		{
 			"SELECT * FROM t WHERE (LOWER(col) = LOWER($1))",
 			1,
@@ -504,3 +811,132 @@ func Test_Query(t *testing.T) {
 		})
 	}
 }
+
+func Test_Search(t *testing.T) {
+	q := Select(Columns("*"), From("posts"), Search(`50%_off\`, "title", "content"))
+
+	want := `%50\%\_off\\%`
+
+	for i, arg := range q.Args() {
+		if arg != want {
+			t.Fatalf("q.Args()[%d] = %q, want = %q\n", i, arg, want)
+		}
+	}
+}
+
+func Test_Restrict(t *testing.T) {
+	q := Select(
+		Columns("*"),
+		From("posts"),
+		Join("users", And(Eq(Ident("users.id"), Ident("posts.user_id")), Eq(Ident("users.active"), Arg(true)))),
+		WhereEq("a", Arg(1)),
+		WhereEq("b", Arg(2)),
+		OrWhereEq("c", Arg(3)),
+		Restrict(WhereEq("org_id", Arg(10)), OrWhereIsNil("org_id")),
+		OrderAsc("id"),
+	)
+
+	want := "SELECT * FROM posts JOIN users ON users.id = posts.user_id AND users.active = $1 WHERE ((((a = $2) AND (b = $3)) OR (c = $4)) AND ((org_id = $5) OR (org_id IS NULL))) ORDER BY id ASC"
+
+	if got := q.Build(); got != want {
+		t.Fatalf("q.Build() mismatch:\nwant = %q\ngot  = %q\n", want, got)
+	}
+
+	args := []any{true, 1, 2, 3, 10}
+
+	for i, arg := range q.Args() {
+		if arg != args[i] {
+			t.Fatalf("q.Args()[%d] = %v, want = %v\n", i, arg, args[i])
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Restrict to panic for non-WHERE option, it did not")
+		}
+	}()
+
+	Select(Columns("*"), From("posts"), Restrict(OrderAsc("id")))
+}
+
+func Test_BuildWith(t *testing.T) {
+	q := Select(
+		Columns("*"),
+		From("posts"),
+		WhereEq("user_id", Arg(1)),
+		WhereIn("id", Select(Columns("post_id"), From("post_tags"), WhereEq("name", Arg("golang")))),
+	)
+
+	tests := []struct {
+		p    Placeholder
+		want string
+	}{
+		{Dollar, "SELECT * FROM posts WHERE (user_id = $1 AND id IN (SELECT post_id FROM post_tags WHERE (name = $2)))"},
+		{Question, "SELECT * FROM posts WHERE (user_id = ? AND id IN (SELECT post_id FROM post_tags WHERE (name = ?)))"},
+	}
+
+	for i, test := range tests {
+		if got := q.BuildWith(test.p); got != test.want {
+			t.Errorf("tests[%d] - q.BuildWith(%v) mismatch:\nwant = %q\ngot  = %q\n", i, test.p, test.want, got)
+		}
+	}
+}
+
+func Test_QueryFilter(t *testing.T) {
+	tests := []struct {
+		query *Query
+		want  string
+		args  []any
+	}{
+		{
+			Select(Columns("*"), From("posts"), WhereEq("user_id", Arg(1)), OrWhereEq("featured", Arg(true)), OrderAsc("id"), Limit(10)),
+			"(user_id = $1 OR featured = $2)",
+			[]any{1, true},
+		},
+		{
+			Select(Columns("*"), From("posts"), Join("users", Eq(Ident("users.id"), Arg(2))), WhereEq("title", Arg("foo")), Restrict(WhereEq("org_id", Arg(3)))),
+			"((title = $1) AND (org_id = $2))",
+			[]any{"foo", 3},
+		},
+		{Select(Columns("*"), From("posts")), "", nil},
+	}
+
+	for i, test := range tests {
+		filter, args := test.query.Filter(Dollar)
+
+		if filter != test.want {
+			t.Errorf("tests[%d] - query.Filter(Dollar) mismatch:\nwant = %q\ngot  = %q\n", i, test.want, filter)
+		}
+
+		if !slices.Equal(args, test.args) {
+			t.Errorf("tests[%d] - query.Filter(Dollar) args = %v, want = %v\n", i, args, test.args)
+		}
+	}
+}
+
+func Test_QueryTable(t *testing.T) {
+	tests := []struct {
+		query *Query
+		stmt  string
+		table string
+	}{
+		{Select(Columns("*"), From("posts"), Join("users", Eq(Ident("users.id"), Ident("posts.user_id")))), "SELECT", "posts"},
+		{Insert("post_tags", Columns("name"), Values("golang")), "INSERT", "post_tags"},
+		{Update("posts", Set("title", Arg("title"))), "UPDATE", "posts"},
+		{Delete("posts"), "DELETE", "posts"},
+		{Truncate("posts"), "TRUNCATE TABLE", "posts"},
+		{Copy("posts", Columns("title")), "COPY", "posts"},
+		{Select(Call("now")), "SELECT", ""},
+		{Union(Select(Columns("*"), From("a")), Select(Columns("*"), From("b"))), "", ""},
+	}
+
+	for i, test := range tests {
+		if stmt := test.query.Statement(); stmt != test.stmt {
+			t.Errorf("tests[%d] - query.Statement() = %q, want = %q\n", i, stmt, test.stmt)
+		}
+
+		if table := test.query.Table(); table != test.table {
+			t.Errorf("tests[%d] - query.Table() = %q, want = %q\n", i, table, test.table)
+		}
+	}
+}
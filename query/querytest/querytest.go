@@ -0,0 +1,105 @@
+// Package querytest provides utilities for testing the queries built via the
+// query package. Queries are asserted against golden files, so that the SQL
+// generated by a program is locked in, and any change to it shows up in
+// review, for example,
+//
+//	func TestPostsQuery(t *testing.T) {
+//	    q := query.Select(
+//	        query.Columns("*"),
+//	        query.From("posts"),
+//	        query.WhereEq("user_id", query.Arg(10)),
+//	    )
+//
+//	    querytest.Golden(t, "posts_by_user", q)
+//	}
+//
+// The golden files are written to the testdata directory of the package under
+// test when the tests are run with the -update flag,
+//
+//	$ go test -run TestPostsQuery -update
+package querytest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andrewpillar/database/query"
+)
+
+var update = flag.Bool("update", false, "update the golden files of queries")
+
+// Dir is the directory golden files are read from, and written to.
+var Dir = "testdata"
+
+// argsPrefix prefixes the line of a golden file that records the number of
+// arguments of the query.
+const argsPrefix = "-- args: "
+
+// format returns the contents of the golden file for the given query.
+func format(q *query.Query) string {
+	return q.Build() + "\n" + argsPrefix + strconv.Itoa(len(q.Args())) + "\n"
+}
+
+// Golden asserts that the SQL, and the number of arguments, of the given query
+// match the golden file of the given name. The golden file is the file
+// name.golden in [Dir]. If the tests are run with the -update flag, then the
+// golden file is written with the query instead.
+func Golden(t testing.TB, name string, q *query.Query) {
+	t.Helper()
+
+	path := filepath.Join(Dir, name+".golden")
+	got := format(q)
+
+	if *update {
+		if err := os.MkdirAll(Dir, 0o755); err != nil {
+			t.Fatalf("querytest: %v\n", err)
+		}
+
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("querytest: %v\n", err)
+		}
+		return
+	}
+
+	b, err := os.ReadFile(path)
+
+	if err != nil {
+		t.Fatalf("querytest: %v, run with -update to create it\n", err)
+	}
+
+	want := string(b)
+
+	if got != want {
+		t.Errorf("querytest: query does not match %s\n%s", path, diff(want, got))
+	}
+}
+
+// diff returns the lines of the given golden file contents that differ.
+func diff(want, got string) string {
+	wantLines := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	gotLines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	var buf strings.Builder
+
+	for i := range max(len(wantLines), len(gotLines)) {
+		var w, g string
+
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+
+		if w != g {
+			fmt.Fprintf(&buf, "\twant = %q\n\tgot  = %q\n", w, g)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,68 @@
+package querytest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// recorder records whether a test failed, without failing the actual test.
+type recorder struct {
+	testing.TB
+
+	failed bool
+}
+
+func (r *recorder) Helper()               {}
+func (r *recorder) Errorf(string, ...any) { r.failed = true }
+func (r *recorder) Fatalf(string, ...any) { r.failed = true }
+
+func postsByUser(id int64) *query.Query {
+	return query.Select(
+		query.Columns("*"),
+		query.From("posts"),
+		query.WhereEq("user_id", query.Arg(id)),
+	)
+}
+
+func TestGolden(t *testing.T) {
+	Golden(t, "posts_by_user", postsByUser(10))
+
+	rec := &recorder{TB: t}
+
+	q := query.Select(query.Columns("*"), query.From("posts"))
+
+	Golden(rec, "posts_by_user", q)
+
+	if !rec.failed {
+		t.Fatalf("Golden(rec, %q, q): expected failure\n", "posts_by_user")
+	}
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	dir := Dir
+	Dir = t.TempDir()
+
+	*update = true
+
+	t.Cleanup(func() {
+		Dir = dir
+		*update = false
+	})
+
+	Golden(t, "posts_by_user", postsByUser(10))
+
+	b, err := os.ReadFile(filepath.Join(Dir, "posts_by_user.golden"))
+
+	if err != nil {
+		t.Fatalf("os.ReadFile: %v\n", err)
+	}
+
+	want := "SELECT * FROM posts WHERE (user_id = $1)\n-- args: 1\n"
+
+	if string(b) != want {
+		t.Fatalf("golden = %q, want = %q\n", string(b), want)
+	}
+}
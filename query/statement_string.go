@@ -14,11 +14,13 @@ func _() {
 	_ = x[updateStmt-4]
 	_ = x[selectDistinctStmt-5]
 	_ = x[selectDistinctOnStmt-6]
+	_ = x[truncateStmt-7]
+	_ = x[copyStmt-8]
 }
 
-const _statement_name = "DELETEINSERTSELECTUPDATESELECT DISTINCTSELECT DISTINCT ON"
+const _statement_name = "DELETEINSERTSELECTUPDATESELECT DISTINCTSELECT DISTINCT ONTRUNCATE TABLECOPY"
 
-var _statement_index = [...]uint8{0, 6, 12, 18, 24, 39, 57}
+var _statement_index = [...]uint8{0, 6, 12, 18, 24, 39, 57, 71, 75}
 
 func (i statement) String() string {
 	i -= 1
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"iter"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// ReadStore handles only the read operations of the [Model]. This would be
+// used for models that are backed by SQL views, or reporting tables, where
+// writes should not be possible. Since a ReadStore has no methods for writing,
+// any attempt at a write is caught at compile time.
+type ReadStore[M Model] struct {
+	s *Store[M]
+}
+
+// NewReadStore returns a new [ReadStore] for the given [Model]. This takes
+// the same arguments as [NewStore].
+func NewReadStore[M Model](db DB, new func() M, opts ...StoreOption) *ReadStore[M] {
+	return NewStore(db, new, opts...).ReadOnly()
+}
+
+// ReadOnly returns a [ReadStore] that uses the same configuration as the store.
+func (s *Store[M]) ReadOnly() *ReadStore[M] {
+	return &ReadStore[M]{
+		s: s,
+	}
+}
+
+// With returns a copy of the store that operates on the given [DB], as per
+// [Store.With].
+func (r *ReadStore[M]) With(db DB) *ReadStore[M] {
+	return r.s.With(db).ReadOnly()
+}
+
+// Preload returns a copy of the store with the given [Loader], as per
+// [Store.Preload].
+func (r *ReadStore[M]) Preload(name string, load Loader[M]) *ReadStore[M] {
+	return r.s.Preload(name, load).ReadOnly()
+}
+
+// Load returns a copy of the store that loads the given relations, as per
+// [Store.Load].
+func (r *ReadStore[M]) Load(names ...string) *ReadStore[M] {
+	return r.s.Load(names...).ReadOnly()
+}
+
+// All returns an iterator over the models that match the given query options,
+// as per [Store.All].
+func (r *ReadStore[M]) All(ctx context.Context, expr query.Expr, opts ...query.Option) iter.Seq2[M, error] {
+	return r.s.All(ctx, expr, opts...)
+}
+
+// Select returns the models that match the given query options, as per
+// [Store.Select].
+func (r *ReadStore[M]) Select(ctx context.Context, expr query.Expr, opts ...query.Option) ([]M, error) {
+	return r.s.Select(ctx, expr, opts...)
+}
+
+// SelectAll returns the models that match the given query options, as per
+// [Store.SelectAll].
+func (r *ReadStore[M]) SelectAll(ctx context.Context, opts ...query.Option) ([]M, error) {
+	return r.s.SelectAll(ctx, opts...)
+}
+
+// SelectOne returns the first model that matches the given query options, as
+// per [Store.SelectOne].
+func (r *ReadStore[M]) SelectOne(ctx context.Context, expr query.Expr, opts ...query.Option) (M, bool, error) {
+	return r.s.SelectOne(ctx, expr, opts...)
+}
+
+// Get returns the first model that matches the given query options, as per
+// [Store.Get].
+func (r *ReadStore[M]) Get(ctx context.Context, opts ...query.Option) (M, bool, error) {
+	return r.s.Get(ctx, opts...)
+}
+
+// Chunk pages through the models that match the given query options, as per
+// [Store.Chunk].
+func (r *ReadStore[M]) Chunk(ctx context.Context, size int, fn func([]M) error, opts ...query.Option) error {
+	return r.s.Chunk(ctx, size, fn, opts...)
+}
+
+// Count returns the number of models that match the given query options.
+func (r *ReadStore[M]) Count(ctx context.Context, opts ...query.Option) (int64, error) {
+	return r.s.Count(ctx, opts...)
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestReadStore(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	})
+
+	for i := range 5 {
+		m := &M{
+			ID:   int64(i + 1),
+			Bool: i%2 == 0,
+			Blob: []byte{},
+			Time: time.Now(),
+		}
+
+		if err := store.Create(ctx, m); err != nil {
+			t.Fatalf("store.Create(ctx, m): %v\n", err)
+		}
+	}
+
+	reads := NewReadStore[*M](db, func() *M {
+		return &M{}
+	})
+
+	n, err := reads.Count(ctx, query.WhereEq("bool", query.Arg(true)))
+
+	if err != nil {
+		t.Fatalf("reads.Count(ctx, ...): %v\n", err)
+	}
+
+	if n != 3 {
+		t.Fatalf("n = %v, want = %v\n", n, 3)
+	}
+
+	mm, err := reads.SelectAll(ctx)
+
+	if err != nil {
+		t.Fatalf("reads.SelectAll(ctx): %v\n", err)
+	}
+
+	if len(mm) != 5 {
+		t.Fatalf("len(mm) = %v, want = %v\n", len(mm), 5)
+	}
+
+	m, ok, err := store.ReadOnly().Get(ctx, query.WhereEq("id", query.Arg(2)))
+
+	if err != nil {
+		t.Fatalf("store.ReadOnly().Get(ctx, ...): %v\n", err)
+	}
+
+	if !ok {
+		t.Fatalf("store.ReadOnly().Get(ctx, ...): expected model, got none\n")
+	}
+
+	if m.Bool {
+		t.Fatalf("m.Bool = %v, want = %v\n", m.Bool, false)
+	}
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// sensitiveOption marks a struct field as holding sensitive data, such as a
+// password or token, whose value should never be logged.
+const sensitiveOption = "sensitive"
+
+// Redacted is the placeholder that the value of a [SensitiveValue] is replaced
+// with when formatted, logged, or encoded as JSON.
+const Redacted = "[REDACTED]"
+
+// SensitiveValue is an argument whose value is given to the database as is,
+// but is replaced with [Redacted] whenever it is formatted, logged, or
+// encoded as JSON. This means the arguments of a [QueryEvent] can be logged
+// without leaking passwords or tokens.
+//
+// The values of struct fields with the "sensitive" option are given as a
+// SensitiveValue when written by a [Store], for example,
+//
+//	type User struct {
+//	    ID       int64
+//	    Email    string
+//	    Password []byte `db:"password,sensitive"`
+//	}
+//
+// Arguments in a query can be marked as sensitive via [Sensitive].
+type SensitiveValue struct {
+	v any
+}
+
+var (
+	_ driver.Valuer  = SensitiveValue{}
+	_ fmt.Formatter  = SensitiveValue{}
+	_ slog.LogValuer = SensitiveValue{}
+	_ json.Marshaler = SensitiveValue{}
+)
+
+// Sensitive returns a [SensitiveValue] for the given value, for example,
+//
+//	u, ok, err := users.Get(ctx, query.WhereEq("reset_token", query.Arg(database.Sensitive(token))))
+func Sensitive(v any) SensitiveValue {
+	if sv, ok := v.(SensitiveValue); ok {
+		return sv
+	}
+	return SensitiveValue{v: v}
+}
+
+// Value implements [driver.Valuer], returning the underlying value as
+// converted by [driver.DefaultParameterConverter].
+func (sv SensitiveValue) Value() (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(sv.v)
+}
+
+// Format implements [fmt.Formatter], writing [Redacted] for every verb.
+func (sv SensitiveValue) Format(f fmt.State, _ rune) {
+	f.Write([]byte(Redacted))
+}
+
+// LogValue implements [slog.LogValuer].
+func (sv SensitiveValue) LogValue() slog.Value {
+	return slog.StringValue(Redacted)
+}
+
+// MarshalJSON implements [json.Marshaler].
+func (sv SensitiveValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Redacted)
+}
+
+// unredact returns the given arguments with any SensitiveValue replaced by its
+// underlying value. The given slice is not modified.
+func unredact(args []any) []any {
+	cloned := false
+
+	for i, arg := range args {
+		sv, ok := arg.(SensitiveValue)
+
+		if !ok {
+			continue
+		}
+
+		if !cloned {
+			args = slices.Clone(args)
+			cloned = true
+		}
+		args[i] = sv.v
+	}
+	return args
+}
+
+// redactedError is the error used in place of an error that occurred when
+// scanning a sensitive column, since such errors, such as those from
+// [strconv], often include the value being scanned.
+type redactedError struct {
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return "invalid value " + Redacted
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redact returns an error that does not include the value of the field if the
+// field is sensitive, otherwise the error is returned as is.
+func (f *structField) redact(err error) error {
+	if err == nil || !f.sensitive {
+		return err
+	}
+	return &redactedError{err: err}
+}
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/andrewpillar/database/query"
+)
+
+const credentialSchema = `CREATE TABLE IF NOT EXISTS credentials (
+	id       INTEGER NOT NULL,
+	email    TEXT NOT NULL,
+	password TEXT NOT NULL,
+	pin      TEXT NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+type Credential struct {
+	ID       int64
+	Email    string
+	Password string `db:"password,sensitive"`
+	Pin      int    `db:"pin,sensitive"`
+}
+
+func (c *Credential) Table() string { return "credentials" }
+
+func (c *Credential) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{c.ID},
+	}
+}
+
+func (c *Credential) Params() Params {
+	return Params{
+		"id":       CreateOnlyParam(c.ID),
+		"email":    MutableParam(c.Email),
+		"password": MutableParam(c.Password),
+		"pin":      MutableParam(c.Pin),
+	}
+}
+
+func TestSensitiveValue(t *testing.T) {
+	sv := Sensitive("hunter2")
+
+	tests := []string{
+		fmt.Sprint(sv),
+		fmt.Sprintf("%v", []any{1, sv}),
+		fmt.Sprintf("%+v %#v %s %q", sv, sv, sv, sv),
+	}
+
+	for i, s := range tests {
+		if strings.Contains(s, "hunter2") {
+			t.Errorf("tests[%d] - %q contains sensitive value\n", i, s)
+		}
+	}
+
+	b, err := json.Marshal(map[string]any{"password": sv})
+
+	if err != nil {
+		t.Fatalf("json.Marshal(...): %v\n", err)
+	}
+
+	if strings.Contains(string(b), "hunter2") {
+		t.Errorf("json.Marshal(...) = %s, contains sensitive value\n", b)
+	}
+
+	v, err := sv.Value()
+
+	if err != nil {
+		t.Fatalf("sv.Value(): %v\n", err)
+	}
+
+	if v != "hunter2" {
+		t.Errorf("sv.Value() = %v, want = %v\n", v, "hunter2")
+	}
+}
+
+func TestSensitiveColumns(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, credentialSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", credentialSchema, err)
+	}
+
+	var buf strings.Builder
+
+	log := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
+
+	credentials := NewStore(db, func() *Credential {
+		return &Credential{}
+	}, WithLogger(SlogLogger(log)), WithCache(NewMemoryCache(0)))
+
+	a := &Credential{
+		ID:       1,
+		Email:    "me@example.com",
+		Password: "hunter2",
+		Pin:      9876,
+	}
+
+	if err := credentials.Create(ctx, a); err != nil {
+		t.Fatalf("credentials.Create(ctx, a): %v\n", err)
+	}
+
+	// The cache must still distinguish between sensitive arguments.
+	for _, test := range []struct {
+		password string
+		want     bool
+	}{
+		{"wrong", false},
+		{"hunter2", true},
+	} {
+		_, ok, err := credentials.Get(ctx, query.WhereEq("password", query.Arg(Sensitive(test.password))))
+
+		if err != nil {
+			t.Fatalf("credentials.Get(ctx, ...): %v\n", err)
+		}
+
+		if ok != test.want {
+			t.Fatalf("credentials.Get(ctx, ...): ok = %v, want = %v\n", ok, test.want)
+		}
+	}
+
+	fields := map[string]any{
+		"password": "hunter3",
+		"pin":      1234,
+	}
+
+	if _, err := credentials.UpdateMany(ctx, fields, query.WhereEq("id", query.Arg(a.ID))); err != nil {
+		t.Fatalf("credentials.UpdateMany(ctx, fields, ...): %v\n", err)
+	}
+
+	for _, secret := range []string{"hunter2", "wrong", "9876", "hunter3", "1234"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Fatalf("log contains sensitive value %q\n%s", secret, buf.String())
+		}
+	}
+
+	if !strings.Contains(buf.String(), Redacted) {
+		t.Fatalf("log does not contain %q\n%s", Redacted, buf.String())
+	}
+
+	t.Run("scan-error", func(t *testing.T) {
+		if _, err := db.ExecContext(ctx, "UPDATE credentials SET pin = 'secretpin' WHERE id = 1"); err != nil {
+			t.Fatalf("db.ExecContext(ctx, ...): %v\n", err)
+		}
+
+		_, _, err := NewStore(db, func() *Credential {
+			return &Credential{}
+		}).Get(ctx, query.WhereEq("id", query.Arg(1)))
+
+		if err == nil {
+			t.Fatal("expected error scanning invalid pin, got nil")
+		}
+
+		if strings.Contains(err.Error(), "secretpin") {
+			t.Fatalf("err = %v, contains sensitive value\n", err)
+		}
+
+		var scanErr *ColumnScanError
+
+		if !errors.As(err, &scanErr) {
+			t.Fatalf("err = %T, want = %T\n", err, scanErr)
+		}
+	})
+}
@@ -0,0 +1,987 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// Loader loads related data into the given models. This would typically be
+// done with a single batched query for all of the models, for example
+// selecting all of the tags for a set of posts via a WHERE IN clause, and
+// assigning them to the respective post.
+type Loader[M Model] func(ctx context.Context, db DB, mm []M) error
+
+type preload[M Model] struct {
+	name string
+	load func(ctx context.Context, s *Store[M], mm []M) error
+}
+
+// Preload returns a copy of the store that will call the given [Loader] on the
+// models returned from [Store.Select] and [Store.Get], to eagerly load any
+// related data for the models. The name identifies the relation being loaded,
+// preloading the same name again replaces the previous loader. For example,
+//
+//	pp, err := posts.Preload("Tags", LoadTags).Select(ctx, query.Columns("*"))
+//
+// Loaders are called in the order they were given, with the same [DB] the store
+// operates on. Loaders are not called for models returned from [Store.All],
+// since these are yielded one at a time.
+func (s *Store[M]) Preload(name string, load Loader[M]) *Store[M] {
+	return s.preload(name, func(ctx context.Context, s *Store[M], mm []M) error {
+		return load(ctx, s.DB, mm)
+	})
+}
+
+// preload returns a copy of the store that calls the given function on the
+// models it selects, as per Preload. The function is given the store that
+// selected the models.
+func (s *Store[M]) preload(name string, load func(ctx context.Context, s *Store[M], mm []M) error) *Store[M] {
+	s2 := *s
+	s2.preloads = slices.Clone(s.preloads)
+
+	p := preload[M]{
+		name: name,
+		load: load,
+	}
+
+	i := slices.IndexFunc(s2.preloads, func(p preload[M]) bool {
+		return p.name == name
+	})
+
+	if i < 0 {
+		s2.preloads = append(s2.preloads, p)
+	} else {
+		s2.preloads[i] = p
+	}
+	return &s2
+}
+
+func (s *Store[M]) doPreload(ctx context.Context, mm []M) error {
+	if len(mm) == 0 {
+		return nil
+	}
+
+	for _, p := range s.preloads {
+		if err := p.load(ctx, s, mm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type relationKind uint8
+
+const (
+	belongsTo relationKind = iota + 1
+	hasOne
+	hasMany
+	manyToMany
+	morphOne
+	morphMany
+	morphTo
+)
+
+// Relation describes how a [Model] relates to another Model. Relations are
+// declared on a Model via the [Relater] interface, and are created via the
+// [BelongsTo], [HasOne], [HasMany], and [ManyToMany] functions, and for
+// polymorphic relations, the [MorphOne], [MorphMany], and [MorphTo] functions.
+//
+// Declared relations are understood by [ColumnsRelated], [JoinRelated],
+// [Store.Load], and the [Scanner]. The Scanner will map the columns of a
+// BelongsTo or HasOne relation into the relation's struct field, without the
+// need for a "db" struct tag on that field.
+type Relation struct {
+	// Name is the name of the struct field on the Model into which the related
+	// models are scanned and loaded.
+	Name string
+
+	kind  relationKind
+	model Model
+	keys  []string
+	pivot string
+	alias string
+
+	// The columns in the pivot table that refer to the parent and the related
+	// models respectively, for many-to-many relations.
+	pivotKeys [2]string
+
+	// morphs are the models that can be referred to by a MorphTo relation.
+	morphs []Model
+}
+
+// Relater is the interface that wraps the Relations method. This would be
+// implemented by a [Model] to declare its relations to other models, for
+// example,
+//
+//	func (p *Post) Relations() []database.Relation {
+//	    return []database.Relation{
+//	        database.BelongsTo("User", &User{}, "user_id"),
+//	        database.HasMany("Comments", &Comment{}, "post_id"),
+//	        database.ManyToMany("Tags", &Tag{}, "post_tags", "post_id", "tag_id"),
+//	    }
+//	}
+type Relater interface {
+	Relations() []Relation
+}
+
+// BelongsTo returns a [Relation] where the Model declaring the relation holds
+// the foreign keys that refer to the [PrimaryKey] of the given Model. For
+// composite keys, the foreign keys must line up with the columns of the
+// PrimaryKey. The named struct field should be a pointer to the given Model's
+// type.
+func BelongsTo(name string, m Model, fks ...string) Relation {
+	return Relation{
+		Name:  name,
+		kind:  belongsTo,
+		model: m,
+		keys:  fks,
+	}
+}
+
+// HasOne returns a [Relation] where the given Model holds the foreign keys
+// that refer to the [PrimaryKey] of the Model declaring the relation. The named
+// struct field should be a pointer to the given Model's type.
+func HasOne(name string, m Model, fks ...string) Relation {
+	return Relation{
+		Name:  name,
+		kind:  hasOne,
+		model: m,
+		keys:  fks,
+	}
+}
+
+// HasMany returns a [Relation] where many of the given Model hold the foreign
+// keys that refer to the [PrimaryKey] of the Model declaring the relation. The
+// named struct field should be a slice of the given Model's type.
+func HasMany(name string, m Model, fks ...string) Relation {
+	return Relation{
+		Name:  name,
+		kind:  hasMany,
+		model: m,
+		keys:  fks,
+	}
+}
+
+// ManyToMany returns a [Relation] where the Model declaring the relation and the
+// given Model are related via the given pivot table. The parentKey and
+// childKey are the columns in the pivot table that refer to the [PrimaryKey]
+// of the declaring Model and the given Model respectively. Many-to-many
+// relations are only supported between models with a single column primary
+// key. The named struct field should be a slice of the given Model's type.
+func ManyToMany(name string, m Model, pivot, parentKey, childKey string) Relation {
+	return Relation{
+		Name:      name,
+		kind:      manyToMany,
+		model:     m,
+		pivot:     pivot,
+		pivotKeys: [2]string{parentKey, childKey},
+	}
+}
+
+// Model returns the related Model that was given to the Relation.
+func (r Relation) Model() Model { return r.model }
+
+// As returns a copy of the Relation that joins the related Model's table under
+// the given alias. The alias is used in place of the table name when joining,
+// selecting, and scanning the related Model, so the columns would be mapped
+// via the struct tag `db:"alias.*:*"`. This allows for a Model to have
+// multiple relations to the same table, for example,
+//
+//	func (m *Message) Relations() []database.Relation {
+//	    return []database.Relation{
+//	        database.BelongsTo("Sender", &User{}, "sender_id").As("sender"),
+//	        database.BelongsTo("Recipient", &User{}, "recipient_id").As("recipient"),
+//	    }
+//	}
+func (r Relation) As(alias string) Relation {
+	r.alias = alias
+	return r
+}
+
+// prefix returns the name the related Model's table is referred to by in
+// queries, this is either the alias or the table name.
+func (r Relation) prefix() string {
+	if r.alias != "" {
+		return r.alias
+	}
+	return r.model.Table()
+}
+
+func relations(m Model) []Relation {
+	if r, ok := m.(Relater); ok {
+		return r.Relations()
+	}
+	return nil
+}
+
+func lookupRelation(m Model, name string) (Relation, error) {
+	for _, r := range relations(m) {
+		if r.Name == name {
+			return r, nil
+		}
+	}
+	return Relation{}, fmt.Errorf("no relation %s on model %T", name, m)
+}
+
+func mustLookupRelation(m Model, name string) Relation {
+	r, err := lookupRelation(m, name)
+
+	if err != nil {
+		panic("database: " + err.Error())
+	}
+	return r
+}
+
+// tag returns the "db" struct tag that would map the relation's columns into
+// its struct field during scanning.
+func (r Relation) tag() string {
+	switch r.kind {
+	case belongsTo:
+		pk := r.model.PrimaryKey()
+		cols := make([]string, 0, len(r.keys)+1)
+
+		for i, fk := range r.keys {
+			cols = append(cols, fk+":"+pk.Columns[i])
+		}
+		return strings.Join(append(cols, r.prefix()+".*:*"), ",")
+	case hasOne:
+		return r.prefix() + ".*:*"
+	default:
+		return "-"
+	}
+}
+
+func (r Relation) join(parent Model) query.Option {
+	var (
+		local   []string
+		foreign []string
+	)
+
+	switch r.kind {
+	case belongsTo:
+		local = r.keys
+		foreign = r.model.PrimaryKey().Columns
+	case hasOne:
+		local = parent.PrimaryKey().Columns
+		foreign = r.keys
+	default:
+		panic("database: cannot join on relation " + r.Name + ", only belongs to and has one relations can be joined")
+	}
+
+	table := r.model.Table()
+	prefix := r.prefix()
+
+	exprs := make([]query.Expr, 0, len(local))
+
+	for i, col := range local {
+		exprs = append(exprs, query.Eq(
+			query.Ident(parent.Table()+"."+col),
+			query.Ident(prefix+"."+foreign[i]),
+		))
+	}
+
+	if r.alias != "" {
+		table += " AS " + r.alias
+	}
+	return query.Join(table, query.And(exprs...))
+}
+
+// ColumnsRelated returns the column [query.Expr] for the given Model along with
+// the columns of its named relations, as per [Columns]. This would typically
+// be used alongside [JoinRelated]. This panics if the Model does not declare a
+// named relation.
+func ColumnsRelated(m Model, names ...string) query.Expr {
+	rels := make([]Relation, 0, len(names))
+
+	for _, name := range names {
+		rels = append(rels, mustLookupRelation(m, name))
+	}
+	return relatedColumns(m, rels...)
+}
+
+// relatedColumns returns the columns of the given Model along with the columns
+// of the given relations, each qualified by the relation's alias, if any.
+func relatedColumns(m Model, rels ...Relation) query.Expr {
+	exprs := []query.Expr{
+		Columns(m),
+	}
+
+	for _, r := range rels {
+		exprs = append(exprs, joinColumns(r.model, r.prefix())...)
+	}
+	return query.Exprs(exprs...)
+}
+
+// JoinRelated returns the JOIN clauses for the named relations of the given
+// Model, for example,
+//
+//	pp, err := posts.Select(
+//	    ctx,
+//	    database.ColumnsRelated(&Post{User: &User{}}, "User"),
+//	    database.JoinRelated(&Post{}, "User"),
+//	)
+//
+// Only BelongsTo and HasOne relations can be joined. This panics if the Model
+// does not declare a named relation, or if the relation cannot be joined.
+func JoinRelated(m Model, names ...string) query.Option {
+	opts := make([]query.Option, 0, len(names))
+
+	for _, name := range names {
+		opts = append(opts, mustLookupRelation(m, name).join(m))
+	}
+	return query.Options(opts...)
+}
+
+// Rel returns a BelongsTo [Relation] on the given Model via the given foreign
+// keys, as per [BelongsTo]. The relation is named after the Model's table.
+// This would be used for ad-hoc joins via [Store.SelectWith], without needing
+// the Model to implement [Relater].
+func Rel(m Model, fks ...string) Relation {
+	return BelongsTo(m.Table(), m, fks...)
+}
+
+// SelectWith returns the models that match the given query options, with the
+// given relation joined onto them. The columns of both the store's [Model] and
+// the related Model are selected, as per [Columns], and the JOIN clause is
+// built from the relation, as per [Join]. For example,
+//
+//	pp, err := posts.SelectWith(ctx, database.Rel(&User{}, "user_id"), query.WhereEq("users.email", query.Arg(email)))
+//
+// is the same as,
+//
+//	pp, err := posts.Select(
+//	    ctx,
+//	    database.Columns(&Post{}, &User{}),
+//	    database.Join(&User{}, "user_id"),
+//	    query.WhereEq("users.email", query.Arg(email)),
+//	)
+//
+// Only BelongsTo and HasOne relations can be joined, this panics otherwise.
+func (s *Store[M]) SelectWith(ctx context.Context, r Relation, opts ...query.Option) ([]M, error) {
+	m := s.new()
+
+	opts = append([]query.Option{r.join(m)}, opts...)
+
+	return s.Select(ctx, relatedColumns(m, r), opts...)
+}
+
+// Load returns a copy of the store that loads the named relations of the
+// store's [Model] into the models returned from [Store.Select] and [Store.Get],
+// as per [Store.Preload]. Each relation is loaded with a single batched query,
+// or with two queries for many-to-many relations. The queries are run like
+// those of the store itself, with its [Dialect], [Keyring], [Mapper], logging,
+// metrics, retries, and replicas. If the Model does not declare a named
+// relation, then an error is returned when the models are selected.
+func (s *Store[M]) Load(names ...string) *Store[M] {
+	s2 := s
+
+	for _, name := range names {
+		s2 = s2.preload(name, func(ctx context.Context, s *Store[M], mm []M) error {
+			r, err := lookupRelation(s.new(), name)
+
+			if err != nil {
+				return err
+			}
+
+			parents := make([]Model, 0, len(mm))
+
+			for _, m := range mm {
+				parents = append(parents, m)
+			}
+			return r.load(ctx, s.related(func() Model { return s.new() }), parents)
+		})
+	}
+	return s2
+}
+
+// LoadRelated selects the children of the given parents, where each child
+// refers to the primary key of its parent via the given foreign key column,
+// and passes each parent along with its children to the given assign function.
+// The children are selected in a single query via a WHERE IN clause. This
+// would typically be used to implement a [Loader] for related data that is not
+// declared as a [Relation], for example,
+//
+//	func LoadTags(ctx context.Context, db database.DB, pp []*Post) error {
+//	    return database.LoadRelated(ctx, db, pp, "post_id", func(p *Post, tt []*Tag) {
+//	        p.Tags = tt
+//	    })
+//	}
+//
+// The assign function is called for every parent, even those without any
+// children. Related loading is only supported for parents with a single column
+// primary key.
+func LoadRelated[P, C Model](ctx context.Context, db DB, parents []P, fk string, assign func(P, []C)) error {
+	if len(parents) == 0 {
+		return nil
+	}
+
+	keys := make([][]any, 0, len(parents))
+	seen := make(map[string]struct{})
+
+	for _, p := range parents {
+		vals := p.PrimaryKey().Values[:1]
+		key := keyOf(vals)
+
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			keys = append(keys, vals)
+		}
+	}
+
+	var zero C
+
+	children, err := NewStore(db, newModelFunc(zero)).Select(ctx, query.Columns("*"), whereKeysIn([]string{fk}, keys))
+
+	if err != nil {
+		return err
+	}
+
+	tab := make(map[string][]C)
+
+	for _, c := range children {
+		vals, err := paramValues(c, []string{fk})
+
+		if err != nil {
+			return err
+		}
+
+		key := keyOf(vals)
+		tab[key] = append(tab[key], c.(C))
+	}
+
+	for _, p := range parents {
+		assign(p, tab[keyOf(p.PrimaryKey().Values[:1])])
+	}
+	return nil
+}
+
+// keyOf returns a string that can be used to compare the given key values
+// across models. Values that implement [driver.Valuer], such as [Null], are
+// compared by their underlying value.
+func keyOf(vals []any) string {
+	var buf strings.Builder
+
+	for i, v := range vals {
+		if i > 0 {
+			buf.WriteByte(0)
+		}
+
+		if valuer, ok := v.(driver.Valuer); ok {
+			if val, err := valuer.Value(); err == nil {
+				v = val
+			}
+		}
+
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		fmt.Fprint(&buf, v)
+	}
+	return buf.String()
+}
+
+func paramValues(m Model, cols []string) ([]any, error) {
+	params := m.Params()
+	vals := make([]any, 0, len(cols))
+
+	for _, col := range cols {
+		p, ok := params[col]
+
+		if !ok {
+			return nil, fmt.Errorf("no param %s on model %T", col, m)
+		}
+		vals = append(vals, p.value)
+	}
+	return vals, nil
+}
+
+// whereKeysIn returns the WHERE IN clause for the given columns matching any of
+// the given keys, accounting for composite keys.
+func whereKeysIn(cols []string, keys [][]any) query.Option {
+	vals := make([]any, 0, len(keys))
+
+	for _, key := range keys {
+		if len(key) > 1 {
+			vals = append(vals, query.List(key...))
+			continue
+		}
+		vals = append(vals, key[0])
+	}
+
+	col := cols[0]
+
+	if len(cols) > 1 {
+		col = "(" + strings.Join(cols, ", ") + ")"
+	}
+	return query.WhereIn(col, query.List(vals...))
+}
+
+func newModelFunc(m Model) func() Model {
+	rt := reflect.TypeOf(m)
+
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+
+	return func() Model {
+		rv := reflect.New(rt)
+		m := rv.Interface().(Model)
+
+		// Allocate the fields of any belongs to or has one relations, so the
+		// foreign keys can be scanned into them.
+		for _, r := range relations(m) {
+			if r.kind != belongsTo && r.kind != hasOne {
+				continue
+			}
+
+			fv := rv.Elem().FieldByName(r.Name)
+
+			if fv.IsValid() && fv.Kind() == reflect.Pointer && fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+		}
+		return m
+	}
+}
+
+// related returns a store for the models returned from the given function
+// that operates on the same DB as the store, with the same configuration, so
+// related models are selected with its dialect, keyring, mapper, logger,
+// metrics, retry policy, and replicas. The cache, change feed, and partitions
+// of the store only apply to its own table, so these are not used by the
+// returned store.
+func (s *Store[M]) related(new func() Model) *Store[Model] {
+	cfg := s.cfg
+	cfg.cache = nil
+	cfg.changes = nil
+	cfg.partitions = nil
+
+	return &Store[Model]{
+		DB:    s.DB,
+		table: new().Table(),
+		new:   new,
+		cfg:   cfg,
+	}
+}
+
+// selectModels selects the models returned from the given function that match
+// the given query options, via a store related to the given store.
+func selectModels(ctx context.Context, s *Store[Model], new func() Model, opts ...query.Option) ([]Model, error) {
+	return s.related(new).Select(ctx, query.Columns("*"), opts...)
+}
+
+func (r Relation) load(ctx context.Context, s *Store[Model], parents []Model) error {
+	if len(parents) == 0 {
+		return nil
+	}
+
+	// The columns and values on the parent used to look up the related models,
+	// and the columns on the related models they refer to.
+	var (
+		parentCols []string
+		childCols  []string
+	)
+
+	switch r.kind {
+	case belongsTo:
+		parentCols = r.keys
+		childCols = r.model.PrimaryKey().Columns
+	case hasOne, hasMany:
+		parentCols = parents[0].PrimaryKey().Columns
+		childCols = r.keys
+	case manyToMany:
+		return r.loadPivot(ctx, s, parents)
+	case morphOne, morphMany:
+		return r.loadMorph(ctx, s, parents)
+	case morphTo:
+		return r.loadMorphTo(ctx, s, parents)
+	}
+
+	keys := make([][]any, 0, len(parents))
+	parentKeys := make([]string, 0, len(parents))
+	seen := make(map[string]struct{})
+
+	for _, p := range parents {
+		var (
+			vals []any
+			err  error
+		)
+
+		if r.kind == belongsTo {
+			vals, err = paramValues(p, parentCols)
+		} else {
+			vals = p.PrimaryKey().Values
+		}
+
+		if err != nil {
+			return err
+		}
+
+		key := keyOf(vals)
+		parentKeys = append(parentKeys, key)
+
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			keys = append(keys, vals)
+		}
+	}
+
+	children, err := selectModels(ctx, s, newModelFunc(r.model), whereKeysIn(childCols, keys))
+
+	if err != nil {
+		return err
+	}
+
+	tab := make(map[string][]Model)
+
+	for _, c := range children {
+		var vals []any
+
+		if r.kind == belongsTo {
+			vals = c.PrimaryKey().Values
+		} else {
+			vals, err = paramValues(c, childCols)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		key := keyOf(vals)
+		tab[key] = append(tab[key], c)
+	}
+
+	for i, p := range parents {
+		if err := r.assign(p, tab[parentKeys[i]]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r Relation) loadPivot(ctx context.Context, s *Store[Model], parents []Model) error {
+	parentKey, childKey := r.pivotKeys[0], r.pivotKeys[1]
+
+	ids := make([]any, 0, len(parents))
+
+	for _, p := range parents {
+		ids = append(ids, p.PrimaryKey().Values[0])
+	}
+
+	q := query.Select(
+		query.Columns(parentKey, childKey),
+		query.From(r.pivot),
+		query.WhereIn(parentKey, query.List(ids...)),
+	)
+
+	rows, err := s.query(ctx, r.pivot, OpSelect, q)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	// Table of parent keys to the keys of their related models, and the
+	// list of unique keys for the related models.
+	pivot := make(map[string][]string)
+	keys := make([][]any, 0)
+	seen := make(map[string]struct{})
+
+	for rows.Next() {
+		var parentId, childId any
+
+		if err := rows.Scan(&parentId, &childId); err != nil {
+			return err
+		}
+
+		childKey := keyOf([]any{childId})
+
+		pkey := keyOf([]any{parentId})
+		pivot[pkey] = append(pivot[pkey], childKey)
+
+		if _, ok := seen[childKey]; !ok {
+			seen[childKey] = struct{}{}
+			keys = append(keys, []any{childId})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows.Close()
+
+	tab := make(map[string]Model)
+
+	if len(keys) > 0 {
+		children, err := selectModels(ctx, s, newModelFunc(r.model), whereKeysIn(r.model.PrimaryKey().Columns, keys))
+
+		if err != nil {
+			return err
+		}
+
+		for _, c := range children {
+			tab[keyOf(c.PrimaryKey().Values)] = c
+		}
+	}
+
+	for _, p := range parents {
+		children := make([]Model, 0)
+
+		for _, key := range pivot[keyOf(p.PrimaryKey().Values)] {
+			if c, ok := tab[key]; ok {
+				children = append(children, c)
+			}
+		}
+
+		if err := r.assign(p, children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assign sets the relation's struct field on the given parent to the given
+// related models.
+func (r Relation) assign(parent Model, children []Model) error {
+	rv := reflect.ValueOf(parent)
+
+	if rv.Kind() != reflect.Pointer {
+		return errors.New("model must be a pointer")
+	}
+
+	rv = rv.Elem()
+	fv := rv.FieldByName(r.Name)
+
+	if !fv.IsValid() {
+		return &StructFieldError{
+			Struct: rv.Type().Name(),
+			Field:  r.Name,
+			Err:    errors.New("no such field for relation"),
+		}
+	}
+
+	convert := func(typ reflect.Type, m Model) (reflect.Value, error) {
+		val := reflect.ValueOf(m)
+
+		if val.Type().AssignableTo(typ) {
+			return val, nil
+		}
+
+		if val.Elem().Type() == typ {
+			return val.Elem(), nil
+		}
+
+		return val, &StructFieldError{
+			Struct: rv.Type().Name(),
+			Field:  r.Name,
+			Err:    fmt.Errorf("cannot assign %T to field of type %s", m, typ),
+		}
+	}
+
+	switch r.kind {
+	case hasMany, manyToMany, morphMany:
+		if fv.Kind() != reflect.Slice {
+			return &StructFieldError{
+				Struct: rv.Type().Name(),
+				Field:  r.Name,
+				Err:    errors.New("field for relation must be a slice"),
+			}
+		}
+
+		slice := reflect.MakeSlice(fv.Type(), 0, len(children))
+
+		for _, c := range children {
+			val, err := convert(fv.Type().Elem(), c)
+
+			if err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, val)
+		}
+		fv.Set(slice)
+	default:
+		if len(children) == 0 {
+			return nil
+		}
+
+		val, err := convert(fv.Type(), children[0])
+
+		if err != nil {
+			return err
+		}
+		fv.Set(val)
+	}
+	return nil
+}
+
+func (s *Store[M]) pivotRelation(name string) (Relation, error) {
+	r, err := lookupRelation(s.new(), name)
+
+	if err != nil {
+		return r, err
+	}
+
+	if r.kind != manyToMany {
+		return r, fmt.Errorf("relation %s is not a many to many relation", name)
+	}
+	return r, nil
+}
+
+func pivotIds(children []Model) []any {
+	ids := make([]any, 0, len(children))
+
+	for _, c := range children {
+		ids = append(ids, c.PrimaryKey().Values[0])
+	}
+	return ids
+}
+
+// attach inserts the rows into the pivot table of the given relation for the
+// children that are not yet related to the parent. The rows already in the
+// pivot table are read from the store's primary DB, since a lagging replica
+// could lead to duplicate rows.
+func (s *Store[M]) attach(ctx context.Context, r Relation, parent Model, children []Model) error {
+	parentKey, childKey := r.pivotKeys[0], r.pivotKeys[1]
+	parentId := parent.PrimaryKey().Values[0]
+
+	q := query.Select(
+		query.Columns(childKey),
+		query.From(r.pivot),
+		query.WhereEq(parentKey, query.Arg(parentId)),
+		query.WhereIn(childKey, query.List(pivotIds(children)...)),
+	)
+
+	rows, err := s.query(ReadPrimary(ctx), r.pivot, OpSelect, q)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	attached := make(map[string]struct{})
+
+	for rows.Next() {
+		var id any
+
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		attached[keyOf([]any{id})] = struct{}{}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows.Close()
+
+	opts := make([]query.Option, 0, len(children))
+
+	for _, id := range pivotIds(children) {
+		key := keyOf([]any{id})
+
+		if _, ok := attached[key]; ok {
+			continue
+		}
+
+		attached[key] = struct{}{}
+		opts = append(opts, query.Values(parentId, id))
+	}
+
+	if len(opts) == 0 {
+		return nil
+	}
+
+	q = query.Insert(r.pivot, query.Columns(parentKey, childKey), opts...)
+
+	_, err = s.exec(ctx, r.pivot, OpCreate, q)
+
+	return err
+}
+
+// Attach relates the given models to the parent via the pivot table of the
+// named many-to-many relation. Models that are already related to the parent
+// are left as is.
+func (s *Store[M]) Attach(ctx context.Context, name string, parent M, children ...Model) error {
+	r, err := s.pivotRelation(name)
+
+	if err != nil {
+		return err
+	}
+
+	if len(children) == 0 {
+		return nil
+	}
+
+	return s.atomic(ctx, func(s *Store[M]) error {
+		return s.attach(ctx, r, parent, children)
+	})
+}
+
+// Detach removes the relation between the given models and the parent from
+// the pivot table of the named many-to-many relation. If no models are given,
+// then all models are detached from the parent.
+func (s *Store[M]) Detach(ctx context.Context, name string, parent M, children ...Model) error {
+	r, err := s.pivotRelation(name)
+
+	if err != nil {
+		return err
+	}
+
+	opts := []query.Option{
+		query.WhereEq(r.pivotKeys[0], query.Arg(parent.PrimaryKey().Values[0])),
+	}
+
+	if len(children) > 0 {
+		opts = append(opts, query.WhereIn(r.pivotKeys[1], query.List(pivotIds(children)...)))
+	}
+
+	q := query.Delete(r.pivot, opts...)
+
+	_, err = s.exec(ctx, r.pivot, OpDelete, q)
+
+	return err
+}
+
+// Sync makes the given models the only ones related to the parent via the
+// pivot table of the named many-to-many relation. Any models not given are
+// detached, and any given models not yet related are attached. This is done
+// within a single transaction if the store's [DB] is able to begin one.
+func (s *Store[M]) Sync(ctx context.Context, name string, parent M, children ...Model) error {
+	r, err := s.pivotRelation(name)
+
+	if err != nil {
+		return err
+	}
+
+	return s.atomic(ctx, func(s *Store[M]) error {
+		opts := []query.Option{
+			query.WhereEq(r.pivotKeys[0], query.Arg(parent.PrimaryKey().Values[0])),
+		}
+
+		if len(children) > 0 {
+			opts = append(opts, query.WhereNotIn(r.pivotKeys[1], query.List(pivotIds(children)...)))
+		}
+
+		q := query.Delete(r.pivot, opts...)
+
+		if _, err := s.exec(ctx, r.pivot, OpDelete, q); err != nil {
+			return err
+		}
+
+		if len(children) == 0 {
+			return nil
+		}
+		return s.attach(ctx, r, parent, children)
+	})
+}
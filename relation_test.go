@@ -0,0 +1,861 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestPreload(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, userPostSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", userPostSchema, err)
+	}
+
+	users := NewStore(db, func() *User {
+		return &User{}
+	})
+
+	posts := NewStore(db, func() *Post {
+		return &Post{
+			User: &User{},
+		}
+	})
+
+	uu := make([]*User, 0, 3)
+
+	for i := 0; i < cap(uu); i++ {
+		uu = append(uu, &User{
+			ID:    int64(i),
+			Email: rand.Text(),
+		})
+	}
+
+	if err := users.Create(ctx, uu...); err != nil {
+		t.Fatalf("users.Create(ctx, uu...): %v\n", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		p := Post{
+			ID:    int64(i),
+			User:  uu[i%len(uu)],
+			Title: fmt.Sprintf("Post %d", i+1),
+		}
+
+		if err := posts.Create(ctx, &p); err != nil {
+			t.Fatalf("posts.Create(ctx, &p): %v\n", err)
+		}
+	}
+
+	calls := 0
+
+	loadUsers := func(ctx context.Context, db DB, pp []*Post) error {
+		calls++
+
+		ids := make([]any, 0, len(pp))
+
+		for _, p := range pp {
+			ids = append(ids, p.User.ID)
+		}
+
+		uu, err := users.With(db).Select(ctx, query.Columns("*"), query.WhereIn("id", query.List(ids...)))
+
+		if err != nil {
+			return err
+		}
+
+		tab := make(map[int64]*User)
+
+		for _, u := range uu {
+			tab[u.ID] = u
+		}
+
+		for _, p := range pp {
+			p.User = tab[p.User.ID]
+		}
+		return nil
+	}
+
+	pp, err := posts.Preload("User", loadUsers).Select(ctx, query.Columns("*"))
+
+	if err != nil {
+		t.Fatalf("posts.Preload(%q, loadUsers).Select(ctx, query.Columns(%q)): %v\n", "User", "*", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("calls = %v, want = %v\n", calls, 1)
+	}
+
+	for _, p := range pp {
+		if want := uu[p.ID%int64(len(uu))]; *p.User != *want {
+			t.Errorf("p.User = %v, want = %v\n", p.User, want)
+		}
+	}
+
+	if _, _, err := posts.Preload("User", loadUsers).Get(ctx, query.WhereEq("id", query.Arg(-1))); err != nil {
+		t.Fatalf("posts.Preload(%q, loadUsers).Get(ctx): %v\n", "User", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("calls = %v, want = %v\n", calls, 1)
+	}
+
+	// The original store should be left untouched by Preload.
+	if _, err := posts.Select(ctx, query.Columns("*")); err != nil {
+		t.Fatalf("posts.Select(ctx, query.Columns(%q)): %v\n", "*", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("calls = %v, want = %v\n", calls, 1)
+	}
+}
+
+const relationSchema = `
+CREATE TABLE IF NOT EXISTS users (
+	id    INTEGER UNIQUE NOT NULL,
+	email VARCHAR UNIQUE NOT NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS posts (
+	id      INTEGER UNIQUE NOT NULL,
+	user_id INTEGER NOT NULL,
+	title   TEXT NOT NULL,
+	PRIMARY KEY (id),
+	FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE IF NOT EXISTS tags (
+	id   INTEGER UNIQUE NOT NULL,
+	name VARCHAR UNIQUE NOT NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS post_tags (
+	post_id INTEGER NOT NULL,
+	tag_id  INTEGER NOT NULL,
+	PRIMARY KEY (post_id, tag_id)
+);
+`
+
+type RelUser struct {
+	ID    int64
+	Email string
+	Posts []*RelPost
+}
+
+func (u *RelUser) Table() string { return "users" }
+
+func (u *RelUser) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{u.ID},
+	}
+}
+
+func (u *RelUser) Params() Params {
+	return Params{
+		"id":    CreateOnlyParam(u.ID),
+		"email": MutableParam(u.Email),
+	}
+}
+
+func (u *RelUser) Relations() []Relation {
+	return []Relation{
+		HasMany("Posts", &RelPost{}, "user_id"),
+	}
+}
+
+type RelPost struct {
+	ID    int64
+	User  *RelUser
+	Title string
+	Tags  []*RelTag
+}
+
+func (p *RelPost) Table() string { return "posts" }
+
+func (p *RelPost) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{p.ID},
+	}
+}
+
+func (p *RelPost) Params() Params {
+	return Params{
+		"id":      CreateOnlyParam(p.ID),
+		"user_id": CreateOnlyParam(p.User.ID),
+		"title":   MutableParam(p.Title),
+	}
+}
+
+func (p *RelPost) Relations() []Relation {
+	return []Relation{
+		BelongsTo("User", &RelUser{}, "user_id"),
+		ManyToMany("Tags", &RelTag{}, "post_tags", "post_id", "tag_id"),
+	}
+}
+
+type RelTag struct {
+	ID   int64
+	Name string
+}
+
+func (t *RelTag) Table() string { return "tags" }
+
+func (t *RelTag) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{t.ID},
+	}
+}
+
+func (t *RelTag) Params() Params {
+	return Params{
+		"id":   CreateOnlyParam(t.ID),
+		"name": MutableParam(t.Name),
+	}
+}
+
+func TestRelationDefinitions(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, relationSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", relationSchema, err)
+	}
+
+	users := NewStore(db, func() *RelUser {
+		return &RelUser{}
+	})
+
+	posts := NewStore(db, func() *RelPost {
+		return &RelPost{
+			User: &RelUser{},
+		}
+	})
+
+	tags := NewStore(db, func() *RelTag {
+		return &RelTag{}
+	})
+
+	uu := []*RelUser{
+		{ID: 1, Email: "one@example.com"},
+		{ID: 2, Email: "two@example.com"},
+	}
+
+	if err := users.Create(ctx, uu...); err != nil {
+		t.Fatalf("users.Create(ctx, uu...): %v\n", err)
+	}
+
+	tt := []*RelTag{
+		{ID: 1, Name: "go"},
+		{ID: 2, Name: "sql"},
+	}
+
+	if err := tags.Create(ctx, tt...); err != nil {
+		t.Fatalf("tags.Create(ctx, tt...): %v\n", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		p := RelPost{
+			ID:    int64(i),
+			User:  uu[i%2],
+			Title: fmt.Sprintf("Post %d", i+1),
+		}
+
+		if err := posts.Create(ctx, &p); err != nil {
+			t.Fatalf("posts.Create(ctx, &p): %v\n", err)
+		}
+
+		for _, tag := range tt[:i%2+1] {
+			q := query.Insert("post_tags", query.Columns("post_id", "tag_id"), query.Values(p.ID, tag.ID))
+
+			if _, err := q.Exec(ctx, db); err != nil {
+				t.Fatalf("db.ExecContext(ctx, %q): %v\n", q.Build(), err)
+			}
+		}
+	}
+
+	t.Run("join", func(t *testing.T) {
+		pp, err := posts.Select(
+			ctx,
+			ColumnsRelated(&RelPost{User: &RelUser{}}, "User"),
+			JoinRelated(&RelPost{}, "User"),
+		)
+
+		if err != nil {
+			t.Fatalf("posts.Select(ctx, ColumnsRelated(...), JoinRelated(...)): %v\n", err)
+		}
+
+		if len(pp) != 4 {
+			t.Fatalf("len(pp) = %v, want = %v\n", len(pp), 4)
+		}
+
+		for _, p := range pp {
+			if want := uu[p.ID%2]; p.User.Email != want.Email {
+				t.Errorf("p.User.Email = %q, want = %q\n", p.User.Email, want.Email)
+			}
+		}
+	})
+
+	t.Run("select-all", func(t *testing.T) {
+		pp, err := posts.SelectAll(
+			ctx,
+			JoinRelated(&RelPost{}, "User"),
+			query.WhereEq("users.email", query.Arg(uu[0].Email)),
+		)
+
+		if err != nil {
+			t.Fatalf("posts.SelectAll(ctx, JoinRelated(...), ...): %v\n", err)
+		}
+
+		if len(pp) != 2 {
+			t.Fatalf("len(pp) = %v, want = %v\n", len(pp), 2)
+		}
+	})
+
+	t.Run("select-with", func(t *testing.T) {
+		pp, err := posts.SelectWith(ctx, Rel(&RelUser{}, "user_id"), query.WhereEq("users.email", query.Arg(uu[1].Email)))
+
+		if err != nil {
+			t.Fatalf("posts.SelectWith(ctx, Rel(...), ...): %v\n", err)
+		}
+
+		if len(pp) != 2 {
+			t.Fatalf("len(pp) = %v, want = %v\n", len(pp), 2)
+		}
+
+		for _, p := range pp {
+			if p.User.Email != uu[1].Email {
+				t.Errorf("p.User.Email = %q, want = %q\n", p.User.Email, uu[1].Email)
+			}
+		}
+	})
+
+	t.Run("select-one", func(t *testing.T) {
+		p, ok, err := posts.SelectOne(
+			ctx,
+			ColumnsRelated(&RelPost{User: &RelUser{}}, "User"),
+			JoinRelated(&RelPost{}, "User"),
+			query.WhereEq("posts.id", query.Arg(2)),
+		)
+
+		if err != nil {
+			t.Fatalf("posts.SelectOne(ctx, ColumnsRelated(...), JoinRelated(...)): %v\n", err)
+		}
+
+		if !ok {
+			t.Fatalf("posts.SelectOne(ctx, ColumnsRelated(...), JoinRelated(...)): expected post, got none\n")
+		}
+
+		if want := uu[0]; p.User.Email != want.Email {
+			t.Errorf("p.User.Email = %q, want = %q\n", p.User.Email, want.Email)
+		}
+	})
+
+	t.Run("belongs-to", func(t *testing.T) {
+		pp, err := posts.Load("User").Select(ctx, query.Columns("*"))
+
+		if err != nil {
+			t.Fatalf("posts.Load(%q).Select(ctx, query.Columns(%q)): %v\n", "User", "*", err)
+		}
+
+		for _, p := range pp {
+			if want := uu[p.ID%2]; p.User.Email != want.Email {
+				t.Errorf("p.User.Email = %q, want = %q\n", p.User.Email, want.Email)
+			}
+		}
+	})
+
+	t.Run("has-many", func(t *testing.T) {
+		uu2, err := users.Load("Posts").Select(ctx, query.Columns("*"), query.OrderAsc("id"))
+
+		if err != nil {
+			t.Fatalf("users.Load(%q).Select(ctx, query.Columns(%q)): %v\n", "Posts", "*", err)
+		}
+
+		for _, u := range uu2 {
+			if len(u.Posts) != 2 {
+				t.Fatalf("len(u.Posts) = %v, want = %v\n", len(u.Posts), 2)
+			}
+
+			for _, p := range u.Posts {
+				if p.ID%2 != u.ID-1 {
+					t.Errorf("unexpected post %v for user %v\n", p.ID, u.ID)
+				}
+			}
+		}
+	})
+
+	t.Run("many-to-many", func(t *testing.T) {
+		pp, err := posts.Load("User", "Tags").Select(ctx, query.Columns("*"), query.OrderAsc("id"))
+
+		if err != nil {
+			t.Fatalf("posts.Load(%q, %q).Select(ctx, query.Columns(%q)): %v\n", "User", "Tags", "*", err)
+		}
+
+		for _, p := range pp {
+			if n := int(p.ID%2) + 1; len(p.Tags) != n {
+				t.Fatalf("len(p.Tags) = %v, want = %v\n", len(p.Tags), n)
+			}
+		}
+	})
+
+	t.Run("load-related", func(t *testing.T) {
+		parents := []*RelUser{
+			{ID: 1},
+			{ID: 2},
+			{ID: 3},
+		}
+
+		assigned := make(map[int64][]*RelPost)
+
+		err := LoadRelated(ctx, db, parents, "user_id", func(u *RelUser, pp []*RelPost) {
+			assigned[u.ID] = pp
+		})
+
+		if err != nil {
+			t.Fatalf("LoadRelated(ctx, db, parents, %q, ...): %v\n", "user_id", err)
+		}
+
+		if len(assigned) != len(parents) {
+			t.Fatalf("len(assigned) = %v, want = %v\n", len(assigned), len(parents))
+		}
+
+		for _, u := range parents {
+			want := 2
+
+			if u.ID == 3 {
+				want = 0
+			}
+
+			if n := len(assigned[u.ID]); n != want {
+				t.Fatalf("len(assigned[%v]) = %v, want = %v\n", u.ID, n, want)
+			}
+
+			for _, p := range assigned[u.ID] {
+				if p.User.ID != u.ID {
+					t.Errorf("p.User.ID = %v, want = %v\n", p.User.ID, u.ID)
+				}
+			}
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := posts.Load("Comments").Select(ctx, query.Columns("*")); err == nil {
+			t.Fatal("expected error for unknown relation, got nil")
+		}
+	})
+}
+
+func TestAttachDetachSync(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, relationSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", relationSchema, err)
+	}
+
+	u := &RelUser{ID: 1, Email: "one@example.com"}
+
+	if err := NewStore(db, func() *RelUser { return &RelUser{} }).Create(ctx, u); err != nil {
+		t.Fatalf("users.Create(ctx, u): %v\n", err)
+	}
+
+	var rec metricsRecorder
+
+	posts := NewStore(db, func() *RelPost {
+		return &RelPost{
+			User: &RelUser{},
+		}
+	}, WithMetrics(&rec))
+
+	tags := NewStore(db, func() *RelTag {
+		return &RelTag{}
+	})
+
+	tt := []*RelTag{
+		{ID: 1, Name: "go"},
+		{ID: 2, Name: "sql"},
+		{ID: 3, Name: "sqlite"},
+	}
+
+	if err := tags.Create(ctx, tt...); err != nil {
+		t.Fatalf("tags.Create(ctx, tt...): %v\n", err)
+	}
+
+	p := &RelPost{ID: 1, User: u, Title: "Post"}
+
+	if err := posts.Create(ctx, p); err != nil {
+		t.Fatalf("posts.Create(ctx, p): %v\n", err)
+	}
+
+	tagIds := func() []int64 {
+		t.Helper()
+
+		p, _, err := posts.Load("Tags").Get(ctx, p.PrimaryKey().Where())
+
+		if err != nil {
+			t.Fatalf("posts.Load(%q).Get(ctx): %v\n", "Tags", err)
+		}
+
+		ids := make([]int64, 0, len(p.Tags))
+
+		for _, tag := range p.Tags {
+			ids = append(ids, tag.ID)
+		}
+		slices.Sort(ids)
+		return ids
+	}
+
+	rec = metricsRecorder{}
+
+	if err := posts.Attach(ctx, "Tags", p, tt[0], tt[1]); err != nil {
+		t.Fatalf("posts.Attach(ctx, %q, p, tt[0], tt[1]): %v\n", "Tags", err)
+	}
+
+	if want := []Op{OpSelect, OpCreate}; !slices.Equal(rec.ops, want) {
+		t.Fatalf("rec.ops = %v, want = %v\n", rec.ops, want)
+	}
+
+	if want := []string{"post_tags", "post_tags"}; !slices.Equal(rec.tables, want) {
+		t.Fatalf("rec.tables = %v, want = %v\n", rec.tables, want)
+	}
+
+	// Attaching an already attached tag should be a no-op.
+	if err := posts.Attach(ctx, "Tags", p, tt[1]); err != nil {
+		t.Fatalf("posts.Attach(ctx, %q, p, tt[1]): %v\n", "Tags", err)
+	}
+
+	if ids := tagIds(); !slices.Equal(ids, []int64{1, 2}) {
+		t.Fatalf("tagIds() = %v, want = %v\n", ids, []int64{1, 2})
+	}
+
+	if err := posts.Detach(ctx, "Tags", p, tt[0]); err != nil {
+		t.Fatalf("posts.Detach(ctx, %q, p, tt[0]): %v\n", "Tags", err)
+	}
+
+	if ids := tagIds(); !slices.Equal(ids, []int64{2}) {
+		t.Fatalf("tagIds() = %v, want = %v\n", ids, []int64{2})
+	}
+
+	if err := posts.Sync(ctx, "Tags", p, tt[0], tt[2]); err != nil {
+		t.Fatalf("posts.Sync(ctx, %q, p, tt[0], tt[2]): %v\n", "Tags", err)
+	}
+
+	if ids := tagIds(); !slices.Equal(ids, []int64{1, 3}) {
+		t.Fatalf("tagIds() = %v, want = %v\n", ids, []int64{1, 3})
+	}
+
+	if err := posts.Detach(ctx, "Tags", p); err != nil {
+		t.Fatalf("posts.Detach(ctx, %q, p): %v\n", "Tags", err)
+	}
+
+	if ids := tagIds(); len(ids) != 0 {
+		t.Fatalf("tagIds() = %v, want = %v\n", ids, []int64{})
+	}
+
+	if err := posts.Attach(ctx, "User", p, u); err == nil {
+		t.Fatal("expected error attaching to belongs to relation, got nil")
+	}
+}
+
+type Message struct {
+	ID        int64
+	Sender    *User
+	Recipient *User
+}
+
+func (m *Message) Table() string { return "messages" }
+
+func (m *Message) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{m.ID},
+	}
+}
+
+func (m *Message) Params() Params {
+	return Params{
+		"id":           CreateOnlyParam(m.ID),
+		"sender_id":    CreateOnlyParam(m.Sender.ID),
+		"recipient_id": CreateOnlyParam(m.Recipient.ID),
+	}
+}
+
+func (m *Message) Relations() []Relation {
+	return []Relation{
+		BelongsTo("Sender", &User{}, "sender_id").As("sender"),
+		BelongsTo("Recipient", &User{}, "recipient_id").As("recipient"),
+	}
+}
+
+const messageSchema = `CREATE TABLE IF NOT EXISTS messages (
+	id           INTEGER NOT NULL,
+	sender_id    INTEGER NOT NULL,
+	recipient_id INTEGER NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+func TestRelationAlias(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	for _, schema := range []string{userPostSchema, messageSchema} {
+		if _, err := db.ExecContext(ctx, schema); err != nil {
+			t.Fatalf("db.ExecContext(ctx, %q): %v\n", schema, err)
+		}
+	}
+
+	users := NewStore(db, func() *User {
+		return &User{}
+	})
+
+	sender := &User{ID: 1, Email: "sender@example.com"}
+	recipient := &User{ID: 2, Email: "recipient@example.com"}
+
+	if err := users.Create(ctx, sender, recipient); err != nil {
+		t.Fatalf("users.Create(ctx, sender, recipient): %v\n", err)
+	}
+
+	messages := NewStore(db, func() *Message {
+		return &Message{}
+	})
+
+	m := &Message{
+		ID:        1,
+		Sender:    sender,
+		Recipient: recipient,
+	}
+
+	if err := messages.Create(ctx, m); err != nil {
+		t.Fatalf("messages.Create(ctx, m): %v\n", err)
+	}
+
+	mm, err := messages.Select(
+		ctx,
+		ColumnsRelated(m, "Sender", "Recipient"),
+		JoinRelated(m, "Sender", "Recipient"),
+	)
+
+	if err != nil {
+		t.Fatalf("messages.Select(ctx, ...): %v\n", err)
+	}
+
+	if len(mm) != 1 {
+		t.Fatalf("len(mm) = %v, want = %v\n", len(mm), 1)
+	}
+
+	if *mm[0].Sender != *sender {
+		t.Fatalf("mm[0].Sender = %v, want = %v\n", mm[0].Sender, sender)
+	}
+
+	if *mm[0].Recipient != *recipient {
+		t.Fatalf("mm[0].Recipient = %v, want = %v\n", mm[0].Recipient, recipient)
+	}
+}
+
+func TestColumnsAsJoinAs(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	for _, schema := range []string{userPostSchema, messageSchema} {
+		if _, err := db.ExecContext(ctx, schema); err != nil {
+			t.Fatalf("db.ExecContext(ctx, %q): %v\n", schema, err)
+		}
+	}
+
+	stmts := []string{
+		"INSERT INTO users (id, email) VALUES (1, 'sender@example.com'), (2, 'recipient@example.com')",
+		"INSERT INTO messages (id, sender_id, recipient_id) VALUES (1, 1, 2)",
+	}
+
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("db.ExecContext(ctx, %q): %v\n", stmt, err)
+		}
+	}
+
+	messages := NewStore(db, func() *Message {
+		return &Message{}
+	})
+
+	mm, err := messages.Select(
+		ctx,
+		query.Exprs(
+			query.Columns("messages.id"),
+			ColumnsAs(&User{}, "sender"),
+			ColumnsAs(&User{}, "recipient"),
+		),
+		JoinAs(&User{}, "sender", "messages.sender_id"),
+		JoinAs(&User{}, "recipient", "messages.recipient_id"),
+	)
+
+	if err != nil {
+		t.Fatalf("messages.Select(ctx, ...): %v\n", err)
+	}
+
+	if len(mm) != 1 {
+		t.Fatalf("len(mm) = %v, want = %v\n", len(mm), 1)
+	}
+
+	if email := mm[0].Sender.Email; email != "sender@example.com" {
+		t.Fatalf("mm[0].Sender.Email = %q, want = %q\n", email, "sender@example.com")
+	}
+
+	if email := mm[0].Recipient.Email; email != "recipient@example.com" {
+		t.Fatalf("mm[0].Recipient.Email = %q, want = %q\n", email, "recipient@example.com")
+	}
+}
+
+func TestJoinOn(t *testing.T) {
+	tests := []struct {
+		want  string
+		query *query.Query
+	}{
+		{
+			"SELECT * FROM invites JOIN users ON invites.email = users.email",
+			query.Select(
+				query.Columns("*"),
+				query.From("invites"),
+				JoinOn(&User{}, []string{"invites.email"}, []string{"email"}),
+			),
+		},
+		{
+			"SELECT * FROM invites JOIN users AS inviter ON invites.inviter_id = inviter.id AND invites.inviter_email = inviter.email",
+			query.Select(
+				query.Columns("*"),
+				query.From("invites"),
+				JoinOnAs(&User{}, "inviter", []string{"invites.inviter_id", "invites.inviter_email"}, []string{"id", "email"}),
+			),
+		},
+	}
+
+	for i, test := range tests {
+		if built := test.query.Build(); built != test.want {
+			t.Fatalf("tests[%d] - query.Build() = %q, want = %q\n", i, built, test.want)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("JoinOn(&User{}, ...): expected panic, got nil\n")
+		}
+	}()
+
+	JoinOn(&User{}, []string{"invites.email"}, []string{"id", "email"})
+}
+
+const vaultSchema = `CREATE TABLE IF NOT EXISTS vaults (
+	id INTEGER NOT NULL,
+	PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS vault_secrets (
+	id       INTEGER NOT NULL,
+	vault_id INTEGER NOT NULL,
+	value    TEXT NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+type Vault struct {
+	ID      int64
+	Secrets []*VaultSecret
+}
+
+func (v *Vault) Table() string { return "vaults" }
+
+func (v *Vault) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{v.ID},
+	}
+}
+
+func (v *Vault) Params() Params {
+	return Params{
+		"id": CreateOnlyParam(v.ID),
+	}
+}
+
+func (v *Vault) Relations() []Relation {
+	return []Relation{
+		HasMany("Secrets", &VaultSecret{}, "vault_id"),
+	}
+}
+
+type VaultSecret struct {
+	ID      int64
+	VaultID int64
+	Value   string `db:"value,encrypted"`
+}
+
+func (s *VaultSecret) Table() string { return "vault_secrets" }
+
+func (s *VaultSecret) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{s.ID},
+	}
+}
+
+func (s *VaultSecret) Params() Params {
+	return Params{
+		"id":       CreateOnlyParam(s.ID),
+		"vault_id": CreateOnlyParam(s.VaultID),
+		"value":    MutableParam(s.Value),
+	}
+}
+
+func TestLoadStoreConfig(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, vaultSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", vaultSchema, err)
+	}
+
+	kr := NewKeyring(NewKey(t, "k1", "0123456789abcdef"))
+
+	var rec metricsRecorder
+
+	vaults := NewStore(db, func() *Vault {
+		return &Vault{}
+	}, WithKeyring(kr), WithMetrics(&rec))
+
+	secrets := NewStore(db, func() *VaultSecret {
+		return &VaultSecret{}
+	}, WithKeyring(kr))
+
+	if err := vaults.Create(ctx, &Vault{ID: 1}); err != nil {
+		t.Fatalf("vaults.Create(ctx, &Vault{}): %v\n", err)
+	}
+
+	if err := secrets.Create(ctx, &VaultSecret{ID: 1, VaultID: 1, Value: "hunter2"}); err != nil {
+		t.Fatalf("secrets.Create(ctx, &VaultSecret{}): %v\n", err)
+	}
+
+	rec = metricsRecorder{}
+
+	v, _, err := vaults.Load("Secrets").Get(ctx, query.WhereEq("id", query.Arg(1)))
+
+	if err != nil {
+		t.Fatalf("vaults.Load(%q).Get(ctx): %v\n", "Secrets", err)
+	}
+
+	if len(v.Secrets) != 1 || v.Secrets[0].Value != "hunter2" {
+		t.Fatalf("v.Secrets = %v, want decrypted secret\n", v.Secrets)
+	}
+
+	if want := []string{"vaults", "vault_secrets"}; !slices.Equal(rec.tables, want) {
+		t.Fatalf("rec.tables = %v, want = %v\n", rec.tables, want)
+	}
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type replicaSet struct {
+	dbs  []DB
+	next atomic.Uint64
+}
+
+func (r *replicaSet) pick() DB {
+	n := r.next.Add(1)
+	return r.dbs[(n-1)%uint64(len(r.dbs))]
+}
+
+// WithReplicas configures the read replicas for a [Store]. Queries that only
+// read data, such as those from [Store.Select] and [Store.Get], are routed to
+// the replicas in a round-robin fashion, whereas queries that write data are
+// routed to the store's [DB].
+//
+// Reads can be routed to the store's DB via the context returned from
+// [ReadPrimary] or [ReadPrimaryAfterWrite]. Replicas are not used by a store
+// returned from [Store.With], so that reads within a transaction see the
+// writes made within it.
+func WithReplicas(dbs ...DB) StoreOption {
+	return func(cfg *storeConfig) {
+		if len(dbs) == 0 {
+			cfg.replicas = nil
+			return
+		}
+
+		cfg.replicas = &replicaSet{
+			dbs: dbs,
+		}
+	}
+}
+
+type readPrimaryKey struct{}
+
+type readPrimary struct {
+	// sticky is whether reads should only be routed to the primary after a
+	// write has been made.
+	sticky bool
+	wrote  atomic.Bool
+}
+
+// ReadPrimary returns a copy of the given context that routes any reads made
+// with it to the primary [DB] of a [Store], instead of its replicas.
+func ReadPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readPrimaryKey{}, &readPrimary{})
+}
+
+// ReadPrimaryAfterWrite returns a copy of the given context that routes any
+// reads made with it to the primary [DB] of a [Store] once a write has been
+// made with it. This avoids reading stale data from a replica that has yet to
+// receive the write, for example when a model is created then immediately
+// queried during the same request.
+func ReadPrimaryAfterWrite(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readPrimaryKey{}, &readPrimary{
+		sticky: true,
+	})
+}
+
+// reader returns the DB that reads should be routed to for the given context.
+func (s *Store[M]) reader(ctx context.Context) DB {
+	if s.cfg.replicas == nil {
+		return s.DB
+	}
+
+	if p, ok := ctx.Value(readPrimaryKey{}).(*readPrimary); ok {
+		if !p.sticky || p.wrote.Load() {
+			return s.DB
+		}
+	}
+	return s.cfg.replicas.pick()
+}
+
+// wrote marks the given context as having been used for a write, if it was
+// returned from ReadPrimaryAfterWrite.
+func wrote(ctx context.Context) {
+	if p, ok := ctx.Value(readPrimaryKey{}).(*readPrimary); ok {
+		p.wrote.Store(true)
+	}
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestReplicas(t *testing.T) {
+	ctx := t.Context()
+	primary := NewDB(t)
+
+	replica, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "replica.sqlite"))
+
+	if err != nil {
+		t.Fatalf("sql.Open(%q, %q): %v\n", "sqlite", "replica.sqlite", err)
+	}
+
+	defer replica.Close()
+
+	for _, db := range []*sql.DB{primary, replica} {
+		if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+			t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+		}
+	}
+
+	store := NewStore[*M](primary, func() *M {
+		return &M{}
+	}, WithReplicas(replica))
+
+	count := func(ctx context.Context, s *Store[*M]) int {
+		t.Helper()
+
+		mm, err := s.Select(ctx, query.Columns("*"))
+
+		if err != nil {
+			t.Fatalf("s.Select(ctx, query.Columns(%q)): %v\n", "*", err)
+		}
+		return len(mm)
+	}
+
+	m := &M{
+		ID:     1,
+		Str:    "string",
+		BigStr: "bigstring",
+		Blob:   []byte{},
+		Time:   time.Now(),
+	}
+
+	afterWrite := ReadPrimaryAfterWrite(ctx)
+
+	if n := count(afterWrite, store); n != 0 {
+		t.Fatalf("count(afterWrite, store) = %v, want = %v\n", n, 0)
+	}
+
+	if err := store.Create(afterWrite, m); err != nil {
+		t.Fatalf("store.Create(afterWrite, m): %v\n", err)
+	}
+
+	// Reads should go to the replica, which will not have the model.
+	if n := count(ctx, store); n != 0 {
+		t.Fatalf("count(ctx, store) = %v, want = %v\n", n, 0)
+	}
+
+	if n := count(ReadPrimary(ctx), store); n != 1 {
+		t.Fatalf("count(ReadPrimary(ctx), store) = %v, want = %v\n", n, 1)
+	}
+
+	if n := count(afterWrite, store); n != 1 {
+		t.Fatalf("count(afterWrite, store) = %v, want = %v\n", n, 1)
+	}
+
+	if n := count(ctx, store.With(primary)); n != 1 {
+		t.Fatalf("count(ctx, store.With(primary)) = %v, want = %v\n", n, 1)
+	}
+}
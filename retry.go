@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// RetryPolicy configures how the queries run by a [Store] are retried when they
+// fail with a transient error.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a query is attempted, this
+	// includes the initial attempt.
+	MaxAttempts int
+
+	// Backoff returns how long to wait before the given attempt, starting from
+	// 1 for the first retry. If nil, then the query is retried immediately.
+	Backoff func(attempt int) time.Duration
+
+	// Retryable reports whether the given error is transient, and the query
+	// should be retried. If nil, then [IsTransient] is used.
+	Retryable func(err error) bool
+}
+
+// WithRetry configures the [RetryPolicy] used by a [Store] for retrying queries
+// that fail with a transient error. Queries are not retried if the store is
+// operating on a [sql.Tx], since the failure of a statement may have aborted
+// the transaction. In that case the whole transaction should be retried
+// instead.
+func WithRetry(p RetryPolicy) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.retry = p
+	}
+}
+
+// ExponentialBackoff returns a backoff function for a [RetryPolicy] that
+// doubles the wait on each attempt, starting from base and never exceeding
+// max.
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := base
+
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		return min(d, max)
+	}
+}
+
+// sqlStateError is implemented by the errors of Postgres drivers, such as pgx
+// and lib/pq, to return the SQLSTATE code of the error.
+type sqlStateError interface {
+	SQLState() string
+}
+
+// codeError is implemented by the errors of SQLite drivers, such as
+// modernc.org/sqlite, to return the result code of the error.
+type codeError interface {
+	Code() int
+}
+
+// IsTransient reports whether the given error is a transient error that may
+// succeed if the query is retried. This reports true for the following,
+//
+//   - [driver.ErrBadConn], and [sql.ErrConnDone]
+//   - Postgres serialization failures and deadlocks (SQLSTATE 40001 and 40P01)
+//   - SQLite busy and locked errors (SQLITE_BUSY and SQLITE_LOCKED)
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+
+	var stateErr sqlStateError
+
+	if errors.As(err, &stateErr) {
+		switch stateErr.SQLState() {
+		case "40001", "40P01":
+			return true
+		}
+	}
+
+	var codeErr codeError
+
+	if errors.As(err, &codeErr) {
+		// The primary result code is held in the lower 8 bits, the upper bits
+		// are for extended result codes.
+		switch codeErr.Code() & 0xff {
+		case 5, 6:
+			return true
+		}
+	}
+	return false
+}
+
+// retry calls fn until it succeeds, or returns an error that is not transient,
+// as per the store's [RetryPolicy].
+func (s *Store[M]) retry(ctx context.Context, fn func() error) error {
+	p := s.cfg.retry
+
+	if _, ok := s.DB.(*sql.Tx); ok || p.MaxAttempts <= 1 {
+		return fn()
+	}
+
+	retryable := p.Retryable
+
+	if retryable == nil {
+		retryable = IsTransient
+	}
+
+	var err error
+
+	for attempt := 0; attempt < p.MaxAttempts; attempt++ {
+		if attempt > 0 && p.Backoff != nil {
+			t := time.NewTimer(p.Backoff(attempt))
+
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return errors.Join(err, ctx.Err())
+			case <-t.C:
+			}
+		}
+
+		if err = fn(); err == nil || !retryable(err) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// flakyDB fails the first n queries executed against it with the given error.
+type flakyDB struct {
+	execRecorder
+
+	n   int
+	err error
+}
+
+func (db *flakyDB) ExecContext(ctx context.Context, q string, args ...any) (sql.Result, error) {
+	if db.n > 0 {
+		db.n--
+		return nil, db.err
+	}
+	return db.execRecorder.ExecContext(ctx, q, args...)
+}
+
+type sqlStateErr string
+
+func (e sqlStateErr) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateErr) SQLState() string { return string(e) }
+
+type codeErr int
+
+func (e codeErr) Error() string { return fmt.Sprintf("code %d", int(e)) }
+func (e codeErr) Code() int     { return int(e) }
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("error"), false},
+		{driver.ErrBadConn, true},
+		{fmt.Errorf("wrapped: %w", driver.ErrBadConn), true},
+		{sqlStateErr("40001"), true},
+		{sqlStateErr("40P01"), true},
+		{sqlStateErr("23505"), false},
+		{codeErr(5), true},
+		{codeErr(6), true},
+		{codeErr(517), true},
+		{codeErr(19), false},
+	}
+
+	for _, test := range tests {
+		if got := IsTransient(test.err); got != test.want {
+			t.Errorf("IsTransient(%v) = %v, want = %v\n", test.err, got, test.want)
+		}
+	}
+}
+
+func TestStoreRetry(t *testing.T) {
+	ctx := t.Context()
+
+	tests := []struct {
+		n       int
+		err     error
+		attempt int
+		wantErr bool
+	}{
+		{2, driver.ErrBadConn, 3, false},
+		{3, driver.ErrBadConn, 3, true},
+		{1, errors.New("error"), 1, true},
+	}
+
+	for _, test := range tests {
+		db := &flakyDB{
+			n:   test.n,
+			err: test.err,
+		}
+
+		var rec metricsRecorder
+
+		store := NewStore[*M](db, func() *M {
+			return &M{}
+		}, WithMetrics(&rec), WithRetry(RetryPolicy{
+			MaxAttempts: 3,
+			Backoff:     ExponentialBackoff(time.Millisecond, 5*time.Millisecond),
+		}))
+
+		err := store.Create(ctx, &M{})
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("store.Create(ctx, &M{}) = %v, want error = %v\n", err, test.wantErr)
+		}
+
+		if len(rec.ops) != test.attempt {
+			t.Errorf("attempts = %v, want = %v\n", len(rec.ops), test.attempt)
+		}
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+
+	want := []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		40 * time.Millisecond,
+		50 * time.Millisecond,
+		50 * time.Millisecond,
+	}
+
+	for i, d := range want {
+		if got := backoff(i + 1); got != d {
+			t.Errorf("backoff(%v) = %v, want = %v\n", i+1, got, d)
+		}
+	}
+}
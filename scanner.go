@@ -1,12 +1,20 @@
 package database
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"encoding"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+
+	"github.com/andrewpillar/database/query"
 )
 
 // Row represents a single row from a set of multiple rows queried from the
@@ -50,7 +58,108 @@ type structField struct {
 	// and a struct field name. So the column "id" would match with the struct
 	// field of "ID".
 	fold func(s, t []byte) bool
-	val  reflect.Value
+
+	// snake is the snake_case form of the name, this is used as a fallback for
+	// matching a column name, so the column "created_at" would match with the
+	// struct field of "CreatedAt".
+	snake string
+
+	// index is the sequence of field indexes to get to the field from the
+	// top-level struct, as per [reflect.Value.FieldByIndex]. Any pointers to
+	// structs along the way are dereferenced.
+	index []int
+	typ   reflect.Type
+
+	// tagged is whether the field's name was given via a struct tag, in which
+	// case the name is already the column name.
+	tagged bool
+
+	// json is whether the field is stored as JSON in the column.
+	json bool
+
+	// encrypted is whether the field is stored encrypted in the column.
+	encrypted bool
+
+	// sensitive is whether the field's value is redacted from logs and
+	// errors.
+	sensitive bool
+}
+
+// value returns the field from the given struct value. If a nil pointer is
+// found on the way to the field, then false is returned.
+func (f *structField) value(rv reflect.Value) (reflect.Value, bool) {
+	for i, idx := range f.index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(idx)
+	}
+	return rv, true
+}
+
+// alloc returns the field from the given struct value, allocating any nil
+// pointers to structs found on the way to the field.
+func (f *structField) alloc(rv reflect.Value) reflect.Value {
+	for i, idx := range f.index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(idx)
+	}
+	return rv
+}
+
+// direct reports whether the field can be given directly to [sql.Rows.Scan],
+// instead of being scanned into an interface value first. This is true for
+// fields that are, or point to, a bool, number, string, or []byte.
+func (f *structField) direct() bool {
+	if f.json || f.encrypted {
+		return false
+	}
+
+	rt := f.typ
+
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+
+	pt := reflect.PointerTo(rt)
+
+	if pt.Implements(textUnmarshalerType) || pt.Implements(binaryUnmarshalerType) {
+		return false
+	}
+
+	switch rt.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return rt.Elem().Kind() == reflect.Uint8
+	}
+	return false
+}
+
+// nest returns a copy of the field, nested within the field at the given index.
+func (f *structField) nest(i int, name string) *structField {
+	return &structField{
+		name:      name,
+		fold:      foldFunc([]byte(name)),
+		snake:     SnakeCase(name),
+		index:     append([]int{i}, f.index...),
+		typ:       f.typ,
+		tagged:    f.tagged,
+		json:      f.json,
+		encrypted: f.encrypted,
+		sensitive: f.sensitive,
+	}
 }
 
 type structFields struct {
@@ -76,7 +185,12 @@ func (s *structFields) get(name string) (*structField, bool) {
 
 	for _, fld := range s.arr {
 		if fld.fold([]byte(fld.name), []byte(name)) {
-			s.put(name, fld)
+			return fld, true
+		}
+	}
+
+	for _, fld := range s.arr {
+		if fld.snake == name {
 			return fld, true
 		}
 	}
@@ -88,22 +202,95 @@ type Scanner struct {
 	rows *sql.Rows
 	cols []string
 	dest []any
+
+	// scanRow scans the current row into the given destinations. This is
+	// the Scan method of the rows, unless the row data comes from elsewhere,
+	// such as the records of a CSV import.
+	scanRow func(dest ...any) error
+
+	// fields holds the struct fields that each of the columns map to for a
+	// given type, so columns are only matched once per type.
+	fields map[reflect.Type][]*structField
+
+	// types holds the column types of the rows, these are only retrieved
+	// when scanning into a map.
+	types []*sql.ColumnType
+
+	mapper      *Mapper
+	timeLayouts []string
+	keyring     *Keyring
+
+	// strict is whether an error is returned for columns that do not map to
+	// a struct field.
+	strict bool
+
+	// plaintext is whether the values of encrypted fields are scanned as is,
+	// instead of being decrypted, such as when importing.
+	plaintext bool
+
+	// direct is whether fields are scanned into directly where possible, and
+	// scanned records which of the columns were for the current row.
+	direct  bool
+	scanned []bool
+}
+
+// ScannerOption is a function that configures a [Scanner] when it is created
+// via [NewScanner].
+type ScannerOption func(*Scanner)
+
+// UseMapper configures the [Mapper] a [Scanner] uses for matching columns to
+// struct fields. The Mapper is tried first, before falling back to the default
+// matching.
+func UseMapper(m Mapper) ScannerOption {
+	return func(sc *Scanner) {
+		sc.mapper = &m
+	}
+}
+
+// UseStrictColumns configures a [Scanner] to return a [ColumnScanError] for any
+// column that does not map to a struct field, instead of ignoring it. This is
+// useful for catching typos in struct tags and column names.
+func UseStrictColumns() ScannerOption {
+	return func(sc *Scanner) {
+		sc.strict = true
+	}
+}
+
+// UseDirectScan configures a [Scanner] to scan columns directly into struct
+// fields that are, or point to, a bool, number, string, or []byte. This avoids
+// scanning each column into an interface value and converting it afterwards,
+// which can be costly for wide tables.
+//
+// Unlike the default scanning, a NULL column cannot be scanned directly into a
+// non-pointer field, and will result in an error. Such columns should be
+// scanned into pointer fields, or types such as [Null].
+func UseDirectScan() ScannerOption {
+	return func(sc *Scanner) {
+		sc.direct = true
+	}
 }
 
 // NewScanner returns a [Scanner] for scanning the given [database.sql.Rows]
 // into Models.
-func NewScanner(rows *sql.Rows) (*Scanner, error) {
+func NewScanner(rows *sql.Rows, opts ...ScannerOption) (*Scanner, error) {
 	cols, err := rows.Columns()
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &Scanner{
-		rows: rows,
-		cols: cols,
-		dest: make([]any, 0, len(cols)),
-	}, nil
+	sc := &Scanner{
+		rows:        rows,
+		cols:        cols,
+		scanRow:     rows.Scan,
+		dest:        make([]any, 0, len(cols)),
+		timeLayouts: DefaultTimeLayouts,
+	}
+
+	for _, opt := range opts {
+		opt(sc)
+	}
+	return sc, nil
 }
 
 type StructFieldError struct {
@@ -120,7 +307,23 @@ func (e *StructFieldError) Error() string {
 	return fmt.Sprintf("struct field %s.%s: %s", e.Struct, e.Field, e.Err)
 }
 
-const scanAliasTag = "db"
+func (e *StructFieldError) Unwrap() error {
+	return e.Err
+}
+
+const (
+	scanAliasTag = "db"
+	jsonOption   = "json"
+)
+
+// fieldCache holds the *structFields for each struct type that has been
+// scanned into, so struct tags are only parsed once per type.
+var fieldCache sync.Map
+
+type cachedFields struct {
+	fields *structFields
+	err    error
+}
 
 func (sc *Scanner) getFields(rv reflect.Value) (*structFields, error) {
 	if rv.IsNil() {
@@ -134,21 +337,138 @@ func (sc *Scanner) getFields(rv reflect.Value) (*structFields, error) {
 	if rv.Kind() != reflect.Struct {
 		return nil, errors.New("target must be struct or pointer to struct")
 	}
+	return typeFields(rv.Type())
+}
+
+// typeFields returns the fields of the given struct type, caching them for
+// subsequent calls.
+func typeFields(rt reflect.Type) (*structFields, error) {
+	return buildingFields(rt, make(map[reflect.Type]*structFields))
+}
+
+// buildingFields returns the fields of the given struct type, building them if
+// they are not cached. The given map holds the fields of the types that are
+// currently being built, so a type that refers back to itself, such as a
+// Parent field of the same type, is given the fields built so far instead of
+// being built again forever. Fields are only cached once no other type is
+// being built, since those built in the meantime may refer to the partial
+// fields of another.
+func buildingFields(rt reflect.Type, building map[reflect.Type]*structFields) (*structFields, error) {
+	if v, ok := fieldCache.Load(rt); ok {
+		c := v.(cachedFields)
+		return c.fields, c.err
+	}
+
+	if fields, ok := building[rt]; ok {
+		return fields, nil
+	}
+
+	fields := &structFields{}
+
+	building[rt] = fields
+
+	err := buildFields(rt, fields, building)
+
+	delete(building, rt)
+
+	if err != nil {
+		fields = nil
+	}
+
+	if len(building) == 0 {
+		fieldCache.Store(rt, cachedFields{
+			fields: fields,
+			err:    err,
+		})
+	}
+	return fields, err
+}
+
+// pendingTarget is a column mapped to the target field of a struct whose
+// fields were still being built when the column was mapped.
+type pendingTarget struct {
+	col    string
+	index  int
+	target string
+	nested *structFields
+}
 
-	var fields structFields
+func buildFields(rt reflect.Type, fields *structFields, building map[reflect.Type]*structFields) error {
+	// Columns mapped to the fields of a type that is still being built, these
+	// are mapped once the fields of this type are built.
+	pending := make([]pendingTarget, 0)
 
-	rt := rv.Type()
+	// Tags derived from any relations declared on the struct, these are used
+	// for the relation's field if no tag is explicitly set.
+	reltags := make(map[string]string)
 
-	for i := 0; i < rv.NumField(); i++ {
+	if m, ok := reflect.New(rt).Interface().(Model); ok {
+		for _, r := range relations(m) {
+			reltags[r.Name] = r.tag()
+		}
+	}
+
+	// Indexes of the untagged struct fields whose fields are mapped after all
+	// other fields, so the fields of the outer struct take precedence.
+	nested := make([]int, 0)
+
+	for i := 0; i < rt.NumField(); i++ {
 		sf := rt.Field(i)
-		sv := rv.Field(i)
 
-		if v := sf.Tag.Get(scanAliasTag); v != "" {
+		v := sf.Tag.Get(scanAliasTag)
+
+		if v == "" {
+			v = reltags[sf.Name]
+		}
+
+		if v == "" && isNestedStruct(sf) && sf.Type != rt {
+			nested = append(nested, i)
+
+			// Embedded structs are mapped as if they had the `db:"*:*"`
+			// struct tag, so only nested struct fields are mapped as is.
+			if sf.Anonymous {
+				continue
+			}
+		}
+
+		if v != "" {
 			if v == "-" {
 				continue
 			}
 
-			for _, col := range strings.Split(v, ",") {
+			cols := strings.Split(v, ",")
+
+			// The "json" option marks the field as being stored as JSON, so
+			// it is unmarshalled when scanned.
+			isJSON := slices.Contains(cols, jsonOption)
+
+			// The "encrypted" option marks the field as being stored
+			// encrypted, so it is decrypted when scanned.
+			isEncrypted := slices.Contains(cols, encryptedOption)
+
+			// The "sensitive" option marks the field as holding a value that
+			// is redacted from logs and errors.
+			isSensitive := slices.Contains(cols, sensitiveOption)
+
+			cols = slices.DeleteFunc(cols, func(col string) bool {
+				return slices.Contains(tagOptions, col)
+			})
+
+			for _, col := range cols {
+				if col == "" {
+					fields.put(sf.Name, &structField{
+						name:      sf.Name,
+						fold:      foldFunc([]byte(sf.Name)),
+						snake:     SnakeCase(sf.Name),
+						index:     []int{i},
+						typ:       sf.Type,
+						json:      isJSON,
+						encrypted: isEncrypted,
+						sensitive: isSensitive,
+					})
+					continue
+				}
+
 				if strings.Contains(col, ":") {
 					parts := strings.SplitN(col, ":", 2)
 
@@ -156,7 +476,7 @@ func (sc *Scanner) getFields(rv reflect.Value) (*structFields, error) {
 					target := parts[1]
 
 					if target == "" {
-						return nil, &StructFieldError{
+						return &StructFieldError{
 							Tag:    col,
 							Struct: rt.Name(),
 							Field:  sf.Name,
@@ -164,14 +484,24 @@ func (sc *Scanner) getFields(rv reflect.Value) (*structFields, error) {
 						}
 					}
 
-					if sv.IsNil() {
-						continue
+					nt := sf.Type
+
+					if nt.Kind() == reflect.Pointer {
+						nt = nt.Elem()
 					}
 
-					nested, err := sc.getFields(sv)
+					if nt.Kind() != reflect.Struct {
+						return &StructFieldError{
+							Struct: rt.Name(),
+							Field:  sf.Name,
+							Err:    errors.New("target must be struct or pointer to struct"),
+						}
+					}
+
+					nested, err := buildingFields(nt, building)
 
 					if err != nil {
-						return nil, &StructFieldError{
+						return &StructFieldError{
 							Struct: rt.Name(),
 							Field:  sf.Name,
 							Err:    err,
@@ -184,7 +514,7 @@ func (sc *Scanner) getFields(rv reflect.Value) (*structFields, error) {
 						prefix := parts[0]
 
 						if prefix == "" {
-							return nil, &StructFieldError{
+							return &StructFieldError{
 								Tag:    col,
 								Struct: rt.Name(),
 								Field:  sf.Name,
@@ -194,68 +524,273 @@ func (sc *Scanner) getFields(rv reflect.Value) (*structFields, error) {
 
 						if parts[1] == "*" {
 							for _, fld := range nested.arr {
-								fld.name = prefix + "." + fld.name
-								fields.put(fld.name, fld)
+								name := prefix + "." + fld.name
+								fields.put(name, fld.nest(i, name))
 							}
 							continue
 						}
 					}
 
 					if fld, ok := nested.get(target); ok {
-						fields.put(col, fld)
+						fields.put(col, fld.nest(i, fld.name))
+						continue
+					}
+
+					if _, ok := building[nt]; ok && col != "*" {
+						pending = append(pending, pendingTarget{
+							col:    col,
+							index:  i,
+							target: target,
+							nested: nested,
+						})
 						continue
 					}
 
 					if col == "*" && target == "*" {
 						for _, fld := range nested.arr {
-							fields.put(fld.name, fld)
+							fields.put(fld.name, fld.nest(i, fld.name))
 						}
 					}
 					continue
 				}
 
 				fields.put(col, &structField{
-					name: col,
-					fold: foldFunc([]byte(col)),
-					val:  sv,
+					name:      col,
+					fold:      foldFunc([]byte(col)),
+					snake:     SnakeCase(col),
+					index:     []int{i},
+					typ:       sf.Type,
+					tagged:    true,
+					json:      isJSON,
+					encrypted: isEncrypted,
+					sensitive: isSensitive,
 				})
 			}
 			continue
 		}
 
 		fields.put(sf.Name, &structField{
-			name: sf.Name,
-			fold: foldFunc([]byte(sf.Name)),
-			val:  sv,
+			name:  sf.Name,
+			fold:  foldFunc([]byte(sf.Name)),
+			snake: SnakeCase(sf.Name),
+			index: []int{i},
+			typ:   sf.Type,
 		})
 	}
-	return &fields, nil
+
+	for _, i := range nested {
+		sf := rt.Field(i)
+
+		nt := sf.Type
+
+		if nt.Kind() == reflect.Pointer {
+			nt = nt.Elem()
+		}
+
+		nf, err := buildingFields(nt, building)
+
+		if err != nil {
+			return &StructFieldError{
+				Struct: rt.Name(),
+				Field:  sf.Name,
+				Err:    err,
+			}
+		}
+
+		for _, fld := range nf.arr {
+			name := fld.name
+
+			if !sf.Anonymous {
+				name = sf.Name + "." + name
+			}
+			fields.put(name, fld.nest(i, name))
+		}
+	}
+
+	for _, p := range pending {
+		if fld, ok := p.nested.get(p.target); ok {
+			fields.put(p.col, fld.nest(p.index, fld.name))
+		}
+	}
+	return nil
+}
+
+// isNestedStruct reports whether the given struct field's own fields should be
+// mapped. This is true for embedded structs, and pointers to structs, and for
+// plain struct values. Structs that are scanned as a single value, such as
+// [time.Time] or those implementing [sql.Scanner], are excluded.
+func isNestedStruct(sf reflect.StructField) bool {
+	rt := sf.Type
+
+	if sf.Anonymous && rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+
+	if rt.Kind() != reflect.Struct || rt == timeType {
+		return false
+	}
+
+	// Unexported pointers cannot be allocated, and unexported fields cannot
+	// be set unless they are embedded values.
+	if !sf.IsExported() && (!sf.Anonymous || sf.Type.Kind() == reflect.Pointer) {
+		return false
+	}
+
+	pt := reflect.PointerTo(rt)
+
+	if pt.Implements(scannerType) || pt.Implements(textUnmarshalerType) || pt.Implements(binaryUnmarshalerType) {
+		return false
+	}
+	return true
 }
 
+var (
+	scannerType           = reflect.TypeFor[sql.Scanner]()
+	textUnmarshalerType   = reflect.TypeFor[encoding.TextUnmarshaler]()
+	binaryUnmarshalerType = reflect.TypeFor[encoding.BinaryUnmarshaler]()
+)
+
+// ColumnScanError records the column that could not be scanned into a struct,
+// either because it could not be converted to the type of the struct field it
+// maps to, or because it does not map to any field when strict column matching
+// is used.
 type ColumnScanError struct {
-	Table  string
-	Column string
-	Value  string
-	Type   reflect.Type
+	// Table is the table of the [Model] being scanned into, if any.
+	Table string
+
+	// Column is the column that could not be scanned, and Columns is the full
+	// list of columns in the result set.
+	Column  string
+	Columns []string
+
+	// Value is the kind of the column's value, and Type is the type of the
+	// struct field it was being scanned into.
+	Value string
+	Type  reflect.Type
+
+	// Struct and Field are the names of the struct and field being scanned
+	// into. If the column does not map to a field then Field is empty.
 	Struct string
 	Field  string
+
+	// Fields is the list of columns the struct fields map to, and Suggestion
+	// is the one closest to Column, if any.
+	Fields     []string
+	Suggestion string
+
+	// Err is the underlying error that occurred during conversion, if any.
+	Err error
 }
 
-func colScanError(m Model, col string, fld *structField, val reflect.Value) error {
-	rv := reflect.ValueOf(m)
+func (sc *Scanner) colScanError(dest any, col string, fld *structField, fv, val reflect.Value, err error) error {
+	var table string
 
-	return &ColumnScanError{
-		Table:  m.Table(),
-		Column: col,
-		Value:  val.Kind().String(),
-		Type:   fld.val.Type(),
-		Struct: rv.Elem().Type().Name(),
-		Field:  fld.name,
+	if m, ok := dest.(Model); ok {
+		table = m.Table()
+	}
+
+	rv := reflect.ValueOf(dest)
+
+	e := &ColumnScanError{
+		Table:   table,
+		Column:  col,
+		Columns: sc.cols,
+		Struct:  rv.Elem().Type().Name(),
+		Err:     err,
+	}
+
+	if fields, err := sc.getFields(rv); err == nil {
+		e.Fields = make([]string, 0, len(fields.arr))
+
+		for _, f := range fields.arr {
+			e.Fields = append(e.Fields, f.name)
+		}
 	}
+
+	if fld == nil {
+		e.Suggestion = suggest(col, e.Fields)
+		return e
+	}
+
+	e.Field = fld.name
+	e.Type = fv.Type()
+	e.Err = fld.redact(err)
+
+	if val.IsValid() {
+		e.Value = val.Kind().String()
+	}
+	return e
 }
 
 func (e *ColumnScanError) Error() string {
-	return fmt.Sprintf("cannot scan column %s.%s of type %s into Go struct field %s.%s of type %s", e.Table, e.Column, e.Value, e.Struct, e.Field, e.Type)
+	col := e.Column
+
+	if e.Table != "" {
+		col = e.Table + "." + col
+	}
+
+	if e.Field == "" {
+		msg := fmt.Sprintf("no Go struct field in %s for column %s", e.Struct, col)
+
+		if e.Suggestion != "" {
+			msg += fmt.Sprintf(", did you mean %q?", e.Suggestion)
+		}
+		return msg + " (columns: " + strings.Join(e.Columns, ", ") + ")"
+	}
+
+	msg := fmt.Sprintf("cannot scan column %s of type %s into Go struct field %s.%s of type %s", col, e.Value, e.Struct, e.Field, e.Type)
+
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ColumnScanError) Unwrap() error { return e.Err }
+
+// suggest returns the name closest to the given column, as per the edit
+// distance between the two. If no name is close enough, then an empty string
+// is returned.
+func suggest(col string, names []string) string {
+	var (
+		best  string
+		limit = len(col)/3 + 1
+	)
+
+	col = strings.ToLower(col)
+
+	for _, name := range names {
+		if d := editDistance(col, strings.ToLower(name)); d <= limit {
+			best = name
+			limit = d - 1
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between the two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
 }
 
 func (sc *Scanner) toString(src any) string {
@@ -300,22 +835,57 @@ func (sc *Scanner) toString(src any) string {
 // working with embedded structs.
 //
 // `db:"users.*:*"` Maps all columns with the prefix of "users." to the
-// underlying struct, useful for working with related models via joins.
+// underlying struct, useful for working with related models via joins. The
+// prefix need not be a table name, so the same table can be joined multiple
+// times under different aliases, such as `db:"author.*:*"` and
+// `db:"editor.*:*"`.
+//
+// If the Model implements [Relater], then the fields of any BelongsTo and
+// HasOne relations are mapped as if they had the respective struct tags, for
+// example `db:"user_id:id,users.*:*"`. A struct tag on the field takes
+// precedence over the relation.
+//
+// Untagged embedded structs, and pointers to structs, are mapped as if they had
+// the struct tag `db:"*:*"`, with the fields of the outer struct taking
+// precedence. The fields of untagged nested struct values are mapped with the
+// field's name as the prefix, so the column "address.street" would be scanned
+// into the field Address.Street.
+//
+// If no struct tags are specified then a case insensitive comparison is done on
+// the column name and the field name to determine if the column should be
+// scanned into the field. If that fails, then the column name is compared with
+// the field name converted via [SnakeCase], so the column "created_at" would be
+// scanned into the field CreatedAt.
 //
-// If no struct tags are specified then a comparison is done on the column name
-// and the field name to determine if the column should be scanned into the
-// field.
+// Fields that implement [sql.Scanner] are scanned via their Scan method. Fields
+// that implement [encoding.TextUnmarshaler] or [encoding.BinaryUnmarshaler],
+// such as [netip.Addr], are unmarshalled from text and blob columns.
+//
+// [netip.Addr]: https://pkg.go.dev/net/netip#Addr
 func (sc *Scanner) Scan(m Model) error {
-	if scanner, ok := m.(RowScanner); ok {
-		row := Row{
-			scan:    sc.rows.Scan,
-			Columns: sc.cols,
-		}
+	return sc.scan(m)
+}
 
-		if err := scanner.Scan(&row); err != nil {
-			return err
+// ScanStruct scans the current row of data into the given struct, in the same
+// way [Scanner.Scan] does for Models. It is expected for the given value to be
+// a pointer to a struct. This would be used for scanning rows that do not map
+// onto a [Model], such as the results of an aggregate query.
+func (sc *Scanner) ScanStruct(v any) error {
+	return sc.scan(v)
+}
+
+// ScanMap scans the current row of data into a map of column names to values.
+// The values returned by the driver are normalized, so that text columns are
+// returned as a string instead of a []byte, and any []byte values are copied
+// so they remain valid after the next call to Next.
+func (sc *Scanner) ScanMap() (map[string]any, error) {
+	if sc.types == nil {
+		types, err := sc.rows.ColumnTypes()
+
+		if err != nil {
+			return nil, err
 		}
-		return nil
+		sc.types = types
 	}
 
 	sc.dest = sc.dest[0:0]
@@ -325,53 +895,250 @@ func (sc *Scanner) Scan(m Model) error {
 		sc.dest = append(sc.dest, &val)
 	}
 
-	rv := reflect.ValueOf(m)
+	if err := sc.rows.Scan(sc.dest...); err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]any, len(sc.cols))
+
+	for i, col := range sc.cols {
+		m[col] = normalizeValue(sc.types[i], *sc.dest[i].(*any))
+	}
+	return m, nil
+}
+
+// normalizeValue normalizes the given value that was scanned from a column of
+// the given type. A []byte is returned as a string, unless the column is
+// binary.
+func normalizeValue(typ *sql.ColumnType, v any) any {
+	b, ok := v.([]byte)
+
+	if !ok {
+		return v
+	}
+
+	name := strings.ToUpper(typ.DatabaseTypeName())
+
+	if strings.Contains(name, "BLOB") || strings.Contains(name, "BINARY") || name == "BYTEA" {
+		return bytes.Clone(b)
+	}
+	return string(b)
+}
+
+// columnFields returns the struct field for each of the scanner's columns for
+// the type of the given value. If a column does not map to a field then it is
+// nil.
+func (sc *Scanner) columnFields(rv reflect.Value) ([]*structField, error) {
+	if fields, ok := sc.fields[rv.Type()]; ok {
+		return fields, nil
+	}
+
+	sf, err := sc.getFields(rv)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]*structField, len(sc.cols))
+
+	for i, col := range sc.cols {
+		if fld, ok := sc.mapper.lookup(sf, col); ok {
+			fields[i] = fld
+		}
+	}
+
+	if sc.fields == nil {
+		sc.fields = make(map[reflect.Type][]*structField)
+	}
+
+	sc.fields[rv.Type()] = fields
+	return fields, nil
+}
+
+func (sc *Scanner) scan(v any) error {
+	if scanner, ok := v.(RowScanner); ok {
+		row := Row{
+			scan:    sc.scanRow,
+			Columns: sc.cols,
+		}
+
+		if err := scanner.Scan(&row); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
 
 	if rv.Kind() != reflect.Pointer {
-		return errors.New("model must be a pointer")
+		return errors.New("target must be a pointer")
 	}
 
-	fields, err := sc.getFields(rv)
+	fields, err := sc.columnFields(rv)
 
 	if err != nil {
 		return err
 	}
 
-	if err := sc.rows.Scan(sc.dest...); err != nil {
+	root := rv.Elem()
+
+	sc.dest = sc.dest[0:0]
+	scanned := sc.scanned[0:0]
+
+	for _, fld := range fields {
+		if sc.direct && fld != nil && fld.direct() {
+			if field, ok := fld.value(root); ok {
+				sc.dest = append(sc.dest, field.Addr().Interface())
+				scanned = append(scanned, true)
+				continue
+			}
+		}
+
+		var val any
+		sc.dest = append(sc.dest, &val)
+		scanned = append(scanned, false)
+	}
+
+	sc.scanned = scanned
+
+	if err := sc.scanRow(sc.dest...); err != nil {
 		return err
 	}
 
 	for i, col := range sc.cols {
-		fld, ok := fields.get(col)
+		fld := fields[i]
 
-		if !ok {
+		if scanned[i] {
+			continue
+		}
+
+		if fld == nil {
+			if sc.strict {
+				return sc.colScanError(v, col, nil, reflect.Value{}, reflect.Value{}, nil)
+			}
 			continue
 		}
 
 		rv := reflect.ValueOf(sc.dest[i])
 		el := rv.Elem()
 
+		field, ok := fld.value(root)
+
+		if !ok {
+			// Nested structs are only allocated for non-NULL columns, so a
+			// LEFT JOIN with no matching row leaves the pointer as nil.
+			if el.IsNil() {
+				continue
+			}
+			field = fld.alloc(root)
+		}
+
 		if src := el.Interface(); src != nil {
+			if fld.encrypted && !sc.plaintext {
+				b, err := sc.keyring.decrypt(src)
+
+				if err != nil {
+					return &StructFieldError{
+						Tag:    col,
+						Struct: root.Type().Name(),
+						Field:  fld.name,
+						Err:    fld.redact(err),
+					}
+				}
+
+				// Keep the type of the column as scanned, so the plaintext is
+				// converted as the column would have been.
+				if _, ok := src.(string); ok {
+					src = string(b)
+				} else {
+					src = b
+				}
+			}
+
+			if fld.json {
+				if err := unmarshalJSON(src, field); err != nil {
+					return &StructFieldError{
+						Tag:    col,
+						Struct: root.Type().Name(),
+						Field:  fld.name,
+						Err:    fld.redact(err),
+					}
+				}
+				continue
+			}
+
 			val := reflect.ValueOf(src)
 
-			fv := reflect.New(fld.val.Type())
+			fv := reflect.New(field.Type())
 
 			// If the struct field implements sql.Scanner then call scan and
 			// use that value instead of reflect.ValueOf(p).
-			if scanner, ok := fv.Interface().(sql.Scanner); ok {
+			scanner, ok := fv.Interface().(sql.Scanner)
+
+			if ok {
 				if err := scanner.Scan(src); err != nil {
 					return err
 				}
 				val = fv.Elem()
 			}
 
-			switch fld.val.Kind() {
+			// time.Time implements encoding.TextUnmarshaler, but is parsed
+			// separately so the scanner's time layouts are used.
+			if !ok && !isTime(field.Type()) {
+				v, unmarshalled, err := unmarshalValue(field.Type(), src)
+
+				if err != nil {
+					return &StructFieldError{
+						Tag:    col,
+						Struct: root.Type().Name(),
+						Field:  fld.name,
+						Err:    fld.redact(err),
+					}
+				}
+
+				if unmarshalled {
+					field.Set(v)
+					continue
+				}
+			}
+
+			if !ok && isArray(field.Type()) {
+				if err := scanArray(src, field); err != nil {
+					return &StructFieldError{
+						Tag:    col,
+						Struct: root.Type().Name(),
+						Field:  fld.name,
+						Err:    fld.redact(err),
+					}
+				}
+				continue
+			}
+
+			if !ok && isTime(field.Type()) {
+				t, parsed, err := parseTime(src, sc.timeLayouts)
+
+				if err != nil {
+					return &StructFieldError{
+						Tag:    col,
+						Struct: root.Type().Name(),
+						Field:  fld.name,
+						Err:    fld.redact(err),
+					}
+				}
+
+				if parsed {
+					setTime(field, t)
+					continue
+				}
+			}
+
+			switch field.Kind() {
 			case reflect.Pointer:
-				if fld.val.IsNil() && src != nil {
+				if field.IsNil() && src != nil {
 					ptr := reflect.New(val.Type())
 					ptr.Elem().Set(val)
 
-					fld.val.Set(ptr)
+					field.Set(ptr)
 				}
 			case reflect.Bool:
 				var b bool
@@ -384,51 +1151,386 @@ func (sc *Scanner) Scan(m Model) error {
 				default:
 					s := sc.toString(src)
 
-					v, err := strconv.ParseBool(s)
+					parsed, err := strconv.ParseBool(s)
 
 					if err != nil {
-						return fmt.Errorf("cannot parse %T (%q) as bool: %v", src, s, err)
+						return sc.colScanError(v, col, fld, field, val, err)
 					}
-					b = v
+					b = parsed
 				}
-				fld.val.SetBool(b)
+				field.SetBool(b)
 			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 				s := sc.toString(src)
 
-				i64, err := strconv.ParseInt(s, 10, fld.val.Type().Bits())
+				i64, err := strconv.ParseInt(s, 10, field.Type().Bits())
 
 				if err != nil {
-					return fmt.Errorf("cannot parse %T (%q) as int: %v", src, s, err)
+					return sc.colScanError(v, col, fld, field, val, err)
 				}
-				fld.val.SetInt(i64)
+				field.SetInt(i64)
 			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 				s := sc.toString(src)
 
-				u64, err := strconv.ParseUint(s, 10, fld.val.Type().Bits())
+				u64, err := strconv.ParseUint(s, 10, field.Type().Bits())
 
 				if err != nil {
-					return fmt.Errorf("cannot parse %T (%q) as uint: %v", src, s, err)
+					return sc.colScanError(v, col, fld, field, val, err)
 				}
-				fld.val.SetUint(u64)
+				field.SetUint(u64)
 			case reflect.Float32, reflect.Float64:
 				s := sc.toString(src)
 
-				f64, err := strconv.ParseFloat(s, fld.val.Type().Bits())
+				f64, err := strconv.ParseFloat(s, field.Type().Bits())
 
 				if err != nil {
-					return fmt.Errorf("cannot parse %T (%q) as float: %v", src, s, err)
+					return sc.colScanError(v, col, fld, field, val, err)
 				}
-				fld.val.SetFloat(f64)
+				field.SetFloat(f64)
 			default:
-				want := fld.val.Kind()
+				want := field.Kind()
 				got := val.Kind()
 
 				if want != got {
-					return colScanError(m, col, fld, val)
+					return sc.colScanError(v, col, fld, field, val, nil)
 				}
-				fld.val.Set(val)
+				field.Set(val)
 			}
 		}
 	}
 	return nil
 }
+
+func unmarshalJSON(src any, field reflect.Value) error {
+	var b []byte
+
+	switch v := src.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("cannot unmarshal %T as JSON", src)
+	}
+	return json.Unmarshal(b, field.Addr().Interface())
+}
+
+// unmarshalValue returns a value of the given type unmarshalled from the given
+// source value, if the type implements [encoding.TextUnmarshaler] or
+// [encoding.BinaryUnmarshaler], with text unmarshalling being preferred if the
+// type implements both. If the type is a pointer,
+// then the type it points to is checked instead. If the type implements
+// neither interface, then false is returned.
+func unmarshalValue(rt reflect.Type, src any) (reflect.Value, bool, error) {
+	ptr := rt.Kind() == reflect.Pointer
+
+	if ptr {
+		rt = rt.Elem()
+	}
+
+	pv := reflect.New(rt)
+
+	var err error
+
+	switch u := pv.Interface().(type) {
+	case encoding.TextUnmarshaler:
+		switch v := src.(type) {
+		case string:
+			err = u.UnmarshalText([]byte(v))
+		case []byte:
+			err = u.UnmarshalText(v)
+		default:
+			return reflect.Value{}, false, nil
+		}
+	case encoding.BinaryUnmarshaler:
+		switch v := src.(type) {
+		case string:
+			err = u.UnmarshalBinary([]byte(v))
+		case []byte:
+			err = u.UnmarshalBinary(v)
+		default:
+			return reflect.Value{}, false, nil
+		}
+	default:
+		return reflect.Value{}, false, nil
+	}
+
+	if err != nil {
+		return reflect.Value{}, true, err
+	}
+
+	if ptr {
+		return pv, true, nil
+	}
+	return pv.Elem(), true, nil
+}
+
+// paramValue returns the value of the given parameter for the given column of
+// the model. The column is matched to a struct field as it would be when
+// scanned, via the given Mapper. If the field has the "json" option, then the
+// value is marshalled to JSON. If the field has the "encrypted" option, then
+// the value is encrypted via the given Keyring, and if it has the "sensitive"
+// option, then the value is given as a [SensitiveValue]. Slices and arrays are
+// encoded as per [arrayValue] for the given dialect.
+func paramValue(d Dialect, kr *Keyring, mp *Mapper, m Model, col string, v any) (any, error) {
+	// Expressions are built into the query as is.
+	if _, ok := v.(query.Expr); ok {
+		return v, nil
+	}
+
+	var fld *structField
+
+	rt := reflect.TypeOf(m)
+
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+
+	if rt.Kind() == reflect.Struct {
+		fields, err := typeFields(rt)
+
+		if err != nil {
+			return nil, err
+		}
+
+		fld, _ = mp.lookup(fields, col)
+	}
+
+	val, err := fieldValue(d, kr, fld, v)
+
+	if err != nil {
+		return nil, fmt.Errorf("column %s: %w", col, err)
+	}
+
+	if fld != nil && fld.sensitive {
+		return Sensitive(val), nil
+	}
+	return val, nil
+}
+
+// fieldValue encodes the given value for the given struct field, which may be
+// nil if the value does not map to a field.
+func fieldValue(d Dialect, kr *Keyring, fld *structField, v any) (any, error) {
+	if fld != nil && fld.json {
+		b, err := json.Marshal(v)
+
+		if err != nil {
+			return nil, err
+		}
+		v = string(b)
+	}
+
+	if fld != nil && fld.encrypted {
+		return kr.encrypt(v)
+	}
+
+	if fld != nil && fld.json {
+		return v, nil
+	}
+
+	if val, ok, err := arrayValue(d, v); ok || err != nil {
+		return val, err
+	}
+	return v, nil
+}
+
+var errUnknownColumn = errors.New("unknown column")
+
+// setColumn sets the struct field of the given model that maps to the given
+// column, as per [Scanner.Scan] with the given Mapper, to the given value. The
+// value is converted to the type of the field if need be.
+func setColumn(mapper *Mapper, m Model, col string, v any) error {
+	fields, err := (&Scanner{}).getFields(reflect.ValueOf(m))
+
+	if err != nil {
+		return err
+	}
+
+	fld, ok := mapper.lookup(fields, col)
+
+	if !ok {
+		return fmt.Errorf("%w %s", errUnknownColumn, col)
+	}
+
+	field := fld.alloc(reflect.ValueOf(m).Elem())
+
+	val := reflect.ValueOf(v)
+
+	if !val.IsValid() {
+		field.SetZero()
+		return nil
+	}
+
+	if val.Type().AssignableTo(field.Type()) {
+		field.Set(val)
+		return nil
+	}
+
+	if val.Type().ConvertibleTo(field.Type()) {
+		field.Set(val.Convert(field.Type()))
+		return nil
+	}
+	return fmt.Errorf("cannot set column %s of type %T into field %s of type %s", col, v, fld.name, field.Type())
+}
+
+// copyColumns copies the fields of the given columns from the src model to the
+// dst model, both of which are expected to be of the same type. The columns are
+// mapped to fields with the given Mapper, and those that do not map to a field
+// are skipped.
+func copyColumns(mapper *Mapper, dst, src Model, cols []string) error {
+	fields, err := (&Scanner{}).getFields(reflect.ValueOf(src))
+
+	if err != nil {
+		return err
+	}
+
+	for _, col := range cols {
+		fld, ok := mapper.lookup(fields, col)
+
+		if !ok {
+			continue
+		}
+		fld.alloc(reflect.ValueOf(dst).Elem()).Set(fld.alloc(reflect.ValueOf(src).Elem()))
+	}
+	return nil
+}
+
+// rowTo scans the current row into a value of type T. If T is a pointer, such
+// as a pointer to a [Model], then a new value is allocated for it to be scanned
+// into.
+func rowTo[T any](sc *Scanner) (T, error) {
+	var t T
+
+	rv := reflect.ValueOf(&t).Elem()
+
+	if rv.Kind() == reflect.Pointer {
+		rv.Set(reflect.New(rv.Type().Elem()))
+
+		if err := sc.scan(t); err != nil {
+			var zero T
+			return zero, err
+		}
+		return t, nil
+	}
+
+	if err := sc.scan(&t); err != nil {
+		var zero T
+		return zero, err
+	}
+	return t, nil
+}
+
+// All runs the given query against the database and scans each row into a
+// value of type T via the [Scanner]. The query is built with the placeholders
+// of the given [Dialect]. T can either be a struct, or a pointer to a struct
+// such as a [Model], in which case a new value is allocated for each row. This
+// allows for models to be queried without a [Store], for example,
+//
+//	q := query.Select(
+//	    query.Columns("*"),
+//	    query.From("posts"),
+//	    query.WhereEq("user_id", query.Arg(u.ID)),
+//	)
+//
+//	pp, err := database.All[*Post](ctx, db, database.Postgres, q)
+//
+// The struct need not be a Model, and is mapped using the same "db" struct
+// tags. This is useful for queries whose results do not map onto a Model, such
+// as reports or aggregates, for example,
+//
+//	type FileStats struct {
+//	    Count int64
+//	    Total int64
+//	}
+//
+//	q := query.Select(
+//	    query.Exprs(
+//	        query.As(query.Count("*"), "count"),
+//	        query.As(query.Sum(query.Ident("size")), "total"),
+//	    ),
+//	    query.From("files"),
+//	)
+//
+//	stats, err := database.All[FileStats](ctx, db, database.Postgres, q)
+func All[T any](ctx context.Context, db DB, d Dialect, q *query.Query) ([]T, error) {
+	rows, err := queryRows(ctx, db, d, q)
+
+	if err != nil {
+		return nil, err
+	}
+	return CollectRows(rows, rowTo[T])
+}
+
+// One runs the given query against the database and scans the first row into
+// a value of type T, as per [All]. The returned bool reports whether a row was
+// found, mirroring [Store.Get].
+func One[T any](ctx context.Context, db DB, d Dialect, q *query.Query) (T, bool, error) {
+	rows, err := queryRows(ctx, db, d, q)
+
+	if err != nil {
+		var zero T
+		return zero, false, err
+	}
+
+	t, err := CollectOneRow(rows, rowTo[T])
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return t, false, nil
+		}
+		return t, false, err
+	}
+	return t, true, nil
+}
+
+// QueryValue runs the given query against the database and scans the single
+// value it returns into a value of type T. The query is built with the
+// placeholders of the given [Dialect]. This is intended for queries that
+// return a single value, such as COUNT, SUM, or EXISTS, for example,
+//
+//	q := query.Select(query.Count("*"), query.From("files"))
+//
+//	n, err := database.QueryValue[int64](ctx, db, database.Postgres, q)
+//
+// If the query returns no rows then [sql.ErrNoRows] is returned.
+func QueryValue[T any](ctx context.Context, db DB, d Dialect, q *query.Query) (T, error) {
+	var t T
+
+	rows, err := queryRows(ctx, db, d, q)
+
+	if err != nil {
+		return t, err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return t, err
+		}
+		return t, sql.ErrNoRows
+	}
+
+	if err := rows.Scan(&t); err != nil {
+		return t, err
+	}
+	return t, rows.Err()
+}
+
+// SelectMaps runs the given query against the database and scans each row into
+// a map of column names to values via [Scanner.ScanMap]. The query is built
+// with the placeholders of the given [Dialect]. This is useful for ad-hoc
+// queries where the columns being selected are not known ahead of time.
+func SelectMaps(ctx context.Context, db DB, d Dialect, q *query.Query) ([]map[string]any, error) {
+	rows, err := queryRows(ctx, db, d, q)
+
+	if err != nil {
+		return nil, err
+	}
+	return CollectRows(rows, (*Scanner).ScanMap)
+}
+
+// queryRows builds the given query with the placeholders of the given dialect,
+// and runs it against the database.
+func queryRows(ctx context.Context, db DB, d Dialect, q *query.Query) (*sql.Rows, error) {
+	return db.QueryContext(ctx, q.BuildWith(d.placeholder()), q.Args()...)
+}
@@ -1,9 +1,15 @@
 package database
 
 import (
+	"context"
 	"crypto/rand"
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
+	"net/netip"
+	"net/url"
+	"reflect"
 	"testing"
 	"time"
 
@@ -321,3 +327,911 @@ func TestRowScanner(t *testing.T) {
 	}
 	t.Log(n.Data)
 }
+
+func TestAllStruct(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, numberSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", numberSchema, err)
+	}
+
+	store := NewStore[*Number](db, func() *Number {
+		return &Number{}
+	})
+
+	for i := 0; i < 10; i++ {
+		n := Number{
+			I:    I(i % 2),
+			Uint: uint(i),
+		}
+
+		if err := store.Create(ctx, &n); err != nil {
+			t.Fatalf("store.Create(ctx, &n): %v\n", err)
+		}
+	}
+
+	type Stats struct {
+		Count int64
+		Total uint64 `db:"total"`
+	}
+
+	q := query.Select(
+		query.Exprs(
+			query.As(query.Count("*"), "count"),
+			query.As(query.Sum(query.Ident("uint")), "total"),
+		),
+		query.From("numbers"),
+		query.WhereEq("i", query.Arg(1)),
+	)
+
+	stats, err := All[Stats](ctx, db, SQLite, q)
+
+	if err != nil {
+		t.Fatalf("All[Stats](ctx, db, SQLite, q): %v\n", err)
+	}
+
+	if len(stats) != 1 {
+		t.Fatalf("len(stats) = %v, want = %v\n", len(stats), 1)
+	}
+
+	want := Stats{Count: 5, Total: 25}
+
+	if stats[0] != want {
+		t.Fatalf("stats[0] = %v, want = %v\n", stats[0], want)
+	}
+}
+
+func TestAllOne(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, numberSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", numberSchema, err)
+	}
+
+	store := NewStore[*Number](db, func() *Number {
+		return &Number{}
+	})
+
+	for i := 0; i < 4; i++ {
+		n := Number{
+			I:    I(i % 2),
+			Uint: uint(i),
+		}
+
+		if err := store.Create(ctx, &n); err != nil {
+			t.Fatalf("store.Create(ctx, &n): %v\n", err)
+		}
+	}
+
+	q := query.Select(query.Columns("*"), query.From("numbers"), query.WhereEq("i", query.Arg(1)), query.OrderAsc("uint"))
+
+	nn, err := All[*Number](ctx, db, SQLite, q)
+
+	if err != nil {
+		t.Fatalf("All[*Number](ctx, db, SQLite, q): %v\n", err)
+	}
+
+	if len(nn) != 2 {
+		t.Fatalf("len(nn) = %v, want = %v\n", len(nn), 2)
+	}
+
+	for i, want := range []uint{1, 3} {
+		if nn[i].Uint != want {
+			t.Errorf("nn[%d].Uint = %v, want = %v\n", i, nn[i].Uint, want)
+		}
+	}
+
+	type UintRow struct {
+		Uint uint
+	}
+
+	q = query.Select(query.Columns("uint"), query.From("numbers"), query.WhereEq("uint", query.Arg(2)))
+
+	n, ok, err := One[UintRow](ctx, db, SQLite, q)
+
+	if err != nil {
+		t.Fatalf("One[UintRow](ctx, db, SQLite, q): %v\n", err)
+	}
+
+	if !ok {
+		t.Fatalf("ok = %v, want = %v\n", ok, true)
+	}
+
+	if n.Uint != 2 {
+		t.Errorf("n.Uint = %v, want = %v\n", n.Uint, 2)
+	}
+
+	q = query.Select(query.Columns("uint"), query.From("numbers"), query.WhereEq("uint", query.Arg(10)))
+
+	if _, ok, err := One[*Number](ctx, db, SQLite, q); err != nil || ok {
+		t.Fatalf("One[*Number](ctx, db, SQLite, q) = %v, %v, want = %v, %v\n", ok, err, false, nil)
+	}
+}
+
+func TestFieldCache(t *testing.T) {
+	rt := reflect.TypeOf(Post{})
+
+	fields1, err := typeFields(rt)
+
+	if err != nil {
+		t.Fatalf("typeFields(%s): %v\n", rt, err)
+	}
+
+	fields2, err := typeFields(rt)
+
+	if err != nil {
+		t.Fatalf("typeFields(%s): %v\n", rt, err)
+	}
+
+	if fields1 != fields2 {
+		t.Fatalf("typeFields(%s) returned different fields for the same type\n", rt)
+	}
+
+	fld, ok := fields1.get("users.email")
+
+	if !ok {
+		t.Fatalf("fields1.get(%q): expected field, got none\n", "users.email")
+	}
+
+	// Fields nested in a nil pointer cannot be resolved.
+	if _, ok := fld.value(reflect.ValueOf(Post{})); ok {
+		t.Fatalf("fld.value(...): expected no value for nil pointer\n")
+	}
+
+	p := Post{User: &User{Email: "me@example.com"}}
+
+	v, ok := fld.value(reflect.ValueOf(p))
+
+	if !ok {
+		t.Fatalf("fld.value(...): expected value, got none\n")
+	}
+
+	if s := v.String(); s != p.User.Email {
+		t.Fatalf("v.String() = %q, want = %q\n", s, p.User.Email)
+	}
+}
+
+type JSONNotification struct {
+	ID   int64
+	Data map[string]any `db:"data,json"`
+}
+
+func (n *JSONNotification) Table() string { return "notifications" }
+
+func (n *JSONNotification) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{n.ID},
+	}
+}
+
+func (n *JSONNotification) Params() Params {
+	return Params{
+		"id":   CreateOnlyParam(n.ID),
+		"data": MutableParam(n.Data),
+	}
+}
+
+const nodeSchema = `CREATE TABLE IF NOT EXISTS nodes (
+	id        INTEGER NOT NULL,
+	parent_id INTEGER NULL,
+	name      TEXT NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+// TreeNode refers back to itself via its Parent, which is declared before the ID
+// field that the parent_id column maps to.
+type TreeNode struct {
+	Parent *TreeNode `db:"parent_id:id"`
+	ID     int64
+	Name   string
+}
+
+func (n *TreeNode) Table() string { return "nodes" }
+
+func (n *TreeNode) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{n.ID},
+	}
+}
+
+func (n *TreeNode) Params() Params {
+	return Params{
+		"id":   CreateOnlyParam(n.ID),
+		"name": MutableParam(n.Name),
+	}
+}
+
+func TestScanSelfReference(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	stmts := []string{
+		nodeSchema,
+		"INSERT INTO nodes (id, parent_id, name) VALUES (1, NULL, 'root')",
+		"INSERT INTO nodes (id, parent_id, name) VALUES (2, 1, 'child')",
+	}
+
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("db.ExecContext(ctx, %q): %v\n", stmt, err)
+		}
+	}
+
+	store := NewStore(db, func() *TreeNode {
+		return &TreeNode{}
+	})
+
+	nn, err := store.Select(ctx, query.Columns("*"), query.OrderAsc("id"))
+
+	if err != nil {
+		t.Fatalf("store.Select(ctx, query.Columns(%q)): %v\n", "*", err)
+	}
+
+	if len(nn) != 2 {
+		t.Fatalf("len(nn) = %v, want = %v\n", len(nn), 2)
+	}
+
+	if nn[0].Parent != nil {
+		t.Fatalf("nn[0].Parent = %+v, want = %v\n", nn[0].Parent, nil)
+	}
+
+	if nn[1].Parent == nil || nn[1].Parent.ID != 1 {
+		t.Fatalf("nn[1].Parent = %+v, want parent with id %v\n", nn[1].Parent, 1)
+	}
+
+	if nn[1].ID != 2 || nn[1].Name != "child" {
+		t.Fatalf("nn[1] = %+v, want id %v and name %q\n", nn[1], 2, "child")
+	}
+}
+
+// JSONFieldNotification has a JSON field tagged without a column name, so the
+// column is mapped from the field name.
+type JSONFieldNotification struct {
+	ID   int64
+	Data map[string]any `db:",json"`
+}
+
+func (n *JSONFieldNotification) Table() string { return "notifications" }
+
+func (n *JSONFieldNotification) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{n.ID},
+	}
+}
+
+func (n *JSONFieldNotification) Params() Params {
+	return Params{
+		"id":   CreateOnlyParam(n.ID),
+		"data": MutableParam(n.Data),
+	}
+}
+
+func testJSONColumn[M Model](t *testing.T, db *sql.DB, id int64, new func() M, data func(M) *map[string]any) {
+	ctx := t.Context()
+
+	store := NewStore(db, new)
+
+	where := query.WhereEq("id", query.Arg(id))
+
+	get := func(t *testing.T) map[string]any {
+		t.Helper()
+
+		n, ok, err := store.Get(ctx, where)
+
+		if err != nil {
+			t.Fatalf("store.Get(ctx): %v\n", err)
+		}
+
+		if !ok {
+			t.Fatalf("ok = %v, want = %v\n", ok, true)
+		}
+		return *data(n)
+	}
+
+	n := new()
+
+	*data(n) = map[string]any{
+		"field": "value",
+	}
+
+	if err := store.Create(ctx, n); err != nil {
+		t.Fatalf("store.Create(ctx, n): %v\n", err)
+	}
+
+	if v := get(t)["field"]; v != "value" {
+		t.Fatalf("Data[%q] = %v, want = %v\n", "field", v, "value")
+	}
+
+	(*data(n))["field"] = "updated"
+
+	if _, err := store.Update(ctx, n); err != nil {
+		t.Fatalf("store.Update(ctx, n): %v\n", err)
+	}
+
+	if v := get(t)["field"]; v != "updated" {
+		t.Fatalf("Data[%q] = %v, want = %v\n", "field", v, "updated")
+	}
+
+	fields := map[string]any{
+		"data": map[string]any{"field": "many"},
+	}
+
+	if _, err := store.UpdateMany(ctx, fields, where); err != nil {
+		t.Fatalf("store.UpdateMany(ctx, fields, where): %v\n", err)
+	}
+
+	if v := get(t)["field"]; v != "many" {
+		t.Fatalf("Data[%q] = %v, want = %v\n", "field", v, "many")
+	}
+}
+
+func TestJSONTag(t *testing.T) {
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(t.Context(), notificationSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", notificationSchema, err)
+	}
+
+	t.Run("named", func(t *testing.T) {
+		testJSONColumn(t, db, 10, func() *JSONNotification {
+			return &JSONNotification{ID: 10}
+		}, func(n *JSONNotification) *map[string]any {
+			return &n.Data
+		})
+	})
+
+	t.Run("unnamed", func(t *testing.T) {
+		testJSONColumn(t, db, 20, func() *JSONFieldNotification {
+			return &JSONFieldNotification{ID: 20}
+		}, func(n *JSONFieldNotification) *map[string]any {
+			return &n.Data
+		})
+	})
+}
+
+func TestScanNilNested(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, userPostSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", userPostSchema, err)
+	}
+
+	stmts := []string{
+		"INSERT INTO users (id, email) VALUES (1, 'me@example.com')",
+		"INSERT INTO users (id, email) VALUES (2, 'you@example.com')",
+		"INSERT INTO posts (id, user_id, title) VALUES (1, 1, 'Post 1')",
+		"INSERT INTO posts (id, user_id, title) VALUES (2, 2, 'Post 2')",
+	}
+
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("db.ExecContext(ctx, %q): %v\n", stmt, err)
+		}
+	}
+
+	q := `SELECT posts.id, posts.title, users.id AS "users.id", users.email AS "users.email"
+	FROM posts
+	LEFT JOIN users ON users.id = posts.user_id AND users.email = 'me@example.com'
+	ORDER BY posts.id`
+
+	rows, err := db.QueryContext(ctx, q)
+
+	if err != nil {
+		t.Fatalf("db.QueryContext(ctx, %q): %v\n", q, err)
+	}
+
+	defer rows.Close()
+
+	sc, err := NewScanner(rows)
+
+	if err != nil {
+		t.Fatalf("NewScanner(rows): %v\n", err)
+	}
+
+	pp := make([]*Post, 0, 2)
+
+	for rows.Next() {
+		p := &Post{}
+
+		if err := sc.Scan(p); err != nil {
+			t.Fatalf("sc.Scan(p): %v\n", err)
+		}
+		pp = append(pp, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		t.Fatalf("rows.Err(): %v\n", err)
+	}
+
+	if len(pp) != 2 {
+		t.Fatalf("len(pp) = %v, want = %v\n", len(pp), 2)
+	}
+
+	if pp[0].User == nil {
+		t.Fatalf("pp[0].User = %v, want = %v\n", pp[0].User, "non-nil")
+	}
+
+	if pp[0].User.Email != "me@example.com" {
+		t.Fatalf("pp[0].User.Email = %q, want = %q\n", pp[0].User.Email, "me@example.com")
+	}
+
+	// No user matched the LEFT JOIN, so the pointer should remain nil.
+	if pp[1].User != nil {
+		t.Fatalf("pp[1].User = %v, want = %v\n", pp[1].User, nil)
+	}
+}
+
+func TestSelectMaps(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore(db, func() *M {
+		return &M{}
+	})
+
+	m := &M{
+		ID:     1,
+		Str:    "string",
+		BigStr: "bigstring",
+		Int:    10,
+		Bool:   true,
+		Blob:   []byte("blob"),
+		Time:   time.Now(),
+	}
+
+	if err := store.Create(ctx, m); err != nil {
+		t.Fatalf("store.Create(ctx, m): %v\n", err)
+	}
+
+	q := query.Select(query.Columns("id", "str", "int", "blob"), query.From("models"))
+
+	mm, err := SelectMaps(ctx, db, SQLite, q)
+
+	if err != nil {
+		t.Fatalf("SelectMaps(ctx, db, SQLite, q): %v\n", err)
+	}
+
+	if len(mm) != 1 {
+		t.Fatalf("len(mm) = %v, want = %v\n", len(mm), 1)
+	}
+
+	want := map[string]any{
+		"id":   int64(1),
+		"str":  "string",
+		"int":  int64(10),
+		"blob": []byte("blob"),
+	}
+
+	if !reflect.DeepEqual(mm[0], want) {
+		t.Fatalf("mm[0] = %v, want = %v\n", mm[0], want)
+	}
+}
+
+func TestQueryValue(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, numberSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", numberSchema, err)
+	}
+
+	q := query.Select(query.Count("*"), query.From("numbers"))
+
+	n, err := QueryValue[int64](ctx, db, SQLite, q)
+
+	if err != nil {
+		t.Fatalf("QueryValue[int64](ctx, db, SQLite, q): %v\n", err)
+	}
+
+	if n != 0 {
+		t.Fatalf("n = %v, want = %v\n", n, 0)
+	}
+
+	q = query.Select(query.Columns("i"), query.From("numbers"))
+
+	if _, err := QueryValue[int64](ctx, db, SQLite, q); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("QueryValue[int64](ctx, db, SQLite, q) = %v, want = %v\n", err, sql.ErrNoRows)
+	}
+}
+
+type Host struct {
+	Addr    netip.Addr
+	Gateway *netip.Addr
+	URL     *url.URL
+}
+
+const hostSchema = `CREATE TABLE IF NOT EXISTS hosts (
+	addr    TEXT NOT NULL,
+	gateway TEXT NULL,
+	url     TEXT NOT NULL
+);`
+
+func TestUnmarshalerScanning(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, hostSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", hostSchema, err)
+	}
+
+	stmt := "INSERT INTO hosts (addr, gateway, url) VALUES ('192.168.1.10', '192.168.1.1', 'https://example.com/path')"
+
+	if _, err := db.ExecContext(ctx, stmt); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", stmt, err)
+	}
+
+	q := query.Select(query.Columns("*"), query.From("hosts"))
+
+	hh, err := All[Host](ctx, db, SQLite, q)
+
+	if err != nil {
+		t.Fatalf("All[Host](ctx, db, SQLite, q): %v\n", err)
+	}
+
+	if len(hh) != 1 {
+		t.Fatalf("len(hh) = %v, want = %v\n", len(hh), 1)
+	}
+
+	h := hh[0]
+
+	if want := netip.MustParseAddr("192.168.1.10"); h.Addr != want {
+		t.Fatalf("h.Addr = %v, want = %v\n", h.Addr, want)
+	}
+
+	if want := netip.MustParseAddr("192.168.1.1"); h.Gateway == nil || *h.Gateway != want {
+		t.Fatalf("h.Gateway = %v, want = %v\n", h.Gateway, want)
+	}
+
+	if want := "https://example.com/path"; h.URL == nil || h.URL.String() != want {
+		t.Fatalf("h.URL = %v, want = %v\n", h.URL, want)
+	}
+}
+
+func TestColumnScanError(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, numberSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", numberSchema, err)
+	}
+
+	store := NewStore(db, func() *Number {
+		return &Number{}
+	}, WithStrictColumns())
+
+	if err := store.Create(ctx, &Number{I: 1}); err != nil {
+		t.Fatalf("store.Create(ctx, &Number{I: 1}): %v\n", err)
+	}
+
+	_, err := store.Select(ctx, query.Exprs(query.Ident("i"), query.As(query.Ident("uint8"), "unit8")))
+
+	var scanErr *ColumnScanError
+
+	if !errors.As(err, &scanErr) {
+		t.Fatalf("store.Select(...) = %v, want = %T\n", err, scanErr)
+	}
+
+	if scanErr.Column != "unit8" {
+		t.Fatalf("scanErr.Column = %q, want = %q\n", scanErr.Column, "unit8")
+	}
+
+	if scanErr.Suggestion != "Uint8" {
+		t.Fatalf("scanErr.Suggestion = %q, want = %q\n", scanErr.Suggestion, "Uint8")
+	}
+
+	if want := []string{"i", "unit8"}; !reflect.DeepEqual(scanErr.Columns, want) {
+		t.Fatalf("scanErr.Columns = %v, want = %v\n", scanErr.Columns, want)
+	}
+
+	_, err = store.Select(ctx, query.Exprs(query.As(query.Lit("'abc'"), "i")))
+
+	if !errors.As(err, &scanErr) {
+		t.Fatalf("store.Select(...) = %v, want = %T\n", err, scanErr)
+	}
+
+	if scanErr.Field != "I" {
+		t.Fatalf("scanErr.Field = %q, want = %q\n", scanErr.Field, "I")
+	}
+
+	if scanErr.Err == nil {
+		t.Fatalf("scanErr.Err = %v, want non-nil\n", scanErr.Err)
+	}
+}
+
+type Address struct {
+	Street string
+	City   string
+}
+
+type M3 struct {
+	M
+	Address Address
+}
+
+func TestScanEmbedValue(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore(db, func() *M {
+		return &M{}
+	})
+
+	m := &M{
+		ID:   1,
+		Str:  "string",
+		Blob: []byte("blob"),
+		Time: time.Now(),
+	}
+
+	if err := store.Create(ctx, m); err != nil {
+		t.Fatalf("store.Create(ctx, m): %v\n", err)
+	}
+
+	q := query.Select(
+		query.Exprs(
+			query.Ident("id"),
+			query.Ident("str"),
+			query.As(query.Lit("'1 Main Street'"), "address.street"),
+			query.As(query.Lit("'Springfield'"), "address.city"),
+		),
+		query.From("models"),
+	)
+
+	mm, err := All[M3](ctx, db, SQLite, q)
+
+	if err != nil {
+		t.Fatalf("All[M3](ctx, db, SQLite, q): %v\n", err)
+	}
+
+	if len(mm) != 1 {
+		t.Fatalf("len(mm) = %v, want = %v\n", len(mm), 1)
+	}
+
+	m3 := mm[0]
+
+	if m3.ID != m.ID {
+		t.Fatalf("m3.ID = %v, want = %v\n", m3.ID, m.ID)
+	}
+
+	if m3.Str != m.Str {
+		t.Fatalf("m3.Str = %v, want = %v\n", m3.Str, m.Str)
+	}
+
+	want := Address{Street: "1 Main Street", City: "Springfield"}
+
+	if m3.Address != want {
+		t.Fatalf("m3.Address = %v, want = %v\n", m3.Address, want)
+	}
+}
+
+func TestDirectScan(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore(db, func() *M {
+		return &M{}
+	}, WithDirectScan())
+
+	want := &M{
+		ID:     1,
+		Str:    "string",
+		BigStr: "bigstring",
+		Int:    10,
+		BigInt: 1 << 40,
+		Bool:   true,
+		Blob:   []byte("blob"),
+		Time:   time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+	}
+
+	if err := store.Create(ctx, want); err != nil {
+		t.Fatalf("store.Create(ctx, want): %v\n", err)
+	}
+
+	m, ok, err := store.Get(ctx)
+
+	if err != nil {
+		t.Fatalf("store.Get(ctx): %v\n", err)
+	}
+
+	if !ok {
+		t.Fatalf("ok = %v, want = %v\n", ok, true)
+	}
+
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("m = %v, want = %v\n", m, want)
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	ctx := b.Context()
+	db := NewDB(b)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		b.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore(db, func() *M {
+		return &M{}
+	})
+
+	mm := make([]*M, 0, 100)
+
+	for i := range cap(mm) {
+		mm = append(mm, &M{
+			ID:     int64(i),
+			Str:    "string",
+			BigStr: "bigstring",
+			Int:    i,
+			BigInt: int64(i) << 32,
+			Blob:   []byte("blob"),
+			Time:   time.Now(),
+		})
+	}
+
+	if err := store.Create(ctx, mm...); err != nil {
+		b.Fatalf("store.Create(ctx, mm...): %v\n", err)
+	}
+
+	b.Run("Default", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := store.Select(ctx, query.Columns("*")); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	direct := NewStore(db, func() *M {
+		return &M{}
+	}, WithDirectScan())
+
+	b.Run("Direct", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := direct.Select(ctx, query.Columns("*")); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+type Article struct {
+	ID     int64
+	Title  string
+	Author *User `db:"author_id:id,author.*:*"`
+	Editor *User `db:"editor_id:id,editor.*:*"`
+}
+
+const articleSchema = `CREATE TABLE IF NOT EXISTS articles (
+	id        INTEGER NOT NULL,
+	author_id INTEGER NOT NULL,
+	editor_id INTEGER NOT NULL,
+	title     TEXT NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+func TestScanAliasPrefix(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	for _, schema := range []string{userPostSchema, articleSchema} {
+		if _, err := db.ExecContext(ctx, schema); err != nil {
+			t.Fatalf("db.ExecContext(ctx, %q): %v\n", schema, err)
+		}
+	}
+
+	stmts := []string{
+		"INSERT INTO users (id, email) VALUES (1, 'author@example.com'), (2, 'editor@example.com')",
+		"INSERT INTO articles (id, author_id, editor_id, title) VALUES (1, 1, 2, 'Article')",
+	}
+
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("db.ExecContext(ctx, %q): %v\n", stmt, err)
+		}
+	}
+
+	q := `SELECT articles.id, articles.title,
+		author.id AS "author.id", author.email AS "author.email",
+		editor.id AS "editor.id", editor.email AS "editor.email"
+	FROM articles
+	JOIN users author ON author.id = articles.author_id
+	JOIN users editor ON editor.id = articles.editor_id`
+
+	rows, err := db.QueryContext(ctx, q)
+
+	if err != nil {
+		t.Fatalf("db.QueryContext(ctx, %q): %v\n", q, err)
+	}
+
+	a, err := CollectOneRow(rows, RowToStruct[Article])
+
+	if err != nil {
+		t.Fatalf("CollectOneRow(rows, RowToStruct[Article]): %v\n", err)
+	}
+
+	if a.Author == nil || a.Author.Email != "author@example.com" {
+		t.Fatalf("a.Author = %v, want = %v\n", a.Author, "author@example.com")
+	}
+
+	if a.Editor == nil || a.Editor.Email != "editor@example.com" {
+		t.Fatalf("a.Editor = %v, want = %v\n", a.Editor, "editor@example.com")
+	}
+}
+
+// queryRecorder records the queries run against it, failing each of them.
+type queryRecorder struct {
+	execRecorder
+}
+
+var errRecorded = errors.New("recorded")
+
+func (r *queryRecorder) QueryContext(_ context.Context, q string, args ...any) (*sql.Rows, error) {
+	r.queries = append(r.queries, q)
+	r.args = append(r.args, args)
+	return nil, errRecorded
+}
+
+func TestQueryDialect(t *testing.T) {
+	ctx := t.Context()
+
+	q := query.Select(
+		query.Columns("*"),
+		query.From("posts"),
+		query.WhereEq("user_id", query.Arg(1)),
+	)
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{Postgres, "SELECT * FROM posts WHERE (user_id = $1)"},
+		{MySQL, "SELECT * FROM posts WHERE (user_id = ?)"},
+	}
+
+	for _, test := range tests {
+		var rec queryRecorder
+
+		if _, err := All[*Number](ctx, &rec, test.dialect, q); !errors.Is(err, errRecorded) {
+			t.Fatalf("%s: All[*Number](ctx, &rec, q): %v, want = %v\n", test.dialect, err, errRecorded)
+		}
+
+		if _, _, err := One[*Number](ctx, &rec, test.dialect, q); !errors.Is(err, errRecorded) {
+			t.Fatalf("%s: One[*Number](ctx, &rec, q): %v, want = %v\n", test.dialect, err, errRecorded)
+		}
+
+		if _, err := QueryValue[int64](ctx, &rec, test.dialect, q); !errors.Is(err, errRecorded) {
+			t.Fatalf("%s: QueryValue[int64](ctx, &rec, q): %v, want = %v\n", test.dialect, err, errRecorded)
+		}
+
+		if _, err := SelectMaps(ctx, &rec, test.dialect, q); !errors.Is(err, errRecorded) {
+			t.Fatalf("%s: SelectMaps(ctx, &rec, q): %v, want = %v\n", test.dialect, err, errRecorded)
+		}
+
+		if len(rec.queries) != 4 {
+			t.Fatalf("%s: len(rec.queries) = %v, want = %v\n", test.dialect, len(rec.queries), 4)
+		}
+
+		for i, got := range rec.queries {
+			if got != test.want {
+				t.Fatalf("%s: rec.queries[%d] = %q, want = %q\n", test.dialect, i, got, test.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"slices"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// ScopeFunc returns the query option that scopes the queries of a [Store]
+// given the context of the query, such as a WHERE clause on the tenant or
+// user the query is being made for. If nil is returned, then the query is left
+// unscoped.
+type ScopeFunc func(ctx context.Context) query.Option
+
+// Scope returns a copy of the store that applies the query option returned
+// from the given function to every query that reads, updates, or deletes
+// models. This is the building block for rules such as multi-tenancy, where
+// every query should be scoped to the tenant in the context, for example,
+//
+//	posts = posts.Scope(func(ctx context.Context) query.Option {
+//	    return query.WhereEq("org_id", query.Arg(OrgID(ctx)))
+//	})
+//
+// The returned option may only add WHERE clauses. These are ANDed with the
+// WHERE clauses of each query as a whole via [query.Restrict], so a query
+// cannot escape its scope with an OR clause. Scopes are not applied when
+// creating models, since there are no rows to scope, so the scoped columns
+// should be set on the models being created. The scopes of a store can be
+// removed via [Store.Unscoped].
+func (s *Store[M]) Scope(fn ScopeFunc) *Store[M] {
+	s2 := *s
+	s2.scopes = append(slices.Clip(s.scopes), fn)
+	return &s2
+}
+
+// Unscoped returns a copy of the store without any of the scopes given via
+// [Store.Scope]. This would be used for operations that need to see every row,
+// such as administrative tasks.
+func (s *Store[M]) Unscoped() *Store[M] {
+	s2 := *s
+	s2.scopes = nil
+	return &s2
+}
+
+// scoped returns the given query options followed by the options of the
+// store's scopes for the given context. The scopes are applied via
+// [query.Restrict], so they are ANDed with the WHERE clauses of the query as a
+// whole.
+func (s *Store[M]) scoped(ctx context.Context, opts []query.Option) []query.Option {
+	if len(s.scopes) == 0 {
+		return opts
+	}
+
+	scopes := make([]query.Option, 0, len(s.scopes))
+
+	for _, fn := range s.scopes {
+		if opt := fn(ctx); opt != nil {
+			scopes = append(scopes, opt)
+		}
+	}
+
+	if len(scopes) == 0 {
+		return opts
+	}
+	return append(slices.Clip(opts), query.Restrict(scopes...))
+}
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andrewpillar/database/query"
+)
+
+const documentSchema = `CREATE TABLE IF NOT EXISTS documents (
+	id     INTEGER NOT NULL,
+	org_id INTEGER NOT NULL,
+	title  TEXT NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+type Document struct {
+	ID    int64
+	OrgID int64
+	Title string
+}
+
+func (d *Document) Table() string { return "documents" }
+
+func (d *Document) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{d.ID},
+	}
+}
+
+func (d *Document) Params() Params {
+	return Params{
+		"id":     CreateOnlyParam(d.ID),
+		"org_id": CreateOnlyParam(d.OrgID),
+		"title":  MutableParam(d.Title),
+	}
+}
+
+type orgKey struct{}
+
+func TestStoreScope(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, documentSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", documentSchema, err)
+	}
+
+	unscoped := NewStore(db, func() *Document {
+		return &Document{}
+	})
+
+	dd := []*Document{
+		{ID: 1, OrgID: 1, Title: "plan"},
+		{ID: 2, OrgID: 1, Title: "budget"},
+		{ID: 3, OrgID: 2, Title: "plan"},
+	}
+
+	if err := unscoped.Create(ctx, dd...); err != nil {
+		t.Fatalf("unscoped.Create(ctx, dd...): %v\n", err)
+	}
+
+	store := unscoped.Scope(func(ctx context.Context) query.Option {
+		org, ok := ctx.Value(orgKey{}).(int64)
+
+		if !ok {
+			return nil
+		}
+		return query.WhereEq("org_id", query.Arg(org))
+	})
+
+	orgctx := context.WithValue(ctx, orgKey{}, int64(1))
+
+	t.Run("select", func(t *testing.T) {
+		tests := []struct {
+			opts []query.Option
+			want int
+		}{
+			{nil, 2},
+			{[]query.Option{query.WhereEq("title", query.Arg("plan"))}, 1},
+			{[]query.Option{
+				query.WhereEq("title", query.Arg("plan")),
+				query.OrWhereEq("title", query.Arg("budget")),
+			}, 2},
+		}
+
+		for i, test := range tests {
+			mm, err := store.Select(orgctx, query.Columns("*"), test.opts...)
+
+			if err != nil {
+				t.Fatalf("tests[%d] - store.Select(orgctx, ...): %v\n", i, err)
+			}
+
+			if len(mm) != test.want {
+				t.Fatalf("tests[%d] - len(mm) = %v, want = %v\n", i, len(mm), test.want)
+			}
+
+			for _, m := range mm {
+				if m.OrgID != 1 {
+					t.Fatalf("tests[%d] - m.OrgID = %v, want = %v\n", i, m.OrgID, 1)
+				}
+			}
+		}
+
+		// No scope is applied if the context has no org.
+		n, err := store.Count(ctx)
+
+		if err != nil {
+			t.Fatalf("store.Count(ctx): %v\n", err)
+		}
+
+		if n != 3 {
+			t.Fatalf("store.Count(ctx) = %v, want = %v\n", n, 3)
+		}
+
+		if _, ok, err := store.Get(orgctx, query.WhereEq("id", query.Arg(3))); err != nil || ok {
+			t.Fatalf("store.Get(orgctx, ...) = %v, %v, want = false, nil\n", ok, err)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		res, err := store.UpdateMany(orgctx, map[string]any{"title": "draft"}, query.WhereEq("title", query.Arg("plan")))
+
+		if err != nil {
+			t.Fatalf("store.UpdateMany(orgctx, ...): %v\n", err)
+		}
+
+		if n, _ := res.RowsAffected(); n != 1 {
+			t.Fatalf("res.RowsAffected() = %v, want = %v\n", n, 1)
+		}
+
+		d := &Document{ID: 3, OrgID: 2, Title: "stolen"}
+
+		res, err = store.Update(orgctx, d)
+
+		if err != nil {
+			t.Fatalf("store.Update(orgctx, d): %v\n", err)
+		}
+
+		if n, _ := res.RowsAffected(); n != 0 {
+			t.Fatalf("res.RowsAffected() = %v, want = %v\n", n, 0)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		res, err := store.Delete(orgctx, dd...)
+
+		if err != nil {
+			t.Fatalf("store.Delete(orgctx, dd...): %v\n", err)
+		}
+
+		if n, _ := res.RowsAffected(); n != 2 {
+			t.Fatalf("res.RowsAffected() = %v, want = %v\n", n, 2)
+		}
+
+		if err := store.Truncate(orgctx, false); err == nil {
+			t.Fatal("expected error truncating scoped store, got nil")
+		}
+
+		mm, err := store.Unscoped().SelectAll(orgctx)
+
+		if err != nil {
+			t.Fatalf("store.Unscoped().SelectAll(orgctx): %v\n", err)
+		}
+
+		if len(mm) != 1 || mm[0].ID != 3 || mm[0].Title != "plan" {
+			t.Fatalf("mm = %v, want = [%v]\n", mm, dd[2])
+		}
+	})
+}
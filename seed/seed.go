@@ -0,0 +1,186 @@
+// Package seed provides named seeds for bootstrapping the data of a database,
+// such as default users or lookup tables. Seeds are added to a [Seeder] in the
+// order they should be run, and are run via [Seeder.Run]. The seeds that have
+// been run are tracked in a schema_seeds table, so each seed is only ever run
+// once, regardless of how many times the application is started.
+//
+// Each seed is run within its own transaction, alongside the recording of the
+// seed in the tracking table. If two instances of an application run the same
+// seed at the same time, then the primary key of the tracking table means only
+// one of them can record it, and the transaction of the other is rolled back.
+package seed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/andrewpillar/database"
+)
+
+// Func is the function of a seed, which creates the data of the seed via the
+// given transaction.
+type Func func(ctx context.Context, tx *sql.Tx) error
+
+// Seed is a single named seed.
+type Seed struct {
+	Name string
+	Func Func
+}
+
+// DB is the interface that wraps the methods used for running seeds. This is
+// satisfied by [sql.DB] and [sql.Conn].
+type DB interface {
+	database.DB
+	database.Beginner
+}
+
+// Table is the name of the table used for tracking the seeds that have run.
+const Table = "schema_seeds"
+
+const tableSchema = `CREATE TABLE IF NOT EXISTS ` + Table + ` (
+	name   VARCHAR(255) NOT NULL,
+	ran_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (name)
+);`
+
+// record is the row of a seed that has run in the tracking table.
+type record struct {
+	Name  string
+	RanAt time.Time
+}
+
+func (r *record) Table() string { return Table }
+
+func (r *record) PrimaryKey() *database.PrimaryKey {
+	return &database.PrimaryKey{
+		Columns: []string{"name"},
+		Values:  []any{r.Name},
+	}
+}
+
+func (r *record) Params() database.Params {
+	return database.Params{
+		"name":   database.CreateOnlyParam(r.Name),
+		"ran_at": database.CreateOnlyParam(r.RanAt),
+	}
+}
+
+func newRecordStore(db database.DB) *database.Store[*record] {
+	return database.NewStore(db, func() *record {
+		return &record{}
+	})
+}
+
+// Option is a function that configures a [Seeder] when it is created via
+// [New].
+type Option func(*Seeder)
+
+// WithClock configures the [database.Clock] used for timestamping when each
+// seed was run.
+func WithClock(c database.Clock) Option {
+	return func(s *Seeder) {
+		s.clock = c
+	}
+}
+
+// Seeder holds the seeds that are run against a database, in the order they
+// were added.
+type Seeder struct {
+	seeds []*Seed
+	clock database.Clock
+}
+
+// New returns a new [Seeder] configured with the given options.
+func New(opts ...Option) *Seeder {
+	s := &Seeder{
+		clock: database.SystemClock,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Add adds a seed with the given name and function to the seeder, for
+// example,
+//
+//	seeder.Add("default_users", func(ctx context.Context, tx *sql.Tx) error {
+//	    return users.CreateTx(ctx, tx, DefaultUsers...)
+//	})
+//
+// The name of a seed is what is recorded in the tracking table once it has
+// run, so it should not be changed once the seed has been deployed. This will
+// panic if a seed with the same name has already been added.
+func (s *Seeder) Add(name string, fn Func) {
+	for _, sd := range s.seeds {
+		if sd.Name == name {
+			panic("seed: duplicate seed " + name)
+		}
+	}
+
+	s.seeds = append(s.seeds, &Seed{
+		Name: name,
+		Func: fn,
+	})
+}
+
+// ran returns the names of the seeds that have run. The tracking table is
+// created if it does not exist.
+func ran(ctx context.Context, db DB) (map[string]struct{}, error) {
+	if _, err := db.ExecContext(ctx, tableSchema); err != nil {
+		return nil, err
+	}
+
+	rr, err := newRecordStore(db).SelectAll(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{}, len(rr))
+
+	for _, r := range rr {
+		names[r.Name] = struct{}{}
+	}
+	return names, nil
+}
+
+// Run runs every seed that has not yet been run, in the order they were added.
+// The seeds that were run are returned. If a seed fails, then the seeds run
+// before it remain run, and are returned along with the error.
+func (s *Seeder) Run(ctx context.Context, db DB) ([]*Seed, error) {
+	done, err := ran(ctx, db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	run := make([]*Seed, 0, len(s.seeds))
+
+	for _, sd := range s.seeds {
+		if _, ok := done[sd.Name]; ok {
+			continue
+		}
+
+		err := database.Tx(ctx, db, func(tx *sql.Tx) error {
+			if err := sd.Func(ctx, tx); err != nil {
+				return err
+			}
+
+			r := &record{
+				Name:  sd.Name,
+				RanAt: s.clock.Now().UTC(),
+			}
+			return newRecordStore(tx).Create(ctx, r)
+		})
+
+		if err != nil {
+			return run, fmt.Errorf("seed %s: %w", sd.Name, err)
+		}
+		run = append(run, sd)
+	}
+	return run, nil
+}
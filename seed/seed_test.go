@@ -0,0 +1,149 @@
+package seed
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database"
+
+	_ "modernc.org/sqlite"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "db.sqlite")
+
+	db, err := sql.Open("sqlite", dsn)
+
+	if err != nil {
+		t.Fatalf("sql.Open(%q, %q): %v\n", "sqlite", dsn, err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+	return db
+}
+
+func insertUser(username string) Func {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "INSERT INTO users (username) VALUES ($1)", username)
+		return err
+	}
+}
+
+func countUsers(t *testing.T, db *sql.DB) int {
+	t.Helper()
+
+	var n int
+
+	if err := db.QueryRowContext(t.Context(), "SELECT COUNT(*) FROM users").Scan(&n); err != nil {
+		t.Fatalf("db.QueryRowContext(ctx, ...).Scan(&n): %v\n", err)
+	}
+	return n
+}
+
+func TestSeederRun(t *testing.T) {
+	ctx := t.Context()
+	db := openDB(t)
+
+	if _, err := db.ExecContext(ctx, "CREATE TABLE users (username VARCHAR UNIQUE NOT NULL)"); err != nil {
+		t.Fatalf("db.ExecContext(ctx, ...): %v\n", err)
+	}
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	s := New(WithClock(database.NewFrozenClock(now)))
+	s.Add("brian", insertUser("brian"))
+	s.Add("rob", insertUser("rob"))
+
+	run, err := s.Run(ctx, db)
+
+	if err != nil {
+		t.Fatalf("s.Run(ctx, db): %v\n", err)
+	}
+
+	if len(run) != 2 {
+		t.Fatalf("len(run) = %v, want = %v\n", len(run), 2)
+	}
+
+	// Seeds that have already run are skipped, so only the new seed is run.
+	s.Add("ken", insertUser("ken"))
+
+	run, err = s.Run(ctx, db)
+
+	if err != nil {
+		t.Fatalf("s.Run(ctx, db): %v\n", err)
+	}
+
+	if len(run) != 1 || run[0].Name != "ken" {
+		t.Fatalf("run = %v, want = %v\n", run, "[ken]")
+	}
+
+	if n := countUsers(t, db); n != 3 {
+		t.Fatalf("countUsers(t, db) = %v, want = %v\n", n, 3)
+	}
+
+	var ranAt time.Time
+
+	if err := db.QueryRowContext(ctx, "SELECT ran_at FROM "+Table+" WHERE name = 'ken'").Scan(&ranAt); err != nil {
+		t.Fatalf("db.QueryRowContext(ctx, ...).Scan(&ranAt): %v\n", err)
+	}
+
+	if !ranAt.Equal(now) {
+		t.Fatalf("ranAt = %v, want = %v\n", ranAt, now)
+	}
+}
+
+func TestSeederRunError(t *testing.T) {
+	ctx := t.Context()
+	db := openDB(t)
+
+	if _, err := db.ExecContext(ctx, "CREATE TABLE users (username VARCHAR UNIQUE NOT NULL)"); err != nil {
+		t.Fatalf("db.ExecContext(ctx, ...): %v\n", err)
+	}
+
+	errSeed := errors.New("seed failed")
+
+	s := New()
+	s.Add("brian", insertUser("brian"))
+	s.Add("rob", func(ctx context.Context, tx *sql.Tx) error {
+		if err := insertUser("rob")(ctx, tx); err != nil {
+			return err
+		}
+		return errSeed
+	})
+	s.Add("ken", insertUser("ken"))
+
+	run, err := s.Run(ctx, db)
+
+	if !errors.Is(err, errSeed) {
+		t.Fatalf("s.Run(ctx, db): %v, want = %v\n", err, errSeed)
+	}
+
+	if len(run) != 1 || run[0].Name != "brian" {
+		t.Fatalf("run = %v, want = %v\n", run, "[brian]")
+	}
+
+	// The failed seed is rolled back, and the seeds after it are not run.
+	if n := countUsers(t, db); n != 1 {
+		t.Fatalf("countUsers(t, db) = %v, want = %v\n", n, 1)
+	}
+}
+
+func TestSeederAddDuplicate(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected panic for duplicate seed, got nil")
+		}
+	}()
+
+	s := New()
+	s.Add("brian", insertUser("brian"))
+	s.Add("brian", insertUser("brian"))
+}
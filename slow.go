@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// WithSlowQuery configures the duration after which a query run by a [Store]
+// is considered slow. Slow queries are flagged on the [QueryEvent] given to the
+// store's [Logger]. If explain is true, then the plan of the slow query is
+// captured and set on the event too.
+//
+// The plan is captured by running the query again with the EXPLAIN statement
+// appropriate for the store's [Dialect]. For Postgres and MySQL, SELECT
+// queries are explained with EXPLAIN ANALYZE, so that the plan includes the
+// actual timings of the query. Queries that modify data are never analyzed,
+// since this would result in the query being performed again. The plan is
+// captured once the rows of the query are closed, on the same database,
+// transaction, or replica that ran the query.
+func WithSlowQuery(threshold time.Duration, explain bool) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.slowThreshold = threshold
+		cfg.explainSlow = explain
+	}
+}
+
+func (s *Store[M]) isSlow(d time.Duration) bool {
+	return s.cfg.slowThreshold > 0 && d >= s.cfg.slowThreshold
+}
+
+// explain returns the plan for the given query on the given DB, which should be
+// the DB that ran the query. Each row returned from the EXPLAIN statement is
+// written on its own line, with the columns of the row separated by tabs.
+func (s *Store[M]) explain(ctx context.Context, db DB, op Op, q *query.Query) (string, error) {
+	stmt := "EXPLAIN "
+
+	switch s.cfg.dialect {
+	case SQLite:
+		stmt = "EXPLAIN QUERY PLAN "
+	case Postgres, MySQL:
+		if op == OpSelect {
+			stmt = "EXPLAIN ANALYZE "
+		}
+	}
+
+	rows, err := db.QueryContext(ctx, stmt+s.build(q), q.Args()...)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+
+	vals := make([]any, len(cols))
+	dest := make([]any, len(cols))
+
+	for i := range vals {
+		dest[i] = &vals[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+
+		for i, v := range vals {
+			if i > 0 {
+				buf.WriteByte('\t')
+			}
+
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			fmt.Fprint(&buf, v)
+		}
+		buf.WriteByte('\n')
+	}
+
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
@@ -0,0 +1,214 @@
+package database
+
+import (
+	"cmp"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// statsSamples is the number of the most recent latencies kept for each query,
+// from which the percentiles of a [QueryStat] are calculated.
+const statsSamples = 1024
+
+// QueryStat is the statistics of a single shape of query, as collected by
+// [QueryStats].
+//
+// Fingerprint is the fingerprint of the query, as per [query.Fingerprint].
+//
+// Query is the SQL of the first query run with the fingerprint, with
+// placeholders in place of its arguments.
+//
+// Rows is the number of rows returned by the query, or the number of rows
+// affected if the query is a write. For a select, only the rows of the models
+// selected by a [Store] are counted.
+//
+// P50, P95, and P99 are the percentiles of the latency of the query, as
+// calculated from its most recent runs.
+type QueryStat struct {
+	Fingerprint string
+	Query       string
+	Table       string
+	Op          Op
+	Count       int64
+	Errors      int64
+	Rows        int64
+	Total       time.Duration
+	Max         time.Duration
+	P50         time.Duration
+	P95         time.Duration
+	P99         time.Duration
+}
+
+// Mean returns the mean latency of the query.
+func (s QueryStat) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// queryStat is the running statistics of a query.
+type queryStat struct {
+	QueryStat
+
+	// samples is a ring buffer of the most recent latencies of the query,
+	// with next being the index the next latency is written to.
+	samples []time.Duration
+	next    int
+}
+
+func (s *queryStat) observe(d time.Duration, err error) {
+	s.Count++
+	s.Total += d
+	s.Max = max(s.Max, d)
+
+	if err != nil {
+		s.Errors++
+	}
+
+	if len(s.samples) < statsSamples {
+		s.samples = append(s.samples, d)
+		return
+	}
+
+	s.samples[s.next] = d
+	s.next = (s.next + 1) % statsSamples
+}
+
+// percentile returns the latency at the given percentile of the given sorted
+// latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(float64(len(sorted)-1)*p)]
+}
+
+func (s *queryStat) snapshot() QueryStat {
+	sorted := slices.Clone(s.samples)
+	slices.Sort(sorted)
+
+	st := s.QueryStat
+	st.P50 = percentile(sorted, 0.50)
+	st.P95 = percentile(sorted, 0.95)
+	st.P99 = percentile(sorted, 0.99)
+	return st
+}
+
+// QueryStats collects the statistics of the queries run by a [Store], keyed by
+// the fingerprint of each query, so that hot and slow shapes of query can be
+// identified without any external tooling. A QueryStats is safe for
+// concurrent use, and can be shared between multiple stores via
+// [WithQueryStats].
+//
+// QueryStats implements [http.Handler], serving the statistics as JSON, and
+// can be published to [expvar] via [QueryStats.Publish].
+type QueryStats struct {
+	mu    sync.Mutex
+	stats map[string]*queryStat
+}
+
+// NewQueryStats returns a new [QueryStats] for collecting the statistics of
+// queries.
+func NewQueryStats() *QueryStats {
+	return &QueryStats{
+		stats: make(map[string]*queryStat),
+	}
+}
+
+// WithQueryStats configures a [Store] to collect the statistics of the
+// queries it runs to the given [QueryStats].
+func WithQueryStats(qs *QueryStats) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.stats = qs
+	}
+}
+
+// get returns the statistics of the given query, creating them if they do not
+// exist. This expects the lock to be held.
+func (qs *QueryStats) get(table string, op Op, q *query.Query) *queryStat {
+	fp := query.Fingerprint(q)
+
+	st, ok := qs.stats[fp]
+
+	if !ok {
+		st = &queryStat{
+			QueryStat: QueryStat{
+				Fingerprint: fp,
+				Query:       q.Build(),
+				Table:       table,
+				Op:          op,
+			},
+		}
+		qs.stats[fp] = st
+	}
+	return st
+}
+
+func (qs *QueryStats) observe(table string, op Op, q *query.Query, d time.Duration, err error) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	qs.get(table, op, q).observe(d, err)
+}
+
+func (qs *QueryStats) addRows(table string, op Op, q *query.Query, n int64) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	qs.get(table, op, q).Rows += n
+}
+
+// Stats returns the statistics of each query, ordered by the total time spent
+// running the query, so the most costly queries come first.
+func (qs *QueryStats) Stats() []QueryStat {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	stats := make([]QueryStat, 0, len(qs.stats))
+
+	for _, st := range qs.stats {
+		stats = append(stats, st.snapshot())
+	}
+
+	slices.SortFunc(stats, func(a, b QueryStat) int {
+		if c := cmp.Compare(b.Total, a.Total); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Fingerprint, b.Fingerprint)
+	})
+	return stats
+}
+
+// Reset discards the statistics collected so far.
+func (qs *QueryStats) Reset() {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+
+	clear(qs.stats)
+}
+
+// ServeHTTP serves the statistics returned by [QueryStats.Stats] as JSON, for
+// example,
+//
+//	http.Handle("GET /debug/queries", stats)
+func (qs *QueryStats) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(qs.Stats())
+}
+
+// Publish publishes the statistics returned by [QueryStats.Stats] to [expvar]
+// under the given name, so they are served alongside any other variables at
+// /debug/vars. As per [expvar.Publish], this will panic if the name is already
+// in use.
+func (qs *QueryStats) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return qs.Stats()
+	}))
+}
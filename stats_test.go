@@ -0,0 +1,143 @@
+package database
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andrewpillar/database/query"
+)
+
+func TestQueryStats(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	qs := NewQueryStats()
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	}, WithQueryStats(qs))
+
+	for i := 1; i <= 3; i++ {
+		m := &M{
+			ID:   int64(i),
+			Blob: []byte{},
+			Time: time.Now(),
+		}
+
+		if err := store.Create(ctx, m); err != nil {
+			t.Fatalf("store.Create(ctx, m): %v\n", err)
+		}
+	}
+
+	// The same shape of query with different arguments shares a fingerprint.
+	for i := 1; i <= 3; i++ {
+		if _, err := store.Select(ctx, query.Columns("*"), query.Where(query.Lt(query.Ident("id"), query.Arg(i)))); err != nil {
+			t.Fatalf("store.Select(ctx, ...): %v\n", err)
+		}
+	}
+
+	stats := qs.Stats()
+
+	if len(stats) != 2 {
+		t.Fatalf("len(stats) = %v, want = %v\n", len(stats), 2)
+	}
+
+	want := map[Op]QueryStat{
+		OpCreate: {Count: 3, Rows: 3},
+		OpSelect: {Count: 3, Rows: 0 + 1 + 2},
+	}
+
+	for _, st := range stats {
+		w, ok := want[st.Op]
+
+		if !ok {
+			t.Fatalf("unexpected stats for op %s\n", st.Op)
+		}
+
+		if st.Table != "models" {
+			t.Errorf("%s: st.Table = %q, want = %q\n", st.Op, st.Table, "models")
+		}
+
+		if st.Count != w.Count {
+			t.Errorf("%s: st.Count = %v, want = %v\n", st.Op, st.Count, w.Count)
+		}
+
+		if st.Rows != w.Rows {
+			t.Errorf("%s: st.Rows = %v, want = %v\n", st.Op, st.Rows, w.Rows)
+		}
+
+		if st.P50 > st.P99 || st.P99 > st.Max {
+			t.Errorf("%s: st.P50 = %v, st.P99 = %v, st.Max = %v, want ordered\n", st.Op, st.P50, st.P99, st.Max)
+		}
+
+		if st.Mean() <= 0 {
+			t.Errorf("%s: st.Mean() = %v, want > 0\n", st.Op, st.Mean())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	qs.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/queries", nil))
+
+	var served []QueryStat
+
+	if err := json.NewDecoder(rec.Body).Decode(&served); err != nil {
+		t.Fatalf("json.NewDecoder(rec.Body).Decode(&served): %v\n", err)
+	}
+
+	if len(served) != len(stats) {
+		t.Fatalf("len(served) = %v, want = %v\n", len(served), len(stats))
+	}
+
+	qs.Reset()
+
+	if stats := qs.Stats(); len(stats) != 0 {
+		t.Fatalf("len(stats) = %v, want = %v\n", len(stats), 0)
+	}
+
+	// The length of an IN list does not change the fingerprint of a query.
+	for _, ids := range [][]any{{1}, {1, 2}, {1, 2, 3}} {
+		if _, err := store.Select(ctx, query.Columns("*"), query.Where(query.In(query.Ident("id"), query.List(ids...)))); err != nil {
+			t.Fatalf("store.Select(ctx, ...): %v\n", err)
+		}
+	}
+
+	stats = qs.Stats()
+
+	if len(stats) != 1 {
+		t.Fatalf("len(stats) = %v, want = %v\n", len(stats), 1)
+	}
+
+	if stats[0].Rows != 1+2+3 {
+		t.Fatalf("stats[0].Rows = %v, want = %v\n", stats[0].Rows, 1+2+3)
+	}
+}
+
+func TestQueryStatsPercentiles(t *testing.T) {
+	var st queryStat
+
+	for i := 1; i <= statsSamples+100; i++ {
+		st.observe(time.Duration(i)*time.Millisecond, nil)
+	}
+
+	if len(st.samples) != statsSamples {
+		t.Fatalf("len(st.samples) = %v, want = %v\n", len(st.samples), statsSamples)
+	}
+
+	snap := st.snapshot()
+
+	// The oldest 100 samples were overwritten, so the smallest sample left is
+	// 101ms.
+	if want := time.Duration(101+statsSamples/2-1) * time.Millisecond; snap.P50 < want-time.Millisecond || snap.P50 > want+time.Millisecond {
+		t.Errorf("snap.P50 = %v, want = %v\n", snap.P50, want)
+	}
+
+	if want := time.Duration(statsSamples+100) * time.Millisecond; snap.Max != want {
+		t.Errorf("snap.Max = %v, want = %v\n", snap.Max, want)
+	}
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeLayouts are the layouts used for parsing timestamps that are
+// returned from the database as text into [time.Time] fields. These are tried
+// in order until one succeeds.
+var DefaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// isTime reports whether the given type is a time.Time, or a pointer to one.
+func isTime(rt reflect.Type) bool {
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	return rt == timeType
+}
+
+// UseTimeLayouts configures the layouts a [Scanner] uses for parsing
+// timestamps returned as text. This replaces the [DefaultTimeLayouts].
+func UseTimeLayouts(layouts ...string) ScannerOption {
+	return func(sc *Scanner) {
+		sc.timeLayouts = layouts
+	}
+}
+
+// WithTimeLayouts configures the layouts a [Store] uses for parsing timestamps
+// returned as text, as per [UseTimeLayouts].
+func WithTimeLayouts(layouts ...string) StoreOption {
+	return func(cfg *storeConfig) {
+		cfg.timeLayouts = layouts
+	}
+}
+
+// parseTime parses the given source value as a timestamp. Strings are parsed
+// using the given layouts, and integers are treated as seconds since the Unix
+// epoch. If the source value is not a string or integer, then false is
+// returned.
+func parseTime(src any, layouts []string) (time.Time, bool, error) {
+	var s string
+
+	switch v := src.(type) {
+	case int64:
+		return time.Unix(v, 0), true, nil
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return time.Time{}, false, nil
+	}
+
+	s = strings.TrimSpace(s)
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true, nil
+		}
+	}
+
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(i, 0), true, nil
+	}
+	return time.Time{}, true, fmt.Errorf("cannot parse %q as time", s)
+}
+
+// setTime sets the given time.Time, or *time.Time field to the given time.
+func setTime(field reflect.Value, t time.Time) {
+	if field.Kind() == reflect.Pointer {
+		field.Set(reflect.ValueOf(&t))
+		return
+	}
+	field.Set(reflect.ValueOf(t))
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+type Event struct {
+	ID      int64
+	At      time.Time
+	Expires *time.Time
+}
+
+func (e *Event) Table() string { return "events" }
+
+func (e *Event) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{e.ID},
+	}
+}
+
+func (e *Event) Params() Params {
+	return Params{
+		"id":      CreateOnlyParam(e.ID),
+		"at":      CreateOnlyParam(e.At),
+		"expires": CreateOnlyParam(e.Expires),
+	}
+}
+
+// The columns are deliberately declared as TEXT and INTEGER so that the driver
+// returns them as is, instead of as a time.Time.
+const eventSchema = `CREATE TABLE IF NOT EXISTS events (
+	id      INTEGER NOT NULL,
+	at      TEXT NOT NULL,
+	expires INTEGER NULL,
+	PRIMARY KEY (id)
+);`
+
+func TestTimeParsing(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, eventSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", eventSchema, err)
+	}
+
+	stmts := []string{
+		"INSERT INTO events (id, at, expires) VALUES (1, '2024-03-01T10:30:00Z', 1709289000)",
+		"INSERT INTO events (id, at, expires) VALUES (2, '2024-03-01 10:30:00', NULL)",
+		"INSERT INTO events (id, at, expires) VALUES (3, '2024-03-01', NULL)",
+	}
+
+	for _, stmt := range stmts {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("db.ExecContext(ctx, %q): %v\n", stmt, err)
+		}
+	}
+
+	store := NewStore(db, func() *Event {
+		return &Event{}
+	})
+
+	ee, err := store.Select(ctx, Columns(&Event{}))
+
+	if err != nil {
+		t.Fatalf("store.Select(ctx, Columns(&Event{})): %v\n", err)
+	}
+
+	want := []time.Time{
+		time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC),
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	if len(ee) != len(want) {
+		t.Fatalf("len(ee) = %v, want = %v\n", len(ee), len(want))
+	}
+
+	for i, e := range ee {
+		if !e.At.Equal(want[i]) {
+			t.Fatalf("ee[%d].At = %v, want = %v\n", i, e.At, want[i])
+		}
+	}
+
+	if ee[0].Expires == nil || !ee[0].Expires.Equal(want[0]) {
+		t.Fatalf("ee[0].Expires = %v, want = %v\n", ee[0].Expires, want[0])
+	}
+
+	if ee[1].Expires != nil {
+		t.Fatalf("ee[1].Expires = %v, want = %v\n", ee[1].Expires, nil)
+	}
+}
+
+func TestTimeLayouts(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, eventSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", eventSchema, err)
+	}
+
+	stmt := "INSERT INTO events (id, at) VALUES (1, '01/03/2024')"
+
+	if _, err := db.ExecContext(ctx, stmt); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", stmt, err)
+	}
+
+	store := NewStore(db, func() *Event {
+		return &Event{}
+	})
+
+	if _, _, err := store.Get(ctx); err == nil {
+		t.Fatalf("store.Get(ctx): expected error, got nil\n")
+	}
+
+	store = NewStore(db, func() *Event {
+		return &Event{}
+	}, WithTimeLayouts("02/01/2006"))
+
+	e, _, err := store.Get(ctx)
+
+	if err != nil {
+		t.Fatalf("store.Get(ctx): %v\n", err)
+	}
+
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC); !e.At.Equal(want) {
+		t.Fatalf("e.At = %v, want = %v\n", e.At, want)
+	}
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+
+	"github.com/andrewpillar/database/query"
+)
+
+// treeTable is the name of the common table expression used for querying
+// trees of models.
+const treeTable = "tree"
+
+// tree returns the models in the recursive query seeded by the given query,
+// and recursing via the given JOIN expression between the store's table and
+// the tree.
+func (s *Store[M]) tree(ctx context.Context, seed *query.Query, join query.Expr, opts ...query.Option) ([]M, error) {
+	cte := query.Union(
+		seed,
+		query.Select(
+			query.Columns(s.table+".*"),
+			query.From(s.table),
+			query.Join(treeTable, join),
+		),
+	)
+
+	opts = append([]query.Option{
+		query.From(treeTable),
+		query.WithRecursive(treeTable, cte),
+	}, s.scoped(ctx, opts)...)
+
+	q := query.Select(query.Columns("*"), opts...)
+
+	mm := make([]M, 0)
+
+	for m, err := range s.all(ctx, q) {
+		if err != nil {
+			return nil, err
+		}
+		mm = append(mm, m)
+	}
+	return mm, nil
+}
+
+// Descendants returns every model beneath the given model in a tree, where
+// each model refers to its parent via the given column, for example threaded
+// comments with a parent_id column. The descendants are queried via a
+// recursive common table expression, so the tree is fetched in a single
+// query. The given query options are applied to the selection from the tree,
+// for example,
+//
+//	cc, err := comments.Descendants(ctx, c, "parent_id", query.OrderAsc("created_at"))
+//
+// The models can be assembled into a tree via [BuildTree]. Trees are only
+// supported for models with a single column primary key.
+func (s *Store[M]) Descendants(ctx context.Context, m M, parentCol string, opts ...query.Option) ([]M, error) {
+	pk := m.PrimaryKey()
+
+	seed := query.Select(
+		query.Columns("*"),
+		query.From(s.table),
+		query.WhereEq(parentCol, query.Arg(pk.Values[0])),
+	)
+
+	join := query.Eq(
+		query.Ident(s.table+"."+parentCol),
+		query.Ident(treeTable+"."+pk.Columns[0]),
+	)
+	return s.tree(ctx, seed, join, opts...)
+}
+
+// Ancestors returns every model above the given model in a tree, as per
+// [Store.Descendants], starting with its parent and ending with the root of the
+// tree, unless the given query options order them otherwise.
+func (s *Store[M]) Ancestors(ctx context.Context, m M, parentCol string, opts ...query.Option) ([]M, error) {
+	pk := m.PrimaryKey()
+	col := pk.Columns[0]
+
+	seed := query.Select(
+		query.Columns("*"),
+		query.From(s.table),
+		query.WhereIn(col, query.Select(
+			query.Columns(parentCol),
+			query.From(s.table),
+			query.WhereEq(col, query.Arg(pk.Values[0])),
+		)),
+	)
+
+	join := query.Eq(
+		query.Ident(s.table+"."+col),
+		query.Ident(treeTable+"."+parentCol),
+	)
+	return s.tree(ctx, seed, join, opts...)
+}
+
+// Node is a node in a tree of models built via [BuildTree].
+type Node[M Model] struct {
+	Model    M
+	Children []*Node[M]
+}
+
+// BuildTree assembles the given models into a tree, where each model refers to
+// its parent via the given column. The roots of the tree are the models whose
+// parent is not within the given models, so the descendants of a model, as
+// returned from [Store.Descendants], would be assembled under their common
+// parent. The order of the models is preserved among siblings, for example,
+//
+//	cc, err := comments.Descendants(ctx, c, "parent_id", query.OrderAsc("created_at"))
+//
+//	if err != nil {
+//	    // Handle error.
+//	}
+//
+//	thread, err := database.BuildTree(cc, "parent_id")
+//
+// An error is returned if a model does not have a param for the given
+// column.
+func BuildTree[M Model](mm []M, parentCol string) ([]*Node[M], error) {
+	nodes := make(map[string]*Node[M], len(mm))
+	parents := make([]string, 0, len(mm))
+
+	for _, m := range mm {
+		nodes[keyOf(m.PrimaryKey().Values)] = &Node[M]{
+			Model: m,
+		}
+
+		vals, err := paramValues(m, []string{parentCol})
+
+		if err != nil {
+			return nil, err
+		}
+		parents = append(parents, keyOf(vals))
+	}
+
+	roots := make([]*Node[M], 0)
+
+	for i, m := range mm {
+		n := nodes[keyOf(m.PrimaryKey().Values)]
+
+		parent, ok := nodes[parents[i]]
+
+		if !ok || parent == n {
+			roots = append(roots, n)
+			continue
+		}
+		parent.Children = append(parent.Children, n)
+	}
+	return roots, nil
+}
+
+// Walk calls the given function for the node, and each of its descendants,
+// depth first, along with the depth of each node, the node itself being at
+// depth 0. Walking stops if the function returns an error, and that error is
+// returned.
+func (n *Node[M]) Walk(fn func(n *Node[M], depth int) error) error {
+	return n.walk(fn, 0)
+}
+
+func (n *Node[M]) walk(fn func(n *Node[M], depth int) error, depth int) error {
+	if err := fn(n, depth); err != nil {
+		return err
+	}
+
+	for _, c := range n.Children {
+		if err := c.walk(fn, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
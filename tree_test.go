@@ -0,0 +1,132 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/andrewpillar/database/query"
+)
+
+const categorySchema = `CREATE TABLE IF NOT EXISTS categories (
+	id        INTEGER NOT NULL,
+	parent_id INTEGER NULL,
+	name      TEXT NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+type Category struct {
+	ID       int64
+	ParentID Null[int64]
+	Name     string
+}
+
+func (c *Category) Table() string { return "categories" }
+
+func (c *Category) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{c.ID},
+	}
+}
+
+func (c *Category) Params() Params {
+	return Params{
+		"id":        CreateOnlyParam(c.ID),
+		"parent_id": MutableParam(c.ParentID),
+		"name":      MutableParam(c.Name),
+	}
+}
+
+func TestStoreTree(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, categorySchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", categorySchema, err)
+	}
+
+	store := NewStore(db, func() *Category {
+		return &Category{}
+	})
+
+	parent := func(id int64) Null[int64] {
+		var n Null[int64]
+		n.V = id
+		n.Valid = true
+		return n
+	}
+
+	// electronics
+	// ├── computers
+	// │   ├── laptops
+	// │   └── desktops
+	// └── phones
+	// books
+	cc := []*Category{
+		{ID: 1, Name: "electronics"},
+		{ID: 2, ParentID: parent(1), Name: "computers"},
+		{ID: 3, ParentID: parent(2), Name: "laptops"},
+		{ID: 4, ParentID: parent(2), Name: "desktops"},
+		{ID: 5, ParentID: parent(1), Name: "phones"},
+		{ID: 6, Name: "books"},
+	}
+
+	if err := store.Create(ctx, cc...); err != nil {
+		t.Fatalf("store.Create(ctx, cc...): %v\n", err)
+	}
+
+	names := func(cc []*Category) []string {
+		ss := make([]string, 0, len(cc))
+
+		for _, c := range cc {
+			ss = append(ss, c.Name)
+		}
+		return ss
+	}
+
+	desc, err := store.Descendants(ctx, cc[0], "parent_id", query.OrderAsc("id"))
+
+	if err != nil {
+		t.Fatalf("store.Descendants(ctx, cc[0], %q): %v\n", "parent_id", err)
+	}
+
+	if want := []string{"computers", "laptops", "desktops", "phones"}; !slices.Equal(names(desc), want) {
+		t.Fatalf("store.Descendants(ctx, cc[0], %q) = %v, want = %v\n", "parent_id", names(desc), want)
+	}
+
+	anc, err := store.Ancestors(ctx, cc[3], "parent_id")
+
+	if err != nil {
+		t.Fatalf("store.Ancestors(ctx, cc[3], %q): %v\n", "parent_id", err)
+	}
+
+	if want := []string{"computers", "electronics"}; !slices.Equal(names(anc), want) {
+		t.Fatalf("store.Ancestors(ctx, cc[3], %q) = %v, want = %v\n", "parent_id", names(anc), want)
+	}
+
+	if anc, _ := store.Ancestors(ctx, cc[5], "parent_id"); len(anc) != 0 {
+		t.Fatalf("store.Ancestors(ctx, cc[5], %q) = %v, want = %v\n", "parent_id", names(anc), []string{})
+	}
+
+	roots, err := BuildTree(desc, "parent_id")
+
+	if err != nil {
+		t.Fatalf("BuildTree(desc, %q): %v\n", "parent_id", err)
+	}
+
+	if len(roots) != 2 {
+		t.Fatalf("len(roots) = %v, want = %v\n", len(roots), 2)
+	}
+
+	var walked []string
+
+	roots[0].Walk(func(n *Node[*Category], depth int) error {
+		walked = append(walked, fmt.Sprintf("%d:%s", depth, n.Model.Name))
+		return nil
+	})
+
+	if want := []string{"0:computers", "1:laptops", "1:desktops"}; !slices.Equal(walked, want) {
+		t.Fatalf("walked = %v, want = %v\n", walked, want)
+	}
+}
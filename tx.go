@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"sync/atomic"
+)
+
+// Beginner is the interface that wraps the BeginTx method. This is satisfied by
+// [sql.DB] and [sql.Conn].
+type Beginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Tx begins a new transaction on the given database and passes it to fn. If fn
+// returns nil then the transaction is committed, otherwise it is rolled back
+// and the error returned by fn is returned. If fn panics then the transaction
+// is rolled back before the panic is propagated.
+//
+//	err := database.Tx(ctx, db, func(tx *sql.Tx) error {
+//	    if err := posts.With(tx).Create(ctx, p); err != nil {
+//	        return err
+//	    }
+//	    return tags.With(tx).Create(ctx, tt...)
+//	})
+func Tx(ctx context.Context, db Beginner, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	// Changes published, and caches invalidated, by stores operating on the
+	// transaction are held until it is committed.
+	changes := &pendingChanges{}
+
+	txChanges.Store(tx, changes)
+	defer txChanges.Delete(tx)
+
+	defer func() {
+		if v := recover(); v != nil {
+			tx.Rollback()
+			panic(v)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rberr := tx.Rollback(); rberr != nil {
+			return errors.Join(err, rberr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	changes.publish()
+	return nil
+}
+
+// savepoints is used for giving each savepoint a unique name.
+var savepoints atomic.Uint64
+
+// Savepoint calls fn within a SAVEPOINT on the given transaction. If fn returns
+// nil then the savepoint is released, otherwise the transaction is rolled back
+// to the savepoint, undoing only what was done by fn, and the error returned
+// by fn is returned. If fn panics then the transaction is rolled back to the
+// savepoint before the panic is propagated. The transaction itself is left for
+// the caller to commit or roll back, for example,
+//
+//	err := database.Tx(ctx, db, func(tx *sql.Tx) error {
+//	    if err := orders.With(tx).Create(ctx, o); err != nil {
+//	        return err
+//	    }
+//
+//	    // Failing to award points should not fail the order.
+//	    err := database.Savepoint(ctx, tx, func(tx *sql.Tx) error {
+//	        return points.With(tx).Create(ctx, p)
+//	    })
+//
+//	    if err != nil {
+//	        log.Println("failed to award points", err)
+//	    }
+//	    return nil
+//	})
+//
+// Changes published by stores within the savepoint are discarded if it is
+// rolled back.
+func Savepoint(ctx context.Context, tx *sql.Tx, fn func(tx *sql.Tx) error) error {
+	name := "sp_" + strconv.FormatUint(savepoints.Add(1), 10)
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	var (
+		changes *pendingChanges
+		n       int
+	)
+
+	if v, ok := txChanges.Load(tx); ok {
+		changes = v.(*pendingChanges)
+		n = changes.len()
+	}
+
+	rollback := func() error {
+		if changes != nil {
+			changes.truncate(n)
+		}
+
+		_, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+		return err
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			rollback()
+			panic(v)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rberr := rollback(); rberr != nil {
+			return errors.Join(err, rberr)
+		}
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
+
+// WithTx calls fn with a copy of the store that is bound to a transaction, as
+// per [Tx]. If the store is already operating on a [sql.Tx], then fn is called
+// within a [Savepoint] on that transaction, so an error returned from fn only
+// rolls back what was done by fn, and the transaction is left for the caller
+// to commit or roll back. This allows units of work that use WithTx to be
+// composed within a larger transaction.
+//
+// WithTx returns an error if the store's [DB] cannot begin a transaction.
+func (s *Store[M]) WithTx(ctx context.Context, fn func(s *Store[M]) error) error {
+	if tx, ok := s.DB.(*sql.Tx); ok {
+		return Savepoint(ctx, tx, func(*sql.Tx) error {
+			return fn(s)
+		})
+	}
+
+	db, ok := s.DB.(Beginner)
+
+	if !ok {
+		return errors.New("store cannot begin transaction")
+	}
+
+	return Tx(ctx, db, func(tx *sql.Tx) error {
+		return fn(s.With(tx))
+	})
+}
+
+// atomic calls fn within a transaction via [Store.WithTx] if the store's [DB]
+// is able to begin one, otherwise fn is called with the store as is.
+func (s *Store[M]) atomic(ctx context.Context, fn func(s *Store[M]) error) error {
+	if _, ok := s.DB.(Beginner); ok {
+		return s.WithTx(ctx, fn)
+	}
+	return fn(s)
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTx(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	})
+
+	newModel := func(id int64) *M {
+		return &M{
+			ID:     id,
+			Str:    "string",
+			BigStr: "bigstring",
+			Blob:   []byte{},
+			Time:   time.Now(),
+		}
+	}
+
+	m1 := newModel(1)
+
+	err := Tx(ctx, db, func(tx *sql.Tx) error {
+		return store.With(tx).Create(ctx, m1)
+	})
+
+	if err != nil {
+		t.Fatalf("Tx(ctx, db, fn): %v\n", err)
+	}
+
+	if _, ok, err := store.Get(ctx, m1.PrimaryKey().Where()); err != nil || !ok {
+		t.Fatalf("store.Get(ctx, m1.PrimaryKey().Where()) = %v, %v, want = %v, %v\n", ok, err, true, nil)
+	}
+
+	m2 := newModel(2)
+	errRollback := errors.New("rollback")
+
+	err = Tx(ctx, db, func(tx *sql.Tx) error {
+		if err := store.With(tx).Create(ctx, m2); err != nil {
+			return err
+		}
+		return errRollback
+	})
+
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Tx(ctx, db, fn) = %v, want = %v\n", err, errRollback)
+	}
+
+	if _, ok, err := store.Get(ctx, m2.PrimaryKey().Where()); err != nil || ok {
+		t.Fatalf("store.Get(ctx, m2.PrimaryKey().Where()) = %v, %v, want = %v, %v\n", ok, err, false, nil)
+	}
+
+	func() {
+		defer func() {
+			if v := recover(); v == nil {
+				t.Fatal("expected panic to be propagated")
+			}
+		}()
+
+		Tx(ctx, db, func(tx *sql.Tx) error {
+			if err := store.With(tx).Create(ctx, m2); err != nil {
+				return err
+			}
+			panic("panic")
+		})
+	}()
+
+	if _, ok, err := store.Get(ctx, m2.PrimaryKey().Where()); err != nil || ok {
+		t.Fatalf("store.Get(ctx, m2.PrimaryKey().Where()) = %v, %v, want = %v, %v\n", ok, err, false, nil)
+	}
+
+	err = store.WithTx(ctx, func(s *Store[*M]) error {
+		return s.Create(ctx, m2)
+	})
+
+	if err != nil {
+		t.Fatalf("store.WithTx(ctx, fn): %v\n", err)
+	}
+
+	if _, ok, err := store.Get(ctx, m2.PrimaryKey().Where()); err != nil || !ok {
+		t.Fatalf("store.Get(ctx, m2.PrimaryKey().Where()) = %v, %v, want = %v, %v\n", ok, err, true, nil)
+	}
+}
+
+func TestSavepoint(t *testing.T) {
+	ctx := t.Context()
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(ctx, modelSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", modelSchema, err)
+	}
+
+	feed := NewChangeFeed()
+
+	var changes []*Change
+
+	feed.Subscribe(func(_ context.Context, c *Change) {
+		changes = append(changes, c)
+	})
+
+	store := NewStore[*M](db, func() *M {
+		return &M{}
+	}, WithChangeFeed(feed))
+
+	newModel := func(id int64) *M {
+		return &M{
+			ID:     id,
+			Str:    "string",
+			BigStr: "bigstring",
+			Blob:   []byte{},
+			Time:   time.Now(),
+		}
+	}
+
+	m1 := newModel(1)
+	m2 := newModel(2)
+	m3 := newModel(3)
+
+	errRollback := errors.New("rollback")
+
+	err := store.WithTx(ctx, func(s *Store[*M]) error {
+		if err := s.Create(ctx, m1); err != nil {
+			return err
+		}
+
+		err := s.WithTx(ctx, func(s *Store[*M]) error {
+			if err := s.Create(ctx, m2); err != nil {
+				return err
+			}
+			return errRollback
+		})
+
+		if !errors.Is(err, errRollback) {
+			t.Fatalf("s.WithTx(ctx, fn) = %v, want = %v\n", err, errRollback)
+		}
+
+		return s.WithTx(ctx, func(s *Store[*M]) error {
+			return s.Create(ctx, m3)
+		})
+	})
+
+	if err != nil {
+		t.Fatalf("store.WithTx(ctx, fn): %v\n", err)
+	}
+
+	for _, test := range []struct {
+		m    *M
+		want bool
+	}{
+		{m1, true},
+		{m2, false},
+		{m3, true},
+	} {
+		if _, ok, err := store.Get(ctx, test.m.PrimaryKey().Where()); err != nil || ok != test.want {
+			t.Fatalf("store.Get(ctx, %v) = %v, %v, want = %v, %v\n", test.m.ID, ok, err, test.want, nil)
+		}
+	}
+
+	if len(changes) != 2 {
+		t.Fatalf("len(changes) = %v, want = %v\n", len(changes), 2)
+	}
+
+	for i, id := range []int64{1, 3} {
+		if got := changes[i].PrimaryKey.Values[0]; got != id {
+			t.Errorf("changes[%d].PrimaryKey = %v, want = %v\n", i, got, id)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// Validator is the interface that wraps the Validate method. A [Model] that
+// implements Validator is validated before it is created or updated by a
+// [Store], so an invalid model cannot be persisted by a code path that forgot
+// to validate it, for example,
+//
+//	func (p *Post) Validate(ctx context.Context) error {
+//	    if p.Title == "" {
+//	        return errors.New("missing title")
+//	    }
+//	    return nil
+//	}
+type Validator interface {
+	Validate(ctx context.Context) error
+}
+
+// ValidationError records the [Model] that failed validation, and the error
+// returned from its Validate method.
+type ValidationError struct {
+	Table string
+	Model Model
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Table, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// validate calls Validate on each of the given models that implement
+// [Validator], returning a [ValidationError] for the first model that fails.
+func validate[M Model](ctx context.Context, mm ...M) error {
+	for _, m := range mm {
+		v, ok := any(m).(Validator)
+
+		if !ok {
+			continue
+		}
+
+		if err := v.Validate(ctx); err != nil {
+			return &ValidationError{
+				Table: m.Table(),
+				Model: m,
+				Err:   err,
+			}
+		}
+	}
+	return nil
+}
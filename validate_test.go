@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var errInvalidEmail = errors.New("invalid email")
+
+type Account struct {
+	ID    int64
+	Email string
+}
+
+func (a *Account) Table() string { return "accounts" }
+
+func (a *Account) PrimaryKey() *PrimaryKey {
+	return &PrimaryKey{
+		Columns: []string{"id"},
+		Values:  []any{a.ID},
+	}
+}
+
+func (a *Account) Params() Params {
+	return Params{
+		"id":    CreateOnlyParam(a.ID),
+		"email": MutableParam(a.Email),
+	}
+}
+
+func (a *Account) Validate(ctx context.Context) error {
+	if !strings.Contains(a.Email, "@") {
+		return errInvalidEmail
+	}
+	return nil
+}
+
+const accountSchema = `CREATE TABLE IF NOT EXISTS accounts (
+	id    INTEGER NOT NULL,
+	email TEXT NOT NULL,
+	PRIMARY KEY (id)
+);`
+
+func testValidation(t *testing.T, store Storer[*Account]) {
+	ctx := t.Context()
+
+	valid := &Account{ID: 1, Email: "me@example.com"}
+	invalid := &Account{ID: 2, Email: "example.com"}
+
+	err := store.Create(ctx, valid, invalid)
+
+	var verr *ValidationError
+
+	if !errors.As(err, &verr) {
+		t.Fatalf("store.Create(ctx, valid, invalid): %v, want = %T\n", err, verr)
+	}
+
+	if verr.Model != invalid {
+		t.Fatalf("verr.Model = %v, want = %v\n", verr.Model, invalid)
+	}
+
+	if !errors.Is(err, errInvalidEmail) {
+		t.Fatalf("errors.Is(%v, %v) = %v, want = %v\n", err, errInvalidEmail, false, true)
+	}
+
+	// Nothing should be created if any model is invalid.
+	n, err := store.Count(ctx)
+
+	if err != nil {
+		t.Fatalf("store.Count(ctx): %v\n", err)
+	}
+
+	if n != 0 {
+		t.Fatalf("n = %v, want = %v\n", n, 0)
+	}
+
+	if err := store.Create(ctx, valid); err != nil {
+		t.Fatalf("store.Create(ctx, valid): %v\n", err)
+	}
+
+	valid.Email = "example.com"
+
+	if _, err := store.Update(ctx, valid); !errors.Is(err, errInvalidEmail) {
+		t.Fatalf("store.Update(ctx, valid): %v, want = %v\n", err, errInvalidEmail)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	db := NewDB(t)
+
+	if _, err := db.ExecContext(t.Context(), accountSchema); err != nil {
+		t.Fatalf("db.ExecContext(ctx, %q): %v\n", accountSchema, err)
+	}
+
+	testValidation(t, NewStore(db, func() *Account {
+		return &Account{}
+	}))
+}
+
+func TestMemoryStoreValidation(t *testing.T) {
+	testValidation(t, NewMemoryStore(func() *Account {
+		return &Account{}
+	}))
+}